@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	repo_model "code.gitea.io/gitea/models/repo"
+	catalog_indexer "code.gitea.io/gitea/modules/indexer/catalog"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/storage"
 	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
@@ -18,7 +19,7 @@ import (
 var CmdDoor43Metadata = &cli.Command{
 	Name:        "door43metadata",
 	Usage:       "Scan repo(s) for the Door43 Metadata",
-	Description: "A command to update all repos or a repo's Door43 Metadata",
+	Description: "A command to update all repos or a repo's Door43 Metadata, or to rebuild the catalog search index",
 	Action:      runDoor43Metadata,
 	Flags: []cli.Flag{
 		&cli.StringFlag{
@@ -33,6 +34,14 @@ var CmdDoor43Metadata = &cli.Command{
 			Value:   "",
 			Usage:   `Name of a single repo to generate the door43metadata. "owner" must also be set for this to be accepted`,
 		},
+		&cli.BoolFlag{
+			Name:  "force",
+			Usage: `Process all the refs of the repos, including the ones whose commit hasn't changed since they were last processed`,
+		},
+		&cli.BoolFlag{
+			Name:  "rebuild-index",
+			Usage: `Rebuild the catalog search index from the existing door43metadata instead of scanning repos. Gitea must not be running when using the bleve indexer`,
+		},
 	},
 }
 
@@ -53,6 +62,14 @@ func runDoor43Metadata(ctx *cli.Context) error {
 		return err
 	}
 
+	if ctx.Bool("rebuild-index") {
+		if err := catalog_indexer.RebuildCatalogIndexer(stdCtx); err != nil {
+			return err
+		}
+		log.Info("Finished rebuilding the catalog index")
+		return nil
+	}
+
 	if err := storage.Init(); err != nil {
 		return err
 	}
@@ -62,10 +79,10 @@ func runDoor43Metadata(ctx *cli.Context) error {
 		if err != nil {
 			return err
 		}
-		return door43metadata_service.ProcessDoor43MetadataForRepo(stdCtx, repo, "")
+		return door43metadata_service.ProcessDoor43MetadataForRepo(stdCtx, repo, "", repo_model.Door43MetadataTriggerCommandLine)
 	}
 
-	err := door43metadata_service.UpdateDoor43Metadata(stdCtx)
+	err := door43metadata_service.UpdateDoor43Metadata(stdCtx, repo_model.Door43MetadataTriggerCommandLine, ctx.Bool("force"))
 	if err != nil {
 		return err
 	}
@@ -20,6 +20,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
 
 	"xorm.io/builder"
 	"xorm.io/xorm"
@@ -50,6 +51,10 @@ const (
 	NotificationSourceCommit
 	// NotificationSourceRepository is a notification for a repository
 	NotificationSourceRepository
+	/*** DCS Customizations ***/
+	// NotificationSourceCatalogEntry is a notification of a new catalog entry of a release, its tag name is the CommitID
+	NotificationSourceCatalogEntry
+	/*** END DCS Customizations ***/
 )
 
 // Notification represents a notification
@@ -172,6 +177,29 @@ func CreateRepoTransferNotification(ctx context.Context, doer, newOwner *user_mo
 	})
 }
 
+/*** DCS Customizations ***/
+
+// CreateCatalogEntryNotifications creates a notification of a new catalog entry of a release for each of the users
+func CreateCatalogEntryNotifications(ctx context.Context, userIDs []int64, repo *repo_model.Repository, tagName string, updatedBy int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	notify := make([]*Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		notify = append(notify, &Notification{
+			UserID:    userID,
+			RepoID:    repo.ID,
+			Status:    NotificationStatusUnread,
+			UpdatedBy: updatedBy,
+			Source:    NotificationSourceCatalogEntry,
+			CommitID:  tagName,
+		})
+	}
+	return db.Insert(ctx, notify)
+}
+
+/*** END DCS Customizations ***/
+
 // CreateOrUpdateIssueNotifications creates an issue notification
 // for each watcher, or updates it if already exists
 // receiverID > 0 just send to receiver, else send to all watcher
@@ -456,6 +484,8 @@ func (n *Notification) HTMLURL(ctx context.Context) string {
 		return n.Repository.HTMLURL() + "/commit/" + url.PathEscape(n.CommitID)
 	case NotificationSourceRepository:
 		return n.Repository.HTMLURL()
+	case NotificationSourceCatalogEntry: // DCS Customizations
+		return n.Repository.HTMLURL() + "/releases/tag/" + util.PathEscapeSegments(n.CommitID) // DCS Customizations
 	}
 	return ""
 }
@@ -472,6 +502,8 @@ func (n *Notification) Link(ctx context.Context) string {
 		return n.Repository.Link() + "/commit/" + url.PathEscape(n.CommitID)
 	case NotificationSourceRepository:
 		return n.Repository.Link()
+	case NotificationSourceCatalogEntry: // DCS Customizations
+		return n.Repository.Link() + "/releases/tag/" + util.PathEscapeSegments(n.CommitID) // DCS Customizations
 	}
 	return ""
 }
@@ -481,6 +513,15 @@ func (n *Notification) APIURL() string {
 	return setting.AppURL + "api/v1/notifications/threads/" + strconv.FormatInt(n.ID, 10)
 }
 
+/*** DCS Customizations ***/
+
+// IsCatalogEntry returns true if the notification is of a new catalog entry
+func (n *Notification) IsCatalogEntry() bool {
+	return n.Source == NotificationSourceCatalogEntry
+}
+
+/*** END DCS Customizations ***/
+
 // NotificationList contains a list of notifications
 type NotificationList []*Notification
 
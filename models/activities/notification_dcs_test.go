@@ -0,0 +1,30 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package activities_test
+
+import (
+	"testing"
+
+	activities_model "code.gitea.io/gitea/models/activities"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCatalogEntryNotifications(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+
+	assert.NoError(t, activities_model.CreateCatalogEntryNotifications(db.DefaultContext, []int64{4}, repo, "v1.1", 2))
+
+	notf := unittest.AssertExistsAndLoadBean(t, &activities_model.Notification{UserID: 4, RepoID: repo.ID, CommitID: "v1.1"})
+	assert.True(t, notf.IsCatalogEntry())
+	assert.NoError(t, notf.LoadAttributes(db.DefaultContext))
+	assert.Equal(t, repo.Link()+"/releases/tag/v1.1", notf.Link(db.DefaultContext))
+
+	other := unittest.AssertExistsAndLoadBean(t, &activities_model.Notification{ID: 1})
+	assert.False(t, other.IsCatalogEntry())
+}
@@ -32,6 +32,7 @@ type Statistic struct {
 		ProjectBoard, Attachment int64
 		IssueByLabel      []IssueByLabelCount
 		IssueByRepository []IssueByRepositoryCount
+		Door43Downloads   []*repo_model.Door43DownloadCount // DCS Customizations
 	}
 }
 
@@ -84,6 +85,18 @@ func GetStatistic(ctx context.Context) (stats Statistic) {
 			Find(&stats.Counter.IssueByRepository)
 	}
 
+	/*** DCS Customizations ***/
+	if setting.Metrics.EnabledDoor43Downloads {
+		stats.Counter.Door43Downloads, _ = repo_model.GetDoor43DownloadCounts(ctx, &repo_model.Door43DownloadStatsOptions{
+			GroupBy: []repo_model.Door43DownloadGroupBy{
+				repo_model.Door43DownloadGroupByLanguage,
+				repo_model.Door43DownloadGroupBySubject,
+				repo_model.Door43DownloadGroupByKind,
+			},
+		})
+	}
+	/*** END DCS Customizations ***/
+
 	var issueCounts []IssueCount
 
 	_ = e.Select("COUNT(*) AS count, is_closed").Table("issue").GroupBy("is_closed").Find(&issueCounts)
@@ -0,0 +1,193 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package asymkey
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// Door43SigningKeyAlgorithm is the algorithm used by Door43 signing keys
+const Door43SigningKeyAlgorithm = "ed25519"
+
+// Door43SigningKey represents a key used to sign the catalog entries of an owner (user or org).
+// An OwnerID of 0 is the instance key, used for owners that do not have their own key.
+type Door43SigningKey struct {
+	ID                  int64              `xorm:"pk autoincr"`
+	OwnerID             int64              `xorm:"UNIQUE NOT NULL"`
+	KeyID               string             `xorm:"INDEX CHAR(16) NOT NULL"`
+	PublicKey           string             `xorm:"TEXT NOT NULL"`
+	PrivateKeyEncrypted string             `xorm:"TEXT NOT NULL"`
+	CreatedUnix         timeutil.TimeStamp `xorm:"INDEX created NOT NULL"`
+}
+
+func init() {
+	db.RegisterModel(new(Door43SigningKey))
+}
+
+// IsInstanceKey returns true if the key is the instance key
+func (key *Door43SigningKey) IsInstanceKey() bool {
+	return key.OwnerID == 0
+}
+
+// PublicKeyBytes returns the raw ed25519 public key
+func (key *Door43SigningKey) PublicKeyBytes() (ed25519.PublicKey, error) {
+	pub, err := base64.StdEncoding.DecodeString(key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size for key %s: %d", key.KeyID, len(pub))
+	}
+	return pub, nil
+}
+
+// PublicKeyPEM returns the public key PEM encoded in PKIX format
+func (key *Door43SigningKey) PublicKeyPEM() (string, error) {
+	pub, err := key.PublicKeyBytes()
+	if err != nil {
+		return "", err
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// Sign signs the data with the private key, returning the base64 encoded signature
+func (key *Door43SigningKey) Sign(data []byte) (string, error) {
+	seed, err := secret.DecryptSecret(setting.SecretKey, key.PrivateKeyEncrypted)
+	if err != nil {
+		return "", err
+	}
+	seedBytes, err := base64.StdEncoding.DecodeString(seed)
+	if err != nil {
+		return "", err
+	}
+	if len(seedBytes) != ed25519.SeedSize {
+		return "", fmt.Errorf("invalid private key size for key %s: %d", key.KeyID, len(seedBytes))
+	}
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(ed25519.NewKeyFromSeed(seedBytes), data)), nil
+}
+
+// Door43SigningKeyID returns the key ID of a public key, the first 8 bytes of its SHA256 hash in hex
+func Door43SigningKeyID(pub ed25519.PublicKey) string {
+	hash := sha256.Sum256(pub)
+	return hex.EncodeToString(hash[:8])
+}
+
+// GetDoor43SigningKey returns the signing key of an owner, 0 being the instance key
+func GetDoor43SigningKey(ctx context.Context, ownerID int64) (*Door43SigningKey, error) {
+	key := &Door43SigningKey{}
+	// a condition rather than the bean's field, which would be ignored for the instance key's 0
+	has, err := db.GetEngine(ctx).Where(builder.Eq{"owner_id": ownerID}).Get(key)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDoor43SigningKeyNotExist{ownerID}
+	}
+	return key, nil
+}
+
+// CreateDoor43SigningKey generates and stores a new signing key for an owner, 0 being the instance key
+func CreateDoor43SigningKey(ctx context.Context, ownerID int64) (*Door43SigningKey, error) {
+	if _, err := GetDoor43SigningKey(ctx, ownerID); err == nil {
+		return nil, ErrDoor43SigningKeyAlreadyExist{ownerID}
+	} else if !IsErrDoor43SigningKeyNotExist(err) {
+		return nil, err
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := secret.EncryptSecret(setting.SecretKey, base64.StdEncoding.EncodeToString(priv.Seed()))
+	if err != nil {
+		return nil, err
+	}
+	key := &Door43SigningKey{
+		OwnerID:             ownerID,
+		KeyID:               Door43SigningKeyID(pub),
+		PublicKey:           base64.StdEncoding.EncodeToString(pub),
+		PrivateKeyEncrypted: encrypted,
+	}
+	if _, err := db.GetEngine(ctx).Insert(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// InitInstanceDoor43SigningKey generates the instance signing key if it does not yet exist
+func InitInstanceDoor43SigningKey(ctx context.Context) error {
+	_, err := GetDoor43SigningKey(ctx, 0)
+	if err == nil || !IsErrDoor43SigningKeyNotExist(err) {
+		return err
+	}
+	_, err = CreateDoor43SigningKey(ctx, 0)
+	if IsErrDoor43SigningKeyAlreadyExist(err) {
+		// Created by another instance in the meantime
+		return nil
+	}
+	return err
+}
+
+// DeleteDoor43SigningKey deletes the signing key of an owner
+func DeleteDoor43SigningKey(ctx context.Context, ownerID int64) error {
+	_, err := db.GetEngine(ctx).Where(builder.Eq{"owner_id": ownerID}).Delete(&Door43SigningKey{})
+	return err
+}
+
+// ErrDoor43SigningKeyNotExist represents a "Door43SigningKeyNotExist" kind of error.
+type ErrDoor43SigningKeyNotExist struct {
+	OwnerID int64
+}
+
+// IsErrDoor43SigningKeyNotExist checks if an error is a ErrDoor43SigningKeyNotExist.
+func IsErrDoor43SigningKeyNotExist(err error) bool {
+	_, ok := err.(ErrDoor43SigningKeyNotExist)
+	return ok
+}
+
+func (err ErrDoor43SigningKeyNotExist) Error() string {
+	return fmt.Sprintf("door43 signing key does not exist [owner_id: %d]", err.OwnerID)
+}
+
+func (err ErrDoor43SigningKeyNotExist) Unwrap() error {
+	return util.ErrNotExist
+}
+
+// ErrDoor43SigningKeyAlreadyExist represents a "Door43SigningKeyAlreadyExist" kind of error.
+type ErrDoor43SigningKeyAlreadyExist struct {
+	OwnerID int64
+}
+
+// IsErrDoor43SigningKeyAlreadyExist checks if an error is a ErrDoor43SigningKeyAlreadyExist.
+func IsErrDoor43SigningKeyAlreadyExist(err error) bool {
+	_, ok := err.(ErrDoor43SigningKeyAlreadyExist)
+	return ok
+}
+
+func (err ErrDoor43SigningKeyAlreadyExist) Error() string {
+	return fmt.Sprintf("door43 signing key already exists [owner_id: %d]", err.OwnerID)
+}
+
+func (err ErrDoor43SigningKeyAlreadyExist) Unwrap() error {
+	return util.ErrAlreadyExist
+}
@@ -60,7 +60,7 @@ func SearchCatalogByCondition(ctx context.Context, opts *door43metadata.SearchCa
 			0, err
 	}
 
-	sess := db.GetEngine(db.DefaultContext).
+	sess := db.GetEngine(ctx).
 		Join("INNER", "repository", "`repository`.id = `door43_metadata`.repo_id").
 		Join("INNER", "user", "`repository`.owner_id = `user`.id").
 		Join("LEFT", "release", "`release`.id = `door43_metadata`.release_id").
@@ -68,6 +68,12 @@ func SearchCatalogByCondition(ctx context.Context, opts *door43metadata.SearchCa
 		Where(cond)
 
 	for _, orderBy := range opts.OrderBy {
+		if orderBy == door43metadata.CatalogOrderByRelevance {
+			if opts.UseMatchedIDs && len(opts.MatchedIDs) > 0 {
+				sess.OrderBy(door43metadata.GetRelevanceOrderBy(opts.MatchedIDs))
+			}
+			continue
+		}
 		sess.OrderBy(orderBy.String())
 	}
 
@@ -100,10 +106,11 @@ func SearchDoor43MetadataFieldByCondition(ctx context.Context, opts *door43metad
 		field = "`door43_metadata`." + field
 	}
 
-	sess := db.GetEngine(db.DefaultContext).Table("door43_metadata").
+	sess := db.GetEngine(ctx).Table("door43_metadata").
 		Select("DISTINCT "+field).
 		Join("INNER", "repository", "`repository`.id = `door43_metadata`.repo_id").
 		Join("INNER", "user", "`repository`.owner_id = `user`.id").
+		Join("LEFT", "release", "`release`.id = `door43_metadata`.release_id").
 		Where(cond).
 		OrderBy(field)
 
@@ -114,3 +121,88 @@ func SearchDoor43MetadataFieldByCondition(ctx context.Context, opts *door43metad
 
 	return results, nil
 }
+
+// CatalogFacetCount is the number of entries of a catalog search having a value of a facet
+type CatalogFacetCount struct {
+	Value string
+	Count int64
+}
+
+// CountCatalogFacets counts the entries matching the search options by the values of each facet, most common values first
+func CountCatalogFacets(ctx context.Context, opts *door43metadata.SearchCatalogOptions, facets []door43metadata.CatalogFacet) (map[door43metadata.CatalogFacet][]*CatalogFacetCount, error) {
+	cond := door43metadata.SearchCatalogCondition(opts)
+	return CountCatalogFacetsByCondition(ctx, cond, facets)
+}
+
+// CountCatalogFacetsByCondition counts the entries matching the condition by the values of each facet, most common values first
+func CountCatalogFacetsByCondition(ctx context.Context, cond builder.Cond, facets []door43metadata.CatalogFacet) (map[door43metadata.CatalogFacet][]*CatalogFacetCount, error) {
+	results := make(map[door43metadata.CatalogFacet][]*CatalogFacetCount, len(facets))
+	for _, facet := range facets {
+		var counts []*CatalogFacetCount
+		var err error
+		if facet == door43metadata.CatalogFacetBook {
+			counts, err = countCatalogBooks(ctx, cond)
+		} else {
+			counts, err = countCatalogFacet(ctx, cond, door43metadata.CatalogFacetColumns[facet])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("count facet %s: %w", facet, err)
+		}
+		results[facet] = counts
+	}
+	return results, nil
+}
+
+func countCatalogFacet(ctx context.Context, cond builder.Cond, column string) ([]*CatalogFacetCount, error) {
+	rows := make([]*struct {
+		Value string `xorm:"value"`
+		Num   int64  `xorm:"num"`
+	}, 0, 20)
+	err := db.GetEngine(ctx).Table("door43_metadata").
+		Select(column+" AS value, COUNT(*) AS num").
+		Join("INNER", "repository", "`repository`.id = `door43_metadata`.repo_id").
+		Join("INNER", "user", "`repository`.owner_id = `user`.id").
+		Join("LEFT", "release", "`release`.id = `door43_metadata`.release_id").
+		Where(cond).
+		GroupBy(column).
+		OrderBy("num DESC, value").
+		Find(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]*CatalogFacetCount, 0, len(rows))
+	for _, row := range rows {
+		if row.Value != "" {
+			counts = append(counts, &CatalogFacetCount{Value: row.Value, Count: row.Num})
+		}
+	}
+	return counts, nil
+}
+
+// countCatalogBooks counts the entries by the books of their ingredients, which are stored lower case and once per entry
+func countCatalogBooks(ctx context.Context, cond builder.Cond) ([]*CatalogFacetCount, error) {
+	rows := make([]*struct {
+		Value string `xorm:"value"`
+		Num   int64  `xorm:"num"`
+	}, 0, 70)
+	err := db.GetEngine(ctx).Table("door43_metadata_ingredient").
+		Select("`door43_metadata_ingredient`.identifier AS value, COUNT(DISTINCT `door43_metadata`.id) AS num").
+		Join("INNER", "door43_metadata", "`door43_metadata`.id = `door43_metadata_ingredient`.door43_metadata_id").
+		Join("INNER", "repository", "`repository`.id = `door43_metadata`.repo_id").
+		Join("INNER", "user", "`repository`.owner_id = `user`.id").
+		Join("LEFT", "release", "`release`.id = `door43_metadata`.release_id").
+		Where(cond).
+		GroupBy("`door43_metadata_ingredient`.identifier").
+		OrderBy("num DESC, value").
+		Find(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]*CatalogFacetCount, 0, len(rows))
+	for _, row := range rows {
+		counts = append(counts, &CatalogFacetCount{Value: row.Value, Count: row.Num})
+	}
+	return counts, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountCatalogFacets(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, dm := range []*repo_model.Door43Metadata{
+		{RepoID: 1, ReleaseID: 1, Ref: "v1.1", RefType: "tag", Stage: door43metadata.StageProd, IsLatestForStage: true, MetadataType: "rc",
+			Subject: "Bible", Language: "en", Ingredients: []*structs.Ingredient{{Identifier: "GEN"}, {Identifier: "gen"}, {Identifier: "exo"}}},
+		{RepoID: 1, Ref: "master", RefType: "branch", Stage: door43metadata.StageLatest, IsLatestForStage: true, MetadataType: "rc",
+			Subject: "Bible", Language: "en", Ingredients: []*structs.Ingredient{{Identifier: "gen"}}},
+		{RepoID: 4, Ref: "master", RefType: "branch", Stage: door43metadata.StageLatest, IsLatestForStage: true, MetadataType: "sb",
+			Subject: "Translation Notes", Language: "fr", Ingredients: []*structs.Ingredient{{Identifier: "gen"}}},
+	} {
+		assert.NoError(t, db.Insert(db.DefaultContext, dm))
+		assert.NoError(t, repo_model.SyncDoor43MetadataIngredients(db.DefaultContext, dm))
+	}
+
+	facets := []door43metadata.CatalogFacet{door43metadata.CatalogFacetLanguage, door43metadata.CatalogFacetSubject, door43metadata.CatalogFacetBook}
+	counts, err := CountCatalogFacets(db.DefaultContext, &door43metadata.SearchCatalogOptions{Stage: door43metadata.StageLatest}, facets)
+	assert.NoError(t, err)
+	assert.Equal(t, []*CatalogFacetCount{{Value: "en", Count: 2}, {Value: "fr", Count: 1}}, counts[door43metadata.CatalogFacetLanguage])
+	assert.Equal(t, []*CatalogFacetCount{{Value: "Bible", Count: 2}, {Value: "Translation Notes", Count: 1}}, counts[door43metadata.CatalogFacetSubject])
+	// books are counted case-insensitively and once per entry
+	assert.Equal(t, []*CatalogFacetCount{{Value: "gen", Count: 3}, {Value: "exo", Count: 1}}, counts[door43metadata.CatalogFacetBook])
+
+	// the tag filter needs the release table joined
+	counts, err = CountCatalogFacets(db.DefaultContext, &door43metadata.SearchCatalogOptions{Stage: door43metadata.StageLatest, Tags: []string{"v1.1"}}, facets)
+	assert.NoError(t, err)
+	assert.Equal(t, []*CatalogFacetCount{{Value: "en", Count: 1}}, counts[door43metadata.CatalogFacetLanguage])
+	assert.Equal(t, []*CatalogFacetCount{{Value: "exo", Count: 1}, {Value: "gen", Count: 1}}, counts[door43metadata.CatalogFacetBook])
+}
+
+func TestSearchCatalogMatchedIDs(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	dm1 := &repo_model.Door43Metadata{RepoID: 1, Ref: "master", RefType: "branch", Stage: door43metadata.StageLatest, IsLatestForStage: true,
+		MetadataType: "rc", Subject: "Bible", Language: "en", ReleaseDateUnix: 1}
+	dm2 := &repo_model.Door43Metadata{RepoID: 4, Ref: "master", RefType: "branch", Stage: door43metadata.StageLatest, IsLatestForStage: true,
+		MetadataType: "rc", Subject: "Bible", Language: "fr", ReleaseDateUnix: 1}
+	assert.NoError(t, db.Insert(db.DefaultContext, dm1, dm2))
+
+	// more matched IDs than some databases allow parameters, the most relevant first
+	matchedIDs := []int64{dm2.ID, dm1.ID}
+	for id := dm2.ID + 1000; len(matchedIDs) < 3000; id++ {
+		matchedIDs = append(matchedIDs, id)
+	}
+	dms, count, err := SearchCatalog(db.DefaultContext, &door43metadata.SearchCatalogOptions{
+		Stage:         door43metadata.StageLatest,
+		Keywords:      []string{"bible"},
+		MatchedIDs:    matchedIDs,
+		UseMatchedIDs: true,
+		OrderBy:       []door43metadata.CatalogOrderBy{door43metadata.CatalogOrderByRelevance, door43metadata.CatalogOrderByRepoName},
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	if assert.Len(t, dms, 2) {
+		assert.Equal(t, dm2.ID, dms[0].ID)
+		assert.Equal(t, dm1.ID, dms[1].ID)
+	}
+
+	dms, count, err = SearchCatalog(db.DefaultContext, &door43metadata.SearchCatalogOptions{
+		Stage:         door43metadata.StageLatest,
+		Keywords:      []string{"obs"},
+		UseMatchedIDs: true,
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+	assert.Empty(t, dms)
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+)
+
+// testLangnames is the custom langnames.json of the tests, which is only read the first time the languages are needed
+const testLangnames = `[
+	{"lc": "en", "lr": "Europe", "cc": ["GB", "US", "CA"], "gw": true},
+	{"lc": "fr", "lr": "Europe", "cc": ["FR", "CA", "CI"], "gw": true},
+	{"lc": "es-419", "lr": "Americas", "cc": ["MX", "US"], "gw": true},
+	{"lc": "nv", "lr": "Americas", "cc": ["US"], "gw": false},
+	{"lc": "bci", "lr": "Africa", "cc": ["CI"], "gw": false},
+	{"lc": "x') OR ('1", "lr": "Africa", "cc": ["CI"], "gw": false}
+]`
+
+func condToSQL(t *testing.T, cond builder.Cond) string {
+	sql, args, err := builder.ToSQL(cond)
+	assert.NoError(t, err)
+	assert.Empty(t, args)
+	return sql
+}
+
+func TestLanguageConds(t *testing.T) {
+	customPath := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(customPath, "options", "languages"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(customPath, "options", "languages", "langnames.json"), []byte(testLangnames), 0o644))
+	defer test.MockVariableValue(&setting.CustomPath, customPath)()
+
+	assert.Equal(t, []string{"en", "es-419"}, dcs.GetGatewayLanguages("nv"))
+	assert.Equal(t, []string{"es-419", "fr"}, dcs.GetGatewayLanguages("en"))
+	assert.Equal(t, []string{"en"}, dcs.GetGatewayLanguages("fr"))
+	assert.Empty(t, dcs.GetGatewayLanguages("xx"))
+
+	assert.Equal(t, []string{"nv", "bci", "fr", "en", "es-419"}, AddGatewayLanguages([]string{"nv", "bci, fr", "nv"}))
+	assert.Empty(t, AddGatewayLanguages(nil))
+
+	assert.Equal(t, "`door43_metadata`.language IN ('bci')", condToSQL(t, GetRegionCond([]string{"africa"})))
+	assert.Equal(t, "`door43_metadata`.language IN ('en','fr','bci')", condToSQL(t, GetRegionCond([]string{"Europe,AFRICA"})))
+	assert.Equal(t, "1 = 0", condToSQL(t, GetRegionCond([]string{"Antarctica"})))
+	assert.False(t, GetRegionCond(nil).IsValid())
+
+	assert.Equal(t, "`door43_metadata`.language IN ('bci','en','es-419','fr','nv')", condToSQL(t, GetCountryCond([]string{"us", "CI"})))
+	assert.Equal(t, "`door43_metadata`.language IN ('en','fr')", condToSQL(t, GetCountryCond([]string{"CA"})))
+	assert.Equal(t, "1 = 0", condToSQL(t, GetCountryCond([]string{"AQ"})))
+	assert.False(t, GetCountryCond(nil).IsValid())
+
+	// no parameters are bound however many languages there are
+	langs := make([]string, 5000)
+	for i := range langs {
+		langs[i] = "en"
+	}
+	sql := condToSQL(t, getLanguageCodesCond(langs))
+	assert.Equal(t, 5000, strings.Count(sql, "'en'"))
+}
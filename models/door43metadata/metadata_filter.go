@@ -0,0 +1,168 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+
+	"xorm.io/builder"
+)
+
+// MetadataFilterPrefix is the prefix of the search parameters and tokens filtering by a metadata field,
+// e.g. metadata.dublin_core.publisher=unfoldingWord
+const MetadataFilterPrefix = "metadata."
+
+const maxMetadataFilterPathLength = 10
+
+var metadataFilterKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_\-]+$`)
+
+// MetadataFilter filters entries by the value of a field of their metadata (manifest)
+type MetadataFilter struct {
+	Path   []string // keys of objects or indexes of arrays leading to the field
+	Values []string // the field, or an element of the field if it is an array, must equal (or contain if partial) one of them
+}
+
+// ParseMetadataFilter parses the dot separated path to a metadata field, e.g. dublin_core.publisher, and the values to filter by
+func ParseMetadataFilter(path string, values []string) (*MetadataFilter, error) {
+	keys := strings.Split(strings.TrimPrefix(path, MetadataFilterPrefix), ".")
+	if len(keys) > maxMetadataFilterPathLength {
+		return nil, fmt.Errorf("invalid metadata path [%s]: more than %d keys", path, maxMetadataFilterPathLength)
+	}
+	for _, key := range keys {
+		if !metadataFilterKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid metadata path [%s]: keys can only contain letters, numbers, '_' and '-'", path)
+		}
+	}
+	filter := &MetadataFilter{Path: keys}
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			filter.Values = append(filter.Values, v)
+		}
+	}
+	if len(filter.Values) == 0 {
+		return nil, fmt.Errorf("invalid metadata filter [%s]: no value", path)
+	}
+	return filter, nil
+}
+
+// isArrayIndex returns true if the key is an index of an array rather than a key of an object
+func isArrayIndex(key string) bool {
+	_, err := strconv.Atoi(key)
+	return err == nil
+}
+
+// jsonPath returns the path as a JSON path of MySQL, SQLite and MSSQL, e.g. $."dublin_core"."contributor"[0]
+func (f *MetadataFilter) jsonPath() string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, key := range f.Path {
+		if isArrayIndex(key) {
+			sb.WriteString("[" + key + "]")
+		} else {
+			sb.WriteString(`."` + key + `"`)
+		}
+	}
+	return sb.String()
+}
+
+// postgresPath returns the path as a PostgreSQL text array, e.g. {"dublin_core","contributor","0"}
+func (f *MetadataFilter) postgresPath() string {
+	return `{"` + strings.Join(f.Path, `","`) + `"}`
+}
+
+// hasArrayIndex returns true if the path goes through an array
+func (f *MetadataFilter) hasArrayIndex() bool {
+	for _, key := range f.Path {
+		if isArrayIndex(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// containmentDocs returns the JSON documents a PostgreSQL jsonb metadata contains if the field, or an element
+// of it if it is an array, equals the value as string, number or boolean. Using containment lets the
+// GIN index on the metadata be used
+func (f *MetadataFilter) containmentDocs(value string) []string {
+	scalars := []any{value}
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		scalars = append(scalars, n)
+	}
+	if b, err := strconv.ParseBool(value); err == nil && (value == "true" || value == "false") {
+		scalars = append(scalars, b)
+	}
+	docs := make([]string, 0, len(scalars)*2)
+	for _, scalar := range scalars {
+		for _, field := range []any{scalar, []any{scalar}} {
+			for i := len(f.Path) - 1; i >= 0; i-- {
+				field = map[string]any{f.Path[i]: field}
+			}
+			bs, err := json.Marshal(field)
+			if err == nil {
+				docs = append(docs, string(bs))
+			}
+		}
+	}
+	return docs
+}
+
+// valueCond returns the condition of the field, or an element of it if it is an array, matching the value
+// in the SQL of the configured database
+func (f *MetadataFilter) valueCond(value string, partialMatch bool) builder.Cond {
+	const column = "`door43_metadata`.metadata"
+	pattern := "%" + value + "%"
+	switch {
+	case setting.Database.Type.IsMySQL():
+		if partialMatch {
+			return builder.Expr("JSON_UNQUOTE(JSON_EXTRACT("+column+", ?)) LIKE ?", f.jsonPath(), pattern)
+		}
+		return builder.Expr("(JSON_CONTAINS(JSON_EXTRACT("+column+", ?), JSON_QUOTE(?)) OR JSON_UNQUOTE(JSON_EXTRACT("+column+", ?)) = ?)",
+			f.jsonPath(), value, f.jsonPath(), value)
+	case setting.Database.Type.IsPostgreSQL():
+		if partialMatch {
+			return builder.Expr("("+column+"::jsonb #>> ?::text[]) LIKE ?", f.postgresPath(), pattern)
+		}
+		if f.hasArrayIndex() {
+			return builder.Expr("(("+column+"::jsonb #> ?::text[]) @> to_jsonb(?::text) OR ("+column+"::jsonb #>> ?::text[]) = ?)",
+				f.postgresPath(), value, f.postgresPath(), value)
+		}
+		cond := builder.NewCond()
+		for _, doc := range f.containmentDocs(value) {
+			cond = cond.Or(builder.Expr(column+"::jsonb @> ?::jsonb", doc))
+		}
+		return cond
+	case setting.Database.Type.IsMSSQL():
+		if partialMatch {
+			return builder.Expr("(JSON_VALUE("+column+", ?) LIKE ? OR EXISTS (SELECT 1 FROM OPENJSON("+column+", ?) WHERE [value] LIKE ?))",
+				f.jsonPath(), pattern, f.jsonPath(), pattern)
+		}
+		return builder.Expr("(JSON_VALUE("+column+", ?) = ? OR EXISTS (SELECT 1 FROM OPENJSON("+column+", ?) WHERE [value] = ?))",
+			f.jsonPath(), value, f.jsonPath(), value)
+	default:
+		// SQLite's json_each walks the elements of an array or the value itself if it isn't one
+		if partialMatch {
+			return builder.Expr("EXISTS (SELECT 1 FROM json_each("+column+", ?) WHERE CAST(json_each.value AS TEXT) LIKE ?)", f.jsonPath(), pattern)
+		}
+		return builder.Expr("EXISTS (SELECT 1 FROM json_each("+column+", ?) WHERE CAST(json_each.value AS TEXT) = ?)", f.jsonPath(), value)
+	}
+}
+
+// GetMetadataFilterCond gets the condition of the entries matching all the metadata filters
+func GetMetadataFilterCond(filters []*MetadataFilter, partialMatch bool) builder.Cond {
+	cond := builder.NewCond()
+	for _, filter := range filters {
+		valuesCond := builder.NewCond()
+		for _, value := range filter.Values {
+			valuesCond = valuesCond.Or(filter.valueCond(value, partialMatch))
+		}
+		cond = cond.And(valuesCond)
+	}
+	return cond
+}
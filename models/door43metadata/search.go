@@ -4,9 +4,14 @@
 package door43metadata
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 
 	"xorm.io/builder"
@@ -43,8 +48,67 @@ const (
 	CatalogOrderByStarsReverse       CatalogOrderBy = "`repository`.num_stars DESC"
 	CatalogOrderByForks              CatalogOrderBy = "`repository`.num_forks ASC"
 	CatalogOrderByForksReverse       CatalogOrderBy = "`repository`.num_forks DESC"
+	CatalogOrderByRelevance          CatalogOrderBy = "relevance" // order of the MatchedIDs, ignored if not using them
 )
 
+// CatalogFacet is a field the entries of a catalog search can be counted by
+type CatalogFacet string
+
+// The facets of a catalog search, named after their filter parameters
+const (
+	CatalogFacetSubject       CatalogFacet = "subject"
+	CatalogFacetLanguage      CatalogFacet = "lang"
+	CatalogFacetOwner         CatalogFacet = "owner"
+	CatalogFacetMetadataType  CatalogFacet = "metadataType"
+	CatalogFacetCheckingLevel CatalogFacet = "checkingLevel"
+	CatalogFacetBook          CatalogFacet = "book"
+)
+
+// CatalogFacets are all the facets of a catalog search
+var CatalogFacets = []CatalogFacet{
+	CatalogFacetSubject,
+	CatalogFacetLanguage,
+	CatalogFacetOwner,
+	CatalogFacetMetadataType,
+	CatalogFacetCheckingLevel,
+	CatalogFacetBook,
+}
+
+// CatalogFacetColumns are the columns the facets are counted by. The book facet is counted from the ingredient table
+var CatalogFacetColumns = map[CatalogFacet]string{
+	CatalogFacetSubject:       "`door43_metadata`.subject",
+	CatalogFacetLanguage:      "`door43_metadata`.language",
+	CatalogFacetOwner:         "`user`.name",
+	CatalogFacetMetadataType:  "`door43_metadata`.metadata_type",
+	CatalogFacetCheckingLevel: "`door43_metadata`.checking_level",
+}
+
+// ParseCatalogFacets returns the facets of the given names, "all" being all the facets
+func ParseCatalogFacets(names []string) ([]CatalogFacet, error) {
+	var facets []CatalogFacet
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if name == "all" {
+			return CatalogFacets, nil
+		}
+		found := false
+		for _, facet := range CatalogFacets {
+			if strings.EqualFold(name, string(facet)) {
+				facets = append(facets, facet)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid facet [%s]", name)
+		}
+	}
+	return facets, nil
+}
+
 // SearchCatalogOptions holds the search options
 type SearchCatalogOptions struct {
 	db.ListOptions
@@ -58,15 +122,22 @@ type SearchCatalogOptions struct {
 	Resources        []string
 	ContentFormats   []string
 	CheckingLevels   []string
+	VerifiedLevels   []int
 	Books            []string
+	MetadataFilters  []*MetadataFilter // fields of the metadata to match, e.g. dublin_core.publisher
 	IncludeHistory   bool
 	MetadataTypes    []string
 	MetadataVersions []string
 	ShowIngredients  util.OptionalBool
 	Languages        []string
 	LanguageIsGL     util.OptionalBool
+	Regions          []string // regions of the languages, e.g. Africa, as given by langnames.json
+	Countries        []string // codes of the countries the languages are spoken in, e.g. TZ
+	IncludeGLs       bool     // also match the gateway languages that serve the Languages
 	OrderBy          []CatalogOrderBy
 	PartialMatch     bool
+	MatchedIDs       []int64 // IDs of the entries matching the Keywords found by the catalog indexer, most relevant first
+	UseMatchedIDs    bool    // filter by MatchedIDs instead of matching the Keywords in the database
 }
 
 // GetMetadataCond Get the metadata condition
@@ -91,15 +162,24 @@ func SearchCatalogCondition(opts *SearchCatalogOptions) builder.Cond {
 	}
 
 	keywordCond := builder.NewCond()
-	for _, keyword := range opts.Keywords {
-		keywordCond = keywordCond.Or(builder.Like{"`repository`.lower_name", strings.TrimSpace(keyword)})
-		keywordCond = keywordCond.Or(builder.Like{"`user`.lower_name", strings.TrimSpace(keyword)})
-		keywordCond = keywordCond.Or(GetMetadataCond(keyword))
+	if opts.UseMatchedIDs {
+		keywordCond = GetMatchedIDsCond(opts.MatchedIDs)
+	} else {
+		for _, keyword := range opts.Keywords {
+			keywordCond = keywordCond.Or(builder.Like{"`repository`.lower_name", strings.TrimSpace(keyword)})
+			keywordCond = keywordCond.Or(builder.Like{"`user`.lower_name", strings.TrimSpace(keyword)})
+			keywordCond = keywordCond.Or(GetMetadataCond(keyword))
+		}
 	}
 
 	stageCond := GetStageCond(opts.Stage)
 	historyCond := GetHistoryCond(opts.IncludeHistory)
 
+	languages := opts.Languages
+	if opts.IncludeGLs {
+		languages = AddGatewayLanguages(languages)
+	}
+
 	langIsGLCond := builder.NewCond()
 	if opts.LanguageIsGL != util.OptionalBoolNone {
 		langIsGLCond = builder.Eq{"`door43_metadata`.language_is_gl": opts.LanguageIsGL.IsTrue()}
@@ -110,8 +190,12 @@ func SearchCatalogCondition(opts *SearchCatalogOptions) builder.Cond {
 		GetResourceCond(opts.Resources),
 		GetContentFormatCond(opts.ContentFormats, opts.PartialMatch),
 		GetBookCond(opts.Books),
-		GetLanguageCond(opts.Languages, opts.PartialMatch),
+		GetMetadataFilterCond(opts.MetadataFilters, opts.PartialMatch),
+		GetLanguageCond(languages, opts.PartialMatch),
+		GetRegionCond(opts.Regions),
+		GetCountryCond(opts.Countries),
 		GetCheckingLevelCond(opts.CheckingLevels),
+		GetVerifiedCheckingLevelCond(opts.VerifiedLevels),
 		GetMetadataTypeCond(opts.MetadataTypes, opts.PartialMatch),
 		GetTagCond(opts.Tags),
 		repoCond,
@@ -130,6 +214,45 @@ func SearchCatalogCondition(opts *SearchCatalogOptions) builder.Cond {
 	return cond
 }
 
+// MaxRelevanceRanks is the number of most relevant matched IDs ranked by the relevance order,
+// the less relevant matches come after them in the next order
+const MaxRelevanceRanks = 100
+
+// GetRelevanceOrderBy returns the ORDER BY clause which orders entries in the order of the first MaxRelevanceRanks matched IDs
+func GetRelevanceOrderBy(matchedIDs []int64) string {
+	if len(matchedIDs) == 0 {
+		return ""
+	}
+	if len(matchedIDs) > MaxRelevanceRanks {
+		matchedIDs = matchedIDs[:MaxRelevanceRanks]
+	}
+	var orderBy strings.Builder
+	orderBy.WriteString("CASE `door43_metadata`.id")
+	for i, id := range matchedIDs {
+		fmt.Fprintf(&orderBy, " WHEN %d THEN %d", id, i)
+	}
+	fmt.Fprintf(&orderBy, " ELSE %d END", len(matchedIDs))
+	return orderBy.String()
+}
+
+// GetMatchedIDsCond returns the condition of the entries being one of the matched IDs. The IDs are written in the SQL
+// rather than bound, since there can be more of them than the number of parameters some databases allow
+func GetMatchedIDsCond(matchedIDs []int64) builder.Cond {
+	if len(matchedIDs) == 0 {
+		return builder.Expr("1 = 0")
+	}
+	var in strings.Builder
+	in.WriteString("`door43_metadata`.id IN (")
+	for i, id := range matchedIDs {
+		if i > 0 {
+			in.WriteString(",")
+		}
+		in.WriteString(strconv.FormatInt(id, 10))
+	}
+	in.WriteString(")")
+	return builder.Expr(in.String())
+}
+
 // SplitAtCommaNotInString split s at commas, ignoring commas in strings.
 func SplitAtCommaNotInString(s string, requireSpaceAfterComma bool) []string {
 	var res []string
@@ -243,34 +366,134 @@ func GetMetadataVersionCond(versions []string, partialMatch bool) builder.Cond {
 	return versionCond
 }
 
-// GetLanguageCond gets the language condition
+// GetLanguageCond gets the language condition, matching the language of the entry or the language code
+// the repo's name starts with, e.g. "en" of en_ult
 func GetLanguageCond(languages []string, partialMatch bool) builder.Cond {
 	langCond := builder.NewCond()
 	for _, lang := range languages {
 		for _, v := range strings.Split(lang, ",") {
+			v = strings.TrimSpace(v)
 			if partialMatch {
 				langCond = langCond.
-					Or(builder.Like{"`door43_metadata`.language", strings.TrimSpace(v)}).
-					Or(builder.Like{"CONCAT(SUBSTRING_INDEX(`repository`.lower_name, '_', 1), '_')", strings.TrimSpace(v) + "\\_"})
+					Or(builder.Like{"`door43_metadata`.language", v}).
+					Or(builder.Expr(repoNameLanguageSQL()+" LIKE ?", "%"+v))
 			} else {
 				langCond = langCond.
-					Or(builder.Eq{"`door43_metadata`.language": strings.TrimSpace(v)}).
-					Or(builder.Eq{"CONCAT(SUBSTRING_INDEX(`repository`.lower_name, '_', 1), '_')": strings.TrimSpace(v) + "_"})
+					Or(builder.Eq{"`door43_metadata`.language": v}).
+					Or(builder.Expr(repoNameLanguageSQL()+" = ?", v))
 			}
 		}
 	}
 	return langCond
 }
 
-// GetBookCond gets the book condition
+// AddGatewayLanguages returns the languages with the gateway languages that serve them added
+func AddGatewayLanguages(languages []string) []string {
+	result := make([]string, 0, len(languages))
+	seen := make(map[string]bool)
+	add := func(lang string) {
+		if lang != "" && !seen[lang] {
+			seen[lang] = true
+			result = append(result, lang)
+		}
+	}
+	for _, lang := range languages {
+		for _, v := range strings.Split(lang, ",") {
+			add(strings.TrimSpace(v))
+		}
+	}
+	for _, lang := range result {
+		for _, gl := range dcs.GetGatewayLanguages(lang) {
+			add(gl)
+		}
+	}
+	return result
+}
+
+// GetRegionCond gets the condition of the languages of the regions
+func GetRegionCond(regions []string) builder.Cond {
+	if len(regions) == 0 {
+		return builder.NewCond()
+	}
+	return getLanguageCodesCond(dcs.GetLanguagesByRegions(splitValues(regions)))
+}
+
+// GetCountryCond gets the condition of the languages spoken in the countries
+func GetCountryCond(countries []string) builder.Cond {
+	if len(countries) == 0 {
+		return builder.NewCond()
+	}
+	return getLanguageCodesCond(dcs.GetLanguagesByCountries(splitValues(countries)))
+}
+
+// languageCodeRegexp matches the characters language codes are made of, so they can be written in the SQL as they are
+var languageCodeRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// getLanguageCodesCond returns the condition of the entries being in one of the languages. As with the matched IDs,
+// the codes are written in the SQL rather than bound, since a region can have more languages than the number of
+// parameters some databases allow. Codes with other characters aren't valid ones and are left out
+func getLanguageCodesCond(langs []string) builder.Cond {
+	var in strings.Builder
+	for _, lang := range langs {
+		if !languageCodeRegexp.MatchString(lang) {
+			continue
+		}
+		if in.Len() > 0 {
+			in.WriteString(",")
+		}
+		in.WriteString("'" + lang + "'")
+	}
+	if in.Len() == 0 {
+		return builder.Expr("1 = 0")
+	}
+	return builder.Expr("`door43_metadata`.language IN (" + in.String() + ")")
+}
+
+// splitValues splits the comma delimited values
+func splitValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+// repoNameLanguageSQL returns the SQL expression of the repo's lower name up to its first underscore,
+// which is the language code for repos named like en_ult, for the configured database
+func repoNameLanguageSQL() string {
+	switch {
+	case setting.Database.Type.IsMySQL():
+		return "SUBSTRING_INDEX(`repository`.lower_name, '_', 1)"
+	case setting.Database.Type.IsPostgreSQL():
+		return "SPLIT_PART(`repository`.lower_name, '_', 1)"
+	case setting.Database.Type.IsMSSQL():
+		return "LEFT(`repository`.lower_name, CHARINDEX('_', `repository`.lower_name + '_') - 1)"
+	default:
+		return "SUBSTR(`repository`.lower_name, 1, INSTR(`repository`.lower_name || '_', '_') - 1)"
+	}
+}
+
+// GetBookCond gets the book condition, matching the entries with an ingredient of one of the books
 func GetBookCond(books []string) builder.Cond {
-	bookCond := builder.NewCond()
+	identifiers := make([]string, 0, len(books))
 	for _, book := range books {
 		for _, v := range strings.Split(book, ",") {
-			bookCond = bookCond.Or(builder.Expr("JSON_CONTAINS(LOWER(JSON_EXTRACT(`door43_metadata`.ingredients, '$')), JSON_OBJECT('identifier', ?))", strings.ToLower(v)))
+			if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+				identifiers = append(identifiers, v)
+			}
 		}
 	}
-	return bookCond
+	if len(identifiers) == 0 {
+		return builder.NewCond()
+	}
+	return builder.In("`door43_metadata`.id",
+		builder.Select("door43_metadata_id").
+			From("door43_metadata_ingredient").
+			Where(builder.In("identifier", identifiers)))
 }
 
 // GetCheckingLevelCond gets the checking level condition
@@ -284,6 +507,30 @@ func GetCheckingLevelCond(checkingLevels []string) builder.Cond {
 	return checkingCond
 }
 
+// ParseCheckingLevels parses the comma separated checking levels of a query into integers
+func ParseCheckingLevels(values []string) ([]int, error) {
+	var levels []int
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			level, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("invalid checking level [%s]", v)
+			}
+			levels = append(levels, level)
+		}
+	}
+	return levels, nil
+}
+
+// GetVerifiedCheckingLevelCond gets the verified checking level condition
+func GetVerifiedCheckingLevelCond(verifiedLevels []int) builder.Cond {
+	verifiedCond := builder.NewCond()
+	for _, level := range verifiedLevels {
+		verifiedCond = verifiedCond.Or(builder.Gte{"`door43_metadata`.verified_checking_level": level})
+	}
+	return verifiedCond
+}
+
 // GetTagCond gets the tag condition
 func GetTagCond(tags []string) builder.Cond {
 	tagCond := builder.NewCond()
@@ -0,0 +1,38 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRelevanceOrderBy(t *testing.T) {
+	assert.Empty(t, GetRelevanceOrderBy(nil))
+	assert.Equal(t, "CASE `door43_metadata`.id WHEN 3 THEN 0 WHEN 1 THEN 1 ELSE 2 END", GetRelevanceOrderBy([]int64{3, 1}))
+
+	// only the most relevant IDs are ranked
+	matchedIDs := make([]int64, 0, 1000)
+	for id := int64(1); id <= 1000; id++ {
+		matchedIDs = append(matchedIDs, id)
+	}
+	orderBy := GetRelevanceOrderBy(matchedIDs)
+	assert.Equal(t, MaxRelevanceRanks, strings.Count(orderBy, " WHEN "))
+	assert.True(t, strings.HasSuffix(orderBy, " WHEN 100 THEN 99 ELSE 100 END"))
+}
+
+func TestParseCheckingLevels(t *testing.T) {
+	levels, err := ParseCheckingLevels([]string{"1, 2", "3"})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, levels)
+
+	levels, err = ParseCheckingLevels(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, levels)
+
+	_, err = ParseCheckingLevels([]string{"1,abc"})
+	assert.EqualError(t, err, "invalid checking level [abc]")
+}
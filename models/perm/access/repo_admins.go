@@ -0,0 +1,28 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package access
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"xorm.io/builder"
+)
+
+// GetRepoAdmins returns the active users with admin access to a repo: its owner if it is a user, and the
+// collaborators and team members (including the owners of an organization) with admin access
+func GetRepoAdmins(ctx context.Context, repo *repo_model.Repository) ([]*user_model.User, error) {
+	users := make([]*user_model.User, 0, 5)
+	return users, db.GetEngine(ctx).
+		Where(builder.Eq{"`user`.type": user_model.UserTypeIndividual, "`user`.is_active": true, "`user`.prohibit_login": false}).
+		And(builder.Eq{"`user`.id": repo.OwnerID}.
+			Or(builder.In("`user`.id", builder.Select("user_id").From("access").
+				Where(builder.Eq{"repo_id": repo.ID}.And(builder.Gte{"mode": perm.AccessModeAdmin}))))).
+		OrderBy("`user`.id").
+		Find(&users)
+}
@@ -34,6 +34,7 @@ type RepoArchiver struct { //revive:disable-line:exported
 	Type        git.ArchiveType `xorm:"unique(s)"`
 	Status      ArchiverStatus
 	CommitID    string             `xorm:"VARCHAR(40) unique(s)"`
+	PathsHash   string             `xorm:"VARCHAR(64) unique(s) NOT NULL DEFAULT ''"` // DCS Customizations
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL created"`
 }
 
@@ -43,6 +44,11 @@ func init() {
 
 // RelativePath returns the archive path relative to the archive storage root.
 func (archiver *RepoArchiver) RelativePath() string {
+	/*** DCS Customizations ***/
+	if archiver.PathsHash != "" {
+		return fmt.Sprintf("%d/%s/%s-%s.%s", archiver.RepoID, archiver.CommitID[:2], archiver.CommitID, archiver.PathsHash, archiver.Type.String())
+	}
+	/*** END DCS Customizations ***/
 	return fmt.Sprintf("%d/%s/%s.%s", archiver.RepoID, archiver.CommitID[:2], archiver.CommitID, archiver.Type.String())
 }
 
@@ -61,10 +67,15 @@ func repoArchiverForRelativePath(relativePath string) (*RepoArchiver, error) {
 		return nil, util.SilentWrap{Message: fmt.Sprintf("invalid storage path: %s", relativePath), Err: util.ErrInvalidArgument}
 	}
 
+	/*** DCS Customizations ***/
+	commitID, pathsHash, _ := strings.Cut(nameExts[0], "-")
+	/*** END DCS Customizations ***/
+
 	return &RepoArchiver{
-		RepoID:   repoID,
-		CommitID: parts[1] + nameExts[0],
-		Type:     git.ToArchiveType(nameExts[1]),
+		RepoID:    repoID,
+		CommitID:  parts[1] + commitID, // DCS Customizations
+		PathsHash: pathsHash,           // DCS Customizations
+		Type:      git.ToArchiveType(nameExts[1]),
 	}, nil
 }
 
@@ -78,8 +89,15 @@ func DeleteRepoArchiver(ctx context.Context, archiver *RepoArchiver) error {
 
 // GetRepoArchiver get an archiver
 func GetRepoArchiver(ctx context.Context, repoID int64, tp git.ArchiveType, commitID string) (*RepoArchiver, error) {
+	return GetRepoArchiverByPathsHash(ctx, repoID, tp, commitID, "") // DCS Customizations
+}
+
+/*** DCS Customizations ***/
+
+// GetRepoArchiverByPathsHash get an archiver of only the paths hashed to pathsHash, of the whole tree if empty
+func GetRepoArchiverByPathsHash(ctx context.Context, repoID int64, tp git.ArchiveType, commitID, pathsHash string) (*RepoArchiver, error) {
 	var archiver RepoArchiver
-	has, err := db.GetEngine(ctx).Where("repo_id=?", repoID).And("`type`=?", tp).And("commit_id=?", commitID).Get(&archiver)
+	has, err := db.GetEngine(ctx).Where("repo_id=?", repoID).And("`type`=?", tp).And("commit_id=?", commitID).And("paths_hash=?", pathsHash).Get(&archiver)
 	if err != nil {
 		return nil, err
 	}
@@ -89,6 +107,8 @@ func GetRepoArchiver(ctx context.Context, repoID int64, tp git.ArchiveType, comm
 	return nil, nil
 }
 
+/*** END DCS Customizations ***/
+
 // ExistsRepoArchiverWithStoragePath checks if there is a RepoArchiver for a given storage path
 func ExistsRepoArchiverWithStoragePath(ctx context.Context, storagePath string) (bool, error) {
 	// We need to invert the path provided func (archiver *RepoArchiver) RelativePath() above
@@ -33,6 +33,8 @@ type Attachment struct {
 	CustomDownloadURL string             `xorm:"-"`
 	/*** DCS Customizations ***/
 	BrowserDownloadURL string `xorm:"-" json:"browser_download_url"`
+	Checksum           string `json:"checksum"`  // algorithm prefixed checksum of a linked file, e.g. sha256:<hex digest>
+	MimeType           string `json:"mime_type"` // media type of a linked file
 	/*** END DCS Customizations ***/
 }
 
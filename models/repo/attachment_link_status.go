@@ -0,0 +1,112 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// AttachmentLinkStatus is the result of the last check of a release attachment linking to an external file
+type AttachmentLinkStatus struct {
+	ID              int64              `xorm:"pk autoincr"`
+	AttachmentID    int64              `xorm:"UNIQUE NOT NULL"`
+	RepoID          int64              `xorm:"INDEX NOT NULL"`
+	ReleaseID       int64              `xorm:"INDEX NOT NULL"`
+	Name            string             // name of the attachment when checked
+	URL             string             `xorm:"TEXT"` // the checked URL, so a status is only used while the link is the same
+	StatusCode      int                // 0 if no response was received
+	Size            int64              `xorm:"NOT NULL DEFAULT -1"` // size reported by the server, -1 if unknown
+	Error           string             `xorm:"TEXT"`
+	IsBroken        bool               `xorm:"INDEX NOT NULL DEFAULT false"`
+	BrokenSinceUnix timeutil.TimeStamp // first check the link was found broken, 0 if it isn't
+	CheckedUnix     timeutil.TimeStamp `xorm:"INDEX"`
+}
+
+func init() {
+	db.RegisterModel(new(AttachmentLinkStatus))
+}
+
+// IsExternalAttachmentCond is the condition of the release attachments linking to an external file
+func IsExternalAttachmentCond() builder.Cond {
+	return builder.Gt{"release_id": 0}.And(builder.Like{"name", "|http"}.Or(builder.Like{"name", "|ftp"}))
+}
+
+// GetAttachmentLinkStatus returns the status of the link of an attachment, nil if it hasn't been checked
+func GetAttachmentLinkStatus(ctx context.Context, attachmentID int64) (*AttachmentLinkStatus, error) {
+	status := &AttachmentLinkStatus{}
+	has, err := db.GetEngine(ctx).Where(builder.Eq{"attachment_id": attachmentID}).Get(status)
+	if err != nil || !has {
+		return nil, err
+	}
+	return status, nil
+}
+
+// SaveAttachmentLinkStatus inserts the status of the link of an attachment or updates the existing one
+func SaveAttachmentLinkStatus(ctx context.Context, status *AttachmentLinkStatus) error {
+	if status.ID == 0 {
+		return db.Insert(ctx, status)
+	}
+	_, err := db.GetEngine(ctx).ID(status.ID).AllCols().Update(status)
+	return err
+}
+
+// DeleteAttachmentLinkStatuses deletes the statuses of the links of the given attachments
+func DeleteAttachmentLinkStatuses(ctx context.Context, attachmentIDs ...int64) error {
+	if len(attachmentIDs) == 0 {
+		return nil
+	}
+	_, err := db.GetEngine(ctx).In("attachment_id", attachmentIDs).Delete(&AttachmentLinkStatus{})
+	return err
+}
+
+// DeleteOrphanedAttachmentLinkStatuses deletes the statuses of attachments that no longer exist or no longer link
+// to an external file
+func DeleteOrphanedAttachmentLinkStatuses(ctx context.Context) (int64, error) {
+	return db.GetEngine(ctx).
+		Where(builder.NotIn("attachment_id", builder.Select("id").From("attachment").Where(IsExternalAttachmentCond()))).
+		Delete(&AttachmentLinkStatus{})
+}
+
+// GetAttachmentLinkStatusesByReleaseIDs returns the statuses of the links of the attachments of the given releases
+// by attachment ID
+func GetAttachmentLinkStatusesByReleaseIDs(ctx context.Context, releaseIDs []int64) (map[int64]*AttachmentLinkStatus, error) {
+	statuses := make(map[int64]*AttachmentLinkStatus, len(releaseIDs))
+	if len(releaseIDs) == 0 {
+		return statuses, nil
+	}
+	list := make([]*AttachmentLinkStatus, 0, len(releaseIDs))
+	if err := db.GetEngine(ctx).In("release_id", releaseIDs).Find(&list); err != nil {
+		return nil, err
+	}
+	for _, status := range list {
+		statuses[status.AttachmentID] = status
+	}
+	return statuses, nil
+}
+
+// GetBrokenAttachmentLinkStatusesByReleaseIDs returns the statuses of the broken links of the existing attachments of
+// the given releases by release ID, ordered by name
+func GetBrokenAttachmentLinkStatusesByReleaseIDs(ctx context.Context, releaseIDs []int64) (map[int64][]*AttachmentLinkStatus, error) {
+	statuses := make(map[int64][]*AttachmentLinkStatus, len(releaseIDs))
+	if len(releaseIDs) == 0 {
+		return statuses, nil
+	}
+	list := make([]*AttachmentLinkStatus, 0, 5)
+	if err := db.GetEngine(ctx).
+		Where(builder.In("release_id", releaseIDs).And(builder.Eq{"is_broken": true})).
+		And(builder.In("attachment_id", builder.Select("id").From("attachment").Where(builder.In("release_id", releaseIDs)))).
+		Asc("name").
+		Find(&list); err != nil {
+		return nil, err
+	}
+	for _, status := range list {
+		statuses[status.ReleaseID] = append(statuses[status.ReleaseID], status)
+	}
+	return statuses, nil
+}
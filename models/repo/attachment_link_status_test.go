@@ -0,0 +1,52 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoor43MetadataListLoadBrokenLinks(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, status := range []*repo_model.AttachmentLinkStatus{
+		{AttachmentID: 9, RepoID: 1, ReleaseID: 1, Name: "attach1", IsBroken: true, StatusCode: 404},
+		{AttachmentID: 11, RepoID: 40, ReleaseID: 2, Name: "attach2", IsBroken: false, StatusCode: 200},
+		{AttachmentID: 12, RepoID: 2, ReleaseID: 11, Name: "attach3", IsBroken: true},
+		// the attachment no longer exists
+		{AttachmentID: 1000, RepoID: 1, ReleaseID: 1, Name: "deleted", IsBroken: true},
+	} {
+		assert.NoError(t, repo_model.SaveAttachmentLinkStatus(db.DefaultContext, status))
+	}
+
+	dms := repo_model.Door43MetadataList{
+		{RepoID: 1, ReleaseID: 1},
+		{RepoID: 40, ReleaseID: 2},
+		{RepoID: 2, ReleaseID: 11},
+		{RepoID: 1},
+	}
+	assert.NoError(t, dms.LoadBrokenLinks(db.DefaultContext))
+	if assert.Len(t, dms[0].BrokenLinks, 1) {
+		assert.EqualValues(t, 9, dms[0].BrokenLinks[0].AttachmentID)
+	}
+	assert.NotNil(t, dms[1].BrokenLinks)
+	assert.Empty(t, dms[1].BrokenLinks)
+	if assert.Len(t, dms[2].BrokenLinks, 1) {
+		assert.EqualValues(t, 12, dms[2].BrokenLinks[0].AttachmentID)
+	}
+	assert.Empty(t, dms[3].BrokenLinks)
+
+	// loading a single entry gives the same statuses
+	for _, dm := range dms {
+		single := &repo_model.Door43Metadata{RepoID: dm.RepoID, ReleaseID: dm.ReleaseID}
+		assert.NoError(t, single.LoadBrokenLinks(db.DefaultContext))
+		assert.Equal(t, dm.BrokenLinks, single.BrokenLinks)
+	}
+}
@@ -0,0 +1,408 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// Door43CollectionLatestVersion is the version name that refers to the most recent version of a collection
+const Door43CollectionLatestVersion = "latest"
+
+/*** START Door43Collection ***/
+
+// Door43Collection is a named bundle of catalog entries owned by a user or organization, e.g. a gateway language kit
+type Door43Collection struct {
+	ID          int64              `xorm:"pk autoincr"`
+	OwnerID     int64              `xorm:"UNIQUE(owner_name) NOT NULL"`
+	Owner       *user_model.User   `xorm:"-"`
+	LowerName   string             `xorm:"UNIQUE(owner_name) NOT NULL"`
+	Name        string             `xorm:"NOT NULL"`
+	Title       string             `xorm:"NOT NULL"`
+	Description string             `xorm:"TEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created NOT NULL"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+func init() {
+	db.RegisterModel(new(Door43Collection))
+	db.RegisterModel(new(Door43CollectionVersion))
+	db.RegisterModel(new(Door43CollectionMember))
+}
+
+// LoadOwner loads the owner of the collection
+func (c *Door43Collection) LoadOwner(ctx context.Context) error {
+	if c.Owner == nil {
+		owner, err := user_model.GetUserByID(ctx, c.OwnerID)
+		if err != nil {
+			return err
+		}
+		c.Owner = owner
+	}
+	return nil
+}
+
+// FullName returns the owner's name and the collection's name
+func (c *Door43Collection) FullName() string {
+	if c.Owner == nil {
+		return c.Name
+	}
+	return c.Owner.Name + "/" + c.Name
+}
+
+// HTMLURL returns the URL of the collection's page. The owner must be loaded
+func (c *Door43Collection) HTMLURL() string {
+	return fmt.Sprintf("%scatalog/collections/%s", setting.AppURL, c.FullName())
+}
+
+// APIURL returns the API URL of the collection. The owner must be loaded
+func (c *Door43Collection) APIURL() string {
+	return fmt.Sprintf("%sapi/v1/catalog/collections/%s", setting.AppURL, c.FullName())
+}
+
+// InsertDoor43Collection inserts a collection
+func InsertDoor43Collection(ctx context.Context, c *Door43Collection) error {
+	c.LowerName = strings.ToLower(c.Name)
+	has, err := db.GetEngine(ctx).Exist(&Door43Collection{OwnerID: c.OwnerID, LowerName: c.LowerName})
+	if err != nil {
+		return err
+	} else if has {
+		return ErrDoor43CollectionAlreadyExist{c.OwnerID, c.Name}
+	}
+	_, err = db.GetEngine(ctx).Insert(c)
+	return err
+}
+
+// UpdateDoor43CollectionCols updates the given columns of a collection
+func UpdateDoor43CollectionCols(ctx context.Context, c *Door43Collection, cols ...string) error {
+	_, err := db.GetEngine(ctx).ID(c.ID).Cols(cols...).Update(c)
+	return err
+}
+
+// GetDoor43CollectionByOwnerAndName returns the collection of the owner with the given name
+func GetDoor43CollectionByOwnerAndName(ctx context.Context, ownerID int64, name string) (*Door43Collection, error) {
+	c := &Door43Collection{OwnerID: ownerID, LowerName: strings.ToLower(name)}
+	has, err := db.GetEngine(ctx).Get(c)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDoor43CollectionNotExist{ownerID, name}
+	}
+	return c, nil
+}
+
+// GetDoor43CollectionsByOwnerID returns the collections of the owner, ordered by name
+func GetDoor43CollectionsByOwnerID(ctx context.Context, ownerID int64) ([]*Door43Collection, error) {
+	collections := make([]*Door43Collection, 0, 10)
+	return collections, db.GetEngine(ctx).
+		Where(builder.Eq{"owner_id": ownerID}).
+		OrderBy("lower_name").
+		Find(&collections)
+}
+
+// DeleteDoor43Collection deletes a collection with all its versions
+func DeleteDoor43Collection(ctx context.Context, c *Door43Collection) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		versionIDs := make([]int64, 0, 10)
+		if err := db.GetEngine(ctx).Table("door43_collection_version").
+			Where(builder.Eq{"collection_id": c.ID}).
+			Cols("id").
+			Find(&versionIDs); err != nil {
+			return err
+		}
+		if len(versionIDs) > 0 {
+			if _, err := db.GetEngine(ctx).In("version_id", versionIDs).Delete(&Door43CollectionMember{}); err != nil {
+				return err
+			}
+		}
+		if _, err := db.GetEngine(ctx).Delete(&Door43CollectionVersion{CollectionID: c.ID}); err != nil {
+			return err
+		}
+		_, err := db.GetEngine(ctx).ID(c.ID).Delete(&Door43Collection{})
+		return err
+	})
+}
+
+/*** END Door43Collection ***/
+
+/*** START Door43CollectionVersion ***/
+
+// Door43CollectionVersion is a released version of a collection, pinning each member to a repo's release tag and commit
+type Door43CollectionVersion struct {
+	ID           int64                     `xorm:"pk autoincr"`
+	CollectionID int64                     `xorm:"UNIQUE(collection_version) NOT NULL"`
+	Collection   *Door43Collection         `xorm:"-"`
+	LowerVersion string                    `xorm:"UNIQUE(collection_version) NOT NULL"`
+	Version      string                    `xorm:"NOT NULL"`
+	Note         string                    `xorm:"TEXT"`
+	PublisherID  int64                     `xorm:"NOT NULL"`
+	Members      []*Door43CollectionMember `xorm:"-"`
+	CreatedUnix  timeutil.TimeStamp        `xorm:"INDEX created NOT NULL"`
+}
+
+// LoadMembers loads the members of the version in their order
+func (v *Door43CollectionVersion) LoadMembers(ctx context.Context) error {
+	if v.Members != nil {
+		return nil
+	}
+	members := make([]*Door43CollectionMember, 0, 10)
+	if err := db.GetEngine(ctx).
+		Where(builder.Eq{"version_id": v.ID}).
+		OrderBy("sort, id").
+		Find(&members); err != nil {
+		return err
+	}
+	v.Members = members
+	return nil
+}
+
+// InsertDoor43CollectionVersion inserts a version of a collection with its members
+func InsertDoor43CollectionVersion(ctx context.Context, v *Door43CollectionVersion) error {
+	v.LowerVersion = strings.ToLower(v.Version)
+	if v.LowerVersion == Door43CollectionLatestVersion {
+		return ErrDoor43CollectionVersionInvalid{v.Version}
+	}
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		has, err := db.GetEngine(ctx).Exist(&Door43CollectionVersion{CollectionID: v.CollectionID, LowerVersion: v.LowerVersion})
+		if err != nil {
+			return err
+		} else if has {
+			return ErrDoor43CollectionVersionAlreadyExist{v.CollectionID, v.Version}
+		}
+		if _, err := db.GetEngine(ctx).Insert(v); err != nil {
+			return err
+		}
+		for i, m := range v.Members {
+			m.VersionID = v.ID
+			m.Sort = i
+		}
+		if len(v.Members) > 0 {
+			if _, err := db.GetEngine(ctx).Insert(v.Members); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetDoor43CollectionVersion returns the version of a collection, or the most recent one for Door43CollectionLatestVersion
+func GetDoor43CollectionVersion(ctx context.Context, collectionID int64, version string) (*Door43CollectionVersion, error) {
+	v := &Door43CollectionVersion{}
+	sess := db.GetEngine(ctx).Where(builder.Eq{"collection_id": collectionID})
+	if strings.ToLower(version) == Door43CollectionLatestVersion {
+		sess = sess.OrderBy("created_unix DESC, id DESC")
+	} else {
+		sess = sess.And(builder.Eq{"lower_version": strings.ToLower(version)})
+	}
+	has, err := sess.Get(v)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDoor43CollectionVersionNotExist{collectionID, version}
+	}
+	return v, nil
+}
+
+// GetDoor43CollectionVersions returns the versions of a collection, most recent first
+func GetDoor43CollectionVersions(ctx context.Context, collectionID int64) ([]*Door43CollectionVersion, error) {
+	versions := make([]*Door43CollectionVersion, 0, 10)
+	return versions, db.GetEngine(ctx).
+		Where(builder.Eq{"collection_id": collectionID}).
+		OrderBy("created_unix DESC, id DESC").
+		Find(&versions)
+}
+
+// DeleteDoor43CollectionVersion deletes a version of a collection with its members
+func DeleteDoor43CollectionVersion(ctx context.Context, v *Door43CollectionVersion) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Delete(&Door43CollectionMember{VersionID: v.ID}); err != nil {
+			return err
+		}
+		_, err := db.GetEngine(ctx).ID(v.ID).Delete(&Door43CollectionVersion{})
+		return err
+	})
+}
+
+/*** END Door43CollectionVersion ***/
+
+/*** START Door43CollectionMember ***/
+
+// Door43CollectionMember is a repo's release tag pinned in a version of a collection
+type Door43CollectionMember struct {
+	ID        int64           `xorm:"pk autoincr"`
+	VersionID int64           `xorm:"INDEX NOT NULL"`
+	RepoID    int64           `xorm:"INDEX NOT NULL"`
+	Repo      *Repository     `xorm:"-"`
+	Ref       string          `xorm:"NOT NULL"`
+	CommitSHA string          `xorm:"NOT NULL VARCHAR(40)"`
+	Sort      int             `xorm:"NOT NULL DEFAULT 0"`
+	Entry     *Door43Metadata `xorm:"-"`
+}
+
+// LoadRepo loads the repo of the member, leaving it nil if the repo no longer exists
+func (m *Door43CollectionMember) LoadRepo(ctx context.Context) error {
+	if m.Repo != nil {
+		return nil
+	}
+	repo, err := GetRepositoryByID(ctx, m.RepoID)
+	if err != nil {
+		if IsErrRepoNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := repo.LoadOwner(ctx); err != nil {
+		return err
+	}
+	m.Repo = repo
+	return nil
+}
+
+// LoadEntry loads the catalog entry of the member's repo and tag, leaving it nil if it no longer exists
+func (m *Door43CollectionMember) LoadEntry(ctx context.Context) error {
+	if m.Entry != nil {
+		return nil
+	}
+	if err := m.LoadRepo(ctx); err != nil || m.Repo == nil {
+		return err
+	}
+	dm, err := GetDoor43MetadataByRepoIDAndRef(ctx, m.RepoID, m.Ref)
+	if err != nil {
+		if IsErrDoor43MetadataNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	dm.Repo = m.Repo
+	m.Entry = dm
+	return nil
+}
+
+/*** END Door43CollectionMember ***/
+
+/*** Error Structs & Functions ***/
+
+// ErrDoor43CollectionNotExist represents a "Door43CollectionNotExist" kind of error.
+type ErrDoor43CollectionNotExist struct {
+	OwnerID int64
+	Name    string
+}
+
+// IsErrDoor43CollectionNotExist checks if an error is a ErrDoor43CollectionNotExist.
+func IsErrDoor43CollectionNotExist(err error) bool {
+	_, ok := err.(ErrDoor43CollectionNotExist)
+	return ok
+}
+
+func (err ErrDoor43CollectionNotExist) Error() string {
+	return fmt.Sprintf("collection does not exist [owner_id: %d, name: %s]", err.OwnerID, err.Name)
+}
+
+func (err ErrDoor43CollectionNotExist) Unwrap() error {
+	return util.ErrNotExist
+}
+
+// ErrDoor43CollectionAlreadyExist represents a "Door43CollectionAlreadyExist" kind of error.
+type ErrDoor43CollectionAlreadyExist struct {
+	OwnerID int64
+	Name    string
+}
+
+// IsErrDoor43CollectionAlreadyExist checks if an error is a ErrDoor43CollectionAlreadyExist.
+func IsErrDoor43CollectionAlreadyExist(err error) bool {
+	_, ok := err.(ErrDoor43CollectionAlreadyExist)
+	return ok
+}
+
+func (err ErrDoor43CollectionAlreadyExist) Error() string {
+	return fmt.Sprintf("collection already exists [owner_id: %d, name: %s]", err.OwnerID, err.Name)
+}
+
+func (err ErrDoor43CollectionAlreadyExist) Unwrap() error {
+	return util.ErrAlreadyExist
+}
+
+// ErrDoor43CollectionVersionNotExist represents a "Door43CollectionVersionNotExist" kind of error.
+type ErrDoor43CollectionVersionNotExist struct {
+	CollectionID int64
+	Version      string
+}
+
+// IsErrDoor43CollectionVersionNotExist checks if an error is a ErrDoor43CollectionVersionNotExist.
+func IsErrDoor43CollectionVersionNotExist(err error) bool {
+	_, ok := err.(ErrDoor43CollectionVersionNotExist)
+	return ok
+}
+
+func (err ErrDoor43CollectionVersionNotExist) Error() string {
+	return fmt.Sprintf("collection version does not exist [collection_id: %d, version: %s]", err.CollectionID, err.Version)
+}
+
+func (err ErrDoor43CollectionVersionNotExist) Unwrap() error {
+	return util.ErrNotExist
+}
+
+// ErrDoor43CollectionVersionAlreadyExist represents a "Door43CollectionVersionAlreadyExist" kind of error.
+type ErrDoor43CollectionVersionAlreadyExist struct {
+	CollectionID int64
+	Version      string
+}
+
+// IsErrDoor43CollectionVersionAlreadyExist checks if an error is a ErrDoor43CollectionVersionAlreadyExist.
+func IsErrDoor43CollectionVersionAlreadyExist(err error) bool {
+	_, ok := err.(ErrDoor43CollectionVersionAlreadyExist)
+	return ok
+}
+
+func (err ErrDoor43CollectionVersionAlreadyExist) Error() string {
+	return fmt.Sprintf("collection version already exists [collection_id: %d, version: %s]", err.CollectionID, err.Version)
+}
+
+func (err ErrDoor43CollectionVersionAlreadyExist) Unwrap() error {
+	return util.ErrAlreadyExist
+}
+
+// ErrDoor43CollectionVersionInvalid represents a "Door43CollectionVersionInvalid" kind of error.
+type ErrDoor43CollectionVersionInvalid struct {
+	Version string
+}
+
+// IsErrDoor43CollectionVersionInvalid checks if an error is a ErrDoor43CollectionVersionInvalid.
+func IsErrDoor43CollectionVersionInvalid(err error) bool {
+	_, ok := err.(ErrDoor43CollectionVersionInvalid)
+	return ok
+}
+
+func (err ErrDoor43CollectionVersionInvalid) Error() string {
+	return fmt.Sprintf("collection version is reserved [version: %s]", err.Version)
+}
+
+// ErrDoor43CollectionMemberInvalid represents a "Door43CollectionMemberInvalid" kind of error.
+type ErrDoor43CollectionMemberInvalid struct {
+	Repo   string
+	Ref    string
+	Reason string
+}
+
+// IsErrDoor43CollectionMemberInvalid checks if an error is a ErrDoor43CollectionMemberInvalid.
+func IsErrDoor43CollectionMemberInvalid(err error) bool {
+	_, ok := err.(ErrDoor43CollectionMemberInvalid)
+	return ok
+}
+
+func (err ErrDoor43CollectionMemberInvalid) Error() string {
+	return fmt.Sprintf("collection member is not valid: %s [repo: %s, ref: %s]", err.Reason, err.Repo, err.Ref)
+}
+
+/*** END Error Structs & Functions ***/
@@ -0,0 +1,245 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// Door43DownloadKind is what was downloaded of a catalog entry
+type Door43DownloadKind string
+
+const (
+	// Door43DownloadKindArchive is a zipball or tarball of the entry's ref
+	Door43DownloadKindArchive Door43DownloadKind = "archive"
+	// Door43DownloadKindAttachment is an attachment of the entry's release
+	Door43DownloadKindAttachment Door43DownloadKind = "attachment"
+	// Door43DownloadKindIngredient is a raw or media file of one of the entry's ingredients
+	Door43DownloadKindIngredient Door43DownloadKind = "ingredient"
+)
+
+// Door43DownloadKinds are all the kinds of downloads that are counted
+var Door43DownloadKinds = []Door43DownloadKind{Door43DownloadKindArchive, Door43DownloadKindAttachment, Door43DownloadKindIngredient}
+
+// IsValid returns true if the kind is one of Door43DownloadKinds
+func (k Door43DownloadKind) IsValid() bool {
+	for _, kind := range Door43DownloadKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Door43DownloadStat is the number of downloads of a kind of a catalog entry on a day (UTC).
+// The language and subject of the entry are copied so the counts can be aggregated by them
+type Door43DownloadStat struct {
+	ID               int64              `xorm:"pk autoincr"`
+	Door43MetadataID int64              `xorm:"UNIQUE(s) NOT NULL"`
+	Day              timeutil.TimeStamp `xorm:"UNIQUE(s) INDEX NOT NULL"` // midnight UTC of the day
+	Kind             Door43DownloadKind `xorm:"UNIQUE(s) VARCHAR(20) NOT NULL"`
+	RepoID           int64              `xorm:"INDEX NOT NULL"`
+	Language         string             `xorm:"INDEX"`
+	Subject          string             `xorm:"INDEX"`
+	Count            int64              `xorm:"NOT NULL DEFAULT 0"`
+}
+
+func init() {
+	db.RegisterModel(new(Door43DownloadStat))
+}
+
+// Door43DownloadDay returns the day of the time in which its downloads are counted
+func Door43DownloadDay(t time.Time) timeutil.TimeStamp {
+	t = t.UTC()
+	return timeutil.TimeStamp(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix())
+}
+
+// AddDoor43DownloadCount adds downloads of the kind to the count of the catalog entry of the day
+func AddDoor43DownloadCount(ctx context.Context, dm *Door43Metadata, kind Door43DownloadKind, day timeutil.TimeStamp, count int64) error {
+	incr := func() (int64, error) {
+		return db.GetEngine(ctx).
+			Where(builder.Eq{"door43_metadata_id": dm.ID, "day": day, "kind": kind}).
+			Incr("count", count).
+			NoAutoCondition().
+			Update(&Door43DownloadStat{})
+	}
+	if affected, err := incr(); err != nil || affected > 0 {
+		return err
+	}
+	err := db.Insert(ctx, &Door43DownloadStat{
+		Door43MetadataID: dm.ID,
+		Day:              day,
+		Kind:             kind,
+		RepoID:           dm.RepoID,
+		Language:         dm.Language,
+		Subject:          dm.Subject,
+		Count:            count,
+	})
+	if err != nil {
+		// the count of the day may have just been inserted by another worker
+		if affected, incrErr := incr(); incrErr == nil && affected > 0 {
+			return nil
+		}
+	}
+	return err
+}
+
+// DeleteDoor43DownloadStatsByRepoID deletes the download counts of the entries of a repo
+func DeleteDoor43DownloadStatsByRepoID(ctx context.Context, repoID int64) error {
+	_, err := db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID}).Delete(&Door43DownloadStat{})
+	return err
+}
+
+// Door43DownloadGroupBy is a field download counts can be summed up by
+type Door43DownloadGroupBy string
+
+const (
+	Door43DownloadGroupByDay      Door43DownloadGroupBy = "day"
+	Door43DownloadGroupByKind     Door43DownloadGroupBy = "kind"
+	Door43DownloadGroupByLanguage Door43DownloadGroupBy = "lang"
+	Door43DownloadGroupBySubject  Door43DownloadGroupBy = "subject"
+	Door43DownloadGroupByRepo     Door43DownloadGroupBy = "repo"
+	Door43DownloadGroupByEntry    Door43DownloadGroupBy = "entry"
+)
+
+// door43DownloadGroupByColumns are the columns of each field download counts can be summed up by
+var door43DownloadGroupByColumns = map[Door43DownloadGroupBy]string{
+	Door43DownloadGroupByDay:      "day",
+	Door43DownloadGroupByKind:     "kind",
+	Door43DownloadGroupByLanguage: "language",
+	Door43DownloadGroupBySubject:  "subject",
+	Door43DownloadGroupByRepo:     "repo_id",
+	Door43DownloadGroupByEntry:    "door43_metadata_id",
+}
+
+// ErrInvalidDoor43DownloadGroupBy represents an unknown field to sum up download counts by
+type ErrInvalidDoor43DownloadGroupBy struct {
+	GroupBy string
+}
+
+// IsErrInvalidDoor43DownloadGroupBy checks if an error is a ErrInvalidDoor43DownloadGroupBy.
+func IsErrInvalidDoor43DownloadGroupBy(err error) bool {
+	_, ok := err.(ErrInvalidDoor43DownloadGroupBy)
+	return ok
+}
+
+func (err ErrInvalidDoor43DownloadGroupBy) Error() string {
+	return fmt.Sprintf("download counts can't be grouped by %q [valid: day, kind, lang, subject, repo, entry]", err.GroupBy)
+}
+
+func (err ErrInvalidDoor43DownloadGroupBy) Unwrap() error {
+	return util.ErrInvalidArgument
+}
+
+// ParseDoor43DownloadGroupBy parses a list of fields to sum up download counts by
+func ParseDoor43DownloadGroupBy(fields []string) ([]Door43DownloadGroupBy, error) {
+	groupBy := make([]Door43DownloadGroupBy, 0, len(fields))
+	for _, field := range fields {
+		for _, f := range strings.Split(field, ",") {
+			f = strings.ToLower(strings.TrimSpace(f))
+			if f == "" {
+				continue
+			}
+			if _, ok := door43DownloadGroupByColumns[Door43DownloadGroupBy(f)]; !ok {
+				return nil, ErrInvalidDoor43DownloadGroupBy{f}
+			}
+			groupBy = append(groupBy, Door43DownloadGroupBy(f))
+		}
+	}
+	return groupBy, nil
+}
+
+// Door43DownloadStatsOptions are the options to sum up download counts
+type Door43DownloadStatsOptions struct {
+	RepoID            int64
+	OwnerID           int64
+	Door43MetadataIDs []int64
+	Languages         []string
+	Subjects          []string
+	Kinds             []Door43DownloadKind
+	Since             timeutil.TimeStamp // first day counted, 0 for no limit
+	Before            timeutil.TimeStamp // first day not counted, 0 for no limit
+	GroupBy           []Door43DownloadGroupBy
+	PublicOnly        bool // only count the downloads of repos that are public
+}
+
+func (opts *Door43DownloadStatsOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.OwnerID > 0 {
+		cond = cond.And(builder.In("repo_id", builder.Select("id").From("repository").Where(builder.Eq{"owner_id": opts.OwnerID})))
+	}
+	if opts.PublicOnly {
+		cond = cond.And(builder.In("repo_id", builder.Select("id").From("repository").Where(builder.Eq{"is_private": false})))
+	}
+	if len(opts.Door43MetadataIDs) > 0 {
+		cond = cond.And(builder.In("door43_metadata_id", opts.Door43MetadataIDs))
+	}
+	if len(opts.Languages) > 0 {
+		cond = cond.And(builder.In("language", opts.Languages))
+	}
+	if len(opts.Subjects) > 0 {
+		cond = cond.And(builder.In("subject", opts.Subjects))
+	}
+	if len(opts.Kinds) > 0 {
+		cond = cond.And(builder.In("kind", opts.Kinds))
+	}
+	if opts.Since > 0 {
+		cond = cond.And(builder.Gte{"day": opts.Since})
+	}
+	if opts.Before > 0 {
+		cond = cond.And(builder.Lt{"day": opts.Before})
+	}
+	return cond
+}
+
+// Door43DownloadCount is a sum of download counts. Only the fields they were grouped by are set
+type Door43DownloadCount struct {
+	Day              timeutil.TimeStamp
+	Kind             Door43DownloadKind
+	Language         string
+	Subject          string
+	RepoID           int64
+	Door43MetadataID int64
+	Count            int64
+}
+
+// GetDoor43DownloadCounts returns the sums of the download counts matching the options, grouped by the given fields
+// and sorted by them, or the total if not grouped
+func GetDoor43DownloadCounts(ctx context.Context, opts *Door43DownloadStatsOptions) ([]*Door43DownloadCount, error) {
+	columns := make([]string, 0, len(opts.GroupBy))
+	for _, g := range opts.GroupBy {
+		column, ok := door43DownloadGroupByColumns[g]
+		if !ok {
+			return nil, ErrInvalidDoor43DownloadGroupBy{string(g)}
+		}
+		columns = append(columns, column)
+	}
+
+	sess := db.GetEngine(ctx).Table("door43_download_stat").Where(opts.toConds())
+	if len(columns) > 0 {
+		sess = sess.Select(strings.Join(columns, ", ") + ", COALESCE(SUM(`count`), 0) AS `count`").
+			GroupBy(strings.Join(columns, ", ")).
+			OrderBy(strings.Join(columns, ", "))
+	} else {
+		sess = sess.Select("COALESCE(SUM(`count`), 0) AS `count`")
+	}
+
+	counts := make([]*Door43DownloadCount, 0, 10)
+	if err := sess.Find(&counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
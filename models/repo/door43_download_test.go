@@ -0,0 +1,69 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo_test
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoor43DownloadCounts(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	dm1 := &repo_model.Door43Metadata{ID: 1, RepoID: 1, Language: "en", Subject: "Bible"}
+	dm2 := &repo_model.Door43Metadata{ID: 2, RepoID: 2, Language: "fr", Subject: "Bible"}
+	today := repo_model.Door43DownloadDay(time.Now())
+	assert.NoError(t, repo_model.AddDoor43DownloadCount(db.DefaultContext, dm1, repo_model.Door43DownloadKindArchive, today, 1))
+	assert.NoError(t, repo_model.AddDoor43DownloadCount(db.DefaultContext, dm1, repo_model.Door43DownloadKindArchive, today, 2))
+	assert.NoError(t, repo_model.AddDoor43DownloadCount(db.DefaultContext, dm1, repo_model.Door43DownloadKindIngredient, today, 1))
+	assert.NoError(t, repo_model.AddDoor43DownloadCount(db.DefaultContext, dm2, repo_model.Door43DownloadKindAttachment, today, 1))
+	unittest.AssertCount(t, &repo_model.Door43DownloadStat{}, 3)
+
+	counts, err := repo_model.GetDoor43DownloadCounts(db.DefaultContext, &repo_model.Door43DownloadStatsOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, counts, 1) {
+		assert.EqualValues(t, 5, counts[0].Count)
+	}
+
+	counts, err = repo_model.GetDoor43DownloadCounts(db.DefaultContext, &repo_model.Door43DownloadStatsOptions{
+		Since:   repo_model.Door43DownloadDay(time.Now()),
+		GroupBy: []repo_model.Door43DownloadGroupBy{repo_model.Door43DownloadGroupByLanguage},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, counts, 2) {
+		assert.Equal(t, "en", counts[0].Language)
+		assert.EqualValues(t, 4, counts[0].Count)
+		assert.Equal(t, "fr", counts[1].Language)
+		assert.EqualValues(t, 1, counts[1].Count)
+	}
+
+	counts, err = repo_model.GetDoor43DownloadCounts(db.DefaultContext, &repo_model.Door43DownloadStatsOptions{
+		RepoID:  1,
+		Kinds:   []repo_model.Door43DownloadKind{repo_model.Door43DownloadKindArchive},
+		GroupBy: []repo_model.Door43DownloadGroupBy{repo_model.Door43DownloadGroupByDay, repo_model.Door43DownloadGroupByEntry},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, counts, 1) {
+		assert.Equal(t, repo_model.Door43DownloadDay(time.Now()), counts[0].Day)
+		assert.EqualValues(t, 1, counts[0].Door43MetadataID)
+		assert.EqualValues(t, 3, counts[0].Count)
+	}
+
+	counts, err = repo_model.GetDoor43DownloadCounts(db.DefaultContext, &repo_model.Door43DownloadStatsOptions{
+		Before: repo_model.Door43DownloadDay(time.Now()),
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, counts, 1) {
+		assert.EqualValues(t, 0, counts[0].Count)
+	}
+
+	_, err = repo_model.ParseDoor43DownloadGroupBy([]string{"day,lang", "owner"})
+	assert.True(t, repo_model.IsErrInvalidDoor43DownloadGroupBy(err))
+}
@@ -0,0 +1,237 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+/*** START Door43Subscription ***/
+
+// Door43Subscription is a saved catalog query of a user who wants to be notified of new prod (and preprod)
+// releases of the languages, subjects, owners and books it lists. Each non-empty list must match.
+type Door43Subscription struct {
+	ID             int64                `xorm:"pk autoincr"`
+	UserID         int64                `xorm:"INDEX NOT NULL"`
+	User           *user_model.User     `xorm:"-"`
+	Name           string               `xorm:"NOT NULL"`
+	Languages      []string             `xorm:"JSON"`
+	Subjects       []string             `xorm:"JSON"`
+	Owners         []string             `xorm:"JSON"`
+	Books          []string             `xorm:"JSON"`
+	Stage          door43metadata.Stage `xorm:"NOT NULL"`
+	EmailDigest    bool                 `xorm:"INDEX NOT NULL DEFAULT false"`
+	LastDigestUnix timeutil.TimeStamp   `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix    timeutil.TimeStamp   `xorm:"INDEX created NOT NULL"`
+	UpdatedUnix    timeutil.TimeStamp   `xorm:"INDEX updated"`
+}
+
+func init() {
+	db.RegisterModel(new(Door43Subscription))
+}
+
+// LoadUser loads the user of the subscription
+func (s *Door43Subscription) LoadUser(ctx context.Context) error {
+	if s.User == nil {
+		user, err := user_model.GetUserByID(ctx, s.UserID)
+		if err != nil {
+			return err
+		}
+		s.User = user
+	}
+	return nil
+}
+
+// Validate checks the subscription has a supported stage and at least one filter
+func (s *Door43Subscription) Validate() error {
+	if s.Stage != door43metadata.StageProd && s.Stage != door43metadata.StagePreProd {
+		return ErrDoor43SubscriptionInvalid{"stage must be prod or preprod"}
+	}
+	if len(s.Languages) == 0 && len(s.Subjects) == 0 && len(s.Owners) == 0 && len(s.Books) == 0 {
+		return ErrDoor43SubscriptionInvalid{"at least one language, subject, owner or book is required"}
+	}
+	return nil
+}
+
+// Matches returns true if the catalog entry is a release of the subscription's stage that matches all of its
+// filters. The entry must have its repo loaded
+func (s *Door43Subscription) Matches(dm *Door43Metadata) bool {
+	if dm.RefType != "tag" || dm.Stage > s.Stage || dm.Stage < door43metadata.StageProd {
+		return false
+	}
+	if len(s.Languages) > 0 && !containsFold(s.Languages, dm.Language) {
+		return false
+	}
+	if len(s.Subjects) > 0 && !containsFold(s.Subjects, dm.Subject) {
+		return false
+	}
+	if len(s.Owners) > 0 && (dm.Repo == nil || !containsFold(s.Owners, dm.Repo.OwnerName)) {
+		return false
+	}
+	if len(s.Books) > 0 {
+		for _, ingredient := range dm.Ingredients {
+			if containsFold(s.Books, ingredient.Identifier) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// InsertDoor43Subscription inserts a subscription
+func InsertDoor43Subscription(ctx context.Context, s *Door43Subscription) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).Insert(s)
+	return err
+}
+
+// UpdateDoor43SubscriptionCols updates the given columns of a subscription
+func UpdateDoor43SubscriptionCols(ctx context.Context, s *Door43Subscription, cols ...string) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).ID(s.ID).Cols(cols...).Update(s)
+	return err
+}
+
+// GetDoor43SubscriptionByID returns the subscription of the user with the given ID
+func GetDoor43SubscriptionByID(ctx context.Context, id, userID int64) (*Door43Subscription, error) {
+	s := &Door43Subscription{}
+	has, err := db.GetEngine(ctx).Where(builder.Eq{"id": id, "user_id": userID}).Get(s)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDoor43SubscriptionNotExist{id}
+	}
+	return s, nil
+}
+
+// GetDoor43SubscriptionsByUserID returns the subscriptions of a user, ordered by name
+func GetDoor43SubscriptionsByUserID(ctx context.Context, userID int64) ([]*Door43Subscription, error) {
+	subscriptions := make([]*Door43Subscription, 0, 10)
+	return subscriptions, db.GetEngine(ctx).
+		Where(builder.Eq{"user_id": userID}).
+		OrderBy("name, id").
+		Find(&subscriptions)
+}
+
+// GetDoor43SubscriptionsMatchingEntry returns all the subscriptions that match the catalog entry.
+// The entry must have its repo loaded
+func GetDoor43SubscriptionsMatchingEntry(ctx context.Context, dm *Door43Metadata) ([]*Door43Subscription, error) {
+	subscriptions := make([]*Door43Subscription, 0, 10)
+	if err := db.GetEngine(ctx).
+		Where(builder.Gte{"stage": dm.Stage}).
+		Find(&subscriptions); err != nil {
+		return nil, err
+	}
+	matching := subscriptions[:0]
+	for _, s := range subscriptions {
+		if s.Matches(dm) {
+			matching = append(matching, s)
+		}
+	}
+	return matching, nil
+}
+
+// GetDoor43SubscriptionsWithEmailDigest returns all the subscriptions whose users want an email digest
+func GetDoor43SubscriptionsWithEmailDigest(ctx context.Context) ([]*Door43Subscription, error) {
+	subscriptions := make([]*Door43Subscription, 0, 10)
+	return subscriptions, db.GetEngine(ctx).
+		Where(builder.Eq{"email_digest": true}).
+		OrderBy("user_id, id").
+		Find(&subscriptions)
+}
+
+// GetReleaseDoor43MetadataReleasedSince returns the prod and preprod catalog entries of releases published after the
+// given time with their repos loaded, oldest first. The release date is used rather than when the entry was created
+// since entries are created again when a repo is made public, unarchived or transferred.
+func GetReleaseDoor43MetadataReleasedSince(ctx context.Context, since timeutil.TimeStamp) ([]*Door43Metadata, error) {
+	dms := make([]*Door43Metadata, 0, 50)
+	if err := db.GetEngine(ctx).
+		Where(builder.Eq{"ref_type": "tag"}).
+		And(builder.In("stage", door43metadata.StageProd, door43metadata.StagePreProd)).
+		And(builder.Gt{"release_date_unix": since}).
+		OrderBy("release_date_unix, id").
+		Find(&dms); err != nil {
+		return nil, err
+	}
+	for _, dm := range dms {
+		if err := dm.LoadRepo(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return dms, nil
+}
+
+// DeleteDoor43Subscription deletes a subscription
+func DeleteDoor43Subscription(ctx context.Context, s *Door43Subscription) error {
+	_, err := db.GetEngine(ctx).ID(s.ID).Delete(&Door43Subscription{})
+	return err
+}
+
+/*** END Door43Subscription ***/
+
+/*** Error Structs & Functions ***/
+
+// ErrDoor43SubscriptionNotExist represents a "Door43SubscriptionNotExist" kind of error.
+type ErrDoor43SubscriptionNotExist struct {
+	ID int64
+}
+
+// IsErrDoor43SubscriptionNotExist checks if an error is a ErrDoor43SubscriptionNotExist.
+func IsErrDoor43SubscriptionNotExist(err error) bool {
+	_, ok := err.(ErrDoor43SubscriptionNotExist)
+	return ok
+}
+
+func (err ErrDoor43SubscriptionNotExist) Error() string {
+	return fmt.Sprintf("subscription does not exist [id: %d]", err.ID)
+}
+
+func (err ErrDoor43SubscriptionNotExist) Unwrap() error {
+	return util.ErrNotExist
+}
+
+// ErrDoor43SubscriptionInvalid represents a "Door43SubscriptionInvalid" kind of error.
+type ErrDoor43SubscriptionInvalid struct {
+	Reason string
+}
+
+// IsErrDoor43SubscriptionInvalid checks if an error is a ErrDoor43SubscriptionInvalid.
+func IsErrDoor43SubscriptionInvalid(err error) bool {
+	_, ok := err.(ErrDoor43SubscriptionInvalid)
+	return ok
+}
+
+func (err ErrDoor43SubscriptionInvalid) Error() string {
+	return fmt.Sprintf("subscription is invalid: %s", err.Reason)
+}
+
+func (err ErrDoor43SubscriptionInvalid) Unwrap() error {
+	return util.ErrInvalidArgument
+}
+
+/*** END Error Structs & Functions ***/
@@ -6,31 +6,96 @@ package repo
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"sort"
 	"strings"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/door43metadata"
 	"code.gitea.io/gitea/models/system"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
 
 	"xorm.io/builder"
 )
 
 /*** INIT DB ***/
 
-// InitDoor43Metadata does some db management
-func InitDoor43Metadata() error {
-	switch setting.Database.Type {
-	case "mysql":
-		_, err := db.GetEngine(db.DefaultContext).Exec("ALTER TABLE `door43_metadata` MODIFY `metadata` JSON")
-		if err != nil {
-			return fmt.Errorf("Error changing door43_metadata metadata column type: %v", err)
+// InitDoor43Metadata does some db management: it stores the metadata column as native JSON on MySQL if it isn't yet.
+// Converting it on PostgreSQL and storing the ingredients of older entries are left to gitea doctor, as they can take long
+func InitDoor43Metadata(ctx context.Context) error {
+	if !setting.Database.Type.IsMySQL() {
+		return nil
+	}
+	// Only altered if needed as it rebuilds the table
+	isNative, err := Door43MetadataColumnIsNativeJSON(ctx)
+	if err != nil || isNative {
+		return err
+	}
+	if _, err := db.GetEngine(ctx).Exec("ALTER TABLE `door43_metadata` MODIFY `metadata` JSON"); err != nil {
+		return fmt.Errorf("Error changing door43_metadata metadata column type: %v", err)
+	}
+	return nil
+}
+
+// Door43MetadataColumnIsNativeJSON returns true if the metadata column is of the JSON type of the database, always on
+// the databases without one. On PostgreSQL it also has to have the index of the metadata.<path>=<value> filters
+func Door43MetadataColumnIsNativeJSON(ctx context.Context) (bool, error) {
+	var schema, columnType string
+	switch {
+	case setting.Database.Type.IsMySQL():
+		schema, columnType = "DATABASE()", "json"
+	case setting.Database.Type.IsPostgreSQL():
+		schema, columnType = "CURRENT_SCHEMA()", "jsonb"
+	default:
+		return true, nil
+	}
+	var dataType string
+	if _, err := db.GetEngine(ctx).
+		SQL("SELECT data_type FROM information_schema.columns WHERE table_schema = " + schema +
+			" AND table_name = 'door43_metadata' AND column_name = 'metadata'").
+		Get(&dataType); err != nil {
+		return false, fmt.Errorf("Error getting door43_metadata metadata column type: %v", err)
+	}
+	if strings.ToLower(dataType) != columnType {
+		return false, nil
+	}
+	if setting.Database.Type.IsPostgreSQL() {
+		return db.GetEngine(ctx).Table("pg_indexes").
+			Where("schemaname = CURRENT_SCHEMA() AND indexname = ?", door43MetadataGINIndex).
+			Exist()
+	}
+	return true, nil
+}
+
+// door43MetadataGINIndex is the index of the metadata column on PostgreSQL
+const door43MetadataGINIndex = "IDX_door43_metadata_metadata_gin"
+
+// ConvertDoor43MetadataColumnToNativeJSON changes the metadata column to the JSON type of the database and indexes it
+// on PostgreSQL. It rewrites the whole table and fails if any metadata is not valid JSON
+func ConvertDoor43MetadataColumnToNativeJSON(ctx context.Context) error {
+	isNative, err := Door43MetadataColumnIsNativeJSON(ctx)
+	if err != nil || isNative {
+		return err
+	}
+	switch {
+	case setting.Database.Type.IsMySQL():
+		_, err = db.GetEngine(ctx).Exec("ALTER TABLE `door43_metadata` MODIFY `metadata` JSON")
+	case setting.Database.Type.IsPostgreSQL():
+		if _, err = db.GetEngine(ctx).Exec("ALTER TABLE `door43_metadata` ALTER COLUMN `metadata` TYPE JSONB USING `metadata`::jsonb"); err != nil {
+			break
 		}
+		// Lets the catalog's metadata.<path>=<value> filters, which use jsonb containment, use an index
+		_, err = db.GetEngine(ctx).Exec("CREATE INDEX IF NOT EXISTS `" + door43MetadataGINIndex + "` ON `door43_metadata` USING GIN (`metadata` jsonb_path_ops)")
+	}
+	if err != nil {
+		return fmt.Errorf("Error changing door43_metadata metadata column type: %v", err)
 	}
 	return nil
 }
@@ -41,33 +106,37 @@ func InitDoor43Metadata() error {
 
 // Door43Metadata represents the metadata of repository's release or default branch (ReleaseID = 0).
 type Door43Metadata struct {
-	ID                int64                   `xorm:"pk autoincr"`
-	RepoID            int64                   `xorm:"INDEX UNIQUE(repo_ref) NOT NULL"`
-	Repo              *Repository             `xorm:"-"`
-	ReleaseID         int64                   `xorm:"NOT NULL"`
-	Release           *Release                `xorm:"-"`
-	Ref               string                  `xorm:"INDEX UNIQUE(repo_ref) NOT NULL"`
-	RefType           string                  `xorm:"NOT NULL"`
-	CommitSHA         string                  `xorm:"NOT NULL VARCHAR(40)"`
-	Stage             door43metadata.Stage    `xorm:"INDEX NOT NULL"`
-	MetadataType      string                  `xorm:"INDEX NOT NULL"`
-	MetadataVersion   string                  `xorm:"NOT NULL"`
-	Resource          string                  `xorm:"NOT NULL"`
-	Subject           string                  `xorm:"INDEX NOT NULL"`
-	Title             string                  `xorm:"NOT NULL"`
-	Language          string                  `xorm:"INDEX NOT NULL"`
-	LanguageTitle     string                  `xorm:"NOT NULL"`
-	LanguageDirection string                  `xorm:"NOT NULL"`
-	LanguageIsGL      bool                    `xorm:"NOT NULL"`
-	ContentFormat     string                  `xorm:"NOT NULL"`
-	CheckingLevel     int                     `xorm:"NOT NULL"`
-	Ingredients       []*structs.Ingredient   `xorm:"JSON"`
-	Metadata          *map[string]interface{} `xorm:"JSON"`
-	ReleaseDateUnix   timeutil.TimeStamp      `xorm:"NOT NULL"`
-	IsLatestForStage  bool                    `xorm:"INDEX"`
-	IsRepoMetadata    bool                    `xorm:"INDEX"`
-	CreatedUnix       timeutil.TimeStamp      `xorm:"INDEX created NOT NULL"`
-	UpdatedUnix       timeutil.TimeStamp      `xorm:"INDEX updated"`
+	ID                    int64                   `xorm:"pk autoincr"`
+	RepoID                int64                   `xorm:"INDEX UNIQUE(repo_ref) NOT NULL"`
+	Repo                  *Repository             `xorm:"-"`
+	ReleaseID             int64                   `xorm:"NOT NULL"`
+	Release               *Release                `xorm:"-"`
+	BrokenLinks           []*AttachmentLinkStatus `xorm:"-"` // statuses of the broken links of the release's attachments, nil until loaded
+	Ref                   string                  `xorm:"INDEX UNIQUE(repo_ref) NOT NULL"`
+	RefType               string                  `xorm:"NOT NULL"`
+	CommitSHA             string                  `xorm:"NOT NULL VARCHAR(40)"`
+	Stage                 door43metadata.Stage    `xorm:"INDEX NOT NULL"`
+	MetadataType          string                  `xorm:"INDEX NOT NULL"`
+	MetadataVersion       string                  `xorm:"NOT NULL"`
+	Resource              string                  `xorm:"NOT NULL"`
+	Subject               string                  `xorm:"INDEX NOT NULL"`
+	Title                 string                  `xorm:"NOT NULL"`
+	Language              string                  `xorm:"INDEX NOT NULL"`
+	LanguageTitle         string                  `xorm:"NOT NULL"`
+	LanguageDirection     string                  `xorm:"NOT NULL"`
+	LanguageIsGL          bool                    `xorm:"NOT NULL"`
+	ContentFormat         string                  `xorm:"NOT NULL"`
+	CheckingLevel         int                     `xorm:"NOT NULL"`
+	VerifiedCheckingLevel int                     `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Ingredients           []*structs.Ingredient   `xorm:"JSON"`
+	Metadata              *map[string]interface{} `xorm:"JSON"`
+	ReleaseDateUnix       timeutil.TimeStamp      `xorm:"NOT NULL"`
+	IsLatestForStage      bool                    `xorm:"INDEX"`
+	IsRepoMetadata        bool                    `xorm:"INDEX"`
+	ProcessingVersion     int                     `xorm:"NOT NULL DEFAULT 0"` // version of the processing that stored the entry
+	IsRevision            bool                    `xorm:"-"`                  // a previous version of the entry, from its revisions
+	CreatedUnix           timeutil.TimeStamp      `xorm:"INDEX created NOT NULL"`
+	UpdatedUnix           timeutil.TimeStamp      `xorm:"INDEX updated"`
 }
 
 func init() {
@@ -101,6 +170,10 @@ func (dm *Door43Metadata) LoadRelease(ctx context.Context) error {
 	if dm.Release != nil {
 		dm.Release.Door43Metadata = dm
 		dm.Release.Repo = dm.Repo
+		if dm.Release.Publisher != nil && dm.Release.Attachments != nil {
+			// already loaded, such as by Door43MetadataList.LoadReleases
+			return nil
+		}
 		if err := dm.Release.LoadAttributes(ctx); err != nil {
 			log.Warn("LoadRelease - calling dm.Release.loadAttributes Error: %v\n", err)
 			return err
@@ -109,6 +182,14 @@ func (dm *Door43Metadata) LoadRelease(ctx context.Context) error {
 	return nil
 }
 
+// LoadBrokenLinks loads the statuses of the broken links of the attachments of the entry's release
+func (dm *Door43Metadata) LoadBrokenLinks(ctx context.Context) error {
+	if dm.BrokenLinks != nil {
+		return nil
+	}
+	return Door43MetadataList{dm}.LoadBrokenLinks(ctx)
+}
+
 // LoadAttributes load repo and release attributes for a door43 metadata
 func (dm *Door43Metadata) LoadAttributes(ctx context.Context) error {
 	if err := dm.LoadRepo(ctx); err != nil {
@@ -144,6 +225,24 @@ func (dm *Door43Metadata) GetZipballURL() string {
 	return fmt.Sprintf("%s/archive/%s.zip", dm.Repo.HTMLURL(), dm.Ref)
 }
 
+// GetPackagedZipballURL get the URL of the zip archive of the tag or branch packaged according to the entry's
+// metadata type, a resource container or a scripture burrito, empty if there is none for the type
+func (dm *Door43Metadata) GetPackagedZipballURL() string {
+	var ext string
+	switch dm.MetadataType {
+	case "rc":
+		ext = "rc.zip"
+	case "sb":
+		ext = "burrito.zip"
+	default:
+		return ""
+	}
+	if dm.RefType == "branch" {
+		return fmt.Sprintf("%s/archive/%s.%s", dm.Repo.HTMLURL(), dm.CommitSHA[0:10], ext)
+	}
+	return fmt.Sprintf("%s/archive/%s.%s", dm.Repo.HTMLURL(), dm.Ref, ext)
+}
+
 // GetReleaseURL get the URL the release API
 func (dm *Door43Metadata) GetReleaseURL(ctx context.Context) string {
 	if dm.ReleaseID > 0 {
@@ -151,7 +250,7 @@ func (dm *Door43Metadata) GetReleaseURL(ctx context.Context) string {
 			return dm.Release.APIURL()
 		}
 		if err := dm.LoadRepo(ctx); err == nil {
-			return fmt.Sprintf("%sapi/v1/repos/%s/releases/%d", setting.AppURL, dm.Repo.FullName(), dm.ReleaseID)
+			return fmt.Sprintf("%s/releases/%d", dm.Repo.APIURL(), dm.ReleaseID)
 		}
 	}
 	return ""
@@ -236,6 +335,21 @@ func (dm *Door43Metadata) GetContentsURL() string {
 	return fmt.Sprintf("%s/contents?ref=%s", dm.Repo.APIURL(), dm.Ref)
 }
 
+// GetProgressURL gets the URL of the page of the translation progress of the tag or branch
+func (dm *Door43Metadata) GetProgressURL() string {
+	return fmt.Sprintf("%s/metadata/progress?ref=%s", dm.Repo.HTMLURL(), url.QueryEscape(dm.Ref))
+}
+
+// HasIngredientsProgress returns true if the translation progress of any of the ingredients has been computed
+func (dm *Door43Metadata) HasIngredientsProgress() bool {
+	for _, ing := range dm.Ingredients {
+		if ing.Progress != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // GetIngredientsIdentifierList get the identifiers of the igredients and returns them as a list of strings
 func (dm *Door43Metadata) GetIngredientsIdentifierList() []string {
 	var ids []string
@@ -292,10 +406,12 @@ func IsDoor43MetadataExist(ctx context.Context, repoID, releaseID int64) (bool,
 
 // InsertDoor43Metadata inserts a door43 metadata
 func InsertDoor43Metadata(ctx context.Context, dm *Door43Metadata) error {
-	if id, err := db.GetEngine(ctx).Insert(dm); err != nil {
+	if affected, err := db.GetEngine(ctx).Insert(dm); err != nil {
 		return err
-	} else if id > 0 {
-		dm.ID = id
+	} else if affected > 0 {
+		if err := SyncDoor43MetadataIngredients(ctx, dm); err != nil {
+			return err
+		}
 		if err := dm.LoadRepo(ctx); err != nil {
 			return err
 		}
@@ -314,13 +430,31 @@ func InsertDoor43Metadata(ctx context.Context, dm *Door43Metadata) error {
 
 // InsertDoor43Metadatas inserts door43 metadatas
 func InsertDoor43Metadatas(ctx context.Context, dms []*Door43Metadata) error {
-	_, err := db.GetEngine(ctx).Insert(dms)
-	return err
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		// Inserted one by one as the IDs are needed for the ingredients
+		for _, dm := range dms {
+			if _, err := db.GetEngine(ctx).Insert(dm); err != nil {
+				return err
+			}
+			if err := SyncDoor43MetadataIngredients(ctx, dm); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // UpdateDoor43MetadataCols update door43 metadata according special columns
 func UpdateDoor43MetadataCols(ctx context.Context, dm *Door43Metadata, cols ...string) error {
 	id, err := db.GetEngine(ctx).ID(dm.ID).Cols(cols...).Update(dm)
+	if err != nil {
+		return err
+	}
+	if util.SliceContainsString(cols, "ingredients", true) {
+		if err := SyncDoor43MetadataIngredients(ctx, dm); err != nil {
+			return err
+		}
+	}
 	if id > 0 && dm.ReleaseID > 0 {
 		err := dm.LoadRepo(ctx)
 		if err != nil {
@@ -336,6 +470,12 @@ func UpdateDoor43MetadataCols(ctx context.Context, dm *Door43Metadata, cols ...s
 // UpdateDoor43Metadata update a;ll door43 metadata
 func UpdateDoor43Metadata(ctx context.Context, dm *Door43Metadata) error {
 	id, err := db.GetEngine(ctx).ID(dm.ID).AllCols().Update(dm)
+	if err != nil {
+		return err
+	}
+	if err := SyncDoor43MetadataIngredients(ctx, dm); err != nil {
+		return err
+	}
 	if id > 0 && dm.ReleaseID > 0 {
 		err := dm.LoadRepo(ctx)
 		if err != nil {
@@ -362,6 +502,18 @@ func GetDoor43MetadataByID(ctx context.Context, id, repoID int64) (*Door43Metada
 	return dm, nil
 }
 
+// GetDoor43MetadataMapByIDs returns the door43 metadata of a repo with the given IDs by ID
+func GetDoor43MetadataMapByIDs(ctx context.Context, repoID int64, ids []int64) (map[int64]*Door43Metadata, error) {
+	dms := make(map[int64]*Door43Metadata, len(ids))
+	if len(ids) == 0 {
+		return dms, nil
+	}
+	return dms, db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repoID}).
+		And(builder.In("id", ids)).
+		Find(&dms)
+}
+
 // GetMostRecentDoor43MetadataByStage returns the most recent Door43Metadatas of a given stage for a repo
 func GetMostRecentDoor43MetadataByStage(ctx context.Context, repoID int64, stage door43metadata.Stage) (*Door43Metadata, error) {
 	dm := &Door43Metadata{RepoID: repoID, Stage: stage}
@@ -391,6 +543,30 @@ func GetDoor43MetadataByRepoIDAndRef(ctx context.Context, repoID int64, ref stri
 	return dm, nil
 }
 
+// GetDoor43MetadataByRepoIDAndCommitSHA returns the most recently updated metadata of a commit of a repo
+func GetDoor43MetadataByRepoIDAndCommitSHA(ctx context.Context, repoID int64, commitSHA string) (*Door43Metadata, error) {
+	dm := &Door43Metadata{
+		RepoID:    repoID,
+		CommitSHA: commitSHA,
+	}
+	has, err := db.GetEngine(ctx).Desc("updated_unix").Get(dm)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDoor43MetadataNotExist{0, repoID, commitSHA}
+	}
+	return dm, nil
+}
+
+// GetDoor43MetadataIDsByRepoID returns the IDs of all the door43 metadata entries of a repo
+func GetDoor43MetadataIDsByRepoID(ctx context.Context, repoID int64) ([]int64, error) {
+	ids := make([]int64, 0, 10)
+	return ids, db.GetEngine(ctx).Table("door43_metadata").
+		Where(builder.Eq{"repo_id": repoID}).
+		Cols("id").
+		Find(&ids)
+}
+
 // GetDoor43MetadataMapValues gets the values of a Door43Metadata map
 func GetDoor43MetadataMapValues(m map[int64]*Door43Metadata) []*Door43Metadata {
 	values := make([]*Door43Metadata, 0, len(m))
@@ -424,18 +600,162 @@ func Door43MetadataListOfMap(dmMap map[int64]*Door43Metadata) Door43MetadataList
 	return Door43MetadataList(GetDoor43MetadataMapValues(dmMap))
 }
 
-// LoadAttributes loads the attributes for the given Door43MetadataList
+// LoadAttributes loads the repos, with their units and latest DMs, and the releases of the given Door43MetadataList
+// in batches, so the number of queries doesn't grow with the number of entries
 func (dms Door43MetadataList) LoadAttributes(ctx context.Context) error {
 	if len(dms) == 0 {
 		return nil
 	}
-	var lastErr error
+	if err := dms.LoadRepos(ctx); err != nil {
+		return err
+	}
+	if err := dms.LoadReleases(ctx); err != nil {
+		return err
+	}
+	if err := dms.LoadBrokenLinks(ctx); err != nil {
+		return err
+	}
+	return dms.getRepos().LoadLatestDMs(ctx)
+}
+
+// getRepos returns the distinct loaded repos of the entries
+func (dms Door43MetadataList) getRepos() RepositoryList {
+	repos := make(RepositoryList, 0, len(dms))
+	seen := make(container.Set[int64])
 	for _, dm := range dms {
-		if err := dm.LoadAttributes(ctx); err != nil && lastErr == nil {
-			lastErr = err
+		if dm.Repo != nil && seen.Add(dm.Repo.ID) {
+			repos = append(repos, dm.Repo)
 		}
 	}
-	return lastErr
+	return repos
+}
+
+// LoadRepos loads the repos of the entries with their owners and units
+func (dms Door43MetadataList) LoadRepos(ctx context.Context) error {
+	repoIDs := make(container.Set[int64])
+	for _, dm := range dms {
+		if dm.Repo == nil {
+			repoIDs.Add(dm.RepoID)
+		}
+	}
+	if len(repoIDs) > 0 {
+		repoMap, err := GetRepositoriesMapByIDs(ctx, repoIDs.Values())
+		if err != nil {
+			return fmt.Errorf("GetRepositoriesMapByIDs: %w", err)
+		}
+		for _, dm := range dms {
+			if dm.Repo != nil {
+				continue
+			}
+			repo, ok := repoMap[dm.RepoID]
+			if !ok {
+				return ErrRepoNotExist{dm.RepoID, 0, "", ""}
+			}
+			dm.Repo = repo
+		}
+	}
+
+	repos := dms.getRepos()
+	ownerIDs := make(container.Set[int64])
+	for _, repo := range repos {
+		if repo.Owner == nil {
+			ownerIDs.Add(repo.OwnerID)
+		}
+	}
+	if len(ownerIDs) > 0 {
+		owners := make(map[int64]*user_model.User, len(ownerIDs))
+		if err := db.GetEngine(ctx).In("id", ownerIDs.Values()).Find(&owners); err != nil {
+			return fmt.Errorf("find owners: %w", err)
+		}
+		for _, repo := range repos {
+			if repo.Owner == nil {
+				owner, ok := owners[repo.OwnerID]
+				if !ok {
+					return user_model.ErrUserNotExist{UID: repo.OwnerID}
+				}
+				repo.Owner = owner
+			}
+		}
+	}
+
+	return repos.LoadUnits(ctx)
+}
+
+// LoadReleases loads the releases of the entries with their publishers and attachments
+func (dms Door43MetadataList) LoadReleases(ctx context.Context) error {
+	releaseIDs := make(container.Set[int64])
+	for _, dm := range dms {
+		if dm.ReleaseID > 0 && dm.Release == nil {
+			releaseIDs.Add(dm.ReleaseID)
+		}
+	}
+	if len(releaseIDs) > 0 {
+		releaseMap := make(map[int64]*Release, len(releaseIDs))
+		if err := db.GetEngine(ctx).In("id", releaseIDs.Values()).Find(&releaseMap); err != nil {
+			return fmt.Errorf("find releases: %w", err)
+		}
+		for _, dm := range dms {
+			if dm.ReleaseID > 0 && dm.Release == nil {
+				// a missing release is left nil as with LoadAttributes of a single entry
+				dm.Release = releaseMap[dm.ReleaseID]
+			}
+		}
+	}
+
+	rels := make([]*Release, 0, len(dms))
+	publisherIDs := make(container.Set[int64])
+	for _, dm := range dms {
+		if dm.Release == nil {
+			continue
+		}
+		dm.Release.Door43Metadata = dm
+		dm.Release.Repo = dm.Repo
+		if dm.Release.Publisher == nil {
+			publisherIDs.Add(dm.Release.PublisherID)
+		}
+		if dm.Release.Attachments == nil {
+			rels = append(rels, dm.Release)
+		}
+	}
+	if len(publisherIDs) > 0 {
+		publishers := make(map[int64]*user_model.User, len(publisherIDs))
+		if err := db.GetEngine(ctx).In("id", publisherIDs.Values()).Find(&publishers); err != nil {
+			return fmt.Errorf("find publishers: %w", err)
+		}
+		for _, dm := range dms {
+			if dm.Release != nil && dm.Release.Publisher == nil {
+				if publisher, ok := publishers[dm.Release.PublisherID]; ok {
+					dm.Release.Publisher = publisher
+				} else {
+					dm.Release.Publisher = user_model.NewGhostUser()
+				}
+			}
+		}
+	}
+	return GetReleaseAttachments(ctx, rels...)
+}
+
+// LoadBrokenLinks loads the statuses of the broken links of the attachments of the releases of the entries
+func (dms Door43MetadataList) LoadBrokenLinks(ctx context.Context) error {
+	releaseIDs := make(container.Set[int64])
+	for _, dm := range dms {
+		if dm.ReleaseID > 0 && dm.BrokenLinks == nil {
+			releaseIDs.Add(dm.ReleaseID)
+		}
+	}
+	statuses, err := GetBrokenAttachmentLinkStatusesByReleaseIDs(ctx, releaseIDs.Values())
+	if err != nil {
+		return err
+	}
+	for _, dm := range dms {
+		if dm.BrokenLinks == nil {
+			dm.BrokenLinks = statuses[dm.ReleaseID]
+			if dm.BrokenLinks == nil {
+				dm.BrokenLinks = []*AttachmentLinkStatus{}
+			}
+		}
+	}
+	return nil
 }
 
 /*** END Door43MEtadataList ***/
@@ -475,6 +795,12 @@ func DeleteDoor43MetadataByID(ctx context.Context, id, repoID int64) error {
 // DeleteDoor43Metadata deletes a metadata from database by given ID.
 func DeleteDoor43Metadata(ctx context.Context, dm *Door43Metadata) error {
 	id, err := db.GetEngine(ctx).Delete(dm)
+	if err != nil {
+		return err
+	}
+	if err := DeleteDoor43MetadataIngredients(ctx, dm.ID); err != nil {
+		return err
+	}
 	if id > 0 && dm.ReleaseID > 0 {
 		if err := dm.LoadRepo(ctx); err != nil {
 			return err
@@ -494,12 +820,17 @@ func DeleteDoor43MetadataByRepoRef(ctx context.Context, repo *Repository, ref st
 		}
 		return nil
 	}
-	_, err = db.GetEngine(db.DefaultContext).ID(dm.ID).Delete(dm)
-	return err
+	if _, err = db.GetEngine(ctx).ID(dm.ID).Delete(dm); err != nil {
+		return err
+	}
+	return DeleteDoor43MetadataIngredients(ctx, dm.ID)
 }
 
 // DeleteAllDoor43MetadatasByRepoID deletes all metadatas from database for a repo by given repo ID.
 func DeleteAllDoor43MetadatasByRepoID(ctx context.Context, repoID int64) (int64, error) {
+	if err := DeleteDoor43MetadataIngredientsByRepoID(ctx, repoID); err != nil {
+		return 0, err
+	}
 	return db.GetEngine(ctx).Delete(Door43Metadata{RepoID: repoID})
 }
 
@@ -0,0 +1,255 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// MaxCheckingLevel is the highest checking level a resource can be verified at
+const MaxCheckingLevel = 3
+
+// IsValidCheckingLevel returns true if the given level is a valid checking level
+func IsValidCheckingLevel(level int) bool {
+	return level >= 1 && level <= MaxCheckingLevel
+}
+
+/*** START Door43Checker ***/
+
+// Door43Checker is a user designated by an owner (user or org) to verify the owner's resources up to a checking level
+type Door43Checker struct {
+	ID          int64              `xorm:"pk autoincr"`
+	OwnerID     int64              `xorm:"INDEX UNIQUE(owner_checker) NOT NULL"`
+	CheckerID   int64              `xorm:"INDEX UNIQUE(owner_checker) NOT NULL"`
+	Checker     *user_model.User   `xorm:"-"`
+	MaxLevel    int                `xorm:"NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created NOT NULL"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+func init() {
+	db.RegisterModel(new(Door43Checker))
+	db.RegisterModel(new(Door43Check))
+}
+
+// LoadChecker loads the user of the checker
+func (c *Door43Checker) LoadChecker(ctx context.Context) error {
+	if c.Checker == nil {
+		checker, err := user_model.GetUserByID(ctx, c.CheckerID)
+		if err != nil {
+			return err
+		}
+		c.Checker = checker
+	}
+	return nil
+}
+
+// GetDoor43Checker returns the checker designated by the owner
+func GetDoor43Checker(ctx context.Context, ownerID, checkerID int64) (*Door43Checker, error) {
+	c := &Door43Checker{OwnerID: ownerID, CheckerID: checkerID}
+	has, err := db.GetEngine(ctx).Get(c)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDoor43CheckerNotExist{ownerID, checkerID}
+	}
+	return c, nil
+}
+
+// GetDoor43CheckersByOwnerID returns all the checkers designated by the owner
+func GetDoor43CheckersByOwnerID(ctx context.Context, ownerID int64) ([]*Door43Checker, error) {
+	checkers := make([]*Door43Checker, 0, 10)
+	if err := db.GetEngine(ctx).
+		Where(builder.Eq{"owner_id": ownerID}).
+		OrderBy("max_level DESC, created_unix").
+		Find(&checkers); err != nil {
+		return nil, err
+	}
+	for _, c := range checkers {
+		if err := c.LoadChecker(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return checkers, nil
+}
+
+// SetDoor43Checker designates a user as a checker of the owner's resources up to the given level, updating an existing designation
+func SetDoor43Checker(ctx context.Context, ownerID, checkerID int64, maxLevel int) (*Door43Checker, error) {
+	if !IsValidCheckingLevel(maxLevel) {
+		return nil, ErrInvalidCheckingLevel{maxLevel}
+	}
+	c, err := GetDoor43Checker(ctx, ownerID, checkerID)
+	if err != nil {
+		if !IsErrDoor43CheckerNotExist(err) {
+			return nil, err
+		}
+		c = &Door43Checker{OwnerID: ownerID, CheckerID: checkerID, MaxLevel: maxLevel}
+		if _, err := db.GetEngine(ctx).Insert(c); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	c.MaxLevel = maxLevel
+	if _, err := db.GetEngine(ctx).ID(c.ID).Cols("max_level").Update(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DeleteDoor43Checker removes the designation of a user as a checker of the owner's resources
+func DeleteDoor43Checker(ctx context.Context, ownerID, checkerID int64) error {
+	_, err := db.GetEngine(ctx).Delete(&Door43Checker{OwnerID: ownerID, CheckerID: checkerID})
+	return err
+}
+
+/*** END Door43Checker ***/
+
+/*** START Door43Check ***/
+
+// Door43Check is a sign-off by a checker that a repo's commit has been checked at a given level
+type Door43Check struct {
+	ID          int64              `xorm:"pk autoincr"`
+	RepoID      int64              `xorm:"INDEX(repo_commit) NOT NULL"`
+	CommitSHA   string             `xorm:"INDEX(repo_commit) NOT NULL VARCHAR(40)"`
+	CheckerID   int64              `xorm:"INDEX NOT NULL"`
+	Checker     *user_model.User   `xorm:"-"`
+	Level       int                `xorm:"NOT NULL"`
+	Note        string             `xorm:"TEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created NOT NULL"`
+}
+
+// LoadChecker loads the user who signed off the check
+func (c *Door43Check) LoadChecker(ctx context.Context) error {
+	if c.Checker == nil {
+		checker, err := user_model.GetUserByID(ctx, c.CheckerID)
+		if err != nil {
+			return err
+		}
+		c.Checker = checker
+	}
+	return nil
+}
+
+// InsertDoor43Check inserts a check
+func InsertDoor43Check(ctx context.Context, c *Door43Check) error {
+	_, err := db.GetEngine(ctx).Insert(c)
+	return err
+}
+
+// GetDoor43ChecksByRepoIDAndCommitSHA returns all checks signed off for a repo's commit
+func GetDoor43ChecksByRepoIDAndCommitSHA(ctx context.Context, repoID int64, commitSHA string) ([]*Door43Check, error) {
+	checks := make([]*Door43Check, 0, 5)
+	if err := db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repoID, "commit_sha": commitSHA}).
+		OrderBy("created_unix DESC").
+		Find(&checks); err != nil {
+		return nil, err
+	}
+	for _, c := range checks {
+		if err := c.LoadChecker(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return checks, nil
+}
+
+// GetVerifiedCheckingLevel returns the highest level a repo's commit has been checked at by a checker
+// who is still designated by the repo's owner for that level, or 0 if it has not been verified
+func GetVerifiedCheckingLevel(ctx context.Context, repo *Repository, commitSHA string) (int, error) {
+	var level int
+	_, err := db.GetEngine(ctx).Table("door43_check").
+		Select("COALESCE(MAX(`door43_check`.level), 0)").
+		Join("INNER", "door43_checker", "`door43_checker`.checker_id = `door43_check`.checker_id AND `door43_checker`.max_level >= `door43_check`.level").
+		Where(builder.Eq{
+			"`door43_check`.repo_id":    repo.ID,
+			"`door43_check`.commit_sha": commitSHA,
+			"`door43_checker`.owner_id": repo.OwnerID,
+		}).
+		Get(&level)
+	return level, err
+}
+
+// UpdateVerifiedCheckingLevel recalculates the verified checking level of all door43 metadatas of a repo's commit
+func UpdateVerifiedCheckingLevel(ctx context.Context, repo *Repository, commitSHA string) (int, error) {
+	level, err := GetVerifiedCheckingLevel(ctx, repo, commitSHA)
+	if err != nil {
+		return 0, err
+	}
+	_, err = db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repo.ID, "commit_sha": commitSHA}).
+		Cols("verified_checking_level").
+		Update(&Door43Metadata{VerifiedCheckingLevel: level})
+	return level, err
+}
+
+// GetCheckedCommitsByOwnerAndChecker returns the repo IDs and commits of the owner's repos that the checker has signed off
+func GetCheckedCommitsByOwnerAndChecker(ctx context.Context, ownerID, checkerID int64) ([]*Door43Check, error) {
+	checks := make([]*Door43Check, 0, 10)
+	err := db.GetEngine(ctx).
+		Select("DISTINCT `door43_check`.repo_id, `door43_check`.commit_sha").
+		Join("INNER", "repository", "`repository`.id = `door43_check`.repo_id").
+		Where(builder.Eq{"`repository`.owner_id": ownerID, "`door43_check`.checker_id": checkerID}).
+		Find(&checks)
+	return checks, err
+}
+
+/*** END Door43Check ***/
+
+/*** Error Structs & Functions ***/
+
+// ErrDoor43CheckerNotExist represents a "Door43CheckerNotExist" kind of error.
+type ErrDoor43CheckerNotExist struct {
+	OwnerID   int64
+	CheckerID int64
+}
+
+// IsErrDoor43CheckerNotExist checks if an error is a ErrDoor43CheckerNotExist.
+func IsErrDoor43CheckerNotExist(err error) bool {
+	_, ok := err.(ErrDoor43CheckerNotExist)
+	return ok
+}
+
+func (err ErrDoor43CheckerNotExist) Error() string {
+	return fmt.Sprintf("user is not a designated checker [owner_id: %d, checker_id: %d]", err.OwnerID, err.CheckerID)
+}
+
+// ErrInvalidCheckingLevel represents a "InvalidCheckingLevel" kind of error.
+type ErrInvalidCheckingLevel struct {
+	Level int
+}
+
+// IsErrInvalidCheckingLevel checks if an error is a ErrInvalidCheckingLevel.
+func IsErrInvalidCheckingLevel(err error) bool {
+	_, ok := err.(ErrInvalidCheckingLevel)
+	return ok
+}
+
+func (err ErrInvalidCheckingLevel) Error() string {
+	return fmt.Sprintf("checking level is not valid [level: %d]", err.Level)
+}
+
+// ErrCheckingLevelNotAllowed represents a "CheckingLevelNotAllowed" kind of error.
+type ErrCheckingLevelNotAllowed struct {
+	Level    int
+	MaxLevel int
+}
+
+// IsErrCheckingLevelNotAllowed checks if an error is a ErrCheckingLevelNotAllowed.
+func IsErrCheckingLevelNotAllowed(err error) bool {
+	_, ok := err.(ErrCheckingLevelNotAllowed)
+	return ok
+}
+
+func (err ErrCheckingLevelNotAllowed) Error() string {
+	return fmt.Sprintf("checker is not allowed to verify at this checking level [level: %d, max_level: %d]", err.Level, err.MaxLevel)
+}
+
+/*** END Error Structs & Functions ***/
@@ -0,0 +1,100 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	"code.gitea.io/gitea/modules/container"
+
+	"xorm.io/builder"
+)
+
+// uniqueStages are the stages of which a repo can have only one latest entry
+var uniqueStages = []door43metadata.Stage{door43metadata.StageProd, door43metadata.StagePreProd, door43metadata.StageLatest}
+
+// findDoor43MetadataRepoIDs returns the distinct IDs of the repos of the entries matching the condition,
+// grouped by the given columns and filtered by the having clause if given
+func findDoor43MetadataRepoIDs(ctx context.Context, cond builder.Cond, groupBy, having string) ([]int64, error) {
+	sess := db.GetEngine(ctx).Table("door43_metadata").Select("repo_id").Where(cond)
+	if groupBy != "" {
+		sess = sess.GroupBy(groupBy)
+	}
+	if having != "" {
+		sess = sess.Having(having)
+	}
+	var repoIDs []int64
+	if err := sess.Find(&repoIDs); err != nil {
+		return nil, err
+	}
+	return container.SetOf(repoIDs...).Values(), nil
+}
+
+// GetRepoIDsWithInconsistentLatestDoor43Metadata returns the IDs of the repos having more than one latest entry
+// for the production, pre-production or default branch stage, or having production entries but no latest one
+func GetRepoIDsWithInconsistentLatestDoor43Metadata(ctx context.Context) ([]int64, error) {
+	several, err := findDoor43MetadataRepoIDs(ctx,
+		builder.In("stage", uniqueStages).And(builder.Eq{"is_latest_for_stage": true}),
+		"repo_id, stage", "COUNT(*) > 1")
+	if err != nil {
+		return nil, err
+	}
+	none, err := findDoor43MetadataRepoIDs(ctx,
+		builder.Eq{"stage": door43metadata.StageProd}.
+			And(builder.NotIn("repo_id", builder.Select("repo_id").From("door43_metadata").
+				Where(builder.Eq{"stage": door43metadata.StageProd, "is_latest_for_stage": true}))),
+		"", "")
+	if err != nil {
+		return nil, err
+	}
+	return container.SetOf(append(several, none...)...).Values(), nil
+}
+
+// GetRepoIDsWithInconsistentRepoDoor43Metadata returns the IDs of the repos having entries but not exactly one of them
+// being the repo's metadata
+func GetRepoIDsWithInconsistentRepoDoor43Metadata(ctx context.Context) ([]int64, error) {
+	several, err := findDoor43MetadataRepoIDs(ctx, builder.Eq{"is_repo_metadata": true}, "repo_id", "COUNT(*) > 1")
+	if err != nil {
+		return nil, err
+	}
+	none, err := findDoor43MetadataRepoIDs(ctx,
+		builder.NotIn("repo_id", builder.Select("repo_id").From("door43_metadata").Where(builder.Eq{"is_repo_metadata": true})),
+		"", "")
+	if err != nil {
+		return nil, err
+	}
+	return container.SetOf(append(several, none...)...).Values(), nil
+}
+
+// GetDoor43MetadatasWithInvalidRelease returns the entries of releases that don't exist, are drafts or are just tags
+func GetDoor43MetadatasWithInvalidRelease(ctx context.Context) (Door43MetadataList, error) {
+	dms := make(Door43MetadataList, 0, 10)
+	return dms, db.GetEngine(ctx).
+		Where(builder.Gt{"release_id": 0}).
+		And(builder.NotIn("release_id", builder.Select("id").From("`release`").
+			Where(builder.Eq{"is_draft": false, "is_tag": false}))).
+		Find(&dms)
+}
+
+// GetRepoIDsOfDoor43MetadataNotInCatalog returns the IDs of the repos with entries that are private, archived
+// or no longer exist, which shouldn't have any
+func GetRepoIDsOfDoor43MetadataNotInCatalog(ctx context.Context) ([]int64, error) {
+	return findDoor43MetadataRepoIDs(ctx,
+		builder.NotIn("repo_id", builder.Select("id").From("repository").
+			Where(builder.Eq{"is_private": false, "is_archived": false})),
+		"", "")
+}
+
+// GetDoor43MetadataRepoIDs returns the IDs of all the repos with entries
+func GetDoor43MetadataRepoIDs(ctx context.Context) ([]int64, error) {
+	return findDoor43MetadataRepoIDs(ctx, builder.NewCond(), "", "")
+}
+
+// GetDoor43MetadatasByRepoID returns all the entries of a repo
+func GetDoor43MetadatasByRepoID(ctx context.Context, repoID int64) (Door43MetadataList, error) {
+	dms := make(Door43MetadataList, 0, 10)
+	return dms, db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID}).Asc("id").Find(&dms)
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/container"
+
+	"xorm.io/builder"
+)
+
+// Door43MetadataIngredient is a book (ingredient identifier) of a door43 metadata entry, normalized from
+// the entry's ingredients JSON so the catalog can be searched and counted by book on any database
+type Door43MetadataIngredient struct {
+	ID               int64  `xorm:"pk autoincr"`
+	Door43MetadataID int64  `xorm:"INDEX NOT NULL"`
+	RepoID           int64  `xorm:"INDEX NOT NULL"`
+	Identifier       string `xorm:"INDEX NOT NULL"` // lower case
+	Title            string
+	Path             string
+	Sort             int
+	// verses, or frames of OBS, with content and expected, both 0 if the progress isn't tracked
+	ProgressCompleted int `xorm:"NOT NULL DEFAULT 0"`
+	ProgressTotal     int `xorm:"INDEX NOT NULL DEFAULT 0"`
+}
+
+func init() {
+	db.RegisterModel(new(Door43MetadataIngredient))
+}
+
+// door43MetadataIngredientsOf returns the distinct ingredients of the entry to store
+func door43MetadataIngredientsOf(dm *Door43Metadata) []*Door43MetadataIngredient {
+	ingredients := make([]*Door43MetadataIngredient, 0, len(dm.Ingredients))
+	seen := make(container.Set[string])
+	for _, ing := range dm.Ingredients {
+		if ing == nil {
+			continue
+		}
+		identifier := strings.ToLower(strings.TrimSpace(ing.Identifier))
+		if identifier == "" || !seen.Add(identifier) {
+			continue
+		}
+		ingredient := &Door43MetadataIngredient{
+			Door43MetadataID: dm.ID,
+			RepoID:           dm.RepoID,
+			Identifier:       identifier,
+			Title:            ing.Title,
+			Path:             ing.Path,
+			Sort:             ing.Sort,
+		}
+		if ing.Progress != nil {
+			ingredient.ProgressCompleted = ing.Progress.Completed
+			ingredient.ProgressTotal = ing.Progress.Total
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+	return ingredients
+}
+
+// SyncDoor43MetadataIngredients replaces the stored ingredients of the entry with its current ingredients
+func SyncDoor43MetadataIngredients(ctx context.Context, dm *Door43Metadata) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where(builder.Eq{"door43_metadata_id": dm.ID}).Delete(&Door43MetadataIngredient{}); err != nil {
+			return err
+		}
+		ingredients := door43MetadataIngredientsOf(dm)
+		if len(ingredients) == 0 {
+			return nil
+		}
+		_, err := db.GetEngine(ctx).Insert(ingredients)
+		return err
+	})
+}
+
+// DeleteDoor43MetadataIngredients deletes the stored ingredients of the entries with the given IDs
+func DeleteDoor43MetadataIngredients(ctx context.Context, dmIDs ...int64) error {
+	if len(dmIDs) == 0 {
+		return nil
+	}
+	_, err := db.GetEngine(ctx).In("door43_metadata_id", dmIDs).Delete(&Door43MetadataIngredient{})
+	return err
+}
+
+// DeleteDoor43MetadataIngredientsByRepoID deletes the stored ingredients of all the entries of a repo
+func DeleteDoor43MetadataIngredientsByRepoID(ctx context.Context, repoID int64) error {
+	_, err := db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID}).Delete(&Door43MetadataIngredient{})
+	return err
+}
+
+// withoutStoredIngredientsCond matches the entries that have ingredients but none stored, such as the entries made
+// before the ingredients were stored in their own table
+func withoutStoredIngredientsCond() builder.Cond {
+	return builder.NotNull{"ingredients"}.
+		And(builder.NotIn("ingredients", "", "[]", "null")).
+		And(builder.NotIn("id", builder.Select("door43_metadata_id").From("door43_metadata_ingredient")))
+}
+
+// CountDoor43MetadatasWithoutStoredIngredients counts the entries that have ingredients but none stored
+func CountDoor43MetadatasWithoutStoredIngredients(ctx context.Context) (int64, error) {
+	return db.GetEngine(ctx).Where(withoutStoredIngredientsCond()).Count(new(Door43Metadata))
+}
+
+// StoreMissingDoor43MetadataIngredients stores the ingredients of the entries that have ingredients but none stored
+// and returns the number of entries whose ingredients got stored
+func StoreMissingDoor43MetadataIngredients(ctx context.Context) (int64, error) {
+	const batchSize = 200
+	var lastID, count int64
+	for {
+		dms := make([]*Door43Metadata, 0, batchSize)
+		err := db.GetEngine(ctx).
+			Select("id, repo_id, ingredients").
+			Where(builder.Gt{"id": lastID}).
+			And(withoutStoredIngredientsCond()).
+			Asc("id").
+			Limit(batchSize).
+			Find(&dms)
+		if err != nil {
+			return count, err
+		}
+		if len(dms) == 0 {
+			break
+		}
+		for _, dm := range dms {
+			lastID = dm.ID
+			ingredients := door43MetadataIngredientsOf(dm)
+			if len(ingredients) == 0 {
+				continue
+			}
+			if _, err := db.GetEngine(ctx).Insert(ingredients); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreMissingDoor43MetadataIngredients(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	withIngredients := &repo_model.Door43Metadata{RepoID: 1, Ref: "v1", RefType: "tag", Ingredients: []*structs.Ingredient{
+		{Identifier: "GEN", Path: "./01-GEN.usfm"},
+		{Identifier: "gen", Path: "./01-GEN-2.usfm"},
+		{Identifier: "exo", Path: "./02-EXO.usfm"},
+	}}
+	withoutIngredients := &repo_model.Door43Metadata{RepoID: 1, Ref: "master", RefType: "branch", Ingredients: []*structs.Ingredient{}}
+	assert.NoError(t, db.Insert(db.DefaultContext, withIngredients))
+	assert.NoError(t, db.Insert(db.DefaultContext, withoutIngredients))
+
+	count, err := repo_model.CountDoor43MetadatasWithoutStoredIngredients(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	stored, err := repo_model.StoreMissingDoor43MetadataIngredients(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, stored)
+	// the identifiers are stored lower case and once per entry
+	unittest.AssertCount(t, &repo_model.Door43MetadataIngredient{Door43MetadataID: withIngredients.ID}, 2)
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Door43MetadataIngredient{Door43MetadataID: withIngredients.ID, Identifier: "gen"})
+
+	count, err = repo_model.CountDoor43MetadatasWithoutStoredIngredients(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+
+	"xorm.io/builder"
+)
+
+// Door43ProgressOptions are the options to find the catalog entries of repos whose translation progress is tracked
+type Door43ProgressOptions struct {
+	db.ListOptions
+	OwnerID    int64
+	RepoID     int64
+	Languages  []string
+	Subjects   []string
+	PublicOnly bool // only the entries of repos that are public
+}
+
+// toConds returns the conditions on door43_metadata. Only the entry of each repo that represents it
+// (IsRepoMetadata) is reported, which is the entry of its default branch if it has one
+func (opts *Door43ProgressOptions) toConds() builder.Cond {
+	cond := builder.NewCond().
+		And(builder.Eq{"is_repo_metadata": true}).
+		And(builder.In("id", builder.Select("door43_metadata_id").From("door43_metadata_ingredient").Where(builder.Gt{"progress_total": 0})))
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.OwnerID > 0 {
+		cond = cond.And(builder.In("repo_id", builder.Select("id").From("repository").Where(builder.Eq{"owner_id": opts.OwnerID})))
+	}
+	if opts.PublicOnly {
+		cond = cond.And(builder.In("repo_id", builder.Select("id").From("repository").Where(builder.Eq{"is_private": false})))
+	}
+	if len(opts.Languages) > 0 {
+		cond = cond.And(builder.In("language", opts.Languages))
+	}
+	if len(opts.Subjects) > 0 {
+		cond = cond.And(builder.In("subject", opts.Subjects))
+	}
+	return cond
+}
+
+// Door43Progress is the sum of the translation progress of the ingredients of catalog entries
+type Door43Progress struct {
+	Completed int64
+	Total     int64
+}
+
+// Percent returns the percentage of the total which is completed
+func (p *Door43Progress) Percent() float64 {
+	if p == nil || p.Total == 0 {
+		return 0
+	}
+	return float64(p.Completed) * 100 / float64(p.Total)
+}
+
+// FindDoor43MetadatasWithProgress returns a page of the entries matching the options, sorted by language and title,
+// and the number of matching entries
+func FindDoor43MetadatasWithProgress(ctx context.Context, opts *Door43ProgressOptions) (Door43MetadataList, int64, error) {
+	sess := db.GetEngine(ctx).Where(opts.toConds()).Asc("language", "title", "id")
+	if opts.PageSize > 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	dms := make(Door43MetadataList, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&dms)
+	return dms, count, err
+}
+
+// GetDoor43ProgressTotal returns the sum of the translation progress of all the entries matching the options
+func GetDoor43ProgressTotal(ctx context.Context, opts *Door43ProgressOptions) (*Door43Progress, error) {
+	total := &Door43Progress{}
+	_, err := db.GetEngine(ctx).Table("door43_metadata_ingredient").
+		Select("COALESCE(SUM(progress_completed), 0) AS completed, COALESCE(SUM(progress_total), 0) AS total").
+		Where(builder.In("door43_metadata_id", builder.Select("id").From("door43_metadata").Where(opts.toConds()))).
+		Get(total)
+	return total, err
+}
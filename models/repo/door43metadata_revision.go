@@ -0,0 +1,263 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"bytes"
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// Door43MetadataTrigger is the event that caused a door43 metadata entry to be processed
+type Door43MetadataTrigger string
+
+// The events that can cause a door43 metadata entry to be processed
+const (
+	Door43MetadataTriggerPush          Door43MetadataTrigger = "push"
+	Door43MetadataTriggerRelease       Door43MetadataTrigger = "release"
+	Door43MetadataTriggerRepository    Door43MetadataTrigger = "repository"
+	Door43MetadataTriggerDefaultBranch Door43MetadataTrigger = "default_branch"
+	Door43MetadataTriggerManual        Door43MetadataTrigger = "manual"
+	Door43MetadataTriggerCron          Door43MetadataTrigger = "cron"
+	Door43MetadataTriggerCommandLine   Door43MetadataTrigger = "command_line"
+	Door43MetadataTriggerDoctor        Door43MetadataTrigger = "doctor"
+)
+
+// Door43MetadataRevision is a previous version of a door43 metadata entry of a repo's ref,
+// recorded when the entry was updated with different values
+type Door43MetadataRevision struct {
+	ID                    int64                   `xorm:"pk autoincr"`
+	Door43MetadataID      int64                   `xorm:"INDEX NOT NULL"`
+	RepoID                int64                   `xorm:"INDEX(repo_ref) NOT NULL"`
+	Ref                   string                  `xorm:"INDEX(repo_ref) NOT NULL"`
+	Trigger               Door43MetadataTrigger   `xorm:"NOT NULL"`
+	ChangedFields         []string                `xorm:"JSON"`
+	ReleaseID             int64                   `xorm:"NOT NULL DEFAULT 0"`
+	RefType               string                  `xorm:"NOT NULL"`
+	CommitSHA             string                  `xorm:"NOT NULL VARCHAR(40)"`
+	Stage                 door43metadata.Stage    `xorm:"NOT NULL"`
+	MetadataType          string                  `xorm:"NOT NULL"`
+	MetadataVersion       string                  `xorm:"NOT NULL"`
+	Resource              string                  `xorm:"NOT NULL"`
+	Subject               string                  `xorm:"NOT NULL"`
+	Title                 string                  `xorm:"NOT NULL"`
+	Language              string                  `xorm:"NOT NULL"`
+	LanguageTitle         string                  `xorm:"NOT NULL"`
+	LanguageDirection     string                  `xorm:"NOT NULL"`
+	LanguageIsGL          bool                    `xorm:"NOT NULL"`
+	ContentFormat         string                  `xorm:"NOT NULL"`
+	CheckingLevel         int                     `xorm:"NOT NULL"`
+	VerifiedCheckingLevel int                     `xorm:"NOT NULL DEFAULT 0"`
+	Ingredients           []*structs.Ingredient   `xorm:"JSON"`
+	Metadata              *map[string]interface{} `xorm:"JSON"`
+	ReleaseDateUnix       timeutil.TimeStamp      `xorm:"NOT NULL"`
+	ValidFromUnix         timeutil.TimeStamp      `xorm:"INDEX NOT NULL"`
+	CreatedUnix           timeutil.TimeStamp      `xorm:"INDEX created NOT NULL"`
+}
+
+func init() {
+	db.RegisterModel(new(Door43MetadataRevision))
+}
+
+// ToDoor43Metadata returns the door43 metadata entry as it was before the revision was made
+func (rev *Door43MetadataRevision) ToDoor43Metadata() *Door43Metadata {
+	return &Door43Metadata{
+		ID:                    rev.Door43MetadataID,
+		RepoID:                rev.RepoID,
+		ReleaseID:             rev.ReleaseID,
+		Ref:                   rev.Ref,
+		RefType:               rev.RefType,
+		CommitSHA:             rev.CommitSHA,
+		Stage:                 rev.Stage,
+		MetadataType:          rev.MetadataType,
+		MetadataVersion:       rev.MetadataVersion,
+		Resource:              rev.Resource,
+		Subject:               rev.Subject,
+		Title:                 rev.Title,
+		Language:              rev.Language,
+		LanguageTitle:         rev.LanguageTitle,
+		LanguageDirection:     rev.LanguageDirection,
+		LanguageIsGL:          rev.LanguageIsGL,
+		ContentFormat:         rev.ContentFormat,
+		CheckingLevel:         rev.CheckingLevel,
+		VerifiedCheckingLevel: rev.VerifiedCheckingLevel,
+		Ingredients:           rev.Ingredients,
+		Metadata:              rev.Metadata,
+		ReleaseDateUnix:       rev.ReleaseDateUnix,
+		IsRevision:            true,
+		CreatedUnix:           rev.ValidFromUnix,
+		UpdatedUnix:           rev.CreatedUnix,
+	}
+}
+
+// GetDoor43MetadataChangedFields returns the names of the catalog fields that differ between two door43 metadata entries
+func GetDoor43MetadataChangedFields(prev, dm *Door43Metadata) []string {
+	var changed []string
+	add := func(name string, isChanged bool) {
+		if isChanged {
+			changed = append(changed, name)
+		}
+	}
+	add("release", prev.ReleaseID != dm.ReleaseID)
+	add("commit_sha", prev.CommitSHA != dm.CommitSHA)
+	add("stage", prev.Stage != dm.Stage)
+	add("metadata_type", prev.MetadataType != dm.MetadataType)
+	add("metadata_version", prev.MetadataVersion != dm.MetadataVersion)
+	add("resource", prev.Resource != dm.Resource)
+	add("subject", prev.Subject != dm.Subject)
+	add("title", prev.Title != dm.Title)
+	add("language", prev.Language != dm.Language)
+	add("language_title", prev.LanguageTitle != dm.LanguageTitle)
+	add("language_direction", prev.LanguageDirection != dm.LanguageDirection)
+	add("language_is_gl", prev.LanguageIsGL != dm.LanguageIsGL)
+	add("content_format", prev.ContentFormat != dm.ContentFormat)
+	add("checking_level", prev.CheckingLevel != dm.CheckingLevel)
+	add("verified_checking_level", prev.VerifiedCheckingLevel != dm.VerifiedCheckingLevel)
+	add("ingredients", !isSameJSON(ingredientsWithoutProgress(prev.Ingredients), ingredientsWithoutProgress(dm.Ingredients)))
+	add("metadata", !isSameJSON(prev.Metadata, dm.Metadata))
+	add("release_date", prev.ReleaseDateUnix != dm.ReleaseDateUnix)
+	return changed
+}
+
+// ingredientsWithoutProgress returns copies of the ingredients without their translation progress, which changes with
+// the content rather than the metadata
+func ingredientsWithoutProgress(ingredients []*structs.Ingredient) []*structs.Ingredient {
+	result := make([]*structs.Ingredient, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		if ingredient == nil {
+			continue
+		}
+		i := *ingredient
+		i.Progress = nil
+		result = append(result, &i)
+	}
+	return result
+}
+
+// isSameJSON compares the JSON of two values, as values loaded from JSON columns differ in type
+// (e.g. float64 instead of int) from those parsed from the manifest files
+func isSameJSON(a, b any) bool {
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aJSON, bJSON)
+}
+
+// InsertDoor43MetadataRevision records the previous values of a door43 metadata entry that has changed
+func InsertDoor43MetadataRevision(ctx context.Context, prev *Door43Metadata, trigger Door43MetadataTrigger, changedFields []string) error {
+	validFrom := prev.CreatedUnix
+	last := &Door43MetadataRevision{}
+	has, err := db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": prev.RepoID, "ref": prev.Ref}).
+		And(builder.Gte{"created_unix": prev.CreatedUnix}).
+		Desc("created_unix", "id").
+		Get(last)
+	if err != nil {
+		return err
+	}
+	if has {
+		validFrom = last.CreatedUnix
+	}
+
+	rev := &Door43MetadataRevision{
+		Door43MetadataID:      prev.ID,
+		RepoID:                prev.RepoID,
+		Ref:                   prev.Ref,
+		Trigger:               trigger,
+		ChangedFields:         changedFields,
+		ReleaseID:             prev.ReleaseID,
+		RefType:               prev.RefType,
+		CommitSHA:             prev.CommitSHA,
+		Stage:                 prev.Stage,
+		MetadataType:          prev.MetadataType,
+		MetadataVersion:       prev.MetadataVersion,
+		Resource:              prev.Resource,
+		Subject:               prev.Subject,
+		Title:                 prev.Title,
+		Language:              prev.Language,
+		LanguageTitle:         prev.LanguageTitle,
+		LanguageDirection:     prev.LanguageDirection,
+		LanguageIsGL:          prev.LanguageIsGL,
+		ContentFormat:         prev.ContentFormat,
+		CheckingLevel:         prev.CheckingLevel,
+		VerifiedCheckingLevel: prev.VerifiedCheckingLevel,
+		Ingredients:           prev.Ingredients,
+		Metadata:              prev.Metadata,
+		ReleaseDateUnix:       prev.ReleaseDateUnix,
+		ValidFromUnix:         validFrom,
+	}
+	_, err = db.GetEngine(ctx).Insert(rev)
+	return err
+}
+
+// GetDoor43MetadataRevisions returns the revisions of a repo's ref, most recent first
+func GetDoor43MetadataRevisions(ctx context.Context, repoID int64, ref string, listOptions db.ListOptions) ([]*Door43MetadataRevision, error) {
+	sess := db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repoID, "ref": ref}).
+		Desc("created_unix", "id")
+	if listOptions.Page > 0 {
+		sess = db.SetSessionPagination(sess, &listOptions)
+	}
+	revs := make([]*Door43MetadataRevision, 0, 10)
+	return revs, sess.Find(&revs)
+}
+
+// GetDoor43MetadataRevisionsByRepoID returns the revisions of all the refs of a repo, most recent first
+func GetDoor43MetadataRevisionsByRepoID(ctx context.Context, repoID int64) ([]*Door43MetadataRevision, error) {
+	revs := make([]*Door43MetadataRevision, 0, 10)
+	return revs, db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repoID}).
+		Desc("created_unix", "id").
+		Find(&revs)
+}
+
+// GetDoor43MetadataAtTime returns the door43 metadata entry of a repo's ref as it was at the given time
+func GetDoor43MetadataAtTime(ctx context.Context, repoID int64, ref string, at timeutil.TimeStamp) (*Door43Metadata, error) {
+	rev := &Door43MetadataRevision{}
+	has, err := db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repoID, "ref": ref}).
+		And(builder.Lte{"valid_from_unix": at}).
+		And(builder.Gt{"created_unix": at}).
+		Asc("created_unix", "id").
+		Get(rev)
+	if err != nil {
+		return nil, err
+	}
+	if has {
+		return rev.ToDoor43Metadata(), nil
+	}
+
+	dm, err := GetDoor43MetadataByRepoIDAndRef(ctx, repoID, ref)
+	if err != nil {
+		return nil, err
+	}
+	if dm.CreatedUnix > at {
+		return nil, ErrDoor43MetadataNotExist{RepoID: repoID, Ref: ref}
+	}
+	return dm, nil
+}
+
+// DeleteDoor43MetadataRevisionsByRepoID deletes all the revisions of a repo
+func DeleteDoor43MetadataRevisionsByRepoID(ctx context.Context, repoID int64) error {
+	_, err := db.GetEngine(ctx).Delete(&Door43MetadataRevision{RepoID: repoID})
+	return err
+}
+
+// DeleteDoor43MetadataRevisionsByRepoRef deletes all the revisions of a repo's ref
+func DeleteDoor43MetadataRevisionsByRepoRef(ctx context.Context, repoID int64, ref string) error {
+	_, err := db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID, "ref": ref}).Delete(&Door43MetadataRevision{})
+	return err
+}
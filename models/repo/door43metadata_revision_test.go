@@ -0,0 +1,87 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDoor43MetadataChangedFields(t *testing.T) {
+	prev := &repo_model.Door43Metadata{ReleaseID: 1, CommitSHA: "aaa", Title: "ULT", VerifiedCheckingLevel: 1,
+		Ingredients: []*structs.Ingredient{{Identifier: "gen", Progress: &structs.IngredientProgress{Completed: 1, Total: 2}}}}
+	same := *prev
+	same.Ingredients = []*structs.Ingredient{{Identifier: "gen", Progress: &structs.IngredientProgress{Completed: 2, Total: 2}}}
+	assert.Empty(t, repo_model.GetDoor43MetadataChangedFields(prev, &same))
+
+	changed := same
+	changed.ReleaseID = 2
+	changed.CommitSHA = "bbb"
+	changed.VerifiedCheckingLevel = 2
+	changed.Title = "Unlocked Literal Bible"
+	assert.Equal(t, []string{"release", "commit_sha", "title", "verified_checking_level"}, repo_model.GetDoor43MetadataChangedFields(prev, &changed))
+}
+
+func TestGetDoor43MetadataAtTime(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	dm := &repo_model.Door43Metadata{RepoID: 1, ReleaseID: 1, Ref: "v1.1", RefType: "tag", CommitSHA: "aaa", Stage: door43metadata.StageProd,
+		Title: "ULT", VerifiedCheckingLevel: 1, CreatedUnix: 1000, UpdatedUnix: 1000}
+	_, err := db.GetEngine(db.DefaultContext).NoAutoTime().Insert(dm)
+	assert.NoError(t, err)
+
+	prev := *dm
+	dm.CommitSHA = "bbb"
+	dm.Title = "Unlocked Literal Bible"
+	dm.VerifiedCheckingLevel = 2
+	changedFields := repo_model.GetDoor43MetadataChangedFields(&prev, dm)
+	assert.NoError(t, repo_model.InsertDoor43MetadataRevision(db.DefaultContext, &prev, repo_model.Door43MetadataTriggerPush, changedFields))
+	assert.NoError(t, repo_model.UpdateDoor43MetadataCols(db.DefaultContext, dm, "commit_sha", "title", "verified_checking_level"))
+
+	rev := unittest.AssertExistsAndLoadBean(t, &repo_model.Door43MetadataRevision{Door43MetadataID: dm.ID})
+	assert.EqualValues(t, 1000, rev.ValidFromUnix)
+	assert.Equal(t, []string{"commit_sha", "title", "verified_checking_level"}, rev.ChangedFields)
+
+	// before the entry existed
+	_, err = repo_model.GetDoor43MetadataAtTime(db.DefaultContext, 1, "v1.1", 500)
+	assert.True(t, repo_model.IsErrDoor43MetadataNotExist(err))
+
+	// before the update, all the exposed fields are those of the revision
+	old, err := repo_model.GetDoor43MetadataAtTime(db.DefaultContext, 1, "v1.1", 1500)
+	assert.NoError(t, err)
+	assert.True(t, old.IsRevision)
+	assert.Equal(t, dm.ID, old.ID)
+	assert.EqualValues(t, 1, old.ReleaseID)
+	assert.Equal(t, "aaa", old.CommitSHA)
+	assert.Equal(t, "ULT", old.Title)
+	assert.Equal(t, 1, old.VerifiedCheckingLevel)
+
+	// after the update
+	cur, err := repo_model.GetDoor43MetadataAtTime(db.DefaultContext, 1, "v1.1", timeutil.TimeStampNow()+100)
+	assert.NoError(t, err)
+	assert.False(t, cur.IsRevision)
+	assert.Equal(t, "bbb", cur.CommitSHA)
+	assert.Equal(t, 2, cur.VerifiedCheckingLevel)
+}
+
+func TestDeleteDoor43MetadataRevisionsByRepoRef(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, ref := range []string{"v1.1", "master"} {
+		dm := &repo_model.Door43Metadata{ID: 1, RepoID: 1, Ref: ref, CommitSHA: "aaa"}
+		assert.NoError(t, repo_model.InsertDoor43MetadataRevision(db.DefaultContext, dm, repo_model.Door43MetadataTriggerPush, []string{"commit_sha"}))
+	}
+
+	assert.NoError(t, repo_model.DeleteDoor43MetadataRevisionsByRepoRef(db.DefaultContext, 1, "v1.1"))
+	unittest.AssertNotExistsBean(t, &repo_model.Door43MetadataRevision{RepoID: 1, Ref: "v1.1"})
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Door43MetadataRevision{RepoID: 1, Ref: "master"})
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoor43MetadataListLoadAttributes(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	dms := []*repo_model.Door43Metadata{
+		{RepoID: 1, ReleaseID: 1, Ref: "v1.1", RefType: "tag", Stage: door43metadata.StageProd, IsLatestForStage: true},
+		{RepoID: 1, Ref: "master", RefType: "branch", Stage: door43metadata.StageLatest, IsLatestForStage: true},
+		{RepoID: 40, ReleaseID: 2, Ref: "v1.0", RefType: "tag", Stage: door43metadata.StageProd, IsLatestForStage: true},
+		{RepoID: 2, ReleaseID: 11, Ref: "v1.1", RefType: "tag", Stage: door43metadata.StageProd, IsLatestForStage: true},
+		// the release no longer exists
+		{RepoID: 3, ReleaseID: 1000, Ref: "v1", RefType: "tag", Stage: door43metadata.StageProd},
+	}
+	ids := make([]int64, 0, len(dms))
+	for _, dm := range dms {
+		dm.CommitSHA = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+		assert.NoError(t, db.Insert(db.DefaultContext, dm))
+		ids = append(ids, dm.ID)
+	}
+
+	findDMs := func() repo_model.Door43MetadataList {
+		found := make(repo_model.Door43MetadataList, 0, len(ids))
+		assert.NoError(t, db.GetEngine(db.DefaultContext).In("id", ids).Asc("id").Find(&found))
+		return found
+	}
+
+	batch := findDMs()
+	assert.NoError(t, batch.LoadAttributes(db.DefaultContext))
+	// the entries of the same repo share it
+	assert.Same(t, batch[0].Repo, batch[1].Repo)
+
+	for i, dm := range findDMs() {
+		assert.NoError(t, dm.LoadAttributes(db.DefaultContext))
+		assert.NoError(t, dm.Repo.LoadUnits(db.DefaultContext))
+		assert.NoError(t, dm.Repo.LoadLatestDMs(db.DefaultContext))
+		assert.NoError(t, dm.LoadBrokenLinks(db.DefaultContext))
+
+		loaded := batch[i]
+		assert.Equal(t, dm.Repo.ID, loaded.Repo.ID)
+		assert.Equal(t, dm.Repo.Owner.ID, loaded.Repo.Owner.ID)
+		assert.ElementsMatch(t, dm.Repo.Units, loaded.Repo.Units)
+		assert.Equal(t, dmID(dm.Repo.LatestProdDM), dmID(loaded.Repo.LatestProdDM))
+		assert.Equal(t, dmID(dm.Repo.DefaultBranchDM), dmID(loaded.Repo.DefaultBranchDM))
+		assert.Equal(t, dm.Repo.RepoDM.Title, loaded.Repo.RepoDM.Title)
+		assert.Equal(t, dm.BrokenLinks, loaded.BrokenLinks)
+		if dm.Release == nil {
+			assert.Nil(t, loaded.Release, dm.Ref)
+			continue
+		}
+		if assert.NotNil(t, loaded.Release, dm.Ref) {
+			assert.Equal(t, dm.Release.ID, loaded.Release.ID)
+			assert.Equal(t, dm.Release.Publisher.ID, loaded.Release.Publisher.ID)
+			assert.Equal(t, dm.Release.Attachments, loaded.Release.Attachments)
+			assert.Same(t, loaded, loaded.Release.Door43Metadata)
+			assert.Same(t, loaded.Repo, loaded.Release.Repo)
+		}
+	}
+}
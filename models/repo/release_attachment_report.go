@@ -0,0 +1,64 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// ReleaseAttachmentReport records the problems found when unpacking the files.json or links.json attachments
+// of a release, so they can be shown on the release page
+type ReleaseAttachmentReport struct {
+	ID          int64              `xorm:"pk autoincr"`
+	RepoID      int64              `xorm:"INDEX NOT NULL"`
+	ReleaseID   int64              `xorm:"UNIQUE NOT NULL"`
+	Problems    []string           `xorm:"JSON"`
+	NumUnpacked int                `xorm:"NOT NULL DEFAULT 0"` // attachments added or updated from the files
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ReleaseAttachmentReport))
+}
+
+// SaveReleaseAttachmentReport replaces the report of the release with the given one,
+// or just deletes it if the report has no problems
+func SaveReleaseAttachmentReport(ctx context.Context, report *ReleaseAttachmentReport) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := DeleteReleaseAttachmentReport(ctx, report.ReleaseID); err != nil {
+			return err
+		}
+		if len(report.Problems) == 0 {
+			return nil
+		}
+		return db.Insert(ctx, report)
+	})
+}
+
+// DeleteReleaseAttachmentReport deletes the report of a release
+func DeleteReleaseAttachmentReport(ctx context.Context, releaseID int64) error {
+	_, err := db.GetEngine(ctx).Where(builder.Eq{"release_id": releaseID}).Delete(&ReleaseAttachmentReport{})
+	return err
+}
+
+// GetReleaseAttachmentReports returns the reports of the given releases by release ID
+func GetReleaseAttachmentReports(ctx context.Context, releaseIDs []int64) (map[int64]*ReleaseAttachmentReport, error) {
+	reports := make(map[int64]*ReleaseAttachmentReport, len(releaseIDs))
+	if len(releaseIDs) == 0 {
+		return reports, nil
+	}
+	list := make([]*ReleaseAttachmentReport, 0, len(releaseIDs))
+	if err := db.GetEngine(ctx).In("release_id", releaseIDs).Find(&list); err != nil {
+		return nil, err
+	}
+	for _, report := range list {
+		reports[report.ReleaseID] = report
+	}
+	return reports, nil
+}
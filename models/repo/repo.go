@@ -193,6 +193,7 @@ type Repository struct {
 	LatestPreprodDM *Door43Metadata `xorm:"-"`
 	DefaultBranchDM *Door43Metadata `xorm:"-"`
 	RepoDM          *Door43Metadata `xorm:"-"`
+	latestDMsLoaded bool            `xorm:"-"`
 	/*** DCS Customizations ***/
 }
 
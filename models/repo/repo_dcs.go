@@ -15,35 +15,51 @@ import (
 
 // LoadLatestDMs loads the latest DMs
 func (repo *Repository) LoadLatestDMs(ctx context.Context) error {
+	if repo.latestDMsLoaded {
+		return nil
+	}
+
 	if repo.LatestProdDM == nil {
-		dm := &Door43Metadata{RepoID: repo.ID, Stage: door43metadata.StageProd, IsLatestForStage: true}
-		has, err := db.GetEngine(ctx).Desc("release_date_unix").Get(dm)
+		dm := &Door43Metadata{}
+		has, err := db.GetEngine(ctx).
+			Where(builder.Eq{"repo_id": repo.ID, "stage": door43metadata.StageProd, "is_latest_for_stage": true}).
+			Desc("release_date_unix", "id").
+			Get(dm)
 		if err != nil {
 			return err
 		}
 		if has {
+			dm.Repo = repo
 			repo.LatestProdDM = dm
 		}
 	}
 
 	if repo.LatestPreprodDM == nil {
-		dm := &Door43Metadata{RepoID: repo.ID, Stage: door43metadata.StagePreProd, IsLatestForStage: true}
-		has, err := db.GetEngine(ctx).Desc("release_date_unix").Get(dm)
+		dm := &Door43Metadata{}
+		has, err := db.GetEngine(ctx).
+			Where(builder.Eq{"repo_id": repo.ID, "stage": door43metadata.StagePreProd, "is_latest_for_stage": true}).
+			Desc("release_date_unix", "id").
+			Get(dm)
 		if err != nil {
 			return err
 		}
 		if has {
+			dm.Repo = repo
 			repo.LatestPreprodDM = dm
 		}
 	}
 
 	if repo.DefaultBranchDM == nil {
-		dm := &Door43Metadata{RepoID: repo.ID, Stage: door43metadata.StageLatest, IsLatestForStage: true}
-		has, err := db.GetEngine(ctx).Desc("release_date_unix").Get(dm)
+		dm := &Door43Metadata{}
+		has, err := db.GetEngine(ctx).
+			Where(builder.Eq{"repo_id": repo.ID, "stage": door43metadata.StageLatest, "is_latest_for_stage": true}).
+			Desc("release_date_unix", "id").
+			Get(dm)
 		if err != nil {
 			return err
 		}
 		if has {
+			dm.Repo = repo
 			repo.DefaultBranchDM = dm
 		}
 	}
@@ -53,49 +69,149 @@ func (repo *Repository) LoadLatestDMs(ctx context.Context) error {
 		has, err := db.GetEngine(ctx).
 			Where(builder.Eq{"repo_id": repo.ID}).
 			And(builder.Eq{"is_repo_metadata": true}).
-			Desc("release_date_unix").
+			Desc("release_date_unix", "id").
 			Get(dm)
 		if err != nil {
 			return err
 		}
 		if has && dm != nil {
+			dm.Repo = repo
 			repo.RepoDM = dm
 		} else {
-			title := repo.Name
-			metadataType := dcs.GetMetadataTypeFromRepoName(repo.Name)
-			metadataVersion := dcs.GetDefaultMetadataVersionForType(metadataType)
-			subject := dcs.GetSubjectFromRepoName(repo.Name)
-			lang := dcs.GetLanguageFromRepoName(repo.Name)
-			langDir := dcs.GetLanguageDirection(lang)
-			langTitle := dcs.GetLanguageTitle(lang)
-			langIsGL := dcs.LanguageIsGL(lang)
-			repo.RepoDM = &Door43Metadata{
-				RepoID:            repo.ID,
-				MetadataType:      metadataType,
-				MetadataVersion:   metadataVersion,
-				Title:             title,
-				Subject:           subject,
-				Language:          lang,
-				LanguageDirection: langDir,
-				LanguageTitle:     langTitle,
-				LanguageIsGL:      langIsGL,
-			}
+			repo.RepoDM = newDefaultRepoDM(repo)
 		}
 	}
 
+	repo.latestDMsLoaded = true
 	return nil
 }
 
-// LoadLatestDMs loads the latest Door43Metadatas for the given RepositoryList
+// newDefaultRepoDM makes a Door43Metadata for a repo without metadata from what can be derived from the repo's name
+func newDefaultRepoDM(repo *Repository) *Door43Metadata {
+	metadataType := dcs.GetMetadataTypeFromRepoName(repo.Name)
+	lang := dcs.GetLanguageFromRepoName(repo.Name)
+	return &Door43Metadata{
+		RepoID:            repo.ID,
+		Repo:              repo,
+		MetadataType:      metadataType,
+		MetadataVersion:   dcs.GetDefaultMetadataVersionForType(metadataType),
+		Title:             repo.Name,
+		Subject:           dcs.GetSubjectFromRepoName(repo.Name),
+		Language:          lang,
+		LanguageDirection: dcs.GetLanguageDirection(lang),
+		LanguageTitle:     dcs.GetLanguageTitle(lang),
+		LanguageIsGL:      dcs.LanguageIsGL(lang),
+	}
+}
+
+// LoadLatestDMs loads the latest Door43Metadatas for the given RepositoryList with a single query
 func (rl RepositoryList) LoadLatestDMs(ctx context.Context) error {
 	if rl.Len() == 0 {
 		return nil
 	}
-	var lastErr error
+
+	repoMap := make(map[int64]*Repository, len(rl))
+	for _, repo := range rl {
+		if !repo.latestDMsLoaded {
+			repoMap[repo.ID] = repo
+		}
+	}
+	if len(repoMap) == 0 {
+		return nil
+	}
+	repoIDs := make([]int64, 0, len(repoMap))
+	for id := range repoMap {
+		repoIDs = append(repoIDs, id)
+	}
+
+	// Ordered by release date so the most recent entry of each kind is assigned last
+	dms := make([]*Door43Metadata, 0, len(repoIDs)*2)
+	err := db.GetEngine(ctx).
+		Where(builder.In("repo_id", repoIDs)).
+		And(builder.Or(
+			builder.Eq{"is_latest_for_stage": true}.
+				And(builder.In("stage", door43metadata.StageProd, door43metadata.StagePreProd, door43metadata.StageLatest)),
+			builder.Eq{"is_repo_metadata": true},
+		)).
+		Asc("release_date_unix", "id").
+		Find(&dms)
+	if err != nil {
+		return err
+	}
+
+	latestProdDMs := make(map[int64]*Door43Metadata, len(repoMap))
+	latestPreprodDMs := make(map[int64]*Door43Metadata, len(repoMap))
+	defaultBranchDMs := make(map[int64]*Door43Metadata, len(repoMap))
+	repoDMs := make(map[int64]*Door43Metadata, len(repoMap))
+	for _, dm := range dms {
+		dm.Repo = repoMap[dm.RepoID]
+		if dm.IsLatestForStage {
+			switch dm.Stage {
+			case door43metadata.StageProd:
+				latestProdDMs[dm.RepoID] = dm
+			case door43metadata.StagePreProd:
+				latestPreprodDMs[dm.RepoID] = dm
+			case door43metadata.StageLatest:
+				defaultBranchDMs[dm.RepoID] = dm
+			}
+		}
+		if dm.IsRepoMetadata {
+			repoDMs[dm.RepoID] = dm
+		}
+	}
+
+	for _, repo := range rl {
+		if repo.latestDMsLoaded {
+			continue
+		}
+		if repo.LatestProdDM == nil {
+			repo.LatestProdDM = latestProdDMs[repo.ID]
+		}
+		if repo.LatestPreprodDM == nil {
+			repo.LatestPreprodDM = latestPreprodDMs[repo.ID]
+		}
+		if repo.DefaultBranchDM == nil {
+			repo.DefaultBranchDM = defaultBranchDMs[repo.ID]
+		}
+		if repo.RepoDM == nil {
+			if dm, ok := repoDMs[repo.ID]; ok {
+				repo.RepoDM = dm
+			} else {
+				repo.RepoDM = newDefaultRepoDM(repo)
+			}
+		}
+		repo.latestDMsLoaded = true
+	}
+	return nil
+}
+
+// LoadUnits loads the units of the repositories in the given RepositoryList with a single query
+func (rl RepositoryList) LoadUnits(ctx context.Context) error {
+	repoIDs := make([]int64, 0, len(rl))
+	for _, repo := range rl {
+		if repo.Units == nil {
+			repoIDs = append(repoIDs, repo.ID)
+		}
+	}
+	if len(repoIDs) == 0 {
+		return nil
+	}
+
+	units := make([]*RepoUnit, 0, len(repoIDs)*8)
+	if err := db.GetEngine(ctx).In("repo_id", repoIDs).Find(&units); err != nil {
+		return err
+	}
+	unitMap := make(map[int64][]*RepoUnit, len(repoIDs))
+	for _, u := range units {
+		if !u.Type.UnitGlobalDisabled() {
+			unitMap[u.RepoID] = append(unitMap[u.RepoID], u)
+		}
+	}
 	for _, repo := range rl {
-		if err := repo.LoadLatestDMs(ctx); err != nil && lastErr == nil {
-			lastErr = err
+		if repo.Units == nil {
+			// Non-nil even if empty so LoadUnits doesn't query again
+			repo.Units = append(make([]*RepoUnit, 0, len(unitMap[repo.ID])), unitMap[repo.ID]...)
 		}
 	}
-	return lastErr
+	return nil
 }
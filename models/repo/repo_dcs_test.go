@@ -0,0 +1,93 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestRepositoryList(t *testing.T, ids ...int64) repo_model.RepositoryList {
+	repos := make(repo_model.RepositoryList, 0, len(ids))
+	for _, id := range ids {
+		repos = append(repos, unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: id}))
+	}
+	return repos
+}
+
+func dmID(dm *repo_model.Door43Metadata) int64 {
+	if dm == nil {
+		return -1
+	}
+	return dm.ID
+}
+
+func TestRepositoryListLoadLatestDMs(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, dm := range []*repo_model.Door43Metadata{
+		{RepoID: 1, Ref: "v1.0", Stage: door43metadata.StageProd, ReleaseDateUnix: 1000, IsLatestForStage: false},
+		{RepoID: 1, ReleaseID: 1, Ref: "v1.1", Stage: door43metadata.StageProd, ReleaseDateUnix: 2000, IsLatestForStage: true},
+		{RepoID: 1, Ref: "v1.2-rc", Stage: door43metadata.StagePreProd, ReleaseDateUnix: 3000, IsLatestForStage: true},
+		{RepoID: 1, Ref: "master", Stage: door43metadata.StageLatest, ReleaseDateUnix: 4000, IsLatestForStage: true, IsRepoMetadata: true},
+		{RepoID: 2, ReleaseID: 11, Ref: "v1.1", Stage: door43metadata.StageProd, ReleaseDateUnix: 2000, IsLatestForStage: true, IsRepoMetadata: true},
+	} {
+		dm.CommitSHA = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+		assert.NoError(t, db.Insert(db.DefaultContext, dm))
+	}
+
+	batch := getTestRepositoryList(t, 1, 2, 3)
+	assert.NoError(t, batch.LoadLatestDMs(db.DefaultContext))
+	assert.Equal(t, "v1.1", batch[0].LatestProdDM.Ref)
+	assert.Equal(t, "v1.2-rc", batch[0].LatestPreprodDM.Ref)
+	assert.Equal(t, "master", batch[0].DefaultBranchDM.Ref)
+	assert.Equal(t, batch[0].DefaultBranchDM.ID, batch[0].RepoDM.ID)
+	assert.Nil(t, batch[2].LatestProdDM)
+	// a default one from the name for repos without metadata
+	assert.Zero(t, batch[2].RepoDM.ID)
+	assert.Equal(t, batch[2].Name, batch[2].RepoDM.Title)
+
+	// loading each repo on its own gives the same entries
+	for i, repo := range getTestRepositoryList(t, 1, 2, 3) {
+		assert.NoError(t, repo.LoadLatestDMs(db.DefaultContext))
+		assert.Equal(t, dmID(batch[i].LatestProdDM), dmID(repo.LatestProdDM))
+		assert.Equal(t, dmID(batch[i].LatestPreprodDM), dmID(repo.LatestPreprodDM))
+		assert.Equal(t, dmID(batch[i].DefaultBranchDM), dmID(repo.DefaultBranchDM))
+		assert.Equal(t, batch[i].RepoDM.ID, repo.RepoDM.ID)
+		assert.Equal(t, batch[i].RepoDM.Title, repo.RepoDM.Title)
+		assert.Same(t, repo, repo.RepoDM.Repo)
+	}
+
+	// once loaded, neither loads the entries again
+	repo := getTestRepositoryList(t, 3)[0]
+	assert.NoError(t, repo.LoadLatestDMs(db.DefaultContext))
+	assert.NoError(t, db.Insert(db.DefaultContext, &repo_model.Door43Metadata{
+		RepoID: 3, Ref: "v2", Stage: door43metadata.StageProd, ReleaseDateUnix: timeutil.TimeStampNow(), IsLatestForStage: true,
+	}))
+	assert.NoError(t, repo.LoadLatestDMs(db.DefaultContext))
+	assert.Nil(t, repo.LatestProdDM)
+	assert.NoError(t, repo_model.RepositoryList{repo}.LoadLatestDMs(db.DefaultContext))
+	assert.Nil(t, repo.LatestProdDM)
+	assert.NoError(t, batch.LoadLatestDMs(db.DefaultContext))
+	assert.Nil(t, batch[2].LatestProdDM)
+}
+
+func TestRepositoryListLoadUnits(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	batch := getTestRepositoryList(t, 1, 2, 3, 4)
+	assert.NoError(t, batch.LoadUnits(db.DefaultContext))
+	for i, repo := range getTestRepositoryList(t, 1, 2, 3, 4) {
+		assert.NoError(t, repo.LoadUnits(db.DefaultContext))
+		assert.NotNil(t, batch[i].Units)
+		assert.ElementsMatch(t, repo.Units, batch[i].Units, "repo %d", repo.ID)
+	}
+}
@@ -14,4 +14,8 @@ const (
 	UserActivityPubPrivPem = "activitypub.priv_pem"
 	// UserActivityPubPubPem is user's public key
 	UserActivityPubPubPem = "activitypub.pub_pem"
+	/*** DCS Customizations ***/
+	// SettingsKeyRepoNamingPolicy is the setting key for the repo naming policy of an organization
+	SettingsKeyRepoNamingPolicy = "dcs.repo_naming_policy"
+	/*** END DCS Customizations ***/
 )
@@ -348,12 +348,14 @@ func RepoRefForAPI(next http.Handler) http.Handler {
 			ctx.Repo.Commit = commit
 			ctx.Repo.CommitID = ctx.Repo.Commit.ID.String()
 			ctx.Repo.TreePath = ctx.Params("*")
+			ctx.Repo.RefName = ref // DCS Customizations
 			next.ServeHTTP(w, req)
 			return
 		}
 
 		var err error
 		refName := getRefName(ctx.Base, ctx.Repo, RepoRefAny)
+		ctx.Repo.RefName = refName // DCS Customizations
 
 		if ctx.Repo.GitRepo.IsBranchExist(refName) {
 			ctx.Repo.Commit, err = ctx.Repo.GitRepo.GetBranchCommit(refName)
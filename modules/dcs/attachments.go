@@ -0,0 +1,72 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package dcs
+
+import (
+	"bytes"
+	"sync"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/options"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// ReleaseAttachmentsSchemaID is the $id of the schema of the files.json and links.json files attached to releases
+const ReleaseAttachmentsSchemaID = "https://git.door43.org/api/v1/schemas/release_attachments.schema.json"
+
+var (
+	releaseAttachmentsSchema     *jsonschema.Schema
+	releaseAttachmentsSchemaErr  error
+	releaseAttachmentsSchemaOnce sync.Once
+)
+
+// GetReleaseAttachmentsSchemaFile returns the schema of the files.json and links.json files attached to releases,
+// with its $id being where this server publishes it
+func GetReleaseAttachmentsSchemaFile() ([]byte, error) {
+	buf, err := options.AssetFS().ReadFile("schema", "attachments", "release_attachments.schema.json")
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Replace(buf, []byte(ReleaseAttachmentsSchemaID), []byte(setting.AppURL+"api/v1/schemas/release_attachments.schema.json"), 1), nil
+}
+
+// GetReleaseAttachmentsSchema returns the compiled schema of the files.json and links.json files attached to releases
+func GetReleaseAttachmentsSchema() (*jsonschema.Schema, error) {
+	releaseAttachmentsSchemaOnce.Do(func() {
+		var buf []byte
+		buf, releaseAttachmentsSchemaErr = options.AssetFS().ReadFile("schema", "attachments", "release_attachments.schema.json")
+		if releaseAttachmentsSchemaErr != nil {
+			return
+		}
+		compiler := jsonschema.NewCompiler()
+		if releaseAttachmentsSchemaErr = compiler.AddResource(ReleaseAttachmentsSchemaID, bytes.NewReader(buf)); releaseAttachmentsSchemaErr != nil {
+			return
+		}
+		releaseAttachmentsSchema, releaseAttachmentsSchemaErr = compiler.Compile(ReleaseAttachmentsSchemaID)
+	})
+	return releaseAttachmentsSchema, releaseAttachmentsSchemaErr
+}
+
+// ValidateReleaseAttachmentsJSON validates the contents of a files.json or links.json file attached to a release
+func ValidateReleaseAttachmentsJSON(buf []byte) (*jsonschema.ValidationError, error) {
+	var data any
+	if err := json.Unmarshal(buf, &data); err != nil {
+		return nil, err
+	}
+	schema, err := GetReleaseAttachmentsSchema()
+	if err != nil {
+		return nil, err
+	}
+	if err = schema.Validate(data); err != nil {
+		switch e := err.(type) {
+		case *jsonschema.ValidationError:
+			return e, nil
+		default:
+			return nil, e
+		}
+	}
+	return nil, nil
+}
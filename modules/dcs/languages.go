@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -18,6 +19,8 @@ import (
 var (
 	_langnamesJSON      []map[string]interface{}
 	_langnamesJSONKeyed map[string]map[string]interface{}
+
+	_gatewayLanguagesByCountry map[string][]string
 )
 
 // GetLangnamesJSON returns an array of maps from https://td.door43.org/exports/langnames.json
@@ -119,6 +122,148 @@ func GetLanguageTitle(lang string) string {
 	return ""
 }
 
+// GetLanguageAnglicizedTitle returns the anglicized (English) title of the language
+func GetLanguageAnglicizedTitle(lang string) string {
+	langnames := GetLangnamesJSONKeyed()
+	if data, ok := langnames[lang]; ok {
+		if val, ok := data["ang"].(string); ok {
+			return val
+		}
+	}
+	return ""
+}
+
+// GetLanguageRegion returns the region of the language, e.g. Africa
+func GetLanguageRegion(lang string) string {
+	langnames := GetLangnamesJSONKeyed()
+	if data, ok := langnames[lang]; ok {
+		if val, ok := data["lr"].(string); ok {
+			return val
+		}
+	}
+	return ""
+}
+
+// GetLanguageCountries returns the codes of the countries the language is spoken in, e.g. TZ
+func GetLanguageCountries(lang string) []string {
+	var countries []string
+	langnames := GetLangnamesJSONKeyed()
+	if data, ok := langnames[lang]; ok {
+		if vals, ok := data["cc"].([]interface{}); ok {
+			for _, val := range vals {
+				if cc, ok := val.(string); ok && cc != "" {
+					countries = append(countries, cc)
+				}
+			}
+		}
+	}
+	return countries
+}
+
+// GetLanguagesByRegions returns the codes of the languages of any of the regions (case insensitive)
+func GetLanguagesByRegions(regions []string) []string {
+	var langs []string
+	for _, data := range GetLangnamesJSON() {
+		lc, _ := data["lc"].(string)
+		region, _ := data["lr"].(string)
+		for _, r := range regions {
+			if lc != "" && region != "" && strings.EqualFold(region, r) {
+				langs = append(langs, lc)
+				break
+			}
+		}
+	}
+	return langs
+}
+
+// GetLanguagesByCountries returns the codes of the languages spoken in any of the countries (case insensitive)
+func GetLanguagesByCountries(countries []string) []string {
+	var langs []string
+	for lc := range GetLangnamesJSONKeyed() {
+		if languageIsSpokenIn(lc, countries) > 0 {
+			langs = append(langs, lc)
+		}
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// languageIsSpokenIn returns in how many of the countries the language is spoken
+func languageIsSpokenIn(lang string, countries []string) int {
+	count := 0
+	for _, cc := range GetLanguageCountries(lang) {
+		for _, c := range countries {
+			if strings.EqualFold(cc, c) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// getGatewayLanguagesByCountry returns the codes of the gateway languages spoken in each country, keyed by the
+// upper case country code
+func getGatewayLanguagesByCountry() map[string][]string {
+	if _gatewayLanguagesByCountry == nil {
+		byCountry := map[string][]string{}
+		for _, data := range GetLangnamesJSON() {
+			lc, _ := data["lc"].(string)
+			if isGL, _ := data["gw"].(bool); !isGL || lc == "" {
+				continue
+			}
+			for _, cc := range GetLanguageCountries(lc) {
+				byCountry[strings.ToUpper(cc)] = append(byCountry[strings.ToUpper(cc)], lc)
+			}
+		}
+		_gatewayLanguagesByCountry = byCountry
+	}
+	return _gatewayLanguagesByCountry
+}
+
+// GetGatewayLanguages returns the codes of the gateway languages that serve a language, which are those spoken in
+// any of the countries it is spoken in, the ones sharing the most countries first. A gateway language doesn't serve
+// itself
+func GetGatewayLanguages(lang string) []string {
+	shared := make(map[string]int)
+	var gls []string
+	byCountry := getGatewayLanguagesByCountry()
+	for _, cc := range GetLanguageCountries(lang) {
+		for _, gl := range byCountry[strings.ToUpper(cc)] {
+			if gl == lang {
+				continue
+			}
+			if shared[gl] == 0 {
+				gls = append(gls, gl)
+			}
+			shared[gl]++
+		}
+	}
+	sort.Slice(gls, func(i, j int) bool {
+		if shared[gls[i]] != shared[gls[j]] {
+			return shared[gls[i]] > shared[gls[j]]
+		}
+		return gls[i] < gls[j]
+	})
+	return gls
+}
+
+// GetLanguageAlternativeNames returns the alternative names of the language
+func GetLanguageAlternativeNames(lang string) []string {
+	var names []string
+	langnames := GetLangnamesJSONKeyed()
+	if data, ok := langnames[lang]; ok {
+		if vals, ok := data["alt"].([]interface{}); ok {
+			for _, val := range vals {
+				if name, ok := val.(string); ok && name != "" {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names
+}
+
 // LanguageIsGL returns true if string is a valid language and is a GL
 func LanguageIsGL(lang string) bool {
 	langnames := GetLangnamesJSONKeyed()
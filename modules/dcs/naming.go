@@ -0,0 +1,67 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package dcs
+
+import (
+	"strings"
+)
+
+// RepoNamingPolicy is the policy of an organization for the names of its repos
+type RepoNamingPolicy string
+
+const (
+	// RepoNamingPolicyOff does not check repo names
+	RepoNamingPolicyOff RepoNamingPolicy = "off"
+	// RepoNamingPolicySuggest warns about repo names not following the naming conventions and suggests one that does
+	RepoNamingPolicySuggest RepoNamingPolicy = "suggest"
+	// RepoNamingPolicyEnforce rejects repo names not following the naming conventions
+	RepoNamingPolicyEnforce RepoNamingPolicy = "enforce"
+)
+
+// RepoNamingPolicies are the valid repo naming policies
+var RepoNamingPolicies = []RepoNamingPolicy{RepoNamingPolicyOff, RepoNamingPolicySuggest, RepoNamingPolicyEnforce}
+
+// IsValidRepoNamingPolicy returns true if it is a valid repo naming policy
+func IsValidRepoNamingPolicy(policy string) bool {
+	for _, p := range RepoNamingPolicies {
+		if string(p) == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// RepoNameFollowsConventions returns true if the metadata type of a repo can be determined from its name,
+// e.g. fr_ult, en_tn_1co_book or xyz-texttranslation-abc
+func RepoNameFollowsConventions(repoName string) bool {
+	return GetMetadataTypeFromRepoName(repoName) != ""
+}
+
+// SuggestRepoName suggests a name following the naming conventions, <lang>_<resource>, for a repo name with
+// a language code and resource ID in either order, separated by -, _, a space or a dot, e.g. fr-ult or ULT.fr.
+// Returns the lower-cased name if it already follows the conventions and "" if nothing can be suggested
+func SuggestRepoName(repoName string) string {
+	name := strings.ToLower(strings.TrimSpace(repoName))
+	name = strings.NewReplacer(" ", "_", ".", "_").Replace(name)
+	if RepoNameFollowsConventions(name) {
+		return name
+	}
+	// Both language codes, e.g. es-419, and resource IDs, e.g. obs-tn, can have a hyphen, so try every separator
+	for i, c := range name {
+		if c != '_' && c != '-' {
+			continue
+		}
+		prefix := name[:i]
+		suffix := strings.ReplaceAll(name[i+1:], "_", "-")
+		if IsValidLanguage(prefix) && IsValidResource(suffix) {
+			return prefix + "_" + suffix
+		}
+		prefix = strings.ReplaceAll(prefix, "_", "-")
+		suffix = name[i+1:]
+		if IsValidResource(prefix) && IsValidLanguage(suffix) {
+			return suffix + "_" + prefix
+		}
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package dcs
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestRepoName(t *testing.T) {
+	defer test.MockVariableValue(&_langnamesJSONKeyed, map[string]map[string]any{
+		"en":     {"lc": "en"},
+		"fr":     {"lc": "fr"},
+		"es-419": {"lc": "es-419"},
+	})()
+
+	for name, expected := range map[string]string{
+		"fr_ult":     "fr_ult",
+		"FR-ULT":     "fr_ult",
+		"ult.fr":     "fr_ult",
+		"en obs tn":  "en_obs-tn",
+		"en_obs-tn":  "en_obs-tn",
+		"obs-tn_en":  "en_obs-tn",
+		"es-419-tn":  "es-419_tn",
+		"tn_es-419":  "es-419_tn",
+		" fr_ult ":   "fr_ult",
+		"my-repo":    "",
+		"xx_ult":     "",
+		"fr_unknown": "",
+		"":           "",
+	} {
+		assert.Equal(t, expected, SuggestRepoName(name), name)
+	}
+
+	assert.True(t, RepoNameFollowsConventions("es-419_tn"))
+	assert.True(t, RepoNameFollowsConventions("en-texttranslation-abc"))
+	assert.False(t, RepoNameFollowsConventions("FR-ULT"))
+}
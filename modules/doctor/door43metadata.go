@@ -0,0 +1,174 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package doctor
+
+import (
+	"context"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/log"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
+)
+
+// door43MetadataRepoCheck is a consistency check of the door43 metadata entries of the repos returned by getRepoIDs,
+// fixed by calling fix for each of them
+func door43MetadataRepoCheck(name string, getRepoIDs func(context.Context) ([]int64, error), fix func(context.Context, int64) error, fixedMessage string) consistencyCheck {
+	return consistencyCheck{
+		Name: name,
+		Counter: func(ctx context.Context) (int64, error) {
+			repoIDs, err := getRepoIDs(ctx)
+			return int64(len(repoIDs)), err
+		},
+		Fixer: func(ctx context.Context) (int64, error) {
+			repoIDs, err := getRepoIDs(ctx)
+			if err != nil {
+				return 0, err
+			}
+			var fixed int64
+			for _, repoID := range repoIDs {
+				if err := fix(ctx, repoID); err != nil {
+					log.Error("Unable to fix the door43 metadata of repo %d: %v", repoID, err)
+					continue
+				}
+				fixed++
+			}
+			return fixed, nil
+		},
+		FixedMessage: fixedMessage,
+	}
+}
+
+func checkDoor43Metadata(ctx context.Context, logger log.Logger, autofix bool) error {
+	consistencyChecks := []consistencyCheck{
+		door43MetadataRepoCheck("Repositories with door43 metadata that are private, archived or no longer exist",
+			repo_model.GetRepoIDsOfDoor43MetadataNotInCatalog, door43metadata_service.DeleteRepoDoor43Metadatas, "Deleted the door43 metadata of"),
+		{
+			Name: "Door43 metadata of releases that don't exist, are drafts or are tags",
+			Counter: func(ctx context.Context) (int64, error) {
+				dms, err := repo_model.GetDoor43MetadatasWithInvalidRelease(ctx)
+				return int64(len(dms)), err
+			},
+			Fixer: func(ctx context.Context) (int64, error) {
+				dms, err := repo_model.GetDoor43MetadatasWithInvalidRelease(ctx)
+				if err != nil {
+					return 0, err
+				}
+				return int64(len(dms)), door43metadata_service.DeleteDoor43Metadatas(ctx, dms)
+			},
+		},
+		{
+			Name:         "Door43 metadata with ingredients not stored in the ingredient table",
+			Counter:      repo_model.CountDoor43MetadatasWithoutStoredIngredients,
+			Fixer:        repo_model.StoreMissingDoor43MetadataIngredients,
+			FixedMessage: "Stored the ingredients of",
+		},
+		genericOrphanCheck("Door43 metadata ingredients without existing door43 metadata",
+			"door43_metadata_ingredient", "door43_metadata", "door43_metadata_ingredient.door43_metadata_id=door43_metadata.id"),
+		door43MetadataRepoCheck("Repositories without exactly one latest door43 metadata per stage",
+			repo_model.GetRepoIDsWithInconsistentLatestDoor43Metadata, door43metadata_service.RefreshLatestDoor43Metadata, "Fixed"),
+		door43MetadataRepoCheck("Repositories without exactly one door43 metadata being the repo's metadata",
+			repo_model.GetRepoIDsWithInconsistentRepoDoor43Metadata, door43metadata_service.RefreshLatestDoor43Metadata, "Fixed"),
+	}
+
+	for _, c := range consistencyChecks {
+		if err := c.Run(ctx, logger, autofix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkDoor43MetadataCommits(ctx context.Context, logger log.Logger, autofix bool) error {
+	repoIDs, err := repo_model.GetDoor43MetadataRepoIDs(ctx)
+	if err != nil {
+		logger.Critical("Unable to get the repositories with door43 metadata: %v", err)
+		return err
+	}
+
+	numUnreachable := 0
+	numFixed := 0
+	for _, repoID := range repoIDs {
+		repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+		if err != nil {
+			if !repo_model.IsErrRepoNotExist(err) {
+				logger.Warn("Unable to get repository %d: %v", repoID, err)
+			}
+			continue // repos that no longer exist are reported by check-door43-metadata
+		}
+		dms, err := door43metadata_service.GetUnreachableDoor43Metadatas(ctx, repo)
+		if err != nil {
+			logger.Warn("Unable to check the door43 metadata of %s: %v", repo.FullName(), err)
+			continue
+		}
+		if len(dms) == 0 {
+			continue
+		}
+		numUnreachable += len(dms)
+		for _, dm := range dms {
+			logger.Warn("Door43 metadata %d of %s has a ref (%s) or commit (%s) that no longer exists", dm.ID, repo.FullName(), dm.Ref, dm.CommitSHA)
+		}
+		if autofix {
+			if err := door43metadata_service.FixUnreachableDoor43Metadatas(ctx, repo, dms); err != nil {
+				logger.Warn("Unable to fix the door43 metadata of %s: %v", repo.FullName(), err)
+				continue
+			}
+			numFixed += len(dms)
+		}
+	}
+
+	if autofix {
+		logger.Info("%d / %d door43 metadata with unreachable commits deleted and their repositories rescanned", numFixed, numUnreachable)
+	} else if numUnreachable > 0 {
+		logger.Warn("Found %d door43 metadata with unreachable commits", numUnreachable)
+	} else {
+		logger.Info("All door43 metadata of %d repositories have reachable commits", len(repoIDs))
+	}
+	return nil
+}
+
+func checkDoor43MetadataJSON(ctx context.Context, logger log.Logger, autofix bool) error {
+	isNative, err := repo_model.Door43MetadataColumnIsNativeJSON(ctx)
+	if err != nil {
+		logger.Critical("Unable to check the type of the door43 metadata column: %v", err)
+		return err
+	}
+	if isNative {
+		logger.Info("The door43 metadata column is stored as native JSON")
+		return nil
+	}
+	if !autofix {
+		logger.Warn("The door43 metadata column is not stored as native JSON or not indexed, so filtering the catalog by metadata is slow")
+		return nil
+	}
+	if err := repo_model.ConvertDoor43MetadataColumnToNativeJSON(ctx); err != nil {
+		logger.Critical("Unable to convert the door43 metadata column to native JSON: %v", err)
+		return err
+	}
+	logger.Info("Converted the door43 metadata column to native JSON")
+	return nil
+}
+
+func init() {
+	Register(&Check{
+		Title:     "Check consistency of door43 metadata (catalog)",
+		Name:      "check-door43-metadata",
+		IsDefault: false,
+		Run:       checkDoor43Metadata,
+		Priority:  7,
+	})
+	Register(&Check{
+		Title:     "Check that the commits of door43 metadata (catalog) still exist",
+		Name:      "check-door43-metadata-commits",
+		IsDefault: false,
+		Run:       checkDoor43MetadataCommits,
+		Priority:  7,
+	})
+	Register(&Check{
+		Title:     "Check that door43 metadata (catalog) is stored as native JSON, which rewrites the table when fixed",
+		Name:      "check-door43-metadata-json",
+		IsDefault: false,
+		Run:       checkDoor43MetadataJSON,
+		Priority:  7,
+	})
+}
@@ -22,6 +22,12 @@ const (
 	TARGZ
 	// BUNDLE bundle archive type
 	BUNDLE
+	/*** DCS Customizations ***/
+	// RCZIP zip archive packaged as a Resource Container
+	RCZIP
+	// BURRITOZIP zip archive packaged as a Scripture Burrito
+	BURRITOZIP
+	/*** END DCS Customizations ***/
 )
 
 // String converts an ArchiveType to string
@@ -33,6 +39,12 @@ func (a ArchiveType) String() string {
 		return "tar.gz"
 	case BUNDLE:
 		return "bundle"
+	/*** DCS Customizations ***/
+	case RCZIP:
+		return "rc.zip"
+	case BURRITOZIP:
+		return "burrito.zip"
+		/*** END DCS Customizations ***/
 	}
 	return "unknown"
 }
@@ -45,12 +57,18 @@ func ToArchiveType(s string) ArchiveType {
 		return TARGZ
 	case "bundle":
 		return BUNDLE
+	/*** DCS Customizations ***/
+	case "rc.zip":
+		return RCZIP
+	case "burrito.zip":
+		return BURRITOZIP
+		/*** END DCS Customizations ***/
 	}
 	return 0
 }
 
-// CreateArchive create archive content to the target path
-func (repo *Repository) CreateArchive(ctx context.Context, format ArchiveType, target io.Writer, usePrefix bool, commitID string) error {
+// CreateArchive create archive content to the target path, only of the given paths if any
+func (repo *Repository) CreateArchive(ctx context.Context, format ArchiveType, target io.Writer, usePrefix bool, commitID string, paths ...string) error { // DCS Customizations
 	if format.String() == "unknown" {
 		return fmt.Errorf("unknown format: %v", format)
 	}
@@ -61,6 +79,11 @@ func (repo *Repository) CreateArchive(ctx context.Context, format ArchiveType, t
 	}
 	cmd.AddOptionFormat("--format=%s", format.String())
 	cmd.AddDynamicArguments(commitID)
+	/*** DCS Customizations ***/
+	if len(paths) > 0 {
+		cmd.AddDashesAndList(literalPathspecs(paths)...)
+	}
+	/*** END DCS Customizations ***/
 
 	var stderr strings.Builder
 	err := cmd.Run(&RunOpts{
@@ -0,0 +1,39 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// literalPathspecs makes git match the paths literally, without wildcards or magic
+func literalPathspecs(paths []string) []string {
+	pathspecs := make([]string, 0, len(paths))
+	for _, p := range paths {
+		pathspecs = append(pathspecs, ":(literal)"+p)
+	}
+	return pathspecs
+}
+
+// CreateTarArchive writes an uncompressed tar archive without prefix of the commit to the target, only of the given
+// paths if any. It is meant to be read to repackage the files of a commit
+func (repo *Repository) CreateTarArchive(ctx context.Context, target io.Writer, commitID string, paths ...string) error {
+	cmd := NewCommand(ctx, "archive", "--format=tar").AddDynamicArguments(commitID)
+	if len(paths) > 0 {
+		cmd.AddDashesAndList(literalPathspecs(paths)...)
+	}
+
+	var stderr strings.Builder
+	err := cmd.Run(&RunOpts{
+		Dir:    repo.Path,
+		Stdout: target,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return ConcatenateError(err, stderr.String())
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package bleve
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/modules/indexer/catalog/internal"
+	indexer_internal "code.gitea.io/gitea/modules/indexer/internal"
+	inner_bleve "code.gitea.io/gitea/modules/indexer/internal/bleve"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/token/unicodenorm"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+const (
+	catalogIndexerAnalyzer      = "catalogIndexer"
+	catalogIndexerDocType       = "catalogIndexerDocType"
+	catalogIndexerLatestVersion = 1
+)
+
+const unicodeNormalizeName = "unicodeNormalize"
+
+const maxBatchSize = 16
+
+// IndexerData an update to the catalog indexer
+type IndexerData internal.IndexerData
+
+// Type returns the document type, for bleve's mapping.Classifier interface.
+func (i *IndexerData) Type() string {
+	return catalogIndexerDocType
+}
+
+// generateCatalogIndexMapping generates the bleve index mapping for catalog entries
+func generateCatalogIndexMapping() (mapping.IndexMapping, error) {
+	mapping := bleve.NewIndexMapping()
+	docMapping := bleve.NewDocumentMapping()
+
+	numericFieldMapping := bleve.NewNumericFieldMapping()
+	numericFieldMapping.Store = false
+	numericFieldMapping.IncludeInAll = false
+	docMapping.AddFieldMappingsAt("repo_id", numericFieldMapping)
+
+	textFieldMapping := bleve.NewTextFieldMapping()
+	textFieldMapping.Store = false
+	textFieldMapping.IncludeInAll = false
+
+	keywordFieldMapping := bleve.NewTextFieldMapping()
+	keywordFieldMapping.Store = false
+	keywordFieldMapping.IncludeInAll = false
+	keywordFieldMapping.Analyzer = keyword.Name
+
+	docMapping.AddFieldMappingsAt("title", textFieldMapping)
+	docMapping.AddFieldMappingsAt("subject", textFieldMapping)
+	docMapping.AddFieldMappingsAt("resource", keywordFieldMapping)
+	docMapping.AddFieldMappingsAt("language", keywordFieldMapping)
+	docMapping.AddFieldMappingsAt("language_names", textFieldMapping)
+	docMapping.AddFieldMappingsAt("repo_name", textFieldMapping)
+	docMapping.AddFieldMappingsAt("owner_name", textFieldMapping)
+	docMapping.AddFieldMappingsAt("books", keywordFieldMapping)
+
+	if err := mapping.AddCustomTokenFilter(unicodeNormalizeName, map[string]any{
+		"type": unicodenorm.Name,
+		"form": unicodenorm.NFC,
+	}); err != nil {
+		return nil, err
+	} else if err = mapping.AddCustomAnalyzer(catalogIndexerAnalyzer, map[string]any{
+		"type":          custom.Name,
+		"char_filters":  []string{},
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{unicodeNormalizeName, lowercase.Name},
+	}); err != nil {
+		return nil, err
+	}
+
+	mapping.DefaultAnalyzer = catalogIndexerAnalyzer
+	mapping.AddDocumentMapping(catalogIndexerDocType, docMapping)
+	mapping.AddDocumentMapping("_all", bleve.NewDocumentDisabledMapping())
+	mapping.DefaultMapping = bleve.NewDocumentDisabledMapping() // disable default mapping, avoid indexing unexpected structs
+
+	return mapping, nil
+}
+
+var _ internal.Indexer = &Indexer{}
+
+// Indexer implements Indexer interface
+type Indexer struct {
+	inner                    *inner_bleve.Indexer
+	indexer_internal.Indexer // do not composite inner_bleve.Indexer directly to avoid exposing too much
+}
+
+// NewIndexer creates a new bleve local indexer
+func NewIndexer(indexDir string) *Indexer {
+	inner := inner_bleve.NewIndexer(indexDir, catalogIndexerLatestVersion, generateCatalogIndexMapping)
+	return &Indexer{
+		Indexer: inner,
+		inner:   inner,
+	}
+}
+
+// Index will save the index data
+func (b *Indexer) Index(_ context.Context, entries ...*internal.IndexerData) error {
+	batch := inner_bleve.NewFlushingBatch(b.inner.Indexer, maxBatchSize)
+	for _, entry := range entries {
+		if err := batch.Index(indexer_internal.Base36(entry.ID), (*IndexerData)(entry)); err != nil {
+			return err
+		}
+	}
+	return batch.Flush()
+}
+
+// Delete deletes indexes by ids
+func (b *Indexer) Delete(_ context.Context, ids ...int64) error {
+	batch := inner_bleve.NewFlushingBatch(b.inner.Indexer, maxBatchSize)
+	for _, id := range ids {
+		if err := batch.Delete(indexer_internal.Base36(id)); err != nil {
+			return err
+		}
+	}
+	return batch.Flush()
+}
+
+// matchQuery generates a match query for the keyword on a field with a boost and optional fuzziness
+func matchQuery(keyword, field string, boost float64, fuzziness int) *query.MatchQuery {
+	q := bleve.NewMatchQuery(keyword)
+	q.FieldVal = field
+	q.Analyzer = catalogIndexerAnalyzer
+	q.SetBoost(boost)
+	q.SetFuzziness(fuzziness)
+	return q
+}
+
+// termQuery generates a term query for an exact (lowercase) value of a keyword field with a boost
+func termQuery(term, field string, boost float64) *query.TermQuery {
+	q := bleve.NewTermQuery(strings.ToLower(term))
+	q.FieldVal = field
+	q.SetBoost(boost)
+	return q
+}
+
+// Search searches for catalog entries matching the keyword.
+// Returns the matching entry IDs, best match first
+func (b *Indexer) Search(ctx context.Context, options *internal.SearchOptions) (*internal.SearchResult, error) {
+	fuzziness := 0
+	if options.Fuzzy {
+		fuzziness = 1
+	}
+	keywordQueries := []query.Query{
+		inner_bleve.MatchPhraseQuery(options.Keyword, "title", catalogIndexerAnalyzer),
+		matchQuery(options.Keyword, "title", 3, fuzziness),
+		matchQuery(options.Keyword, "language_names", 2, fuzziness),
+		matchQuery(options.Keyword, "subject", 1.5, 0),
+		matchQuery(options.Keyword, "repo_name", 1, 0),
+		matchQuery(options.Keyword, "owner_name", 1, 0),
+		termQuery(options.Keyword, "language", 3),
+		termQuery(options.Keyword, "resource", 2),
+		termQuery(options.Keyword, "books", 1),
+	}
+	search := bleve.NewSearchRequestOptions(bleve.NewDisjunctionQuery(keywordQueries...), options.Limit, options.Offset, false)
+
+	result, err := b.inner.Indexer.SearchInContext(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &internal.SearchResult{
+		Total: int64(result.Total),
+		Hits:  make([]internal.Match, 0, len(result.Hits)),
+	}
+	for _, hit := range result.Hits {
+		id, err := indexer_internal.ParseBase36(hit.ID)
+		if err != nil {
+			return nil, err
+		}
+		ret.Hits = append(ret.Hits, internal.Match{
+			ID:    id,
+			Score: hit.Score,
+		})
+	}
+	return ret, nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/indexer/catalog/internal"
+	indexer_internal "code.gitea.io/gitea/modules/indexer/internal"
+	inner_elasticsearch "code.gitea.io/gitea/modules/indexer/internal/elasticsearch"
+
+	"github.com/olivere/elastic/v7"
+)
+
+const (
+	catalogIndexerLatestVersion = 1
+)
+
+var _ internal.Indexer = &Indexer{}
+
+// Indexer implements Indexer interface
+type Indexer struct {
+	inner                    *inner_elasticsearch.Indexer
+	indexer_internal.Indexer // do not composite inner_elasticsearch.Indexer directly to avoid exposing too much
+}
+
+// NewIndexer creates a new elasticsearch indexer
+func NewIndexer(url, indexerName string) *Indexer {
+	inner := inner_elasticsearch.NewIndexer(url, indexerName, catalogIndexerLatestVersion, defaultMapping)
+	indexer := &Indexer{
+		inner:   inner,
+		Indexer: inner,
+	}
+	return indexer
+}
+
+const (
+	defaultMapping = `
+{
+	"mappings": {
+		"properties": {
+			"id": { "type": "integer", "index": true },
+			"repo_id": { "type": "integer", "index": true },
+
+			"title": { "type": "text", "index": true },
+			"subject": { "type": "text", "index": true },
+			"resource": { "type": "keyword", "index": true },
+			"language": { "type": "keyword", "index": true },
+			"language_names": { "type": "text", "index": true },
+			"repo_name": { "type": "text", "index": true },
+			"owner_name": { "type": "text", "index": true },
+			"books": { "type": "keyword", "index": true }
+		}
+	}
+}
+`
+)
+
+// Index will save the index data
+func (b *Indexer) Index(ctx context.Context, entries ...*internal.IndexerData) error {
+	if len(entries) == 0 {
+		return nil
+	} else if len(entries) == 1 {
+		entry := entries[0]
+		_, err := b.inner.Client.Index().
+			Index(b.inner.VersionedIndexName()).
+			Id(fmt.Sprintf("%d", entry.ID)).
+			BodyJson(entry).
+			Do(ctx)
+		return err
+	}
+
+	reqs := make([]elastic.BulkableRequest, 0)
+	for _, entry := range entries {
+		reqs = append(reqs,
+			elastic.NewBulkIndexRequest().
+				Index(b.inner.VersionedIndexName()).
+				Id(fmt.Sprintf("%d", entry.ID)).
+				Doc(entry),
+		)
+	}
+
+	_, err := b.inner.Client.Bulk().
+		Index(b.inner.VersionedIndexName()).
+		Add(reqs...).
+		Do(graceful.GetManager().HammerContext())
+	return err
+}
+
+// Delete deletes indexes by ids
+func (b *Indexer) Delete(ctx context.Context, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	} else if len(ids) == 1 {
+		_, err := b.inner.Client.Delete().
+			Index(b.inner.VersionedIndexName()).
+			Id(fmt.Sprintf("%d", ids[0])).
+			Do(ctx)
+		return err
+	}
+
+	reqs := make([]elastic.BulkableRequest, 0)
+	for _, id := range ids {
+		reqs = append(reqs,
+			elastic.NewBulkDeleteRequest().
+				Index(b.inner.VersionedIndexName()).
+				Id(fmt.Sprintf("%d", id)),
+		)
+	}
+
+	_, err := b.inner.Client.Bulk().
+		Index(b.inner.VersionedIndexName()).
+		Add(reqs...).
+		Do(graceful.GetManager().HammerContext())
+	return err
+}
+
+// Search searches for catalog entries matching the keyword.
+// Returns the matching entry IDs, best match first
+func (b *Indexer) Search(ctx context.Context, options *internal.SearchOptions) (*internal.SearchResult, error) {
+	multiMatch := elastic.NewMultiMatchQuery(options.Keyword, "title^3", "language_names^2", "subject^1.5", "repo_name", "owner_name")
+	if options.Fuzzy {
+		multiMatch.Fuzziness("AUTO")
+	}
+	query := elastic.NewBoolQuery().Should(
+		multiMatch,
+		elastic.NewTermQuery("language", strings.ToLower(options.Keyword)).Boost(3),
+		elastic.NewTermQuery("resource", strings.ToLower(options.Keyword)).Boost(2),
+		elastic.NewTermQuery("books", strings.ToLower(options.Keyword)),
+	).MinimumNumberShouldMatch(1)
+
+	searchResult, err := b.inner.Client.Search().
+		Index(b.inner.VersionedIndexName()).
+		Query(query).
+		From(options.Offset).
+		Size(options.Limit).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]internal.Match, 0, len(searchResult.Hits.Hits))
+	for _, hit := range searchResult.Hits.Hits {
+		id, _ := strconv.ParseInt(hit.Id, 10, 64)
+		var score float64
+		if hit.Score != nil {
+			score = *hit.Score
+		}
+		hits = append(hits, internal.Match{
+			ID:    id,
+			Score: score,
+		})
+	}
+
+	return &internal.SearchResult{
+		Total: searchResult.TotalHits(),
+		Hits:  hits,
+	}, nil
+}
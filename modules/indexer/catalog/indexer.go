@@ -0,0 +1,379 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+
+	db_model "code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/indexer/catalog/bleve"
+	"code.gitea.io/gitea/modules/indexer/catalog/elasticsearch"
+	"code.gitea.io/gitea/modules/indexer/catalog/internal"
+	"code.gitea.io/gitea/modules/indexer/catalog/meilisearch"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// searchPageSize is the number of ranked matches fetched from the catalog indexer at once
+var searchPageSize = 1000
+
+// MaxSearchMatches is the maximum number of matches returned for a keyword, the most relevant ones, which bounds the
+// number of matched IDs written in the SQL of a catalog search
+var MaxSearchMatches = 5000
+
+// IndexerMetadata is used to send data to the queue, so it contains only the ids.
+// If the IsDelete flag is true, the IDs specify the door43 metadata entries to delete from the index without querying the database.
+// If the IsDelete flag is false, the ID specify the entry to index, so Indexer will query the database to get the entry data.
+// If the id does not exist in the database, its index will be deleted too even if IsDelete is false.
+type IndexerMetadata struct {
+	ID int64 `json:"id"`
+
+	IsDelete bool    `json:"is_delete"`
+	IDs      []int64 `json:"ids"`
+}
+
+var (
+	// catalogIndexerQueue queue of door43 metadata ids to be updated
+	catalogIndexerQueue *queue.WorkerPoolQueue[*IndexerMetadata]
+	// globalIndexer is the global indexer, it cannot be nil.
+	// When the real indexer is not ready, it will be a dummy indexer which will return error to explain it's not ready.
+	globalIndexer atomic.Pointer[internal.Indexer]
+	dummyIndexer  *internal.Indexer
+)
+
+func init() {
+	i := internal.NewDummyIndexer()
+	dummyIndexer = &i
+	globalIndexer.Store(dummyIndexer)
+}
+
+// IsEnabled returns true if catalog searches should go through the catalog indexer instead of the database
+func IsEnabled() bool {
+	return setting.Indexer.CatalogType != "db"
+}
+
+// InitCatalogIndexer initialize the catalog indexer, syncReindex is true then reindex until
+// all door43 metadata entries are indexed.
+func InitCatalogIndexer(syncReindex bool) {
+	if !IsEnabled() {
+		log.Info("Catalog indexer is disabled, catalog searches will use the database")
+		return
+	}
+
+	ctx, _, finished := process.GetManager().AddTypedContext(context.Background(), "Service: CatalogIndexer", process.SystemProcessType, false)
+
+	indexerInitWaitChannel := make(chan time.Duration, 1)
+
+	// Create the Queue
+	catalogIndexerQueue = queue.CreateUniqueQueue(ctx, "catalog_indexer", getCatalogIndexerQueueHandler(ctx))
+
+	graceful.GetManager().RunAtTerminate(finished)
+
+	// Create the Indexer
+	go func() {
+		pprof.SetGoroutineLabels(ctx)
+		start := time.Now()
+		log.Info("PID %d: Initializing Catalog Indexer: %s", os.Getpid(), setting.Indexer.CatalogType)
+		var (
+			catalogIndexer internal.Indexer
+			existed        bool
+			err            error
+		)
+		if setting.Indexer.CatalogType == "bleve" {
+			defer func() {
+				if err := recover(); err != nil {
+					log.Error("PANIC whilst initializing catalog indexer: %v\nStacktrace: %s", err, log.Stack(2))
+					log.Error("The indexer files are likely corrupted and may need to be deleted")
+					log.Error("You can completely remove the %q directory to make Gitea recreate the indexes", setting.Indexer.CatalogPath)
+					globalIndexer.Store(dummyIndexer)
+					log.Fatal("PID: %d Unable to initialize the Bleve Catalog Indexer at path: %s Error: %v", os.Getpid(), setting.Indexer.CatalogPath, err)
+				}
+			}()
+		}
+		catalogIndexer, err = newIndexer()
+		if err != nil {
+			log.Fatal("Unable to create the catalog indexer: %v", err)
+		}
+		existed, err = catalogIndexer.Init(ctx)
+		if err != nil {
+			log.Fatal("Unable to initialize the %s Catalog Indexer: %v", setting.Indexer.CatalogType, err)
+		}
+		globalIndexer.Store(&catalogIndexer)
+
+		graceful.GetManager().RunAtTerminate(func() {
+			log.Debug("Closing catalog indexer")
+			(*globalIndexer.Load()).Close()
+			log.Info("PID: %d Catalog Indexer closed", os.Getpid())
+		})
+
+		// Start processing the queue
+		go graceful.GetManager().RunWithCancel(catalogIndexerQueue)
+
+		// Populate the index
+		if !existed {
+			if syncReindex {
+				graceful.GetManager().RunWithShutdownContext(populateCatalogIndexer)
+			} else {
+				go graceful.GetManager().RunWithShutdownContext(populateCatalogIndexer)
+			}
+		}
+
+		indexerInitWaitChannel <- time.Since(start)
+		close(indexerInitWaitChannel)
+	}()
+
+	if syncReindex {
+		select {
+		case <-indexerInitWaitChannel:
+		case <-graceful.GetManager().IsShutdown():
+		}
+	} else if setting.Indexer.StartupTimeout > 0 {
+		go func() {
+			pprof.SetGoroutineLabels(ctx)
+			timeout := setting.Indexer.StartupTimeout
+			if graceful.GetManager().IsChild() && setting.GracefulHammerTime > 0 {
+				timeout += setting.GracefulHammerTime
+			}
+			select {
+			case duration := <-indexerInitWaitChannel:
+				log.Info("Catalog Indexer Initialization took %v", duration)
+			case <-graceful.GetManager().IsShutdown():
+				log.Warn("Shutdown occurred before catalog index initialisation was complete")
+			case <-time.After(timeout):
+				catalogIndexerQueue.ShutdownWait(5 * time.Second)
+				log.Fatal("Catalog Indexer Initialization timed-out after: %v", timeout)
+			}
+		}()
+	}
+}
+
+// newIndexer creates the catalog indexer of the configured type
+func newIndexer() (internal.Indexer, error) {
+	switch setting.Indexer.CatalogType {
+	case "bleve":
+		return bleve.NewIndexer(setting.Indexer.CatalogPath), nil
+	case "elasticsearch":
+		return elasticsearch.NewIndexer(setting.Indexer.CatalogConnStr, setting.Indexer.CatalogIndexerName), nil
+	case "meilisearch":
+		return meilisearch.NewIndexer(setting.Indexer.CatalogConnStr, setting.Indexer.CatalogConnAuth, setting.Indexer.CatalogIndexerName), nil
+	}
+	return nil, fmt.Errorf("unknown catalog indexer type: %s", setting.Indexer.CatalogType)
+}
+
+func getCatalogIndexerQueueHandler(ctx context.Context) func(items ...*IndexerMetadata) []*IndexerMetadata {
+	return func(items ...*IndexerMetadata) []*IndexerMetadata {
+		var unhandled []*IndexerMetadata
+
+		indexer := *globalIndexer.Load()
+		for _, item := range items {
+			log.Trace("IndexerMetadata Process: %d %v %t", item.ID, item.IDs, item.IsDelete)
+			if item.IsDelete {
+				if err := indexer.Delete(ctx, item.IDs...); err != nil {
+					log.Error("Catalog indexer handler: failed to delete from index: %v Error: %v", item.IDs, err)
+					unhandled = append(unhandled, item)
+				}
+				continue
+			}
+			data, existed, err := getCatalogIndexerData(ctx, item.ID)
+			if err != nil {
+				log.Error("Catalog indexer handler: failed to get door43 metadata data of %d: %v", item.ID, err)
+				unhandled = append(unhandled, item)
+				continue
+			}
+			if !existed {
+				if err := indexer.Delete(ctx, item.ID); err != nil {
+					log.Error("Catalog indexer handler: failed to delete door43 metadata %d from index: %v", item.ID, err)
+					unhandled = append(unhandled, item)
+				}
+				continue
+			}
+			if err := indexer.Index(ctx, data); err != nil {
+				log.Error("Catalog indexer handler: failed to index door43 metadata %d: %v", item.ID, err)
+				unhandled = append(unhandled, item)
+				continue
+			}
+		}
+
+		return unhandled
+	}
+}
+
+// populateCatalogIndexer populate the catalog indexer with door43 metadata data
+func populateCatalogIndexer(ctx context.Context) {
+	ctx, _, finished := process.GetManager().AddTypedContext(ctx, "Service: PopulateCatalogIndexer", process.SystemProcessType, true)
+	defer finished()
+	ctx = contextWithKeepRetry(ctx) // keep retrying since it's a background task
+	if err := PopulateCatalogIndexer(ctx); err != nil {
+		log.Error("Catalog indexer population failed: %v", err)
+	}
+}
+
+// PopulateCatalogIndexer queues the door43 metadata entries of all repos to be (re)indexed
+func PopulateCatalogIndexer(ctx context.Context) error {
+	if !IsEnabled() {
+		return nil
+	}
+	for page := 1; ; page++ {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("shutdown before completion: %w", ctx.Err())
+		default:
+		}
+		repos, _, err := repo_model.SearchRepositoryByName(ctx, &repo_model.SearchRepoOptions{
+			ListOptions: db_model.ListOptions{Page: page, PageSize: repo_model.RepositoryListDefaultPageSize},
+			OrderBy:     db_model.SearchOrderByID,
+			Private:     true,
+			Collaborate: util.OptionalBoolFalse,
+		})
+		if err != nil {
+			log.Error("SearchRepositoryByName: %v", err)
+			continue
+		}
+		if len(repos) == 0 {
+			log.Debug("Catalog Indexer population complete")
+			return nil
+		}
+
+		for _, repo := range repos {
+			if err := updateRepoIndexer(ctx, repo.ID); err != nil {
+				return fmt.Errorf("populate catalog indexer for repo %d: %v", repo.ID, err)
+			}
+		}
+	}
+}
+
+// RebuildCatalogIndexer recreates the catalog index, indexing all the door43 metadata entries directly instead of through the queue.
+// It is meant for the command line, the bleve index cannot be rebuilt while Gitea is running as the index is locked.
+func RebuildCatalogIndexer(ctx context.Context) error {
+	if !IsEnabled() {
+		return fmt.Errorf("the catalog indexer is disabled (CATALOG_INDEXER_TYPE = %s)", setting.Indexer.CatalogType)
+	}
+	if setting.Indexer.CatalogType == "bleve" {
+		log.Info("Removing the catalog index at %s", setting.Indexer.CatalogPath)
+		if err := util.RemoveAll(setting.Indexer.CatalogPath); err != nil {
+			return err
+		}
+	}
+
+	indexer, err := newIndexer()
+	if err != nil {
+		return err
+	}
+	if _, err := indexer.Init(ctx); err != nil {
+		return err
+	}
+	defer indexer.Close()
+
+	var count int
+	for page := 1; ; page++ {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("shutdown before completion: %w", ctx.Err())
+		default:
+		}
+		repos, _, err := repo_model.SearchRepositoryByName(ctx, &repo_model.SearchRepoOptions{
+			ListOptions: db_model.ListOptions{Page: page, PageSize: repo_model.RepositoryListDefaultPageSize},
+			OrderBy:     db_model.SearchOrderByID,
+			Private:     true,
+			Collaborate: util.OptionalBoolFalse,
+		})
+		if err != nil {
+			return fmt.Errorf("SearchRepositoryByName: %w", err)
+		}
+		if len(repos) == 0 {
+			log.Info("Indexed %d catalog entries", count)
+			return nil
+		}
+
+		for _, repo := range repos {
+			ids, err := repo_model.GetDoor43MetadataIDsByRepoID(ctx, repo.ID)
+			if err != nil {
+				return fmt.Errorf("GetDoor43MetadataIDsByRepoID: %w", err)
+			}
+			entries := make([]*internal.IndexerData, 0, len(ids))
+			for _, id := range ids {
+				data, existed, err := getCatalogIndexerData(ctx, id)
+				if err != nil {
+					return fmt.Errorf("get door43 metadata data of %d: %w", id, err)
+				}
+				if existed {
+					entries = append(entries, data)
+				}
+			}
+			if len(entries) == 0 {
+				continue
+			}
+			if err := indexer.Index(ctx, entries...); err != nil {
+				return fmt.Errorf("index door43 metadata of repo %s: %w", repo.FullName(), err)
+			}
+			count += len(entries)
+		}
+	}
+}
+
+// UpdateRepoIndexer add/update all the door43 metadata entries of a repository
+func UpdateRepoIndexer(ctx context.Context, repoID int64) {
+	if err := updateRepoIndexer(ctx, repoID); err != nil {
+		log.Error("Unable to push repo %d to catalog indexer: %v", repoID, err)
+	}
+}
+
+// DeleteRepoCatalogIndexer deletes all the door43 metadata entries of a repo from the index.
+// It must be called before the entries are deleted from the database.
+func DeleteRepoCatalogIndexer(ctx context.Context, repoID int64) {
+	if err := deleteRepoCatalogIndexer(ctx, repoID); err != nil {
+		log.Error("Unable to push deleted repo %d to catalog indexer: %v", repoID, err)
+	}
+}
+
+// DeleteCatalogIndexer deletes door43 metadata entries from the index
+func DeleteCatalogIndexer(ctx context.Context, ids ...int64) {
+	if len(ids) == 0 {
+		return
+	}
+	if err := pushCatalogIndexerQueue(ctx, &IndexerMetadata{IDs: ids, IsDelete: true}); err != nil {
+		log.Error("Unable to push deleted door43 metadata %v to catalog indexer: %v", ids, err)
+	}
+}
+
+// IsAvailable checks if the catalog indexer is enabled and available
+func IsAvailable(ctx context.Context) bool {
+	return IsEnabled() && (*globalIndexer.Load()).Ping(ctx) == nil
+}
+
+// SearchCatalog searches the index for the keyword, fuzzy matching misspelled names, and
+// returns the IDs of the matching door43 metadata entries, most relevant first, up to MaxSearchMatches of them
+func SearchCatalog(ctx context.Context, keyword string) ([]int64, error) {
+	indexer := *globalIndexer.Load()
+	var ids []int64
+	for len(ids) < MaxSearchMatches {
+		limit := min(searchPageSize, MaxSearchMatches-len(ids))
+		result, err := indexer.Search(ctx, &internal.SearchOptions{
+			Keyword: keyword,
+			Fuzzy:   true,
+			Limit:   limit,
+			Offset:  len(ids),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range result.Hits {
+			ids = append(ids, hit.ID)
+		}
+		if len(result.Hits) < limit {
+			break
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"code.gitea.io/gitea/modules/indexer/catalog/bleve"
+	"code.gitea.io/gitea/modules/indexer/catalog/internal"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchCatalog(t *testing.T) {
+	var indexer internal.Indexer = bleve.NewIndexer(t.TempDir())
+	_, err := indexer.Init(context.Background())
+	assert.NoError(t, err)
+	defer indexer.Close()
+
+	entries := make([]*internal.IndexerData, 0, 5)
+	for i := int64(1); i <= 5; i++ {
+		entries = append(entries, &internal.IndexerData{ID: i, RepoID: i, Title: fmt.Sprintf("Unlocked Literal Bible %d", i), Language: "en", Resource: "ult"})
+	}
+	entries = append(entries, &internal.IndexerData{ID: 6, RepoID: 6, Title: "Translation Notes", Language: "fr", Resource: "tn"})
+	assert.NoError(t, indexer.Index(context.Background(), entries...))
+
+	result, err := indexer.Search(context.Background(), &internal.SearchOptions{Keyword: "bible", Limit: 2, Offset: 4})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, result.Total)
+	assert.Len(t, result.Hits, 1)
+
+	globalIndexer.Store(&indexer)
+	defer globalIndexer.Store(dummyIndexer)
+	defer test.MockVariableValue(&searchPageSize, 2)()
+
+	// All the matches are returned, paging through the index
+	ids, err := SearchCatalog(context.Background(), "bible")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{1, 2, 3, 4, 5}, ids)
+
+	// up to the maximum number of matches
+	defer test.MockVariableValue(&MaxSearchMatches, 3)()
+	ids, err = SearchCatalog(context.Background(), "bible")
+	assert.NoError(t, err)
+	assert.Len(t, ids, 3)
+
+	ids, err = SearchCatalog(context.Background(), "Notes")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{6}, ids)
+
+	ids, err = SearchCatalog(context.Background(), "obs")
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+}
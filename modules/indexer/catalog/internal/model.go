@@ -0,0 +1,40 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package internal
+
+// IndexerData data stored in the catalog indexer
+type IndexerData struct {
+	ID     int64 `json:"id"`
+	RepoID int64 `json:"repo_id"`
+
+	// Fields used for keyword searching
+	Title         string   `json:"title"`
+	Subject       string   `json:"subject"`
+	Resource      string   `json:"resource"`
+	Language      string   `json:"language"`
+	LanguageNames []string `json:"language_names"` // autonym, anglicized and alternative names of the language
+	RepoName      string   `json:"repo_name"`
+	OwnerName     string   `json:"owner_name"`
+	Books         []string `json:"books"`
+}
+
+// Match represents on search result
+type Match struct {
+	ID    int64   `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// SearchResult represents search results
+type SearchResult struct {
+	Total int64
+	Hits  []Match
+}
+
+// SearchOptions represents search options
+type SearchOptions struct {
+	Keyword string // keyword to search
+	Fuzzy   bool   // allow fuzzy matching of the keyword, mostly for misspelled language names
+	Limit   int    // maximum number of hits to return, best matches first
+	Offset  int    // number of best hits to skip, to page through the hits
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package meilisearch
+
+import (
+	"context"
+	"strconv"
+
+	"code.gitea.io/gitea/modules/indexer/catalog/internal"
+	indexer_internal "code.gitea.io/gitea/modules/indexer/internal"
+	inner_meilisearch "code.gitea.io/gitea/modules/indexer/internal/meilisearch"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+const (
+	catalogIndexerLatestVersion = 1
+
+	// TODO: make this configurable if necessary
+	maxTotalHits = 10000
+)
+
+var _ internal.Indexer = &Indexer{}
+
+// Indexer implements Indexer interface
+type Indexer struct {
+	inner                    *inner_meilisearch.Indexer
+	indexer_internal.Indexer // do not composite inner_meilisearch.Indexer directly to avoid exposing too much
+}
+
+// NewIndexer creates a new meilisearch indexer
+func NewIndexer(url, apiKey, indexerName string) *Indexer {
+	settings := &meilisearch.Settings{
+		// Typo tolerance of meilisearch gives the fuzzy matching of language names, so it is always on
+		SearchableAttributes: []string{
+			"language",
+			"title",
+			"language_names",
+			"resource",
+			"subject",
+			"books",
+			"repo_name",
+			"owner_name",
+		},
+		DisplayedAttributes: []string{
+			"id",
+		},
+		Pagination: &meilisearch.Pagination{
+			MaxTotalHits: maxTotalHits,
+		},
+	}
+
+	inner := inner_meilisearch.NewIndexer(url, apiKey, indexerName, catalogIndexerLatestVersion, settings)
+	indexer := &Indexer{
+		inner:   inner,
+		Indexer: inner,
+	}
+	return indexer
+}
+
+// Index will save the index data
+func (b *Indexer) Index(_ context.Context, entries ...*internal.IndexerData) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	_, err := b.inner.Client.Index(b.inner.VersionedIndexName()).AddDocuments(entries)
+	return err
+}
+
+// Delete deletes indexes by ids
+func (b *Indexer) Delete(_ context.Context, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	strIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strIDs = append(strIDs, strconv.FormatInt(id, 10))
+	}
+	_, err := b.inner.Client.Index(b.inner.VersionedIndexName()).DeleteDocuments(strIDs)
+	return err
+}
+
+// Search searches for catalog entries matching the keyword.
+// Returns the matching entry IDs, best match first
+func (b *Indexer) Search(ctx context.Context, options *internal.SearchOptions) (*internal.SearchResult, error) {
+	limit := options.Limit
+	if limit <= 0 || limit > maxTotalHits {
+		limit = maxTotalHits
+	}
+	searchRes, err := b.inner.Client.Index(b.inner.VersionedIndexName()).Search(options.Keyword, &meilisearch.SearchRequest{
+		Limit:            int64(limit),
+		Offset:           int64(options.Offset),
+		ShowRankingScore: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]internal.Match, 0, len(searchRes.Hits))
+	for _, hit := range searchRes.Hits {
+		doc := hit.(map[string]any)
+		score, _ := doc["_rankingScore"].(float64)
+		hits = append(hits, internal.Match{
+			ID:    int64(doc["id"].(float64)),
+			Score: score,
+		})
+	}
+
+	return &internal.SearchResult{
+		Total: searchRes.EstimatedTotalHits,
+		Hits:  hits,
+	}, nil
+}
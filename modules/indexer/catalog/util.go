@@ -0,0 +1,120 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/indexer/catalog/internal"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+)
+
+// getCatalogIndexerData returns the indexer data of a door43 metadata entry and a bool value indicating whether the entry exists.
+func getCatalogIndexerData(ctx context.Context, id int64) (*internal.IndexerData, bool, error) {
+	dm, err := repo_model.GetDoor43MetadataByID(ctx, id, 0)
+	if err != nil {
+		if repo_model.IsErrDoor43MetadataNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if err := dm.LoadRepo(ctx); err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	names := make(container.Set[string])
+	var languageNames []string
+	for _, name := range append([]string{dm.LanguageTitle, dcs.GetLanguageTitle(dm.Language), dcs.GetLanguageAnglicizedTitle(dm.Language)},
+		dcs.GetLanguageAlternativeNames(dm.Language)...) {
+		if name != "" && names.Add(name) {
+			languageNames = append(languageNames, name)
+		}
+	}
+
+	return &internal.IndexerData{
+		ID:            dm.ID,
+		RepoID:        dm.RepoID,
+		Title:         dm.Title,
+		Subject:       dm.Subject,
+		Resource:      dm.Resource,
+		Language:      dm.Language,
+		LanguageNames: languageNames,
+		RepoName:      dm.Repo.Name,
+		OwnerName:     dm.Repo.OwnerName,
+		Books:         dm.GetIngredientsIdentifierList(),
+	}, true, nil
+}
+
+func updateRepoIndexer(ctx context.Context, repoID int64) error {
+	ids, err := repo_model.GetDoor43MetadataIDsByRepoID(ctx, repoID)
+	if err != nil {
+		return fmt.Errorf("repo_model.GetDoor43MetadataIDsByRepoID: %w", err)
+	}
+	for _, id := range ids {
+		if err := pushCatalogIndexerQueue(ctx, &IndexerMetadata{ID: id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteRepoCatalogIndexer(ctx context.Context, repoID int64) error {
+	ids, err := repo_model.GetDoor43MetadataIDsByRepoID(ctx, repoID)
+	if err != nil {
+		return fmt.Errorf("repo_model.GetDoor43MetadataIDsByRepoID: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return pushCatalogIndexerQueue(ctx, &IndexerMetadata{
+		IDs:      ids,
+		IsDelete: true,
+	})
+}
+
+type keepRetryKey struct{}
+
+// contextWithKeepRetry returns a context with a key indicating that the indexer should keep retrying.
+// Please note that it's for background tasks only, and it should not be used for user requests, or it may cause blocking.
+func contextWithKeepRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, keepRetryKey{}, true)
+}
+
+func pushCatalogIndexerQueue(ctx context.Context, data *IndexerMetadata) error {
+	if catalogIndexerQueue == nil {
+		// The catalog indexer is disabled (CATALOG_INDEXER_TYPE = db) or not yet initialized
+		log.Trace("Catalog indexer queue is not initialized, ignoring %+v", data)
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		err := catalogIndexerQueue.Push(data)
+		if errors.Is(err, queue.ErrAlreadyInQueue) {
+			return nil
+		}
+		if errors.Is(err, context.DeadlineExceeded) { // the queue is full
+			log.Warn("It seems that catalog indexer is slow and the queue is full. Please check the catalog indexer or increase the queue size.")
+			if ctx.Value(keepRetryKey{}) == nil {
+				return err
+			}
+			log.Debug("Retry to push %+v to catalog indexer queue", data)
+			continue
+		}
+		return err
+	}
+}
@@ -46,6 +46,7 @@ type Collector struct {
 	Users              *prometheus.Desc
 	Watches            *prometheus.Desc
 	Webhooks           *prometheus.Desc
+	Door43Downloads    *prometheus.Desc // DCS Customizations
 }
 
 // NewCollector returns a new Collector with all prometheus.Desc initialized
@@ -196,6 +197,13 @@ func NewCollector() Collector {
 			"Number of Webhooks",
 			nil, nil,
 		),
+		/*** DCS Customizations ***/
+		Door43Downloads: prometheus.NewDesc(
+			namespace+"door43_downloads",
+			"Number of downloads of catalog entries by language, subject and kind",
+			[]string{"language", "subject", "kind"}, nil,
+		),
+		/*** END DCS Customizations ***/
 	}
 }
 
@@ -229,6 +237,7 @@ func (c Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Users
 	ch <- c.Watches
 	ch <- c.Webhooks
+	ch <- c.Door43Downloads // DCS Customizations
 }
 
 // Collect returns the metrics with values
@@ -385,4 +394,16 @@ func (c Collector) Collect(ch chan<- prometheus.Metric) {
 		prometheus.GaugeValue,
 		float64(stats.Counter.Webhook),
 	)
+	/*** DCS Customizations ***/
+	for _, d := range stats.Counter.Door43Downloads {
+		ch <- prometheus.MustNewConstMetric(
+			c.Door43Downloads,
+			prometheus.GaugeValue,
+			float64(d.Count),
+			d.Language,
+			d.Subject,
+			string(d.Kind),
+		)
+	}
+	/*** END DCS Customizations ***/
 }
@@ -0,0 +1,146 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+// Package opds contains the types of OPDS 1.2 (Atom) and OPDS 2.0 (JSON) catalog feeds
+// and of the OpenSearch description used to search them.
+package opds
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Media types of OPDS catalogs and their resources
+const (
+	TypeNavigation  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	TypeAcquisition = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	TypeOPDS2       = "application/opds+json"
+	TypeOpenSearch  = "application/opensearchdescription+xml"
+	TypeHTML        = "text/html"
+)
+
+// Link relations used in OPDS catalogs
+const (
+	RelSelf        = "self"
+	RelStart       = "start"
+	RelUp          = "up"
+	RelNext        = "next"
+	RelPrevious    = "previous"
+	RelFirst       = "first"
+	RelLast        = "last"
+	RelSearch      = "search"
+	RelAlternate   = "alternate"
+	RelSubsection  = "subsection"
+	RelAcquisition = "http://opds-spec.org/acquisition/open-access"
+	RelImage       = "http://opds-spec.org/image"
+	RelThumbnail   = "http://opds-spec.org/image/thumbnail"
+)
+
+// XML namespaces of OPDS 1.2 feeds
+const (
+	NamespaceAtom       = "http://www.w3.org/2005/Atom"
+	NamespaceDC         = "http://purl.org/dc/terms/"
+	NamespaceOPDS       = "http://opds-spec.org/2010/catalog"
+	NamespaceOpenSearch = "http://a9.com/-/spec/opensearch/1.1/"
+	NamespaceThreading  = "http://purl.org/syndication/thread/1.0"
+)
+
+// Feed is an OPDS 1.2 navigation or acquisition feed
+type Feed struct {
+	XMLName      xml.Name  `xml:"feed"`
+	Xmlns        string    `xml:"xmlns,attr"`
+	XmlnsDC      string    `xml:"xmlns:dc,attr"`
+	XmlnsOPDS    string    `xml:"xmlns:opds,attr"`
+	XmlnsOS      string    `xml:"xmlns:opensearch,attr"`
+	XmlnsThr     string    `xml:"xmlns:thr,attr"`
+	ID           string    `xml:"id"`
+	Title        string    `xml:"title"`
+	Updated      time.Time `xml:"updated"`
+	Author       *Author   `xml:"author,omitempty"`
+	Icon         string    `xml:"icon,omitempty"`
+	TotalResults int64     `xml:"opensearch:totalResults,omitempty"`
+	ItemsPerPage int       `xml:"opensearch:itemsPerPage,omitempty"`
+	Links        []*Link   `xml:"link"`
+	Entries      []*Entry  `xml:"entry"`
+}
+
+// NewFeed returns a feed with the OPDS namespaces set
+func NewFeed(id, title string, updated time.Time) *Feed {
+	return &Feed{
+		Xmlns:     NamespaceAtom,
+		XmlnsDC:   NamespaceDC,
+		XmlnsOPDS: NamespaceOPDS,
+		XmlnsOS:   NamespaceOpenSearch,
+		XmlnsThr:  NamespaceThreading,
+		ID:        id,
+		Title:     title,
+		Updated:   updated,
+	}
+}
+
+// Link is a link of an OPDS 1.2 feed or entry
+type Link struct {
+	Rel   string `xml:"rel,attr,omitempty"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+	Count int64  `xml:"thr:count,attr,omitempty"`
+}
+
+// Author is the author of an OPDS 1.2 feed or entry
+type Author struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+// Category is a category (subject) of an OPDS 1.2 entry
+type Category struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+// Content is the content or summary of an OPDS 1.2 entry
+type Content struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// Entry is a navigation or publication entry of an OPDS 1.2 feed
+type Entry struct {
+	ID         string      `xml:"id"`
+	Title      string      `xml:"title"`
+	Updated    time.Time   `xml:"updated"`
+	Authors    []*Author   `xml:"author,omitempty"`
+	Language   string      `xml:"dc:language,omitempty"`
+	Publisher  string      `xml:"dc:publisher,omitempty"`
+	Issued     string      `xml:"dc:issued,omitempty"`
+	Identifier string      `xml:"dc:identifier,omitempty"`
+	Categories []*Category `xml:"category,omitempty"`
+	Summary    *Content    `xml:"summary,omitempty"`
+	Content    *Content    `xml:"content,omitempty"`
+	Links      []*Link     `xml:"link"`
+}
+
+// OpenSearchDescription describes how to search an OPDS catalog
+type OpenSearchDescription struct {
+	XMLName        xml.Name          `xml:"OpenSearchDescription"`
+	Xmlns          string            `xml:"xmlns,attr"`
+	ShortName      string            `xml:"ShortName"`
+	Description    string            `xml:"Description"`
+	InputEncoding  string            `xml:"InputEncoding"`
+	OutputEncoding string            `xml:"OutputEncoding"`
+	URLs           []*OpenSearchURL  `xml:"Url"`
+	Images         []*OpenSearchIcon `xml:"Image,omitempty"`
+}
+
+// OpenSearchURL is a search URL template of an OpenSearch description
+type OpenSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+// OpenSearchIcon is an icon of an OpenSearch description
+type OpenSearchIcon struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
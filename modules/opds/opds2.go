@@ -0,0 +1,69 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package opds
+
+import "time"
+
+// Feed2 is an OPDS 2.0 feed, with navigation links and/or publications
+type Feed2 struct {
+	Metadata     *Feed2Metadata  `json:"metadata"`
+	Links        []*Link2        `json:"links"`
+	Navigation   []*Link2        `json:"navigation,omitempty"`
+	Publications []*Publication2 `json:"publications,omitempty"`
+}
+
+// Feed2Metadata is the metadata of an OPDS 2.0 feed
+type Feed2Metadata struct {
+	Title           string     `json:"title"`
+	Modified        *time.Time `json:"modified,omitempty"`
+	NumberOfItems   int64      `json:"numberOfItems,omitempty"`
+	ItemsPerPage    int        `json:"itemsPerPage,omitempty"`
+	CurrentPage     int        `json:"currentPage,omitempty"`
+	AdditionalTypes string     `json:"@type,omitempty"`
+}
+
+// Link2 is a link of an OPDS 2.0 feed or publication
+type Link2 struct {
+	Rel        string           `json:"rel,omitempty"`
+	Href       string           `json:"href"`
+	Type       string           `json:"type,omitempty"`
+	Title      string           `json:"title,omitempty"`
+	Templated  bool             `json:"templated,omitempty"`
+	Properties *Link2Properties `json:"properties,omitempty"`
+}
+
+// Link2Properties are the properties of an OPDS 2.0 link
+type Link2Properties struct {
+	NumberOfItems int64 `json:"numberOfItems,omitempty"`
+}
+
+// Publication2 is a publication of an OPDS 2.0 feed
+type Publication2 struct {
+	Metadata *Publication2Metadata `json:"metadata"`
+	Links    []*Link2              `json:"links"`
+	Images   []*Link2              `json:"images,omitempty"`
+}
+
+// Publication2Metadata is the metadata of an OPDS 2.0 publication
+type Publication2Metadata struct {
+	Type        string          `json:"@type"`
+	Identifier  string          `json:"identifier"`
+	Title       string          `json:"title"`
+	Author      []*Contributor2 `json:"author,omitempty"`
+	Publisher   []*Contributor2 `json:"publisher,omitempty"`
+	Language    string          `json:"language,omitempty"`
+	Subject     []string        `json:"subject,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Modified    time.Time       `json:"modified"`
+	Published   string          `json:"published,omitempty"`
+}
+
+// Contributor2 is an author or publisher of an OPDS 2.0 publication
+type Contributor2 struct {
+	Name  string   `json:"name"`
+	Links []*Link2 `json:"links,omitempty"`
+}
+
+// PublicationType2 is the schema.org type of the publications
+const PublicationType2 = "http://schema.org/Book"
@@ -3,13 +3,33 @@
 
 package setting
 
+import "time"
+
 // DCS settings
 var DCS struct {
-	Door43PreviewURL string
+	Door43PreviewURL      string
+	CatalogSearchCacheTTL time.Duration
+	MetadataRescanWorkers int
+
+	AttachmentLinkCheckAllowedHostList string
+	AttachmentLinkCheckTimeout         time.Duration
+
+	EnableDownloadStats bool
+
+	CollectionArchiveMaxSize int64
 }
 
 func loadDCSFrom(rootCfg ConfigProvider) {
 	mustMapSetting(rootCfg, "dcs", &DCS)
 	sec := rootCfg.Section("dcs")
 	DCS.Door43PreviewURL = sec.Key("DOOR43_PREVIEW_URL").MustString("https://door43.org")
+	DCS.CatalogSearchCacheTTL = sec.Key("CATALOG_SEARCH_CACHE_TTL").MustDuration(0)
+	DCS.MetadataRescanWorkers = sec.Key("METADATA_RESCAN_WORKERS").MustInt(4)
+	if DCS.MetadataRescanWorkers < 1 {
+		DCS.MetadataRescanWorkers = 1
+	}
+	DCS.AttachmentLinkCheckAllowedHostList = sec.Key("ATTACHMENT_LINK_CHECK_ALLOWED_HOST_LIST").MustString("external")
+	DCS.AttachmentLinkCheckTimeout = sec.Key("ATTACHMENT_LINK_CHECK_TIMEOUT").MustDuration(30 * time.Second)
+	DCS.EnableDownloadStats = sec.Key("ENABLE_DOWNLOAD_STATS").MustBool(true)
+	DCS.CollectionArchiveMaxSize = sec.Key("COLLECTION_ARCHIVE_MAX_SIZE").MustInt64(2048) * 1024 * 1024
 }
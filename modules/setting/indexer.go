@@ -33,6 +33,14 @@ var Indexer = struct {
 	IncludePatterns      []glob.Glob
 	ExcludePatterns      []glob.Glob
 	ExcludeVendored      bool
+
+	/*** DCS Customizations ***/
+	CatalogType        string
+	CatalogPath        string
+	CatalogConnStr     string
+	CatalogConnAuth    string
+	CatalogIndexerName string
+	/*** END DCS Customizations ***/
 }{
 	IssueType:        "bleve",
 	IssuePath:        "indexers/issues.bleve",
@@ -48,6 +56,14 @@ var Indexer = struct {
 	RepoIndexerName:      "gitea_codes",
 	MaxIndexerFileSize:   1024 * 1024,
 	ExcludeVendored:      true,
+
+	/*** DCS Customizations ***/
+	CatalogType:        "bleve",
+	CatalogPath:        "indexers/catalog.bleve",
+	CatalogConnStr:     "",
+	CatalogConnAuth:    "",
+	CatalogIndexerName: "dcs_catalog",
+	/*** END DCS Customizations ***/
 }
 
 func loadIndexerFrom(rootCfg ConfigProvider) {
@@ -87,6 +103,26 @@ func loadIndexerFrom(rootCfg ConfigProvider) {
 	Indexer.ExcludeVendored = sec.Key("REPO_INDEXER_EXCLUDE_VENDORED").MustBool(true)
 	Indexer.MaxIndexerFileSize = sec.Key("MAX_FILE_SIZE").MustInt64(1024 * 1024)
 	Indexer.StartupTimeout = sec.Key("STARTUP_TIMEOUT").MustDuration(30 * time.Second)
+
+	/*** DCS Customizations ***/
+	Indexer.CatalogType = sec.Key("CATALOG_INDEXER_TYPE").MustString("bleve")
+	Indexer.CatalogPath = filepath.ToSlash(sec.Key("CATALOG_INDEXER_PATH").MustString(filepath.ToSlash(filepath.Join(AppDataPath, "indexers/catalog.bleve"))))
+	if !filepath.IsAbs(Indexer.CatalogPath) {
+		Indexer.CatalogPath = filepath.ToSlash(filepath.Join(AppWorkPath, Indexer.CatalogPath))
+	}
+	Indexer.CatalogConnStr = sec.Key("CATALOG_INDEXER_CONN_STR").MustString(Indexer.CatalogConnStr)
+	if Indexer.CatalogType == "meilisearch" {
+		u, err := url.Parse(Indexer.CatalogConnStr)
+		if err != nil {
+			log.Warn("Failed to parse CATALOG_INDEXER_CONN_STR: %v", err)
+			u = &url.URL{}
+		}
+		Indexer.CatalogConnAuth, _ = u.User.Password()
+		u.User = nil
+		Indexer.CatalogConnStr = u.String()
+	}
+	Indexer.CatalogIndexerName = sec.Key("CATALOG_INDEXER_NAME").MustString(Indexer.CatalogIndexerName)
+	/*** END DCS Customizations ***/
 }
 
 // IndexerGlobFromString parses a comma separated list of patterns and returns a glob.Glob slice suited for repo indexing
@@ -9,11 +9,13 @@ var Metrics = struct {
 	Token                    string
 	EnabledIssueByLabel      bool
 	EnabledIssueByRepository bool
+	EnabledDoor43Downloads   bool // DCS Customizations
 }{
 	Enabled:                  false,
 	Token:                    "",
 	EnabledIssueByLabel:      false,
 	EnabledIssueByRepository: false,
+	EnabledDoor43Downloads:   false, // DCS Customizations
 }
 
 func loadMetricsFrom(rootCfg ConfigProvider) {
@@ -18,6 +18,12 @@ type Attachment struct {
 	Created     time.Time `json:"created_at"`
 	UUID        string    `json:"uuid"`
 	DownloadURL string    `json:"browser_download_url"`
+	/*** DCS Customizations ***/
+	// algorithm prefixed checksum of a linked file, e.g. sha256:<hex digest>
+	Checksum string `json:"checksum,omitempty"`
+	// media type of a linked file
+	MimeType string `json:"mime_type,omitempty"`
+	/*** END DCS Customizations ***/
 }
 
 // EditAttachmentOptions options for editing attachments
@@ -37,9 +37,30 @@ type CatalogEntry struct {
 	MetadataVersion        string        `json:"metadata_version"`
 	MetadataType           string        `json:"metadata_type"`
 	ContentFormat          string        `json:"content_format"`
+	CheckingLevel          int           `json:"checking_level"`
+	VerifiedCheckingLevel  int           `json:"verified_checking_level"`
 	Released               time.Time     `json:"released"`
 	Ingredients            []*Ingredient `json:"ingredients,omitempty"`
 	Books                  []string      `json:"books,omitempty"`
+	// zip archive packaged as a resource container for rc entries, as a scripture burrito for sb entries
+	PackagedZipballURL string `json:"packaged_zipball_url,omitempty"`
+	// signature of the entry, only given for production entries
+	Signature *CatalogSignature `json:"signature,omitempty"`
+	// release assets linking to external files that could no longer be downloaded when last checked
+	BrokenLinks []*CatalogBrokenLink `json:"broken_links,omitempty"`
+}
+
+// CatalogBrokenLink is a release asset of a catalog entry linking to an external file that can't be downloaded
+type CatalogBrokenLink struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	// HTTP status code of the last check, 0 if no response was received
+	StatusCode int    `json:"status_code"`
+	Error      string `json:"error"`
+	// swagger:strfmt date-time
+	BrokenSince time.Time `json:"broken_since"`
+	// swagger:strfmt date-time
+	LastChecked time.Time `json:"last_checked"`
 }
 
 // Ingredient is a single project of a resource
@@ -51,6 +72,27 @@ type Ingredient struct {
 	Title          string   `json:"title"`
 	Versification  string   `json:"versification"`
 	AlignmentCount *int     `json:"alignment_count,omitempty"`
+	// git object SHA of the ingredient's path at the commit of the entry
+	Checksum string `json:"checksum,omitempty"`
+	// archives of only the metadata files and the ingredient's path
+	ZipballURL string `json:"zipball_url,omitempty"`
+	TarballURL string `json:"tarball_url,omitempty"`
+	// how many verses (or frames of Open Bible Stories) have content, only for Bible and OBS translations
+	Progress *IngredientProgress `json:"progress,omitempty"`
+}
+
+// IngredientProgress how many verses, or frames of Open Bible Stories, of an ingredient have content, by chapter
+type IngredientProgress struct {
+	Completed int                `json:"completed"`
+	Total     int                `json:"total"`
+	Chapters  []*ChapterProgress `json:"chapters,omitempty"`
+}
+
+// ChapterProgress how many verses, or frames of a story of Open Bible Stories, of a chapter have content
+type ChapterProgress struct {
+	Chapter   int `json:"chapter"`
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
 }
 
 // CatalogSearchResults results of a successful catalog search
@@ -58,6 +100,14 @@ type CatalogSearchResults struct {
 	OK          bool            `json:"ok"`
 	Data        []*CatalogEntry `json:"data"`
 	LastUpdated time.Time       `json:"last_updated"`
+	// number of entries per value of the requested facets, keyed by facet name
+	Facets map[string][]*CatalogFacetCount `json:"facets,omitempty"`
+}
+
+// CatalogFacetCount number of entries of a catalog search having a value of a facet
+type CatalogFacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
 }
 
 // CatalogVersionEndpoints Info on the versions of the catalog
@@ -84,3 +134,355 @@ type CatalogStage struct {
 	GitTreesURL string    `json:"git_trees_url"`
 	ContentsURL string    `json:"contents_url"`
 }
+
+// CatalogCheck a checker's sign-off of a catalog entry's commit at a checking level
+type CatalogCheck struct {
+	ID        int64  `json:"id"`
+	Checker   *User  `json:"checker"`
+	CommitSHA string `json:"commit_sha"`
+	Level     int    `json:"level"`
+	Note      string `json:"note"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// CreateCatalogCheckOption options when signing off a catalog entry at a checking level
+type CreateCatalogCheckOption struct {
+	// required: true
+	Level int    `json:"level" binding:"Required;Range(1,3)"`
+	Note  string `json:"note"`
+}
+
+// CatalogChecker a user designated by an owner to verify its catalog entries
+type CatalogChecker struct {
+	Checker  *User `json:"checker"`
+	MaxLevel int   `json:"max_level"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// EditCatalogCheckerOption options when designating a catalog checker
+type EditCatalogCheckerOption struct {
+	// required: true
+	MaxLevel int `json:"max_level" binding:"Required;Range(1,3)"`
+}
+
+// CatalogSignature a signature of a catalog entry's metadata, commit SHA and ingredient checksums.
+// The signature is of the exact bytes of the base64 decoded payload.
+type CatalogSignature struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	// base64 encoded JSON of the signed content
+	Payload string `json:"payload"`
+	// base64 encoded signature of the payload
+	Signature string `json:"signature"`
+}
+
+// CatalogSignedPayload the content of a catalog entry that is signed
+type CatalogSignedPayload struct {
+	Owner       string                  `json:"owner"`
+	Repo        string                  `json:"repo"`
+	Ref         string                  `json:"ref"`
+	CommitSHA   string                  `json:"commit_sha"`
+	Metadata    *map[string]interface{} `json:"metadata"`
+	Ingredients map[string]string       `json:"ingredients"`
+}
+
+// CatalogSigningKey a public key used to sign catalog entries
+type CatalogSigningKey struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	// owner of the key, empty if it is the instance key
+	Owner string `json:"owner"`
+	// base64 encoded raw public key
+	PublicKey string `json:"public_key"`
+	// PEM encoded PKIX public key
+	PublicKeyPEM string `json:"public_key_pem"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// CatalogEntryRevision a previous version of a catalog entry, replaced when its metadata changed
+type CatalogEntryRevision struct {
+	ID int64 `json:"id"`
+	// event that caused the entry to be updated: push, release, repository, default_branch, manual, cron, command_line or doctor
+	Trigger       string   `json:"trigger"`
+	ChangedFields []string `json:"changed_fields"`
+	// the entry as it was before it was replaced
+	Entry *CatalogEntry `json:"entry"`
+	// swagger:strfmt date-time
+	ValidFrom time.Time `json:"valid_from"`
+	// swagger:strfmt date-time
+	Replaced time.Time `json:"replaced_at"`
+}
+
+// CatalogGraphQLRequest a GraphQL query of the catalog
+type CatalogGraphQLRequest struct {
+	// required: true
+	Query         string         `json:"query" binding:"Required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// CatalogGraphQLResponse the result of a GraphQL query of the catalog
+type CatalogGraphQLResponse struct {
+	// the requested fields, absent if the query could not be executed
+	Data   any                    `json:"data,omitempty"`
+	Errors []*CatalogGraphQLError `json:"errors,omitempty"`
+}
+
+// CatalogGraphQLError an error of a GraphQL query of the catalog
+type CatalogGraphQLError struct {
+	Message string `json:"message"`
+	// path of the field that failed to resolve
+	Path []any `json:"path,omitempty"`
+}
+
+// CatalogCollection a named bundle of catalog entries owned by a user or organization
+type CatalogCollection struct {
+	ID          int64  `json:"id"`
+	Owner       string `json:"owner"`
+	Name        string `json:"name"`
+	FullName    string `json:"full_name"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	HTMLURL     string `json:"html_url"`
+	// the most recent version, empty if no version has been released
+	LatestVersion string `json:"latest_version"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CatalogCollectionVersion a released version of a collection, pinning each member to a release tag
+type CatalogCollectionVersion struct {
+	ID         int64                      `json:"id"`
+	Collection string                     `json:"collection"`
+	Version    string                     `json:"version"`
+	Note       string                     `json:"note"`
+	Members    []*CatalogCollectionMember `json:"members"`
+	// URL of a zip archive of the contents of all the members
+	DownloadURL string `json:"download_url"`
+	HTMLURL     string `json:"html_url"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// CatalogCollectionMember a repo's release tag pinned in a version of a collection
+type CatalogCollectionMember struct {
+	FullName  string `json:"full_name"`
+	Ref       string `json:"ref"`
+	CommitSHA string `json:"commit_sha"`
+	// the catalog entry of the repo's tag, absent if it no longer exists
+	Entry *CatalogEntry `json:"entry,omitempty"`
+}
+
+// CreateCatalogCollectionOption options when creating a collection
+type CreateCatalogCollectionOption struct {
+	// required: true
+	Name        string `json:"name" binding:"Required;AlphaDashDot;MaxSize(100)"`
+	Title       string `json:"title" binding:"MaxSize(255)"`
+	Description string `json:"description"`
+}
+
+// EditCatalogCollectionOption options when editing a collection
+type EditCatalogCollectionOption struct {
+	Title       *string `json:"title" binding:"MaxSize(255)"`
+	Description *string `json:"description"`
+}
+
+// CreateCatalogCollectionVersionOption options when releasing a version of a collection
+type CreateCatalogCollectionVersionOption struct {
+	// required: true
+	Version string `json:"version" binding:"Required;AlphaDashDot;MaxSize(50)"`
+	Note    string `json:"note"`
+	// required: true
+	Members []*CatalogCollectionMemberOption `json:"members" binding:"Required"`
+}
+
+// CatalogCollectionMemberOption a repo's release tag to pin in a version of a collection
+type CatalogCollectionMemberOption struct {
+	// required: true
+	Owner string `json:"owner"`
+	// required: true
+	Repo string `json:"repo"`
+	// release tag of the repo, which must be a catalog entry
+	// required: true
+	Ref string `json:"ref"`
+}
+
+// CatalogSubscription a saved catalog query of new prod or preprod releases a user is notified of
+type CatalogSubscription struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Languages []string `json:"languages"`
+	Subjects  []string `json:"subjects"`
+	Owners    []string `json:"owners"`
+	Books     []string `json:"books"`
+	// prod for prod releases only, preprod for prod and preprod releases
+	Stage string `json:"stage"`
+	// whether an email digest of the matching new releases is sent daily
+	EmailDigest bool `json:"email_digest"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateCatalogSubscriptionOption options when creating a catalog subscription. At least one language, subject,
+// owner or book is required, and a release must match all the given lists
+type CreateCatalogSubscriptionOption struct {
+	// required: true
+	Name      string   `json:"name" binding:"Required;MaxSize(255)"`
+	Languages []string `json:"languages"`
+	Subjects  []string `json:"subjects"`
+	Owners    []string `json:"owners"`
+	Books     []string `json:"books"`
+	// prod (default) for prod releases only, preprod for prod and preprod releases
+	// enum: prod,preprod
+	Stage       string `json:"stage" binding:"OmitEmpty;In(prod,preprod)"`
+	EmailDigest bool   `json:"email_digest"`
+}
+
+// EditCatalogSubscriptionOption options when editing a catalog subscription
+type EditCatalogSubscriptionOption struct {
+	Name      *string   `json:"name" binding:"OmitEmpty;MaxSize(255)"`
+	Languages *[]string `json:"languages"`
+	Subjects  *[]string `json:"subjects"`
+	Owners    *[]string `json:"owners"`
+	Books     *[]string `json:"books"`
+	// enum: prod,preprod
+	Stage       *string `json:"stage" binding:"OmitEmpty;In(prod,preprod)"`
+	EmailDigest *bool   `json:"email_digest"`
+}
+
+// CatalogDownloadStats sums of the daily download counts of catalog entries
+type CatalogDownloadStats struct {
+	// first day counted (UTC)
+	Since string `json:"since"`
+	// last day counted (UTC)
+	Until string `json:"until"`
+	Total int64  `json:"total"`
+	// the sums by the requested group_by fields, only set if grouped
+	Counts []*CatalogDownloadCount `json:"counts,omitempty"`
+}
+
+// CatalogDownloadCount a sum of download counts. Only the fields they were grouped by are given
+type CatalogDownloadCount struct {
+	// day (UTC) in the YYYY-MM-DD format
+	Day string `json:"day,omitempty"`
+	// archive (zipball or tarball), attachment (release asset) or ingredient (raw or media file of an ingredient)
+	Kind     string `json:"kind,omitempty"`
+	Language string `json:"language,omitempty"`
+	Subject  string `json:"subject,omitempty"`
+	// full name of the repo
+	FullName string `json:"full_name,omitempty"`
+	EntryID  int64  `json:"entry_id,omitempty"`
+	// branch or tag of the entry
+	Ref   string `json:"ref,omitempty"`
+	Count int64  `json:"count"`
+}
+
+// ConvertedMetadata the metadata file of a ref converted from a resource container manifest.yaml to a scripture
+// burrito metadata.json or the other way round
+type ConvertedMetadata struct {
+	// metadata type converted from (rc or sb)
+	From string `json:"from"`
+	// metadata type converted to (rc or sb)
+	To string `json:"to"`
+	// path of the converted file in the repo
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// ConvertMetadataOption options when committing the converted metadata of a branch
+type ConvertMetadataOption struct {
+	// metadata type to convert to (rc or sb), the other type of the metadata found if empty
+	To string `json:"to" binding:"OmitEmpty;In(rc,sb)"`
+	// branch to convert the metadata of, the default branch if empty
+	Branch string `json:"branch" binding:"GitRefName;MaxSize(100)"`
+	// new branch to commit the converted metadata to, the branch if empty
+	NewBranch string `json:"new_branch" binding:"GitRefName;MaxSize(100)"`
+	// commit message, a default one if empty
+	Message string `json:"message"`
+}
+
+// TranslationProgress how many verses, or frames of Open Bible Stories, of a catalog entry have content
+type TranslationProgress struct {
+	FullName      string `json:"full_name"`
+	Owner         string `json:"owner"`
+	Name          string `json:"name"`
+	Ref           string `json:"ref"`
+	CommitSHA     string `json:"commit_sha"`
+	Title         string `json:"title"`
+	Subject       string `json:"subject"`
+	MetadataType  string `json:"metadata_type"`
+	Language      string `json:"language"`
+	LanguageTitle string `json:"language_title"`
+	Completed     int64  `json:"completed"`
+	Total         int64  `json:"total"`
+	// percentage of the total which is completed
+	Percent float64 `json:"percent"`
+	// the progress of each book, with the progress of each chapter if requested for a single entry
+	Books []*BookProgress `json:"books,omitempty"`
+	URL   string          `json:"url"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// BookProgress how many verses, or frames of Open Bible Stories, of a book have content
+type BookProgress struct {
+	Identifier string             `json:"identifier"`
+	Title      string             `json:"title"`
+	Completed  int                `json:"completed"`
+	Total      int                `json:"total"`
+	Percent    float64            `json:"percent"`
+	Chapters   []*ChapterProgress `json:"chapters,omitempty"`
+}
+
+// TranslationProgressReport the translation progress of the repos matching a query
+type TranslationProgressReport struct {
+	// sums of the progress of all the matching entries, not only of the page
+	Completed int64                  `json:"completed"`
+	Total     int64                  `json:"total"`
+	Percent   float64                `json:"percent"`
+	Entries   []*TranslationProgress `json:"entries"`
+}
+
+// CatalogLanguage a language of the catalog with its details from langnames.json
+type CatalogLanguage struct {
+	Code string `json:"code"`
+	// the name of the language in the language itself
+	Name           string `json:"name"`
+	AnglicizedName string `json:"anglicized_name"`
+	Direction      string `json:"direction"`
+	Region         string `json:"region"`
+	// codes of the countries the language is spoken in
+	Countries []string `json:"countries"`
+	// alternative names of the language
+	AlternativeNames []string `json:"alternative_names"`
+	IsGL             bool     `json:"is_gl"`
+	// codes of the gateway languages that serve the language, which are those spoken in the same countries
+	GatewayLanguages []string `json:"gateway_languages"`
+	// number of entries in the language
+	EntryCount int64 `json:"entry_count"`
+}
+
+// CatalogLanguageOverview everything the catalog has in a language
+type CatalogLanguageOverview struct {
+	Language *CatalogLanguage          `json:"language"`
+	Subjects []*CatalogLanguageSubject `json:"subjects"`
+	// number of entries of each owner
+	Owners []*CatalogFacetCount `json:"owners"`
+	// most recently released or updated entries, including previous releases
+	RecentEntries []*CatalogEntry `json:"recent_entries"`
+}
+
+// CatalogLanguageSubject number of entries of a subject in a language, in total and by stage (prod, preprod, latest)
+type CatalogLanguageSubject struct {
+	Subject string           `json:"subject"`
+	Total   int64            `json:"total"`
+	Stages  map[string]int64 `json:"stages"`
+}
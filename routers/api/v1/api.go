@@ -291,6 +291,21 @@ func tokenRequiresScopes(requiredScopeCategories ...auth_model.AccessTokenScopeC
 	}
 }
 
+/*** DCS Customizations ***/
+
+// tokenRequiresOwnerScopes requires the organization scope if the context user is an organization, otherwise the user scope
+func tokenRequiresOwnerScopes() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if ctx.ContextUser != nil && ctx.ContextUser.IsOrganization() {
+			tokenRequiresScopes(auth_model.AccessTokenScopeCategoryOrganization)(ctx)
+		} else {
+			tokenRequiresScopes(auth_model.AccessTokenScopeCategoryUser)(ctx)
+		}
+	}
+}
+
+/*** END DCS Customizations ***/
+
 // Contexter middleware already checks token for user sign in process.
 func reqToken() func(ctx *context.APIContext) {
 	return func(ctx *context.APIContext) {
@@ -1109,6 +1124,11 @@ func Routes() *web.Route {
 				m.Get("/raw/*", context.ReferencesGitRepo(), context.RepoRefForAPI, reqRepoReader(unit.TypeCode), repo.GetRawFile)
 				m.Get("/media/*", context.ReferencesGitRepo(), context.RepoRefForAPI, reqRepoReader(unit.TypeCode), repo.GetRawFileOrLFS)
 				m.Get("/archive/*", reqRepoReader(unit.TypeCode), repo.GetArchive)
+				/*** DCS Customizations ***/
+				m.Combo("/metadata/convert", reqRepoReader(unit.TypeCode), context.ReferencesGitRepo()).
+					Get(repo.GetConvertedMetadata).
+					Post(reqToken(), reqRepoWriter(unit.TypeCode), mustNotBeArchived, bind(api.ConvertMetadataOption{}), repo.ConvertMetadata)
+				/*** END DCS Customizations ***/
 				m.Combo("/forks").Get(repo.ListForks).
 					Post(reqToken(), reqRepoReader(unit.TypeCode), bind(api.CreateForkOption{}), repo.CreateFork)
 				m.Group("/branches", func() {
@@ -1557,8 +1577,13 @@ func Routes() *web.Route {
 			m.Get("/langnames.json", dcs.ServeLangnamesJSON)
 			m.Get("/langnames_keyed.json", dcs.ServeLangnamesJSONKeyed)
 		})
+		m.Get("/schemas/release_attachments.schema.json", dcs.ServeReleaseAttachmentsSchema)
 		m.Group("/catalog", func() {
 			m.Get("", catalog.Search)
+			m.Get("/downloads", catalog.ListCatalogDownloads)
+			m.Get("/progress", catalog.ListCatalogProgress)
+			m.Get("/languages", catalog.ListCatalogLanguageDetails)
+			m.Get("/languages/{lang}", catalog.GetCatalogLanguage)
 			m.Group("/list", func() {
 				m.Get("/subjects", catalog.ListCatalogSubjects)
 				m.Get("/owners", catalog.ListCatalogOwners)
@@ -1577,7 +1602,48 @@ func Routes() *web.Route {
 			m.Group("/entry/{username}/{reponame}/{ref}", func() {
 				m.Get("", catalog.GetCatalogEntry)
 				m.Get("/metadata", catalog.GetCatalogMetadata)
+				m.Get("/revisions", catalog.ListCatalogEntryRevisions)
+				m.Get("/downloads", catalog.ListCatalogEntryDownloads)
+				m.Get("/progress", catalog.GetCatalogEntryProgress)
+				m.Combo("/checks").Get(catalog.ListCatalogEntryChecks).
+					Post(reqToken(), tokenRequiresScopes(auth_model.AccessTokenScopeCategoryRepository), bind(api.CreateCatalogCheckOption{}), catalog.CreateCatalogEntryCheck)
 			}, repoAssignment())
+			m.Group("/checkers/{username}", func() {
+				m.Get("", catalog.ListCatalogCheckers)
+				m.Combo("/{checker}").
+					Put(reqToken(), tokenRequiresOwnerScopes(), bind(api.EditCatalogCheckerOption{}), catalog.SetCatalogChecker).
+					Delete(reqToken(), tokenRequiresOwnerScopes(), catalog.DeleteCatalogChecker)
+			}, context_service.UserAssignmentAPI())
+			m.Group("/collections/{username}", func() {
+				m.Combo("").Get(catalog.ListCatalogCollections).
+					Post(reqToken(), tokenRequiresOwnerScopes(), bind(api.CreateCatalogCollectionOption{}), catalog.CreateCatalogCollection)
+				m.Group("/{collection}", func() {
+					m.Combo("").Get(catalog.GetCatalogCollection).
+						Patch(reqToken(), tokenRequiresOwnerScopes(), bind(api.EditCatalogCollectionOption{}), catalog.EditCatalogCollection).
+						Delete(reqToken(), tokenRequiresOwnerScopes(), catalog.DeleteCatalogCollection)
+					m.Combo("/versions").Get(catalog.ListCatalogCollectionVersions).
+						Post(reqToken(), tokenRequiresOwnerScopes(), bind(api.CreateCatalogCollectionVersionOption{}), catalog.CreateCatalogCollectionVersion)
+					m.Group("/versions/{version}", func() {
+						m.Combo("").Get(catalog.GetCatalogCollectionVersion).
+							Delete(reqToken(), tokenRequiresOwnerScopes(), catalog.DeleteCatalogCollectionVersion)
+						m.Get("/download", catalog.DownloadCatalogCollectionVersion)
+					})
+				})
+			}, context_service.UserAssignmentAPI())
+			m.Group("/subscriptions", func() {
+				m.Combo("").Get(catalog.ListCatalogSubscriptions).
+					Post(bind(api.CreateCatalogSubscriptionOption{}), catalog.CreateCatalogSubscription)
+				m.Combo("/{id}").Get(catalog.GetCatalogSubscription).
+					Patch(bind(api.EditCatalogSubscriptionOption{}), catalog.EditCatalogSubscription).
+					Delete(catalog.DeleteCatalogSubscription)
+			}, reqToken(), tokenRequiresScopes(auth_model.AccessTokenScopeCategoryNotification))
+			m.Combo("/graphql").Get(catalog.GraphQLQuery).
+				Post(bind(api.CatalogGraphQLRequest{}), catalog.GraphQL)
+			m.Get("/signing-key", catalog.GetCatalogSigningKey)
+			m.Combo("/signing-key/{username}", context_service.UserAssignmentAPI()).
+				Get(catalog.GetCatalogOwnerSigningKey).
+				Post(reqToken(), tokenRequiresOwnerScopes(), catalog.CreateCatalogOwnerSigningKey).
+				Delete(reqToken(), tokenRequiresOwnerScopes(), catalog.DeleteCatalogOwnerSigningKey)
 		})
 		/*** END DCS Customizations ***/
 	}, sudo())
@@ -6,47 +6,54 @@ package catalog
 import (
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
-	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/door43metadata"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	"code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/services/convert"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
 )
 
 var searchOrderByMap = map[string]map[string]door43metadata.CatalogOrderBy{
 	"asc": {
-		"title":    door43metadata.CatalogOrderByTitle,
-		"subject":  door43metadata.CatalogOrderBySubject,
-		"resource": door43metadata.CatalogOrderByResource,
-		"reponame": door43metadata.CatalogOrderByRepoName,
-		"released": door43metadata.CatalogOrderByOldest,
-		"lang":     door43metadata.CatalogOrderByLangCode,
-		"releases": door43metadata.CatalogOrderByReleases,
-		"stars":    door43metadata.CatalogOrderByStars,
-		"forks":    door43metadata.CatalogOrderByForks,
-		"tag":      door43metadata.CatalogOrderByTag,
+		"title":     door43metadata.CatalogOrderByTitle,
+		"subject":   door43metadata.CatalogOrderBySubject,
+		"resource":  door43metadata.CatalogOrderByResource,
+		"reponame":  door43metadata.CatalogOrderByRepoName,
+		"released":  door43metadata.CatalogOrderByOldest,
+		"lang":      door43metadata.CatalogOrderByLangCode,
+		"releases":  door43metadata.CatalogOrderByReleases,
+		"stars":     door43metadata.CatalogOrderByStars,
+		"forks":     door43metadata.CatalogOrderByForks,
+		"tag":       door43metadata.CatalogOrderByTag,
+		"relevance": door43metadata.CatalogOrderByRelevance,
 	},
 	"desc": {
-		"title":    door43metadata.CatalogOrderByTitleReverse,
-		"subject":  door43metadata.CatalogOrderBySubjectReverse,
-		"resouce":  door43metadata.CatalogOrderByResourceReverse,
-		"reponame": door43metadata.CatalogOrderByRepoNameReverse,
-		"released": door43metadata.CatalogOrderByNewest,
-		"lang":     door43metadata.CatalogOrderByLangCodeReverse,
-		"releases": door43metadata.CatalogOrderByReleasesReverse,
-		"stars":    door43metadata.CatalogOrderByStarsReverse,
-		"forks":    door43metadata.CatalogOrderByForksReverse,
-		"tag":      door43metadata.CatalogOrderByTagReverse,
+		"title":     door43metadata.CatalogOrderByTitleReverse,
+		"subject":   door43metadata.CatalogOrderBySubjectReverse,
+		"resouce":   door43metadata.CatalogOrderByResourceReverse,
+		"reponame":  door43metadata.CatalogOrderByRepoNameReverse,
+		"released":  door43metadata.CatalogOrderByNewest,
+		"lang":      door43metadata.CatalogOrderByLangCodeReverse,
+		"releases":  door43metadata.CatalogOrderByReleasesReverse,
+		"stars":     door43metadata.CatalogOrderByStarsReverse,
+		"forks":     door43metadata.CatalogOrderByForksReverse,
+		"tag":       door43metadata.CatalogOrderByTagReverse,
+		"relevance": door43metadata.CatalogOrderByRelevance,
 	},
 }
 
@@ -55,6 +62,10 @@ func Search(ctx *context.APIContext) {
 	// swagger:operation GET /catalog/search catalog catalogSearch
 	// ---
 	// summary: Search the catalog
+	// description: Besides the parameters below, entries can be filtered by any field of their metadata (manifest) with
+	//   `metadata.<path>=<value>` parameters, where the path is the dot separated keys (or array indexes) of the field,
+	//   e.g. `metadata.dublin_core.publisher=unfoldingWord`. The field, or an element of it if it is an array, must equal
+	//   one of the values given for the path, or contain it if `partialMatch` is true. Values are not split at commas
 	// produces:
 	// - application/json
 	// parameters:
@@ -85,6 +96,24 @@ func Search(ctx *context.APIContext) {
 	//   in: query
 	//   description: list only those that are (true) or are not (false) a gatetway language
 	//   type: boolean
+	// - name: lr
+	//   in: query
+	//   description: search only for entries in the languages of the given region(s), e.g. Africa, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: cc
+	//   in: query
+	//   description: search only for entries in the languages spoken in the given country code(s), e.g. TZ, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: includeGL
+	//   in: query
+	//   description: if true, also include those in the gateway languages that serve the given languages (lang), which are the gateway languages spoken in the same countries
+	//   type: boolean
 	// - name: stage
 	//   in: query
 	//   description: 'specifies which release stage to be return of these stages:
@@ -127,6 +156,14 @@ func Search(ctx *context.APIContext) {
 	//   items:
 	//     type: string
 	//     enum: ["1","2","3"]
+	// - name: verifiedCheckingLevel
+	//   in: query
+	//   description: search only for entries verified by a designated checker of the owner at the given checking level(s) or higher
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//     enum: ["1","2","3"]
 	// - name: book
 	//   in: query
 	//   description: search only for entries with the given book(s) (ingredient identifiers). To match multiple, give the parameter multiple times or give a list comma delimited. Will perform an exact match (case insensitive)
@@ -164,14 +201,21 @@ func Search(ctx *context.APIContext) {
 	// - name: sort
 	//   in: query
 	//   description: sort repos alphanumerically by attribute. Supported values are
-	//                "subject", "title", "reponame", "tag", "released", "lang", "releases", "stars", "forks".
-	//                Default is by "language", "subject" and then "tag"
+	//                "subject", "title", "reponame", "tag", "released", "lang", "releases", "stars", "forks", "relevance".
+	//                "relevance" ranks the entries best matching the keywords (`q`) first.
+	//                Default is by "language", "subject" and then "tag", ranked by "relevance" first if keywords are given
 	//   type: string
 	// - name: order
 	//   in: query
 	//   description: sort order, either "asc" (ascending) or "desc" (descending).
 	//                Default is "asc", ignored if "sort" is not specified.
 	//   type: string
+	// - name: facets
+	//   in: query
+	//   description: facet(s) to count the matching entries by, returned in `facets`. Supported values are
+	//                "subject", "lang", "owner", "metadataType", "checkingLevel", "book" or "all".
+	//                Can use multiple `facets=<facet>`s or a comma-delimited string. Default is none
+	//   type: string
 	// - name: page
 	//   in: query
 	//   description: page number of results to return (1-based)
@@ -194,6 +238,10 @@ func SearchOwner(ctx *context.APIContext) {
 	// swagger:operation GET /catalog/search/{owner} catalog catalogSearchOwner
 	// ---
 	// summary: Search the catalog by owner
+	// description: Besides the parameters below, entries can be filtered by any field of their metadata (manifest) with
+	//   `metadata.<path>=<value>` parameters, where the path is the dot separated keys (or array indexes) of the field,
+	//   e.g. `metadata.dublin_core.publisher=unfoldingWord`. The field, or an element of it if it is an array, must equal
+	//   one of the values given for the path, or contain it if `partialMatch` is true. Values are not split at commas
 	// produces:
 	// - application/json
 	// parameters:
@@ -225,6 +273,24 @@ func SearchOwner(ctx *context.APIContext) {
 	//   in: query
 	//   description: list only those that are (true) or are not (false) a gatetway language
 	//   type: boolean
+	// - name: lr
+	//   in: query
+	//   description: search only for entries in the languages of the given region(s), e.g. Africa, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: cc
+	//   in: query
+	//   description: search only for entries in the languages spoken in the given country code(s), e.g. TZ, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: includeGL
+	//   in: query
+	//   description: if true, also include those in the gateway languages that serve the given languages (lang), which are the gateway languages spoken in the same countries
+	//   type: boolean
 	// - name: stage
 	//   in: query
 	//   description: 'specifies which release stage to be return of these stages:
@@ -267,6 +333,14 @@ func SearchOwner(ctx *context.APIContext) {
 	//   items:
 	//     type: string
 	//     enum: ["1","2","3"]
+	// - name: verifiedCheckingLevel
+	//   in: query
+	//   description: search only for entries verified by a designated checker of the owner at the given checking level(s) or higher
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//     enum: ["1","2","3"]
 	// - name: book
 	//   in: query
 	//   description: search only for entries with the given book(s) (ingredient identifiers). To match multiple, give the parameter multiple times or give a list comma delimited. Will perform an exact match (case insensitive)
@@ -304,14 +378,21 @@ func SearchOwner(ctx *context.APIContext) {
 	// - name: sort
 	//   in: query
 	//   description: sort repos alphanumerically by attribute. Supported values are
-	//                "subject", "title", "reponame", "tag", "released", "lang", "releases", "stars", "forks".
-	//                Default is by "language", "subject" and then "tag"
+	//                "subject", "title", "reponame", "tag", "released", "lang", "releases", "stars", "forks", "relevance".
+	//                "relevance" ranks the entries best matching the keywords (`q`) first.
+	//                Default is by "language", "subject" and then "tag", ranked by "relevance" first if keywords are given
 	//   type: string
 	// - name: order
 	//   in: query
 	//   description: sort order, either "asc" (ascending) or "desc" (descending).
 	//                Default is "asc", ignored if "sort" is not specified.
 	//   type: string
+	// - name: facets
+	//   in: query
+	//   description: facet(s) to count the matching entries by, returned in `facets`. Supported values are
+	//                "subject", "lang", "owner", "metadataType", "checkingLevel", "book" or "all".
+	//                Can use multiple `facets=<facet>`s or a comma-delimited string. Default is none
+	//   type: string
 	// - name: page
 	//   in: query
 	//   description: page number of results to return (1-based)
@@ -334,6 +415,10 @@ func SearchRepo(ctx *context.APIContext) {
 	// swagger:operation GET /catalog/search/{owner}/{repo} catalog catalogSearchRepo
 	// ---
 	// summary: Search the catalog by owner and repo
+	// description: Besides the parameters below, entries can be filtered by any field of their metadata (manifest) with
+	//   `metadata.<path>=<value>` parameters, where the path is the dot separated keys (or array indexes) of the field,
+	//   e.g. `metadata.dublin_core.publisher=unfoldingWord`. The field, or an element of it if it is an array, must equal
+	//   one of the values given for the path, or contain it if `partialMatch` is true. Values are not split at commas
 	// produces:
 	// - application/json
 	// parameters:
@@ -374,6 +459,24 @@ func SearchRepo(ctx *context.APIContext) {
 	//   in: query
 	//   description: list only those that are (true) or are not (false) a gatetway language
 	//   type: boolean
+	// - name: lr
+	//   in: query
+	//   description: search only for entries in the languages of the given region(s), e.g. Africa, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: cc
+	//   in: query
+	//   description: search only for entries in the languages spoken in the given country code(s), e.g. TZ, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: includeGL
+	//   in: query
+	//   description: if true, also include those in the gateway languages that serve the given languages (lang), which are the gateway languages spoken in the same countries
+	//   type: boolean
 	// - name: stage
 	//   in: query
 	//   description: 'specifies which release stage to be return of these stages:
@@ -416,6 +519,14 @@ func SearchRepo(ctx *context.APIContext) {
 	//   items:
 	//     type: string
 	//     enum: ["1","2","3"]
+	// - name: verifiedCheckingLevel
+	//   in: query
+	//   description: search only for entries verified by a designated checker of the owner at the given checking level(s) or higher
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//     enum: ["1","2","3"]
 	// - name: book
 	//   in: query
 	//   description: search only for entries with the given book(s) (ingredient identifiers). To match multiple, give the parameter multiple times or give a list comma delimited. Will perform an exact match (case insensitive)
@@ -453,14 +564,21 @@ func SearchRepo(ctx *context.APIContext) {
 	// - name: sort
 	//   in: query
 	//   description: sort repos alphanumerically by attribute. Supported values are
-	//                "subject", "title", "reponame", "tag", "released", "lang", "releases", "stars", "forks".
-	//                Default is by "language", "subject" and then "tag"
+	//                "subject", "title", "reponame", "tag", "released", "lang", "releases", "stars", "forks", "relevance".
+	//                "relevance" ranks the entries best matching the keywords (`q`) first.
+	//                Default is by "language", "subject" and then "tag", ranked by "relevance" first if keywords are given
 	//   type: string
 	// - name: order
 	//   in: query
 	//   description: sort order, either "asc" (ascending) or "desc" (descending).
 	//                Default is "asc", ignored if "sort" is not specified.
 	//   type: string
+	// - name: facets
+	//   in: query
+	//   description: facet(s) to count the matching entries by, returned in `facets`. Supported values are
+	//                "subject", "lang", "owner", "metadataType", "checkingLevel", "book" or "all".
+	//                Can use multiple `facets=<facet>`s or a comma-delimited string. Default is none
+	//   type: string
 	// - name: page
 	//   in: query
 	//   description: page number of results to return (1-based)
@@ -501,6 +619,24 @@ func ListCatalogSubjects(ctx *context.APIContext) {
 	//   in: query
 	//   description: list only those that are (true) or are not (false) a gatetway language
 	//   type: boolean
+	// - name: lr
+	//   in: query
+	//   description: list only those in the languages of the given region(s), e.g. Africa, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: cc
+	//   in: query
+	//   description: list only those in the languages spoken in the given country code(s), e.g. TZ, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: includeGL
+	//   in: query
+	//   description: if true, also include those in the gateway languages that serve the given languages (lang), which are the gateway languages spoken in the same countries
+	//   type: boolean
 	// - name: stage
 	//   in: query
 	//   description: 'list only those of the given stage or lower, with low to high being:
@@ -540,6 +676,14 @@ func ListCatalogSubjects(ctx *context.APIContext) {
 	//   items:
 	//     type: string
 	//     enum: ["1","2","3"]
+	// - name: verifiedCheckingLevel
+	//   in: query
+	//   description: search only for entries verified by a designated checker of the owner at the given checking level(s) or higher
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//     enum: ["1","2","3"]
 	// - name: book
 	//   in: query
 	//   description: list only those with the given book(s) (ingredient identifiers). To match multiple, give the parameter multiple times or give a list comma delimited. Will perform an exact match (case insensitive)
@@ -585,6 +729,7 @@ func ListCatalogSubjects(ctx *context.APIContext) {
 			"ok":    false,
 			"error": err.Error(),
 		})
+		return
 	}
 	ctx.RespHeader().Set("X-Total-Count", fmt.Sprintf("%d", len(list)))
 	ctx.JSON(http.StatusOK, map[string]any{
@@ -616,6 +761,24 @@ func ListCatalogMetadataTypes(ctx *context.APIContext) {
 	//   in: query
 	//   description: list only those that are (true) or are not (false) a gatetway language
 	//   type: boolean
+	// - name: lr
+	//   in: query
+	//   description: list only those in the languages of the given region(s), e.g. Africa, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: cc
+	//   in: query
+	//   description: list only those in the languages spoken in the given country code(s), e.g. TZ, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: includeGL
+	//   in: query
+	//   description: if true, also include those in the gateway languages that serve the given languages (lang), which are the gateway languages spoken in the same countries
+	//   type: boolean
 	// - name: stage
 	//   in: query
 	//   description: 'list only those of the given stage or lower, with low to high being:
@@ -655,6 +818,14 @@ func ListCatalogMetadataTypes(ctx *context.APIContext) {
 	//   items:
 	//     type: string
 	//     enum: ["1","2","3"]
+	// - name: verifiedCheckingLevel
+	//   in: query
+	//   description: search only for entries verified by a designated checker of the owner at the given checking level(s) or higher
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//     enum: ["1","2","3"]
 	// - name: book
 	//   in: query
 	//   description: list only those with the given book(s) (ingredient identifiers). To match multiple, give the parameter multiple times or give a list comma delimited. Will perform an exact match (case insensitive)
@@ -700,6 +871,7 @@ func ListCatalogMetadataTypes(ctx *context.APIContext) {
 			"ok":    false,
 			"error": err.Error(),
 		})
+		return
 	}
 	ctx.RespHeader().Set("X-Total-Count", fmt.Sprintf("%d", len(list)))
 	ctx.JSON(http.StatusOK, map[string]any{
@@ -731,6 +903,24 @@ func ListCatalogOwners(ctx *context.APIContext) {
 	//   in: query
 	//   description: list only those that are (true) or are not (false) a gatetway language
 	//   type: boolean
+	// - name: lr
+	//   in: query
+	//   description: list only those in the languages of the given region(s), e.g. Africa, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: cc
+	//   in: query
+	//   description: list only those in the languages spoken in the given country code(s), e.g. TZ, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: includeGL
+	//   in: query
+	//   description: if true, also include those in the gateway languages that serve the given languages (lang), which are the gateway languages spoken in the same countries
+	//   type: boolean
 	// - name: stage
 	//   in: query
 	//   description: 'list only those of the given stage or lower, with low to high being:
@@ -770,6 +960,14 @@ func ListCatalogOwners(ctx *context.APIContext) {
 	//   items:
 	//     type: string
 	//     enum: ["1","2","3"]
+	// - name: verifiedCheckingLevel
+	//   in: query
+	//   description: search only for entries verified by a designated checker of the owner at the given checking level(s) or higher
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//     enum: ["1","2","3"]
 	// - name: book
 	//   in: query
 	//   description: list only those with the given book(s) (ingredient identifiers). To match multiple, give the parameter multiple times or give a list comma delimited. Will perform an exact match (case insensitive)
@@ -811,6 +1009,7 @@ func ListCatalogOwners(ctx *context.APIContext) {
 			"ok":    false,
 			"error": err.Error(),
 		})
+		return
 	}
 	var users []*api.User
 	for _, name := range list {
@@ -850,6 +1049,24 @@ func ListCatalogLanguages(ctx *context.APIContext) {
 	//   in: query
 	//   description: list only those that are (true) or are not (false) a gatetway language
 	//   type: boolean
+	// - name: lr
+	//   in: query
+	//   description: list only those in the languages of the given region(s), e.g. Africa, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: cc
+	//   in: query
+	//   description: list only those in the languages spoken in the given country code(s), e.g. TZ, as given by langnames.json. To match multiple, give the parameter multiple times or give a list comma delimited. Is case insensitive
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: includeGL
+	//   in: query
+	//   description: if true, also include those in the gateway languages that serve the given languages (lang), which are the gateway languages spoken in the same countries
+	//   type: boolean
 	// - name: stage
 	//   in: query
 	//   description: 'list only those of the given stage or lower, with low to high being:
@@ -889,6 +1106,14 @@ func ListCatalogLanguages(ctx *context.APIContext) {
 	//   items:
 	//     type: string
 	//     enum: ["1","2","3"]
+	// - name: verifiedCheckingLevel
+	//   in: query
+	//   description: search only for entries verified by a designated checker of the owner at the given checking level(s) or higher
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//     enum: ["1","2","3"]
 	// - name: book
 	//   in: query
 	//   description: list only those with the given book(s) (ingredient identifiers). To match multiple, give the parameter multiple times or give a list comma delimited. Will perform an exact match (case insensitive)
@@ -930,6 +1155,7 @@ func ListCatalogLanguages(ctx *context.APIContext) {
 			"ok":    false,
 			"error": err.Error(),
 		})
+		return
 	}
 	var languages []map[string]interface{}
 	langnames := dcs.GetLangnamesJSONKeyed()
@@ -949,7 +1175,8 @@ func ListCatalogLanguages(ctx *context.APIContext) {
 func GetCatalogEntry(ctx *context.APIContext) {
 	// swagger:operation GET /catalog/entry/{owner}/{repo}/{ref} catalog catalogGetEntry
 	// ---
-	// summary: Get a catalog entry
+	// summary: Get a catalog entry. Production entries, other than previous versions of them, include a signature of their
+	//   metadata, commit SHA and ingredient checksums
 	// produces:
 	// - application/json
 	// parameters:
@@ -968,16 +1195,33 @@ func GetCatalogEntry(ctx *context.APIContext) {
 	//   description: release tag or default branch
 	//   type: string
 	//   required: true
+	// - name: at
+	//   in: query
+	//   description: get the entry as it was in the catalog at this time, in RFC 3339 format
+	//   type: string
+	//   format: date-time
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/CatalogEntry"
 	//   "404":
 	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	ref := ctx.Params("ref")
 	var dm *repo.Door43Metadata
 	var err error
-	dm, err = repo.GetDoor43MetadataByRepoIDAndRef(ctx, ctx.Repo.Repository.ID, ref)
+	if at := ctx.FormTrim("at"); at != "" {
+		var atTime time.Time
+		atTime, err = time.Parse(time.RFC3339, at)
+		if err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "at", err)
+			return
+		}
+		dm, err = repo.GetDoor43MetadataAtTime(ctx, ctx.Repo.Repository.ID, ref, timeutil.TimeStamp(atTime.Unix()))
+	} else {
+		dm, err = repo.GetDoor43MetadataByRepoIDAndRef(ctx, ctx.Repo.Repository.ID, ref)
+	}
 	if err != nil {
 		if !repo.IsErrDoor43MetadataNotExist(err) {
 			ctx.Error(http.StatusInternalServerError, "GetDoor43MetadataByRepoIDAndRef", err)
@@ -995,7 +1239,16 @@ func GetCatalogEntry(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
 		return
 	}
-	ctx.JSON(http.StatusOK, convert.ToCatalogEntry(ctx, dm, perm))
+	entry := convert.ToCatalogEntry(ctx, dm, perm)
+	// Previous versions aren't signed, as the signing key and the verified checks are the current ones
+	if entry != nil && dm.Stage == door43metadata.StageProd && !dm.IsRevision {
+		entry.Signature, err = door43metadata_service.SignDoor43Metadata(ctx, dm)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "SignDoor43Metadata", err)
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, entry)
 }
 
 // GetCatalogMetadata Get the metadata (RC 0.2 manifest) in JSON format for the given ownername, reponame and ref
@@ -1053,7 +1306,37 @@ func QueryStrings(ctx *context.APIContext, name string) []string {
 	return newStrs
 }
 
+// QueryMetadataFilters gets the filters by metadata fields of the `metadata.<path>=<value>` query parameters,
+// e.g. metadata.dublin_core.publisher=unfoldingWord. Values are not split at commas
+func QueryMetadataFilters(ctx *context.APIContext) ([]*door43metadata.MetadataFilter, error) {
+	query := ctx.Req.URL.Query()
+	paths := make([]string, 0, len(query))
+	for key := range query {
+		if strings.HasPrefix(key, door43metadata.MetadataFilterPrefix) {
+			paths = append(paths, key)
+		}
+	}
+	sort.Strings(paths)
+	filters := make([]*door43metadata.MetadataFilter, 0, len(paths))
+	for _, path := range paths {
+		filter, err := door43metadata.ParseMetadataFilter(path, query[path])
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
 func searchCatalog(ctx *context.APIContext) {
+	cacheKey := catalogSearchCacheKey(ctx)
+	if cacheKey != "" {
+		if response := getCachedCatalogSearchResponse(cacheKey); response != nil {
+			writeCatalogSearchResponse(ctx, response)
+			return
+		}
+	}
+
 	var repoID int64
 	var owners, repos []string
 	if ctx.Repo.Repository != nil {
@@ -1080,6 +1363,16 @@ func searchCatalog(ctx *context.APIContext) {
 
 	metadataTypes := QueryStrings(ctx, "metadataType")
 	metadataVersions := QueryStrings(ctx, "metadataVersion")
+	metadataFilters, err := QueryMetadataFilters(ctx)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+	verifiedLevels, err := door43metadata.ParseCheckingLevels(QueryStrings(ctx, "verifiedCheckingLevel"))
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
 
 	keywords := []string{}
 	query := strings.Trim(ctx.FormString("q"), " ")
@@ -1104,11 +1397,16 @@ func searchCatalog(ctx *context.APIContext) {
 		Stage:            stage,
 		Languages:        QueryStrings(ctx, "lang"),
 		LanguageIsGL:     ctx.FormOptionalBool("is_gl"),
+		Regions:          QueryStrings(ctx, "lr"),
+		Countries:        QueryStrings(ctx, "cc"),
+		IncludeGLs:       ctx.FormBool("includeGL"),
 		Subjects:         QueryStrings(ctx, "subject"),
 		Resources:        QueryStrings(ctx, "resource"),
 		ContentFormats:   QueryStrings(ctx, "format"),
 		CheckingLevels:   QueryStrings(ctx, "checkingLevel"),
+		VerifiedLevels:   verifiedLevels,
 		Books:            QueryStrings(ctx, "book"),
+		MetadataFilters:  metadataFilters,
 		IncludeHistory:   ctx.FormBool("includeHistory"),
 		ShowIngredients:  ctx.FormOptionalBool("showIngredients"),
 		MetadataTypes:    metadataTypes,
@@ -1137,30 +1435,49 @@ func searchCatalog(ctx *context.APIContext) {
 		}
 	} else {
 		opts.OrderBy = []door43metadata.CatalogOrderBy{door43metadata.CatalogOrderByLangCode, door43metadata.CatalogOrderBySubject, door43metadata.CatalogOrderByReleaseDateReverse}
+		if len(keywords) > 0 {
+			opts.OrderBy = append([]door43metadata.CatalogOrderBy{door43metadata.CatalogOrderByRelevance}, opts.OrderBy...)
+		}
+	}
+
+	facets, err := door43metadata.ParseCatalogFacets(QueryStrings(ctx, "facets"))
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
 	}
 
-	dms, count, err := models.SearchCatalog(ctx, opts)
+	dms, count, err := door43metadata_service.SearchCatalog(ctx, opts)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "SearchCatalog", err)
 		return
 	}
 
+	var facetCounts map[string][]*api.CatalogFacetCount
+	if len(facets) > 0 {
+		counts, err := door43metadata_service.CountCatalogFacets(ctx, opts, facets)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "CountCatalogFacets", err)
+			return
+		}
+		facetCounts = convert.ToCatalogFacets(counts)
+	}
+
 	results := make([]*api.CatalogEntry, len(dms))
 	var lastUpdated time.Time
+	// The attributes of the entries were loaded in batches by the search, so only the permissions remain per repo
+	perms := make(map[int64]access_model.Permission)
 	for i, dm := range dms {
 		if ctx.Repo != nil && ctx.Repo.Repository != nil {
 			dm.Repo = ctx.Repo.Repository
-		} else {
-			err := dm.LoadAttributes(ctx)
+		}
+		perm, ok := perms[dm.RepoID]
+		if !ok {
+			perm, err = access_model.GetUserRepoPermission(ctx, dm.Repo, ctx.ContextUser)
 			if err != nil {
-				ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+				ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
 				return
 			}
-		}
-		perm, err := access_model.GetUserRepoPermission(ctx, dm.Repo, ctx.ContextUser)
-		if err != nil {
-			ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
-			return
+			perms[dm.RepoID] = perm
 		}
 		dmAPI := convert.ToCatalogEntry(ctx, dm, perm)
 		if opts.ShowIngredients == util.OptionalBoolFalse {
@@ -1176,17 +1493,73 @@ func searchCatalog(ctx *context.APIContext) {
 		lastUpdated = time.Now()
 	}
 
-	if opts.PageSize > 0 {
-		ctx.SetLinkHeader(int(count), opts.PageSize)
+	response := &catalogSearchResponse{
+		Count:    count,
+		PageSize: opts.PageSize,
+		Results: &api.CatalogSearchResults{
+			OK:          true,
+			Data:        results,
+			LastUpdated: lastUpdated,
+			Facets:      facetCounts,
+		},
+	}
+	if cacheKey != "" {
+		putCachedCatalogSearchResponse(cacheKey, response)
+	}
+	writeCatalogSearchResponse(ctx, response)
+}
+
+// catalogSearchResponse is what is written for a catalog search, kept in the cache for anonymous requests
+type catalogSearchResponse struct {
+	Count    int64
+	PageSize int
+	Results  *api.CatalogSearchResults
+}
+
+// catalogSearchCacheKey returns the cache key of the request's search results,
+// or "" if the response shouldn't be cached as the cache is disabled or the request isn't anonymous
+func catalogSearchCacheKey(ctx *context.APIContext) string {
+	if setting.DCS.CatalogSearchCacheTTL <= 0 || cache.GetCache() == nil || ctx.Doer != nil {
+		return ""
+	}
+	return "catalog_search:" + ctx.Req.URL.RequestURI()
+}
+
+// getCachedCatalogSearchResponse returns the cached response of the key, nil if there is none
+func getCachedCatalogSearchResponse(key string) *catalogSearchResponse {
+	cached, ok := cache.GetCache().Get(key).(string)
+	if !ok {
+		return nil
+	}
+	response := &catalogSearchResponse{}
+	if err := json.Unmarshal([]byte(cached), response); err != nil {
+		log.Error("Unable to unmarshal the cached catalog search response [%s]: %v", key, err)
+		return nil
+	}
+	return response
+}
+
+// putCachedCatalogSearchResponse keeps the response in the cache for the configured time
+func putCachedCatalogSearchResponse(key string, response *catalogSearchResponse) {
+	bs, err := json.Marshal(response)
+	if err != nil {
+		log.Error("Unable to marshal the catalog search response [%s]: %v", key, err)
+		return
+	}
+	if err := cache.GetCache().Put(key, string(bs), int64(setting.DCS.CatalogSearchCacheTTL.Seconds())); err != nil {
+		log.Error("Unable to cache the catalog search response [%s]: %v", key, err)
+	}
+}
+
+// writeCatalogSearchResponse writes the results with the paging headers
+func writeCatalogSearchResponse(ctx *context.APIContext, response *catalogSearchResponse) {
+	if response.PageSize > 0 {
+		ctx.SetLinkHeader(int(response.Count), response.PageSize)
 	} else {
-		ctx.SetLinkHeader(int(count), int(count))
+		ctx.SetLinkHeader(int(response.Count), int(response.Count))
 	}
-	ctx.RespHeader().Set("X-Total-Count", fmt.Sprintf("%d", count))
-	ctx.JSON(http.StatusOK, api.CatalogSearchResults{
-		OK:          true,
-		Data:        results,
-		LastUpdated: lastUpdated,
-	})
+	ctx.RespHeader().Set("X-Total-Count", fmt.Sprintf("%d", response.Count))
+	ctx.JSON(http.StatusOK, response.Results)
 }
 
 func getSingleDMFieldList(ctx *context.APIContext, field string) ([]string, error) {
@@ -1203,6 +1576,14 @@ func getSingleDMFieldList(ctx *context.APIContext, field string) ([]string, erro
 
 	metadataTypes := QueryStrings(ctx, "metadataType")
 	metadataVersions := QueryStrings(ctx, "metadataVersion")
+	metadataFilters, err := QueryMetadataFilters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	verifiedLevels, err := door43metadata.ParseCheckingLevels(QueryStrings(ctx, "verifiedCheckingLevel"))
+	if err != nil {
+		return nil, err
+	}
 
 	listOptions := db.ListOptions{
 		ListAll: true,
@@ -1216,11 +1597,16 @@ func getSingleDMFieldList(ctx *context.APIContext, field string) ([]string, erro
 		Stage:            stage,
 		Languages:        QueryStrings(ctx, "lang"),
 		LanguageIsGL:     ctx.FormOptionalBool("is_gl"),
+		Regions:          QueryStrings(ctx, "lr"),
+		Countries:        QueryStrings(ctx, "cc"),
+		IncludeGLs:       ctx.FormBool("includeGL"),
 		Subjects:         QueryStrings(ctx, "subject"),
 		Resources:        QueryStrings(ctx, "resource"),
 		ContentFormats:   QueryStrings(ctx, "format"),
 		CheckingLevels:   QueryStrings(ctx, "checkingLevel"),
+		VerifiedLevels:   verifiedLevels,
 		Books:            QueryStrings(ctx, "book"),
+		MetadataFilters:  metadataFilters,
 		IncludeHistory:   ctx.FormBool("includeHistory"),
 		ShowIngredients:  ctx.FormOptionalBool("showIngredients"),
 		MetadataTypes:    metadataTypes,
@@ -1255,7 +1641,7 @@ func getSingleDMFieldList(ctx *context.APIContext, field string) ([]string, erro
 		opts.OrderBy = []door43metadata.CatalogOrderBy{door43metadata.CatalogOrderByLangCode, door43metadata.CatalogOrderBySubject, door43metadata.CatalogOrderByReleaseDateReverse}
 	}
 
-	results, err := models.SearchDoor43MetadataField(ctx, opts, field)
+	results, err := door43metadata_service.SearchDoor43MetadataField(ctx, opts, field)
 	if err != nil {
 		return nil, err
 	}
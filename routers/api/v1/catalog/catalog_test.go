@@ -0,0 +1,101 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/contexttest"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCatalogEntryAt(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	dm := &repo_model.Door43Metadata{RepoID: 1, ReleaseID: 1, Ref: "v1.1", RefType: "tag", Stage: door43metadata.StageProd, CreatedUnix: 1000, UpdatedUnix: 1000}
+	_, err := db.GetEngine(db.DefaultContext).NoAutoTime().Insert(dm)
+	assert.NoError(t, err)
+
+	for at, status := range map[string]int{
+		"1970-01-01T00:00:00Z": http.StatusNotFound, // before the entry existed
+		"yesterday":            http.StatusUnprocessableEntity,
+	} {
+		ctx, _ := contexttest.MockAPIContext(t, "api/v1/catalog/entry/user2/repo1/v1.1")
+		contexttest.LoadRepo(t, ctx, 1)
+		ctx.SetParams("ref", "v1.1")
+		ctx.Req.Form.Set("at", at)
+		GetCatalogEntry(ctx)
+		assert.Equal(t, status, ctx.Resp.Status(), at)
+	}
+}
+
+func TestSearchCache(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+	defer test.MockVariableValue(&setting.CacheService.Cache, setting.Cache{Enabled: true, Adapter: "memory", Interval: 60})()
+	defer test.MockVariableValue(&setting.DCS.CatalogSearchCacheTTL, time.Minute)()
+	assert.NoError(t, cache.NewContext())
+
+	dm := &repo_model.Door43Metadata{
+		RepoID: 1, ReleaseID: 1, Ref: "v1.1", RefType: "tag", CommitSHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		Stage: door43metadata.StageProd, MetadataType: "rc", Language: "en", ReleaseDateUnix: 1000, IsLatestForStage: true,
+	}
+	assert.NoError(t, db.Insert(db.DefaultContext, dm))
+
+	search := func(doerID int64) string {
+		ctx, resp := contexttest.MockAPIContext(t, "api/v1/catalog/search?stage=prod")
+		ctx.Repo = &context.Repository{}
+		if doerID > 0 {
+			contexttest.LoadUser(t, ctx, doerID)
+		}
+		Search(ctx)
+		assert.Equal(t, http.StatusOK, ctx.Resp.Status())
+		return resp.Header().Get("X-Total-Count")
+	}
+	assert.Equal(t, "1", search(0))
+
+	_, err := db.DeleteByID(db.DefaultContext, dm.ID, &repo_model.Door43Metadata{})
+	assert.NoError(t, err)
+	// anonymous requests get the cached response until it expires
+	assert.Equal(t, "1", search(0))
+	// the others are always searched
+	assert.Equal(t, "0", search(2))
+
+	setting.DCS.CatalogSearchCacheTTL = 0
+	assert.Equal(t, "0", search(0))
+}
+
+func TestSearchVerifiedCheckingLevel(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	dm := &repo_model.Door43Metadata{
+		RepoID: 1, ReleaseID: 1, Ref: "v1.1", RefType: "tag", CommitSHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		Stage: door43metadata.StageProd, MetadataType: "rc", Language: "en", ReleaseDateUnix: 1000, IsLatestForStage: true,
+		VerifiedCheckingLevel: 2,
+	}
+	assert.NoError(t, db.Insert(db.DefaultContext, dm))
+
+	for level, expected := range map[string]string{"1": "1", "2": "1", "3": "0", "3,2": "1", "abc": ""} {
+		ctx, resp := contexttest.MockAPIContext(t, "api/v1/catalog/search")
+		ctx.Repo = &context.Repository{}
+		ctx.Req.Form.Set("verifiedCheckingLevel", level)
+		Search(ctx)
+		if expected == "" {
+			assert.Equal(t, http.StatusUnprocessableEntity, ctx.Resp.Status(), level)
+			continue
+		}
+		assert.Equal(t, http.StatusOK, ctx.Resp.Status(), level)
+		assert.Equal(t, expected, resp.Header().Get("X-Total-Count"), level)
+	}
+}
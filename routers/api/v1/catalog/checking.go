@@ -0,0 +1,305 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/convert"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
+)
+
+// ListCatalogEntryChecks list the checks signed off for the commit of a catalog entry
+func ListCatalogEntryChecks(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/entry/{owner}/{repo}/{ref}/checks catalog catalogListEntryChecks
+	// ---
+	// summary: List the checks signed off by designated checkers for the commit of a catalog entry
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: path
+	//   description: release tag or default branch
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogCheckList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	dm := getCatalogEntryDM(ctx)
+	if ctx.Written() {
+		return
+	}
+	checks, err := repo.GetDoor43ChecksByRepoIDAndCommitSHA(ctx, dm.RepoID, dm.CommitSHA)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDoor43ChecksByRepoIDAndCommitSHA", err)
+		return
+	}
+	apiChecks := make([]*api.CatalogCheck, len(checks))
+	for i, check := range checks {
+		apiChecks[i] = convert.ToCatalogCheck(ctx, check, ctx.Doer)
+	}
+	ctx.JSON(http.StatusOK, apiChecks)
+}
+
+// CreateCatalogEntryCheck signs off the commit of a catalog entry at a checking level
+func CreateCatalogEntryCheck(ctx *context.APIContext) {
+	// swagger:operation POST /catalog/entry/{owner}/{repo}/{ref}/checks catalog catalogCreateEntryCheck
+	// ---
+	// summary: Sign off the commit of a catalog entry at a checking level. The user must be a designated checker of the owner
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: path
+	//   description: release tag or default branch
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateCatalogCheckOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CatalogCheck"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateCatalogCheckOption)
+	dm := getCatalogEntryDM(ctx)
+	if ctx.Written() {
+		return
+	}
+	check, err := door43metadata_service.CheckDoor43Metadata(ctx, ctx.Doer, dm, form.Level, form.Note)
+	if err != nil {
+		switch {
+		case repo.IsErrDoor43CheckerNotExist(err), repo.IsErrCheckingLevelNotAllowed(err):
+			ctx.Error(http.StatusForbidden, "CheckDoor43Metadata", err)
+		case repo.IsErrInvalidCheckingLevel(err):
+			ctx.Error(http.StatusUnprocessableEntity, "CheckDoor43Metadata", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "CheckDoor43Metadata", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToCatalogCheck(ctx, check, ctx.Doer))
+}
+
+// ListCatalogCheckers list the checkers designated by an owner
+func ListCatalogCheckers(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/checkers/{owner} catalog catalogListCheckers
+	// ---
+	// summary: List the users designated by an owner to verify its catalog entries
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogCheckerList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	checkers, err := repo.GetDoor43CheckersByOwnerID(ctx, ctx.ContextUser.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDoor43CheckersByOwnerID", err)
+		return
+	}
+	apiCheckers := make([]*api.CatalogChecker, len(checkers))
+	for i, checker := range checkers {
+		apiCheckers[i] = convert.ToCatalogChecker(ctx, checker, ctx.Doer)
+	}
+	ctx.JSON(http.StatusOK, apiCheckers)
+}
+
+// SetCatalogChecker designates a user as a checker of an owner's catalog entries
+func SetCatalogChecker(ctx *context.APIContext) {
+	// swagger:operation PUT /catalog/checkers/{owner}/{checker} catalog catalogSetChecker
+	// ---
+	// summary: Designate a user to verify an owner's catalog entries up to a checking level
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// - name: checker
+	//   in: path
+	//   description: username of the checker
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditCatalogCheckerOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogChecker"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditCatalogCheckerOption)
+	if !canManageCatalogOwner(ctx) {
+		return
+	}
+	checker := getCatalogCheckerUser(ctx)
+	if ctx.Written() {
+		return
+	}
+	c, err := door43metadata_service.SetDoor43Checker(ctx, ctx.ContextUser, checker, form.MaxLevel)
+	if err != nil {
+		if repo.IsErrInvalidCheckingLevel(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "SetDoor43Checker", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SetDoor43Checker", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToCatalogChecker(ctx, c, ctx.Doer))
+}
+
+// DeleteCatalogChecker removes a user as a checker of an owner's catalog entries
+func DeleteCatalogChecker(ctx *context.APIContext) {
+	// swagger:operation DELETE /catalog/checkers/{owner}/{checker} catalog catalogDeleteChecker
+	// ---
+	// summary: Remove a user as a checker of an owner's catalog entries
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// - name: checker
+	//   in: path
+	//   description: username of the checker
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if !canManageCatalogOwner(ctx) {
+		return
+	}
+	checker := getCatalogCheckerUser(ctx)
+	if ctx.Written() {
+		return
+	}
+	if _, err := repo.GetDoor43Checker(ctx, ctx.ContextUser.ID, checker.ID); err != nil {
+		if repo.IsErrDoor43CheckerNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDoor43Checker", err)
+		}
+		return
+	}
+	if err := door43metadata_service.RemoveDoor43Checker(ctx, ctx.ContextUser, checker); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RemoveDoor43Checker", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// getCatalogEntryDM gets the door43 metadata of the catalog entry of the repo and ref in the path
+func getCatalogEntryDM(ctx *context.APIContext) *repo.Door43Metadata {
+	dm, err := repo.GetDoor43MetadataByRepoIDAndRef(ctx, ctx.Repo.Repository.ID, ctx.Params("ref"))
+	if err != nil {
+		if repo.IsErrDoor43MetadataNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDoor43MetadataByRepoIDAndRef", err)
+		}
+		return nil
+	}
+	dm.Repo = ctx.Repo.Repository
+	return dm
+}
+
+// canManageCatalogOwner returns true if the doer is the owner, an owner of the org or a site admin
+func canManageCatalogOwner(ctx *context.APIContext) bool {
+	if ctx.Doer.IsAdmin || ctx.Doer.ID == ctx.ContextUser.ID {
+		return true
+	}
+	if ctx.ContextUser.IsOrganization() {
+		isOwner, err := organization.OrgFromUser(ctx.ContextUser).IsOwnedBy(ctx, ctx.Doer.ID)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "IsOwnedBy", err)
+			return false
+		}
+		if isOwner {
+			return true
+		}
+	}
+	ctx.Error(http.StatusForbidden, "", "must be the owner, an owner of the organization or a site admin")
+	return false
+}
+
+func getCatalogCheckerUser(ctx *context.APIContext) *user_model.User {
+	checker, err := user_model.GetUserByName(ctx, ctx.Params("checker"))
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+		}
+		return nil
+	}
+	if checker.IsOrganization() {
+		ctx.Error(http.StatusUnprocessableEntity, "", "an organization cannot be a checker")
+		return nil
+	}
+	return checker
+}
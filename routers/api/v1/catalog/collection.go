@@ -0,0 +1,547 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/convert"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
+)
+
+// ListCatalogCollections lists the collections of an owner
+func ListCatalogCollections(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/collections/{owner} catalog catalogListCollections
+	// ---
+	// summary: List the collections of an owner
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogCollectionList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if !user_model.IsUserVisibleToViewer(ctx, ctx.ContextUser, ctx.Doer) {
+		ctx.NotFound()
+		return
+	}
+	collections, err := repo.GetDoor43CollectionsByOwnerID(ctx, ctx.ContextUser.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDoor43CollectionsByOwnerID", err)
+		return
+	}
+	apiCollections := make([]*api.CatalogCollection, 0, len(collections))
+	for _, collection := range collections {
+		collection.Owner = ctx.ContextUser
+		latest := getLatestCollectionVersion(ctx, collection)
+		if ctx.Written() {
+			return
+		}
+		apiCollections = append(apiCollections, convert.ToCatalogCollection(ctx, collection, latest))
+	}
+	ctx.JSON(http.StatusOK, apiCollections)
+}
+
+// CreateCatalogCollection creates a collection of an owner
+func CreateCatalogCollection(ctx *context.APIContext) {
+	// swagger:operation POST /catalog/collections/{owner} catalog catalogCreateCollection
+	// ---
+	// summary: Create a collection of an owner's catalog entries or of others' entries
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateCatalogCollectionOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CatalogCollection"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateCatalogCollectionOption)
+	if !canManageCatalogOwner(ctx) {
+		return
+	}
+	collection := &repo.Door43Collection{
+		OwnerID:     ctx.ContextUser.ID,
+		Owner:       ctx.ContextUser,
+		Name:        form.Name,
+		Title:       form.Title,
+		Description: form.Description,
+	}
+	if collection.Title == "" {
+		collection.Title = form.Name
+	}
+	if err := repo.InsertDoor43Collection(ctx, collection); err != nil {
+		if repo.IsErrDoor43CollectionAlreadyExist(err) {
+			ctx.Error(http.StatusConflict, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "InsertDoor43Collection", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToCatalogCollection(ctx, collection, nil))
+}
+
+// GetCatalogCollection gets a collection of an owner
+func GetCatalogCollection(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/collections/{owner}/{collection} catalog catalogGetCollection
+	// ---
+	// summary: Get a collection
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// - name: collection
+	//   in: path
+	//   description: name of the collection
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogCollection"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	collection := getCatalogCollection(ctx)
+	if ctx.Written() {
+		return
+	}
+	latest := getLatestCollectionVersion(ctx, collection)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToCatalogCollection(ctx, collection, latest))
+}
+
+// EditCatalogCollection edits the title and description of a collection
+func EditCatalogCollection(ctx *context.APIContext) {
+	// swagger:operation PATCH /catalog/collections/{owner}/{collection} catalog catalogEditCollection
+	// ---
+	// summary: Edit the title and description of a collection
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// - name: collection
+	//   in: path
+	//   description: name of the collection
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditCatalogCollectionOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogCollection"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditCatalogCollectionOption)
+	collection := getCatalogCollection(ctx)
+	if ctx.Written() || !canManageCatalogOwner(ctx) {
+		return
+	}
+	if form.Title != nil && *form.Title != "" {
+		collection.Title = *form.Title
+	}
+	if form.Description != nil {
+		collection.Description = *form.Description
+	}
+	if err := repo.UpdateDoor43CollectionCols(ctx, collection, "title", "description"); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateDoor43CollectionCols", err)
+		return
+	}
+	latest := getLatestCollectionVersion(ctx, collection)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToCatalogCollection(ctx, collection, latest))
+}
+
+// DeleteCatalogCollection deletes a collection with all its versions
+func DeleteCatalogCollection(ctx *context.APIContext) {
+	// swagger:operation DELETE /catalog/collections/{owner}/{collection} catalog catalogDeleteCollection
+	// ---
+	// summary: Delete a collection with all its versions
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// - name: collection
+	//   in: path
+	//   description: name of the collection
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	collection := getCatalogCollection(ctx)
+	if ctx.Written() || !canManageCatalogOwner(ctx) {
+		return
+	}
+	if err := door43metadata_service.DeleteCollection(ctx, collection); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteCollection", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListCatalogCollectionVersions lists the versions of a collection
+func ListCatalogCollectionVersions(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/collections/{owner}/{collection}/versions catalog catalogListCollectionVersions
+	// ---
+	// summary: List the versions of a collection, most recent first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// - name: collection
+	//   in: path
+	//   description: name of the collection
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogCollectionVersionList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	collection := getCatalogCollection(ctx)
+	if ctx.Written() {
+		return
+	}
+	versions, err := repo.GetDoor43CollectionVersions(ctx, collection.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDoor43CollectionVersions", err)
+		return
+	}
+	apiVersions := make([]*api.CatalogCollectionVersion, 0, len(versions))
+	for _, v := range versions {
+		members, err := door43metadata_service.GetCollectionVersionMembers(ctx, ctx.Doer, v)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetCollectionVersionMembers", err)
+			return
+		}
+		apiVersions = append(apiVersions, convert.ToCatalogCollectionVersion(ctx, collection, v, members, ctx.Doer))
+	}
+	ctx.JSON(http.StatusOK, apiVersions)
+}
+
+// CreateCatalogCollectionVersion releases a version of a collection
+func CreateCatalogCollectionVersion(ctx *context.APIContext) {
+	// swagger:operation POST /catalog/collections/{owner}/{collection}/versions catalog catalogCreateCollectionVersion
+	// ---
+	// summary: Release a version of a collection, pinning each member to a release tag of a catalog entry
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// - name: collection
+	//   in: path
+	//   description: name of the collection
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateCatalogCollectionVersionOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CatalogCollectionVersion"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateCatalogCollectionVersionOption)
+	collection := getCatalogCollection(ctx)
+	if ctx.Written() || !canManageCatalogOwner(ctx) {
+		return
+	}
+	v, err := door43metadata_service.CreateCollectionVersion(ctx, ctx.Doer, collection, form.Version, form.Note, form.Members)
+	if err != nil {
+		switch {
+		case repo.IsErrDoor43CollectionVersionAlreadyExist(err):
+			ctx.Error(http.StatusConflict, "", err)
+		case repo.IsErrDoor43CollectionVersionInvalid(err), repo.IsErrDoor43CollectionMemberInvalid(err):
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "CreateCollectionVersion", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToCatalogCollectionVersion(ctx, collection, v, v.Members, ctx.Doer))
+}
+
+// GetCatalogCollectionVersion gets a version of a collection with its members
+func GetCatalogCollectionVersion(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/collections/{owner}/{collection}/versions/{version} catalog catalogGetCollectionVersion
+	// ---
+	// summary: Get a version of a collection with the catalog entries of its members
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// - name: collection
+	//   in: path
+	//   description: name of the collection
+	//   type: string
+	//   required: true
+	// - name: version
+	//   in: path
+	//   description: version of the collection, or "latest" for the most recent one
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogCollectionVersion"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	collection, v := getCatalogCollectionVersion(ctx)
+	if ctx.Written() {
+		return
+	}
+	members, err := door43metadata_service.GetCollectionVersionMembers(ctx, ctx.Doer, v)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCollectionVersionMembers", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToCatalogCollectionVersion(ctx, collection, v, members, ctx.Doer))
+}
+
+// DeleteCatalogCollectionVersion deletes a version of a collection
+func DeleteCatalogCollectionVersion(ctx *context.APIContext) {
+	// swagger:operation DELETE /catalog/collections/{owner}/{collection}/versions/{version} catalog catalogDeleteCollectionVersion
+	// ---
+	// summary: Delete a version of a collection
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// - name: collection
+	//   in: path
+	//   description: name of the collection
+	//   type: string
+	//   required: true
+	// - name: version
+	//   in: path
+	//   description: version of the collection
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	_, v := getCatalogCollectionVersion(ctx)
+	if ctx.Written() || !canManageCatalogOwner(ctx) {
+		return
+	}
+	if err := door43metadata_service.DeleteCollectionVersion(ctx, v); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteCollectionVersion", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// DownloadCatalogCollectionVersion downloads the contents of all the members of a collection version as one zip archive
+func DownloadCatalogCollectionVersion(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/collections/{owner}/{collection}/versions/{version}/download catalog catalogDownloadCollectionVersion
+	// ---
+	// summary: Download the contents of all the members of a collection version as one zip archive
+	// description: Each member's files are in a directory named after its repo's full name, along with a
+	//   collection.json manifest of the version. Members the user cannot read are left out.
+	// produces:
+	// - application/zip
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// - name: collection
+	//   in: path
+	//   description: name of the collection
+	//   type: string
+	//   required: true
+	// - name: version
+	//   in: path
+	//   description: version of the collection, or "latest" for the most recent one
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     description: success
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	collection, v := getCatalogCollectionVersion(ctx)
+	if ctx.Written() {
+		return
+	}
+	members, err := door43metadata_service.GetCollectionVersionMembers(ctx, ctx.Doer, v)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCollectionVersionMembers", err)
+		return
+	}
+	rPath, err := door43metadata_service.PrepareCollectionArchive(ctx, collection, v, members)
+	if err != nil {
+		if door43metadata_service.IsErrCollectionArchiveTooLarge(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "PrepareCollectionArchive", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "PrepareCollectionArchive", err)
+		}
+		return
+	}
+
+	downloadName := door43metadata_service.GetCollectionArchiveName(collection, v)
+	if setting.RepoArchive.Storage.MinioConfig.ServeDirect {
+		// If we have a signed url (S3, object storage), redirect to this directly.
+		u, err := storage.RepoArchives.URL(rPath, downloadName)
+		if u != nil && err == nil {
+			ctx.Redirect(u.String())
+			return
+		}
+	}
+	fr, err := storage.RepoArchives.Open(rPath)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Open", err)
+		return
+	}
+	defer fr.Close()
+	ctx.ServeContent(fr, &context.ServeHeaderOptions{
+		Filename:     downloadName,
+		LastModified: v.CreatedUnix.AsLocalTime(),
+	})
+}
+
+// getCatalogCollection gets the collection of the owner and name in the path, if the owner is visible to the doer
+func getCatalogCollection(ctx *context.APIContext) *repo.Door43Collection {
+	if !user_model.IsUserVisibleToViewer(ctx, ctx.ContextUser, ctx.Doer) {
+		ctx.NotFound()
+		return nil
+	}
+	collection, err := repo.GetDoor43CollectionByOwnerAndName(ctx, ctx.ContextUser.ID, ctx.Params("collection"))
+	if err != nil {
+		if repo.IsErrDoor43CollectionNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDoor43CollectionByOwnerAndName", err)
+		}
+		return nil
+	}
+	collection.Owner = ctx.ContextUser
+	return collection
+}
+
+// getCatalogCollectionVersion gets the collection and its version in the path
+func getCatalogCollectionVersion(ctx *context.APIContext) (*repo.Door43Collection, *repo.Door43CollectionVersion) {
+	collection := getCatalogCollection(ctx)
+	if ctx.Written() {
+		return nil, nil
+	}
+	v, err := repo.GetDoor43CollectionVersion(ctx, collection.ID, ctx.Params("version"))
+	if err != nil {
+		if repo.IsErrDoor43CollectionVersionNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDoor43CollectionVersion", err)
+		}
+		return nil, nil
+	}
+	v.Collection = collection
+	return collection, v
+}
+
+// getLatestCollectionVersion gets the most recent version of a collection, nil if none has been released
+func getLatestCollectionVersion(ctx *context.APIContext, collection *repo.Door43Collection) *repo.Door43CollectionVersion {
+	v, err := repo.GetDoor43CollectionVersion(ctx, collection.ID, repo.Door43CollectionLatestVersion)
+	if err != nil {
+		if !repo.IsErrDoor43CollectionVersionNotExist(err) {
+			ctx.Error(http.StatusInternalServerError, "GetDoor43CollectionVersion", err)
+		}
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,244 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// defaultDownloadStatsDays is the number of days the download counts are summed up for if no since date is given
+const defaultDownloadStatsDays = 30
+
+// ListCatalogDownloads sums up the download counts of catalog entries
+func ListCatalogDownloads(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/downloads catalog catalogListDownloads
+	// ---
+	// summary: Sum up the daily download counts of catalog entries
+	// description: Archive (zipball and tarball), release attachment and ingredient file (raw and media) downloads
+	//   are counted daily (UTC) for each catalog entry. The counts are summed up by the given group_by fields.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: query
+	//   description: owner of the entries to count the downloads of
+	//   type: string
+	// - name: repo
+	//   in: query
+	//   description: name of the repo of the entries to count the downloads of. Requires owner
+	//   type: string
+	// - name: lang
+	//   in: query
+	//   description: language codes of the entries to count the downloads of. Multiple values are ORed
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: subject
+	//   in: query
+	//   description: subjects of the entries to count the downloads of. Multiple values are ORed
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: kind
+	//   in: query
+	//   description: kinds of downloads to count. Multiple values are ORed
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//     enum: [archive, attachment, ingredient]
+	// - name: since
+	//   in: query
+	//   description: first day to count (YYYY-MM-DD, UTC). Defaults to 30 days before until
+	//   type: string
+	//   format: date
+	// - name: until
+	//   in: query
+	//   description: last day to count (YYYY-MM-DD, UTC). Defaults to today
+	//   type: string
+	//   format: date
+	// - name: group_by
+	//   in: query
+	//   description: fields to sum up the counts by. The total is returned if not given
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//     enum: [day, kind, lang, subject, repo, entry]
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogDownloadStats"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts := &repo.Door43DownloadStatsOptions{PublicOnly: true}
+	if ownerName := ctx.FormTrim("owner"); ownerName != "" {
+		owner, err := user_model.GetUserByName(ctx, ownerName)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				ctx.NotFound()
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
+		}
+		opts.OwnerID = owner.ID
+		if repoName := ctx.FormTrim("repo"); repoName != "" {
+			r, err := repo.GetRepositoryByName(ctx, owner.ID, repoName)
+			if err != nil {
+				if repo.IsErrRepoNotExist(err) {
+					ctx.NotFound()
+				} else {
+					ctx.Error(http.StatusInternalServerError, "GetRepositoryByName", err)
+				}
+				return
+			}
+			opts.RepoID = r.ID
+		}
+	} else if ctx.FormTrim("repo") != "" {
+		ctx.Error(http.StatusUnprocessableEntity, "", "repo requires owner")
+		return
+	}
+
+	listCatalogDownloads(ctx, opts)
+}
+
+// ListCatalogEntryDownloads sums up the download counts of a catalog entry
+func ListCatalogEntryDownloads(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/entry/{owner}/{repo}/{ref}/downloads catalog catalogListEntryDownloads
+	// ---
+	// summary: Sum up the daily download counts of a catalog entry
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: path
+	//   description: release tag or branch
+	//   type: string
+	//   required: true
+	// - name: kind
+	//   in: query
+	//   description: kinds of downloads to count. Multiple values are ORed
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//     enum: [archive, attachment, ingredient]
+	// - name: since
+	//   in: query
+	//   description: first day to count (YYYY-MM-DD, UTC). Defaults to 30 days before until
+	//   type: string
+	//   format: date
+	// - name: until
+	//   in: query
+	//   description: last day to count (YYYY-MM-DD, UTC). Defaults to today
+	//   type: string
+	//   format: date
+	// - name: group_by
+	//   in: query
+	//   description: fields to sum up the counts by. The total is returned if not given
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//     enum: [day, kind]
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogDownloadStats"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	dm, err := repo.GetDoor43MetadataByRepoIDAndRef(ctx, ctx.Repo.Repository.ID, ctx.Params("ref"))
+	if err != nil {
+		if repo.IsErrDoor43MetadataNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDoor43MetadataByRepoIDAndRef", err)
+		}
+		return
+	}
+	listCatalogDownloads(ctx, &repo.Door43DownloadStatsOptions{
+		RepoID:            dm.RepoID,
+		Door43MetadataIDs: []int64{dm.ID},
+	})
+}
+
+// listCatalogDownloads completes the options with the query parameters and writes the sums of the download counts
+func listCatalogDownloads(ctx *context.APIContext, opts *repo.Door43DownloadStatsOptions) {
+	since, until, err := parseDownloadStatsDays(ctx.FormTrim("since"), ctx.FormTrim("until"))
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+	opts.Since = repo.Door43DownloadDay(since)
+	opts.Before = repo.Door43DownloadDay(until.AddDate(0, 0, 1))
+
+	if opts.Door43MetadataIDs == nil {
+		opts.Languages = QueryStrings(ctx, "lang")
+		opts.Subjects = QueryStrings(ctx, "subject")
+	}
+	for _, kind := range QueryStrings(ctx, "kind") {
+		k := repo.Door43DownloadKind(kind)
+		if !k.IsValid() {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("invalid kind %q [valid: archive, attachment, ingredient]", kind))
+			return
+		}
+		opts.Kinds = append(opts.Kinds, k)
+	}
+	if opts.GroupBy, err = repo.ParseDoor43DownloadGroupBy(ctx.FormStrings("group_by")); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	counts, err := repo.GetDoor43DownloadCounts(ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDoor43DownloadCounts", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToCatalogDownloadStats(ctx, since, until, counts, opts.GroupBy))
+}
+
+// parseDownloadStatsDays parses the YYYY-MM-DD days to count the downloads from and until, defaulting to the last
+// defaultDownloadStatsDays days
+func parseDownloadStatsDays(sinceStr, untilStr string) (since, until time.Time, err error) {
+	until = time.Now().UTC()
+	if untilStr != "" {
+		if until, err = time.Parse("2006-01-02", untilStr); err != nil {
+			return since, until, fmt.Errorf("invalid until date %q, expected YYYY-MM-DD", untilStr)
+		}
+	}
+	since = until.AddDate(0, 0, -defaultDownloadStatsDays)
+	if sinceStr != "" {
+		if since, err = time.Parse("2006-01-02", sinceStr); err != nil {
+			return since, until, fmt.Errorf("invalid since date %q, expected YYYY-MM-DD", sinceStr)
+		}
+	}
+	if since.After(until) {
+		return since, until, fmt.Errorf("since (%s) is after until (%s)", sinceStr, untilStr)
+	}
+	return since, until, nil
+}
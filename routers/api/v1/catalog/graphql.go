@@ -0,0 +1,109 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+
+	"github.com/graphql-go/graphql"
+)
+
+// GraphQLQuery executes a GraphQL query of the catalog given in the URL
+func GraphQLQuery(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/graphql catalog catalogGraphQLQuery
+	// ---
+	// summary: Query the catalog, its repositories, releases, languages and owners with GraphQL
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: query
+	//   in: query
+	//   description: the GraphQL query
+	//   type: string
+	//   required: true
+	// - name: operationName
+	//   in: query
+	//   description: name of the operation of the query to execute
+	//   type: string
+	// - name: variables
+	//   in: query
+	//   description: JSON object of the values of the query's variables
+	//   type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogGraphQLResponse"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	req := &api.CatalogGraphQLRequest{
+		Query:         ctx.FormString("query"),
+		OperationName: ctx.FormString("operationName"),
+	}
+	if req.Query == "" {
+		ctx.Error(http.StatusUnprocessableEntity, "", "query is required")
+		return
+	}
+	if variables := ctx.FormString("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "variables", err)
+			return
+		}
+	}
+	executeGraphQL(ctx, req)
+}
+
+// GraphQL executes a GraphQL query of the catalog given in the request body
+func GraphQL(ctx *context.APIContext) {
+	// swagger:operation POST /catalog/graphql catalog catalogGraphQL
+	// ---
+	// summary: Query the catalog, its repositories, releases, languages and owners with GraphQL
+	// description: The schema is read-only. Catalog searches take the same filters as the catalog search endpoint
+	//   and only return entries of public repositories. Repositories and owners are only given if visible to the user.
+	//   Queries nested more than 10 levels deep or resolving more than 5000 fields, counting the fields of each result
+	//   of a page, are rejected.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CatalogGraphQLRequest"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogGraphQLResponse"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	executeGraphQL(ctx, web.GetForm(ctx).(*api.CatalogGraphQLRequest))
+}
+
+func executeGraphQL(ctx *context.APIContext, req *api.CatalogGraphQLRequest) {
+	if err := checkGraphQLLimits(req.Query, req.OperationName, req.Variables); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	schema, err := getGraphQLSchema()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "getGraphQLSchema", err)
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		RootObject:     map[string]any{graphqlDoerKey: ctx.Doer},
+		Context:        ctx,
+	})
+	ctx.JSON(http.StatusOK, toCatalogGraphQLResponse(result))
+}
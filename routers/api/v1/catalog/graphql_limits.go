@@ -0,0 +1,146 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"fmt"
+	"strconv"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+const (
+	// graphqlMaxQueryLength is the maximum length in bytes of a GraphQL query
+	graphqlMaxQueryLength = 10000
+	// graphqlMaxDepth is the maximum nesting of the selections of a GraphQL query
+	graphqlMaxDepth = 10
+	// graphqlMaxComplexity is the maximum estimated number of fields a GraphQL query resolves
+	graphqlMaxComplexity = 5000
+	// graphqlMaxListArgValues is the maximum number of values of a list argument of a GraphQL search
+	graphqlMaxListArgValues = 50
+)
+
+// graphqlListFields are the fields returning a page of results, whose selections are resolved for each result
+var graphqlListFields = map[string]bool{
+	"catalog":        true,
+	"catalogEntries": true,
+	"languages":      true,
+}
+
+// graphqlLimitsChecker computes the depth and complexity of the selections of a GraphQL query
+type graphqlLimitsChecker struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+	visiting  map[string]bool
+}
+
+// checkGraphQLLimits returns an error if the query is too long, too deeply nested or too complex. The complexity
+// is the number of fields the query resolves, where the selections of fields returning a page of results
+// count once per result of the page. Queries that can't be parsed are left to the executor to report.
+func checkGraphQLLimits(query, operationName string, variables map[string]any) error {
+	if len(query) > graphqlMaxQueryLength {
+		return fmt.Errorf("query is longer than %d bytes", graphqlMaxQueryLength)
+	}
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil
+	}
+
+	c := &graphqlLimitsChecker{
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: variables,
+		visiting:  map[string]bool{},
+	}
+	var operations []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			c.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operations = append(operations, def)
+			}
+		}
+	}
+	for _, op := range operations {
+		depth, complexity := c.selectionSet(op.SelectionSet)
+		if depth > graphqlMaxDepth {
+			return fmt.Errorf("query depth %d exceeds the maximum of %d", depth, graphqlMaxDepth)
+		}
+		if complexity > graphqlMaxComplexity {
+			return fmt.Errorf("query complexity %d exceeds the maximum of %d", complexity, graphqlMaxComplexity)
+		}
+	}
+	return nil
+}
+
+// selectionSet returns the depth and complexity of a selection set, following fragments
+func (c *graphqlLimitsChecker) selectionSet(set *ast.SelectionSet) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, selection := range set.Selections {
+		var d, n int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			d, n = c.field(selection)
+		case *ast.InlineFragment:
+			d, n = c.selectionSet(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := c.fragments[name]
+			if !ok || c.visiting[name] {
+				// unknown and cyclic fragments are reported by the validation of the query
+				continue
+			}
+			c.visiting[name] = true
+			d, n = c.selectionSet(fragment.SelectionSet)
+			delete(c.visiting, name)
+		}
+		depth = max(depth, d)
+		complexity += n
+		if complexity > graphqlMaxComplexity {
+			// no need to go further, and avoids overflowing with nested lists
+			return depth, complexity
+		}
+	}
+	return depth, complexity
+}
+
+// field returns the depth and complexity of a field and its selections
+func (c *graphqlLimitsChecker) field(field *ast.Field) (depth, complexity int) {
+	depth, complexity = c.selectionSet(field.SelectionSet)
+	if graphqlListFields[field.Name.Value] {
+		complexity *= c.pageSize(field)
+	}
+	return depth + 1, complexity + 1
+}
+
+// pageSize returns the number of results of a field returning a page of results, as given by its limit argument
+func (c *graphqlLimitsChecker) pageSize(field *ast.Field) int {
+	limit := setting.API.DefaultPagingNum
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			limit, _ = strconv.Atoi(value.Value)
+		case *ast.Variable:
+			switch v := c.variables[value.Name.Value].(type) {
+			case float64:
+				limit = int(v)
+			case int:
+				limit = v
+			}
+		}
+	}
+	if limit <= 0 {
+		limit = setting.API.DefaultPagingNum
+	}
+	return min(limit, setting.API.MaxResponseItems)
+}
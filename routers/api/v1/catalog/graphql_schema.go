@@ -0,0 +1,591 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/dcs"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
+
+	"github.com/graphql-go/graphql"
+)
+
+var (
+	graphqlSchema     graphql.Schema
+	graphqlSchemaErr  error
+	graphqlSchemaOnce sync.Once
+)
+
+// graphqlDoerKey is the key of the user making a GraphQL query in the root value of the query
+const graphqlDoerKey = "doer"
+
+// graphqlLanguage is a language of the catalog
+type graphqlLanguage struct {
+	Code  string
+	Count int64 // number of catalog entries in the language, only set when listing languages
+}
+
+// graphqlSearchResult is a page of catalog entries matching a search
+type graphqlSearchResult struct {
+	TotalCount int64
+	Entries    []*repo_model.Door43Metadata
+}
+
+// getGraphQLSchema returns the read-only GraphQL schema of the catalog
+func getGraphQLSchema() (graphql.Schema, error) {
+	graphqlSchemaOnce.Do(func() {
+		graphqlSchema, graphqlSchemaErr = newGraphQLSchema()
+	})
+	return graphqlSchema, graphqlSchemaErr
+}
+
+func graphqlDoer(p graphql.ResolveParams) *user_model.User {
+	if root, ok := p.Info.RootValue.(map[string]any); ok {
+		doer, _ := root[graphqlDoerKey].(*user_model.User)
+		return doer
+	}
+	return nil
+}
+
+// graphqlSearchArgs are the arguments of the fields searching the catalog, the same filters as the REST search
+func graphqlSearchArgs() graphql.FieldConfigArgument {
+	stringList := graphql.NewList(graphql.NewNonNull(graphql.String))
+	return graphql.FieldConfigArgument{
+		"query":                  {Type: graphql.String, Description: "keywords to search for, separated by commas"},
+		"owners":                 {Type: stringList},
+		"repos":                  {Type: stringList},
+		"tags":                   {Type: stringList},
+		"stage":                  {Type: graphql.String, Description: "prod, preprod, latest or other; defaults to prod", DefaultValue: "prod"},
+		"languages":              {Type: stringList},
+		"isGatewayLanguage":      {Type: graphql.Boolean},
+		"subjects":               {Type: stringList},
+		"resources":              {Type: stringList},
+		"formats":                {Type: stringList},
+		"checkingLevels":         {Type: stringList},
+		"verifiedCheckingLevels": {Type: stringList},
+		"books":                  {Type: stringList},
+		"metadataTypes":          {Type: stringList},
+		"metadataVersions":       {Type: stringList},
+		"includeHistory":         {Type: graphql.Boolean, DefaultValue: false},
+		"partialMatch":           {Type: graphql.Boolean, DefaultValue: false},
+		"sort":                   {Type: stringList, Description: "fields to sort by, as for the REST catalog search"},
+		"order":                  {Type: graphql.String, Description: "asc or desc", DefaultValue: "asc"},
+		"page":                   {Type: graphql.Int, DefaultValue: 1},
+		"limit":                  {Type: graphql.Int},
+	}
+}
+
+func graphqlStrings(args map[string]any, name string) []string {
+	values, _ := args[name].([]any)
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
+
+// graphqlSearchCatalogOptions converts the search arguments of a field to catalog search options
+func graphqlSearchCatalogOptions(args map[string]any) (*door43metadata.SearchCatalogOptions, error) {
+	for name, arg := range args {
+		if values, ok := arg.([]any); ok && len(values) > graphqlMaxListArgValues {
+			return nil, fmt.Errorf("%s has more than %d values", name, graphqlMaxListArgValues)
+		}
+	}
+
+	stageStr, _ := args["stage"].(string)
+	stage, ok := door43metadata.StageMap[stageStr]
+	if !ok {
+		return nil, fmt.Errorf("invalid stage [%s]", stageStr)
+	}
+	verifiedLevels, err := door43metadata.ParseCheckingLevels(graphqlStrings(args, "verifiedCheckingLevels"))
+	if err != nil {
+		return nil, err
+	}
+
+	var keywords []string
+	if query, _ := args["query"].(string); strings.TrimSpace(query) != "" {
+		keywords = door43metadata.SplitAtCommaNotInString(strings.TrimSpace(query), false)
+	}
+
+	page, _ := args["page"].(int)
+	limit, _ := args["limit"].(int)
+	listOptions := db.ListOptions{Page: page, PageSize: limit}
+	listOptions.SetDefaultValues()
+
+	opts := &door43metadata.SearchCatalogOptions{
+		ListOptions:      listOptions,
+		Keywords:         keywords,
+		Owners:           graphqlStrings(args, "owners"),
+		Repos:            graphqlStrings(args, "repos"),
+		Tags:             graphqlStrings(args, "tags"),
+		Stage:            stage,
+		Languages:        graphqlStrings(args, "languages"),
+		Subjects:         graphqlStrings(args, "subjects"),
+		Resources:        graphqlStrings(args, "resources"),
+		ContentFormats:   graphqlStrings(args, "formats"),
+		CheckingLevels:   graphqlStrings(args, "checkingLevels"),
+		VerifiedLevels:   verifiedLevels,
+		Books:            graphqlStrings(args, "books"),
+		MetadataTypes:    graphqlStrings(args, "metadataTypes"),
+		MetadataVersions: graphqlStrings(args, "metadataVersions"),
+	}
+	opts.IncludeHistory, _ = args["includeHistory"].(bool)
+	opts.PartialMatch, _ = args["partialMatch"].(bool)
+	if isGL, ok := args["isGatewayLanguage"].(bool); ok {
+		opts.LanguageIsGL = util.OptionalBoolOf(isGL)
+	}
+
+	if sortModes := graphqlStrings(args, "sort"); len(sortModes) > 0 {
+		sortOrder, _ := args["order"].(string)
+		searchModeMap, ok := searchOrderByMap[sortOrder]
+		if !ok {
+			return nil, fmt.Errorf("invalid sort order [%s]", sortOrder)
+		}
+		for _, sortMode := range sortModes {
+			orderBy, ok := searchModeMap[strings.ToLower(sortMode)]
+			if !ok {
+				return nil, fmt.Errorf("invalid sort mode: \"%s\"", sortMode)
+			}
+			opts.OrderBy = append(opts.OrderBy, orderBy)
+		}
+	} else {
+		opts.OrderBy = []door43metadata.CatalogOrderBy{door43metadata.CatalogOrderByLangCode, door43metadata.CatalogOrderBySubject, door43metadata.CatalogOrderByReleaseDateReverse}
+		if len(keywords) > 0 {
+			opts.OrderBy = append([]door43metadata.CatalogOrderBy{door43metadata.CatalogOrderByRelevance}, opts.OrderBy...)
+		}
+	}
+	return opts, nil
+}
+
+// graphqlSearchCatalog searches the catalog with the search arguments of a field, restricted by the given options
+func graphqlSearchCatalog(p graphql.ResolveParams, restrict func(opts *door43metadata.SearchCatalogOptions)) (any, error) {
+	opts, err := graphqlSearchCatalogOptions(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	if restrict != nil {
+		restrict(opts)
+	}
+	dms, count, err := door43metadata_service.SearchCatalog(p.Context, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &graphqlSearchResult{TotalCount: count, Entries: dms}, nil
+}
+
+// graphqlEntryResolver resolves a field of a catalog entry needing the entry's repository
+func graphqlEntryResolver(resolve func(dm *repo_model.Door43Metadata) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		dm := p.Source.(*repo_model.Door43Metadata)
+		if err := dm.LoadRepo(p.Context); err != nil {
+			return nil, err
+		}
+		return resolve(dm), nil
+	}
+}
+
+// graphqlReadableRepo returns the repository if the user making the query can read its code, nil otherwise
+func graphqlReadableRepo(ctx context.Context, doer *user_model.User, ownerName, repoName string) (*repo_model.Repository, error) {
+	repo, err := repo_model.GetRepositoryByOwnerAndName(ctx, ownerName, repoName)
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	perm, err := access_model.GetUserRepoPermission(ctx, repo, doer)
+	if err != nil {
+		return nil, err
+	}
+	if !perm.CanRead(unit.TypeCode) {
+		return nil, nil
+	}
+	if err := repo.LoadOwner(ctx); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func newGraphQLSchema() (graphql.Schema, error) {
+	jsonType := graphql.NewScalar(graphql.ScalarConfig{
+		Name:        "JSON",
+		Description: "an arbitrary JSON value",
+		Serialize:   func(value any) any { return value },
+	})
+
+	languageType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Language",
+		Description: "a language of the catalog",
+		Fields: graphql.Fields{
+			"code": {Type: graphql.NewNonNull(graphql.String)},
+			"title": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+				return dcs.GetLanguageTitle(p.Source.(*graphqlLanguage).Code), nil
+			}},
+			"anglicizedTitle": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+				return dcs.GetLanguageAnglicizedTitle(p.Source.(*graphqlLanguage).Code), nil
+			}},
+			"alternativeNames": {Type: graphql.NewList(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return dcs.GetLanguageAlternativeNames(p.Source.(*graphqlLanguage).Code), nil
+			}},
+			"direction": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+				return dcs.GetLanguageDirection(p.Source.(*graphqlLanguage).Code), nil
+			}},
+			"isGatewayLanguage": {Type: graphql.Boolean, Resolve: func(p graphql.ResolveParams) (any, error) {
+				return dcs.LanguageIsGL(p.Source.(*graphqlLanguage).Code), nil
+			}},
+			"count": {Type: graphql.Int, Description: "number of matching catalog entries, only given when listing languages", Resolve: func(p graphql.ResolveParams) (any, error) {
+				if lang := p.Source.(*graphqlLanguage); lang.Count > 0 {
+					return lang.Count, nil
+				}
+				return nil, nil
+			}},
+		},
+	})
+
+	ingredientType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Ingredient",
+		Description: "a project (book) of a catalog entry",
+		Fields: graphql.Fields{
+			"identifier":     {Type: graphql.String},
+			"title":          {Type: graphql.String},
+			"path":           {Type: graphql.String},
+			"sort":           {Type: graphql.Int},
+			"categories":     {Type: graphql.NewList(graphql.String)},
+			"versification":  {Type: graphql.String},
+			"alignmentCount": {Type: graphql.Int},
+			"checksum":       {Type: graphql.String},
+		},
+	})
+
+	attachmentType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Attachment",
+		Description: "a file attached to a release",
+		Fields: graphql.Fields{
+			"id":            {Type: graphql.NewNonNull(graphql.Int)},
+			"name":          {Type: graphql.String},
+			"size":          {Type: graphql.Int},
+			"downloadCount": {Type: graphql.Int},
+			"downloadUrl": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(*repo_model.Attachment).DownloadURL(), nil
+			}},
+			"created": {Type: graphql.DateTime, Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(*repo_model.Attachment).CreatedUnix.AsTime(), nil
+			}},
+		},
+	})
+
+	releaseType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Release",
+		Description: "the release of a catalog entry",
+		Fields: graphql.Fields{
+			"id":           {Type: graphql.NewNonNull(graphql.Int)},
+			"tagName":      {Type: graphql.String},
+			"name":         {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(*repo_model.Release).Title, nil }},
+			"body":         {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(*repo_model.Release).Note, nil }},
+			"url":          {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(*repo_model.Release).HTMLURL(), nil }},
+			"isDraft":      {Type: graphql.Boolean},
+			"isPrerelease": {Type: graphql.Boolean},
+			"published": {Type: graphql.DateTime, Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(*repo_model.Release).CreatedUnix.AsTime(), nil
+			}},
+			"attachments": {Type: graphql.NewList(attachmentType)},
+		},
+	})
+
+	var catalogEntryType, searchResultType *graphql.Object
+
+	ownerType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Owner",
+		Description: "a user or organization owning repositories of the catalog",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":       {Type: graphql.NewNonNull(graphql.Int)},
+				"login":    {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(*user_model.User).Name, nil }},
+				"fullName": {Type: graphql.String},
+				"description": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(*user_model.User).Description, nil
+				}},
+				"website":  {Type: graphql.String},
+				"location": {Type: graphql.String},
+				"url":      {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(*user_model.User).HTMLURL(), nil }},
+				"avatarUrl": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(*user_model.User).AvatarLink(p.Context), nil
+				}},
+				"isOrganization": {Type: graphql.Boolean, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(*user_model.User).IsOrganization(), nil
+				}},
+				"catalogEntries": {
+					Type:        graphql.NewNonNull(searchResultType),
+					Description: "the catalog entries of the owner's repositories",
+					Args:        graphqlSearchArgs(),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return graphqlSearchCatalog(p, func(opts *door43metadata.SearchCatalogOptions) {
+							opts.Owners = []string{p.Source.(*user_model.User).Name}
+						})
+					},
+				},
+			}
+		}),
+	})
+
+	repositoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Repository",
+		Description: "a repository of the catalog",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":   {Type: graphql.NewNonNull(graphql.Int)},
+				"name": {Type: graphql.String},
+				"fullName": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(*repo_model.Repository).FullName(), nil
+				}},
+				"description":   {Type: graphql.String},
+				"website":       {Type: graphql.String},
+				"defaultBranch": {Type: graphql.String},
+				"url": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(*repo_model.Repository).HTMLURL(), nil
+				}},
+				"avatarUrl": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(*repo_model.Repository).AvatarLink(p.Context), nil
+				}},
+				"stars": {Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(*repo_model.Repository).NumStars, nil
+				}},
+				"forks": {Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(*repo_model.Repository).NumForks, nil
+				}},
+				"updated": {Type: graphql.DateTime, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(*repo_model.Repository).UpdatedUnix.AsTime(), nil
+				}},
+				"owner": {Type: ownerType, Resolve: func(p graphql.ResolveParams) (any, error) {
+					repo := p.Source.(*repo_model.Repository)
+					if err := repo.LoadOwner(p.Context); err != nil {
+						return nil, err
+					}
+					return repo.Owner, nil
+				}},
+				"catalogEntries": {
+					Type:        graphql.NewNonNull(searchResultType),
+					Description: "the catalog entries of the repository",
+					Args:        graphqlSearchArgs(),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return graphqlSearchCatalog(p, func(opts *door43metadata.SearchCatalogOptions) {
+							opts.RepoID = p.Source.(*repo_model.Repository).ID
+						})
+					},
+				},
+			}
+		}),
+	})
+
+	catalogEntryType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "CatalogEntry",
+		Description: "the metadata of a repository's release or default branch",
+		Fields: graphql.Fields{
+			"id":        {Type: graphql.NewNonNull(graphql.Int)},
+			"url":       {Type: graphql.String, Description: "URL of the entry in the REST API", Resolve: graphqlEntryResolver(func(dm *repo_model.Door43Metadata) any { return dm.APIURL() })},
+			"ref":       {Type: graphql.String, Description: "release tag or branch name"},
+			"refType":   {Type: graphql.String},
+			"commitSha": {Type: graphql.String},
+			"stage": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(*repo_model.Door43Metadata).Stage.String(), nil
+			}},
+			"title":    {Type: graphql.String},
+			"subject":  {Type: graphql.String},
+			"resource": {Type: graphql.String},
+			"language": {Type: languageType, Resolve: func(p graphql.ResolveParams) (any, error) {
+				return &graphqlLanguage{Code: p.Source.(*repo_model.Door43Metadata).Language}, nil
+			}},
+			"metadataType":          {Type: graphql.String},
+			"metadataVersion":       {Type: graphql.String},
+			"metadataUrl":           {Type: graphql.String, Resolve: graphqlEntryResolver(func(dm *repo_model.Door43Metadata) any { return dm.GetMetadataURL() })},
+			"metadata":              {Type: jsonType, Description: "the metadata file (manifest.yaml or metadata.json) of the entry"},
+			"contentFormat":         {Type: graphql.String},
+			"checkingLevel":         {Type: graphql.Int},
+			"verifiedCheckingLevel": {Type: graphql.Int},
+			"released": {Type: graphql.DateTime, Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(*repo_model.Door43Metadata).ReleaseDateUnix.AsTime(), nil
+			}},
+			"zipballUrl":  {Type: graphql.String, Resolve: graphqlEntryResolver(func(dm *repo_model.Door43Metadata) any { return dm.GetZipballURL() })},
+			"tarballUrl":  {Type: graphql.String, Resolve: graphqlEntryResolver(func(dm *repo_model.Door43Metadata) any { return dm.GetTarballURL() })},
+			"gitTreesUrl": {Type: graphql.String, Resolve: graphqlEntryResolver(func(dm *repo_model.Door43Metadata) any { return dm.GetGitTreesURL() })},
+			"contentsUrl": {Type: graphql.String, Resolve: graphqlEntryResolver(func(dm *repo_model.Door43Metadata) any { return dm.GetContentsURL() })},
+			"books": {Type: graphql.NewList(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(*repo_model.Door43Metadata).GetIngredientsIdentifierList(), nil
+			}},
+			"ingredients": {Type: graphql.NewList(ingredientType), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(*repo_model.Door43Metadata).Ingredients, nil
+			}},
+			"repository": {Type: repositoryType, Resolve: func(p graphql.ResolveParams) (any, error) {
+				dm := p.Source.(*repo_model.Door43Metadata)
+				if err := dm.LoadRepo(p.Context); err != nil {
+					return nil, err
+				}
+				return dm.Repo, nil
+			}},
+			"owner": {Type: ownerType, Resolve: func(p graphql.ResolveParams) (any, error) {
+				dm := p.Source.(*repo_model.Door43Metadata)
+				if err := dm.LoadRepo(p.Context); err != nil {
+					return nil, err
+				}
+				return dm.Repo.Owner, nil
+			}},
+			"release": {Type: releaseType, Resolve: func(p graphql.ResolveParams) (any, error) {
+				dm := p.Source.(*repo_model.Door43Metadata)
+				if dm.ReleaseID == 0 {
+					return nil, nil
+				}
+				if err := dm.LoadAttributes(p.Context); err != nil {
+					return nil, err
+				}
+				return dm.Release, nil
+			}},
+		},
+	})
+
+	searchResultType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "CatalogSearchResult",
+		Description: "a page of the catalog entries matching a search",
+		Fields: graphql.Fields{
+			"totalCount": {Type: graphql.NewNonNull(graphql.Int), Description: "number of matching entries of all pages"},
+			"entries":    {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(catalogEntryType)))},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"catalog": {
+				Type:        graphql.NewNonNull(searchResultType),
+				Description: "search the catalog",
+				Args:        graphqlSearchArgs(),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return graphqlSearchCatalog(p, nil)
+				},
+			},
+			"catalogEntry": {
+				Type:        catalogEntryType,
+				Description: "get the catalog entry of a repository's release tag or branch",
+				Args: graphql.FieldConfigArgument{
+					"owner": {Type: graphql.NewNonNull(graphql.String)},
+					"repo":  {Type: graphql.NewNonNull(graphql.String)},
+					"ref":   {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					repo, err := graphqlReadableRepo(p.Context, graphqlDoer(p), p.Args["owner"].(string), p.Args["repo"].(string))
+					if err != nil || repo == nil {
+						return nil, err
+					}
+					dm, err := repo_model.GetDoor43MetadataByRepoIDAndRef(p.Context, repo.ID, p.Args["ref"].(string))
+					if err != nil {
+						if repo_model.IsErrDoor43MetadataNotExist(err) {
+							return nil, nil
+						}
+						return nil, err
+					}
+					dm.Repo = repo
+					return dm, nil
+				},
+			},
+			"repository": {
+				Type:        repositoryType,
+				Description: "get a repository",
+				Args: graphql.FieldConfigArgument{
+					"owner": {Type: graphql.NewNonNull(graphql.String)},
+					"name":  {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					repo, err := graphqlReadableRepo(p.Context, graphqlDoer(p), p.Args["owner"].(string), p.Args["name"].(string))
+					if err != nil || repo == nil {
+						return nil, err
+					}
+					return repo, nil
+				},
+			},
+			"owner": {
+				Type:        ownerType,
+				Description: "get a user or organization",
+				Args: graphql.FieldConfigArgument{
+					"login": {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					owner, err := user_model.GetUserByName(p.Context, p.Args["login"].(string))
+					if err != nil {
+						if user_model.IsErrUserNotExist(err) {
+							return nil, nil
+						}
+						return nil, err
+					}
+					if !user_model.IsUserVisibleToViewer(p.Context, owner, graphqlDoer(p)) {
+						return nil, nil
+					}
+					return owner, nil
+				},
+			},
+			"language": {
+				Type:        languageType,
+				Description: "get a language by its code",
+				Args: graphql.FieldConfigArgument{
+					"code": {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					code := p.Args["code"].(string)
+					if !dcs.IsValidLanguage(code) {
+						return nil, nil
+					}
+					return &graphqlLanguage{Code: code}, nil
+				},
+			},
+			"languages": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(languageType))),
+				Description: "list a page of the languages of the catalog entries matching the search, with their number of entries",
+				Args:        graphqlSearchArgs(),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					opts, err := graphqlSearchCatalogOptions(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					counts, err := door43metadata_service.CountCatalogFacets(p.Context, opts, []door43metadata.CatalogFacet{door43metadata.CatalogFacetLanguage})
+					if err != nil {
+						return nil, err
+					}
+					pageCounts := util.PaginateSlice(counts[door43metadata.CatalogFacetLanguage], opts.Page, opts.PageSize).([]*models.CatalogFacetCount)
+					languages := make([]*graphqlLanguage, 0, len(pageCounts))
+					for _, count := range pageCounts {
+						languages = append(languages, &graphqlLanguage{Code: count.Value, Count: count.Count})
+					}
+					return languages, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// toCatalogGraphQLResponse converts the result of a GraphQL query to API format
+func toCatalogGraphQLResponse(result *graphql.Result) *api.CatalogGraphQLResponse {
+	resp := &api.CatalogGraphQLResponse{Data: result.Data}
+	for _, err := range result.Errors {
+		resp.Errors = append(resp.Errors, &api.CatalogGraphQLError{
+			Message: err.Message,
+			Path:    err.Path,
+		})
+	}
+	return resp
+}
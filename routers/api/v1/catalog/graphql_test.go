@@ -0,0 +1,86 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/contexttest"
+	"code.gitea.io/gitea/modules/json"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckGraphQLLimits(t *testing.T) {
+	assert.NoError(t, checkGraphQLLimits(`{ catalog { totalCount entries { id repository { name owner { login } } } } }`, "", nil))
+	// left to the executor to report
+	assert.NoError(t, checkGraphQLLimits(`{ catalog {`, "", nil))
+
+	// too deep
+	deep := `{ repository(owner: "user2", name: "repo1") { owner { catalogEntries(limit: 1) { entries { repository { owner { catalogEntries(limit: 1) { entries { repository { owner { login } } } } } } } } } } }`
+	err := checkGraphQLLimits(deep, "", nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "depth")
+	}
+
+	// each entry of a page searches the catalog again
+	nested := `query Q($n: Int) { catalog(limit: $n) { entries { repository { catalogEntries(limit: $n) { entries { id title subject } } } } } }`
+	assert.NoError(t, checkGraphQLLimits(nested, "", map[string]any{"n": float64(5)}))
+	err = checkGraphQLLimits(nested, "", map[string]any{"n": float64(50)})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "complexity")
+	}
+	// the limit is capped to the maximum page size
+	assert.Error(t, checkGraphQLLimits(nested, "", map[string]any{"n": float64(100000)}))
+
+	// fragments are followed, even if only the selected operation is checked
+	fragments := `fragment E on CatalogSearchResult { entries { repository { catalogEntries(limit: 50) { entries { ...F } } } } }
+fragment F on CatalogEntry { id title subject ref }
+query Big { catalog(limit: 50) { ...E } }
+query Small { catalog(limit: 1) { totalCount } }`
+	assert.Error(t, checkGraphQLLimits(fragments, "Big", nil))
+	assert.NoError(t, checkGraphQLLimits(fragments, "Small", nil))
+	// cyclic fragments don't loop
+	assert.NoError(t, checkGraphQLLimits(`fragment A on Owner { catalogEntries { entries { owner { ...A } } } } { owner(login: "user2") { ...A } }`, "", nil))
+
+	assert.Error(t, checkGraphQLLimits(strings.Repeat(" ", graphqlMaxQueryLength+1), "", nil))
+}
+
+func TestGraphQL(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	ctx, resp := contexttest.MockAPIContext(t, "api/v1/catalog/graphql")
+	web.SetForm(ctx, &api.CatalogGraphQLRequest{Query: `{ owner(login: "user2") { login isOrganization } }`})
+	GraphQL(ctx)
+	assert.Equal(t, http.StatusOK, ctx.Resp.Status())
+	result := &api.CatalogGraphQLResponse{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), result))
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, map[string]any{"owner": map[string]any{"login": "user2", "isOrganization": false}}, result.Data)
+
+	// too many values of a list argument
+	languages := make([]string, graphqlMaxListArgValues+1)
+	for i := range languages {
+		languages[i] = `"en"`
+	}
+	ctx, resp = contexttest.MockAPIContext(t, "api/v1/catalog/graphql")
+	web.SetForm(ctx, &api.CatalogGraphQLRequest{Query: `{ catalog(languages: [` + strings.Join(languages, ",") + `]) { totalCount } }`})
+	GraphQL(ctx)
+	assert.Equal(t, http.StatusOK, ctx.Resp.Status())
+	result = &api.CatalogGraphQLResponse{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), result))
+	if assert.Len(t, result.Errors, 1) {
+		assert.Contains(t, result.Errors[0].Message, "languages has more than")
+	}
+
+	ctx, _ = contexttest.MockAPIContext(t, "api/v1/catalog/graphql")
+	web.SetForm(ctx, &api.CatalogGraphQLRequest{Query: `{ catalog(limit: 50) { entries { repository { catalogEntries(limit: 50) { entries { id title } } } } } }`})
+	GraphQL(ctx)
+	assert.Equal(t, http.StatusUnprocessableEntity, ctx.Resp.Status())
+}
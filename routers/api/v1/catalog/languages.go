@@ -0,0 +1,164 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models/door43metadata"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/convert"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
+)
+
+// getLanguageStage returns the stage of the stage query parameter, production by default
+func getLanguageStage(ctx *context.APIContext) (door43metadata.Stage, bool) {
+	stageStr := ctx.FormString("stage")
+	if stageStr == "" {
+		return door43metadata.StageProd, true
+	}
+	stage, ok := door43metadata.StageMap[stageStr]
+	if !ok || stage == door43metadata.StageBranch {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("invalid stage [%s]", stageStr))
+		return stage, false
+	}
+	return stage, true
+}
+
+// ListCatalogLanguageDetails lists the languages of the catalog with their details and number of entries
+func ListCatalogLanguageDetails(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/languages catalog catalogListLanguageDetails
+	// ---
+	// summary: List the languages of the catalog with their details from langnames.json and number of entries, most entries first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: is_gl
+	//   in: query
+	//   description: list only those that are (true) or are not (false) a gateway language
+	//   type: boolean
+	// - name: lr
+	//   in: query
+	//   description: list only the languages of the given region(s), e.g. Africa. Multiple regions are ORed
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: cc
+	//   in: query
+	//   description: list only the languages spoken in the given country code(s), e.g. TZ. Multiple countries are ORed
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: stage
+	//   in: query
+	//   description: 'count only the entries of the given stage or lower, with low to high being:
+	//                "prod" - only the production releases (default);
+	//                "preprod" - pre-production and production releases;
+	//                "latest" - also the default branches'
+	//   type: string
+	//   enum: [prod,preprod,latest]
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogLanguageList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	stage, ok := getLanguageStage(ctx)
+	if !ok {
+		return
+	}
+	counts, err := door43metadata_service.CountCatalogLanguages(ctx, &door43metadata.SearchCatalogOptions{
+		Stage:        stage,
+		LanguageIsGL: ctx.FormOptionalBool("is_gl"),
+		Regions:      QueryStrings(ctx, "lr"),
+		Countries:    QueryStrings(ctx, "cc"),
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CountCatalogLanguages", err)
+		return
+	}
+	languages := make([]*api.CatalogLanguage, 0, len(counts))
+	for _, count := range counts {
+		languages = append(languages, convert.ToCatalogLanguage(count.Value, count.Count))
+	}
+	ctx.JSON(http.StatusOK, languages)
+}
+
+// GetCatalogLanguage gets the details of a language and everything the catalog has in it
+func GetCatalogLanguage(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/languages/{lang} catalog catalogGetLanguage
+	// ---
+	// summary: Get the details of a language, the number of entries of each subject and stage, the owners of
+	//   the entries and the most recent ones
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: lang
+	//   in: path
+	//   description: language code
+	//   type: string
+	//   required: true
+	// - name: stage
+	//   in: query
+	//   description: 'count only the entries of the given stage or lower, with low to high being:
+	//                "prod" - only the production releases (default);
+	//                "preprod" - pre-production and production releases;
+	//                "latest" - also the default branches'
+	//   type: string
+	//   enum: [prod,preprod,latest]
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogLanguageOverview"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	stage, ok := getLanguageStage(ctx)
+	if !ok {
+		return
+	}
+	overview, err := door43metadata_service.GetLanguageOverview(ctx, ctx.Params("lang"), stage)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetLanguageOverview", err)
+		return
+	}
+	if overview == nil {
+		ctx.NotFound()
+		return
+	}
+
+	var entryCount int64
+	subjects := make([]*api.CatalogLanguageSubject, 0, len(overview.Subjects))
+	for _, s := range overview.Subjects {
+		subjects = append(subjects, convert.ToCatalogLanguageSubject(s.Subject, s.Entries))
+		entryCount += int64(len(s.Entries))
+	}
+	perms := make(map[int64]access_model.Permission)
+	recent := make([]*api.CatalogEntry, 0, len(overview.Recent))
+	for _, dm := range overview.Recent {
+		perm, ok := perms[dm.RepoID]
+		if !ok {
+			perm, err = access_model.GetUserRepoPermission(ctx, dm.Repo, ctx.Doer)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+				return
+			}
+			perms[dm.RepoID] = perm
+		}
+		recent = append(recent, convert.ToCatalogEntry(ctx, dm, perm))
+	}
+
+	ctx.JSON(http.StatusOK, &api.CatalogLanguageOverview{
+		Language:      convert.ToCatalogLanguage(overview.Language, entryCount),
+		Subjects:      subjects,
+		Owners:        convert.ToCatalogFacetCounts(overview.Owners),
+		RecentEntries: recent,
+	})
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// ListCatalogProgress lists the translation progress of the Bible and Open Bible Stories repos
+func ListCatalogProgress(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/progress catalog catalogListProgress
+	// ---
+	// summary: List the translation progress of the Bible and Open Bible Stories repos
+	// description: The progress of a repo is the number of verses, or frames of OBS, of its books that have content,
+	//   counted from the entry of its default branch, or of its latest release if it has no default branch entry.
+	//   Entries are sorted by language and title. The completed and total counts are of all the matching entries.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: query
+	//   description: owner of the repos
+	//   type: string
+	// - name: lang
+	//   in: query
+	//   description: language codes of the repos. Multiple values are ORed
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	// - name: subject
+	//   in: query
+	//   description: subjects of the repos. Multiple values are ORed
+	//   type: array
+	//   collectionFormat: multi
+	//   items:
+	//     type: string
+	//     enum: [Bible, Aligned Bible, Open Bible Stories]
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TranslationProgressReport"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	opts := &repo.Door43ProgressOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		Languages:   QueryStrings(ctx, "lang"),
+		Subjects:    QueryStrings(ctx, "subject"),
+		PublicOnly:  true,
+	}
+	if ownerName := ctx.FormTrim("owner"); ownerName != "" {
+		owner, err := user_model.GetUserByName(ctx, ownerName)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				ctx.NotFound()
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
+		}
+		opts.OwnerID = owner.ID
+	}
+
+	dms, count, err := repo.FindDoor43MetadatasWithProgress(ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindDoor43MetadatasWithProgress", err)
+		return
+	}
+	if err := dms.LoadAttributes(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+	total, err := repo.GetDoor43ProgressTotal(ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDoor43ProgressTotal", err)
+		return
+	}
+
+	ctx.SetLinkHeader(int(count), opts.PageSize)
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, convert.ToTranslationProgressReport(dms, total))
+}
+
+// GetCatalogEntryProgress gets the translation progress of each book and chapter of a catalog entry
+func GetCatalogEntryProgress(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/entry/{owner}/{repo}/{ref}/progress catalog catalogGetEntryProgress
+	// ---
+	// summary: Get the translation progress of each book and chapter of a catalog entry
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: path
+	//   description: release tag or branch
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TranslationProgress"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	dm, err := repo.GetDoor43MetadataByRepoIDAndRef(ctx, ctx.Repo.Repository.ID, ctx.Params("ref"))
+	if err != nil {
+		if repo.IsErrDoor43MetadataNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDoor43MetadataByRepoIDAndRef", err)
+		}
+		return
+	}
+	if err := dm.LoadAttributes(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+	progress := convert.ToTranslationProgress(dm, true)
+	if len(progress.Books) == 0 {
+		ctx.NotFound()
+		return
+	}
+	ctx.JSON(http.StatusOK, progress)
+}
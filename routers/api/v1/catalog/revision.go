@@ -0,0 +1,69 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"net/http"
+
+	access_model "code.gitea.io/gitea/models/perm/access"
+	"code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// ListCatalogEntryRevisions list the previous versions of a catalog entry
+func ListCatalogEntryRevisions(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/entry/{owner}/{repo}/{ref}/revisions catalog catalogListEntryRevisions
+	// ---
+	// summary: List the previous versions of a catalog entry, recorded each time its metadata changed, most recent first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: path
+	//   description: release tag or branch
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogEntryRevisionList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	revs, err := repo.GetDoor43MetadataRevisions(ctx, ctx.Repo.Repository.ID, ctx.Params("ref"), utils.GetListOptions(ctx))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDoor43MetadataRevisions", err)
+		return
+	}
+	perm, err := access_model.GetUserRepoPermission(ctx, ctx.Repo.Repository, ctx.ContextUser)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+		return
+	}
+	apiRevs := make([]*api.CatalogEntryRevision, len(revs))
+	for i, rev := range revs {
+		apiRevs[i] = convert.ToCatalogEntryRevision(ctx, rev, perm)
+	}
+	ctx.JSON(http.StatusOK, apiRevs)
+}
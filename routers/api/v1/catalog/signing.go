@@ -0,0 +1,146 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"net/http"
+
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/services/convert"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
+)
+
+// GetCatalogSigningKey gets the instance's public key used to sign catalog entries
+func GetCatalogSigningKey(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/signing-key catalog catalogGetSigningKey
+	// ---
+	// summary: Get the instance's public key used to sign catalog entries of owners without their own key
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogSigningKey"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	key, err := asymkey_model.GetDoor43SigningKey(ctx, 0)
+	if err != nil {
+		if asymkey_model.IsErrDoor43SigningKeyNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDoor43SigningKey", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToCatalogSigningKey(key, nil))
+}
+
+// GetCatalogOwnerSigningKey gets the public key used to sign an owner's catalog entries
+func GetCatalogOwnerSigningKey(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/signing-key/{owner} catalog catalogGetOwnerSigningKey
+	// ---
+	// summary: Get the public key used to sign an owner's catalog entries, which is the instance key if the owner has no key of its own
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogSigningKey"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	key, err := door43metadata_service.GetSigningKeyForOwner(ctx, ctx.ContextUser.ID)
+	if err != nil {
+		if asymkey_model.IsErrDoor43SigningKeyNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetSigningKeyForOwner", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToCatalogSigningKey(key, ctx.ContextUser))
+}
+
+// CreateCatalogOwnerSigningKey generates a key to sign an owner's catalog entries
+func CreateCatalogOwnerSigningKey(ctx *context.APIContext) {
+	// swagger:operation POST /catalog/signing-key/{owner} catalog catalogCreateOwnerSigningKey
+	// ---
+	// summary: Generate a key to sign an owner's catalog entries instead of the instance key
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CatalogSigningKey"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+
+	if !canManageCatalogOwner(ctx) {
+		return
+	}
+	key, err := asymkey_model.CreateDoor43SigningKey(ctx, ctx.ContextUser.ID)
+	if err != nil {
+		if asymkey_model.IsErrDoor43SigningKeyAlreadyExist(err) {
+			ctx.Error(http.StatusConflict, "CreateDoor43SigningKey", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CreateDoor43SigningKey", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToCatalogSigningKey(key, ctx.ContextUser))
+}
+
+// DeleteCatalogOwnerSigningKey deletes the key of an owner so its catalog entries are signed with the instance key
+func DeleteCatalogOwnerSigningKey(ctx *context.APIContext) {
+	// swagger:operation DELETE /catalog/signing-key/{owner} catalog catalogDeleteOwnerSigningKey
+	// ---
+	// summary: Delete an owner's signing key so its catalog entries are signed with the instance key
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner (user or organization)
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if !canManageCatalogOwner(ctx) {
+		return
+	}
+	if _, err := asymkey_model.GetDoor43SigningKey(ctx, ctx.ContextUser.ID); err != nil {
+		if asymkey_model.IsErrDoor43SigningKeyNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDoor43SigningKey", err)
+		}
+		return
+	}
+	if err := asymkey_model.DeleteDoor43SigningKey(ctx, ctx.ContextUser.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteDoor43SigningKey", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,247 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package catalog
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models/door43metadata"
+	"code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// ListCatalogSubscriptions lists the catalog subscriptions of the authenticated user
+func ListCatalogSubscriptions(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/subscriptions catalog catalogListSubscriptions
+	// ---
+	// summary: List the catalog subscriptions of the authenticated user
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogSubscriptionList"
+
+	subscriptions, err := repo.GetDoor43SubscriptionsByUserID(ctx, ctx.Doer.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDoor43SubscriptionsByUserID", err)
+		return
+	}
+	apiSubscriptions := make([]*api.CatalogSubscription, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		apiSubscriptions = append(apiSubscriptions, convert.ToCatalogSubscription(s))
+	}
+	ctx.JSON(http.StatusOK, apiSubscriptions)
+}
+
+// CreateCatalogSubscription creates a catalog subscription of the authenticated user
+func CreateCatalogSubscription(ctx *context.APIContext) {
+	// swagger:operation POST /catalog/subscriptions catalog catalogCreateSubscription
+	// ---
+	// summary: Subscribe to new prod or preprod releases of the given languages, subjects, owners and books
+	// description: A notification is created for each new catalog entry of a release matching the subscription.
+	//   An email digest of the matching releases is also sent daily if email_digest is true.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateCatalogSubscriptionOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CatalogSubscription"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateCatalogSubscriptionOption)
+	s := &repo.Door43Subscription{
+		UserID:      ctx.Doer.ID,
+		User:        ctx.Doer,
+		Name:        form.Name,
+		Languages:   cleanSubscriptionList(form.Languages),
+		Subjects:    cleanSubscriptionList(form.Subjects),
+		Owners:      cleanSubscriptionList(form.Owners),
+		Books:       cleanSubscriptionList(form.Books),
+		Stage:       door43metadata.StageProd,
+		EmailDigest: form.EmailDigest,
+	}
+	if form.Stage != "" {
+		s.Stage = door43metadata.StageMap[form.Stage]
+	}
+	if err := repo.InsertDoor43Subscription(ctx, s); err != nil {
+		if repo.IsErrDoor43SubscriptionInvalid(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "InsertDoor43Subscription", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToCatalogSubscription(s))
+}
+
+// GetCatalogSubscription gets a catalog subscription of the authenticated user
+func GetCatalogSubscription(ctx *context.APIContext) {
+	// swagger:operation GET /catalog/subscriptions/{id} catalog catalogGetSubscription
+	// ---
+	// summary: Get a catalog subscription of the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the subscription
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogSubscription"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	s := getCatalogSubscription(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToCatalogSubscription(s))
+}
+
+// EditCatalogSubscription edits a catalog subscription of the authenticated user
+func EditCatalogSubscription(ctx *context.APIContext) {
+	// swagger:operation PATCH /catalog/subscriptions/{id} catalog catalogEditSubscription
+	// ---
+	// summary: Edit a catalog subscription of the authenticated user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the subscription
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditCatalogSubscriptionOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogSubscription"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditCatalogSubscriptionOption)
+	s := getCatalogSubscription(ctx)
+	if ctx.Written() {
+		return
+	}
+	cols := make([]string, 0, 7)
+	if form.Name != nil && *form.Name != "" {
+		s.Name = *form.Name
+		cols = append(cols, "name")
+	}
+	if form.Languages != nil {
+		s.Languages = cleanSubscriptionList(*form.Languages)
+		cols = append(cols, "languages")
+	}
+	if form.Subjects != nil {
+		s.Subjects = cleanSubscriptionList(*form.Subjects)
+		cols = append(cols, "subjects")
+	}
+	if form.Owners != nil {
+		s.Owners = cleanSubscriptionList(*form.Owners)
+		cols = append(cols, "owners")
+	}
+	if form.Books != nil {
+		s.Books = cleanSubscriptionList(*form.Books)
+		cols = append(cols, "books")
+	}
+	if form.Stage != nil && *form.Stage != "" {
+		s.Stage = door43metadata.StageMap[*form.Stage]
+		cols = append(cols, "stage")
+	}
+	if form.EmailDigest != nil {
+		s.EmailDigest = *form.EmailDigest
+		cols = append(cols, "email_digest")
+	}
+	if len(cols) > 0 {
+		if err := repo.UpdateDoor43SubscriptionCols(ctx, s, cols...); err != nil {
+			if repo.IsErrDoor43SubscriptionInvalid(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "UpdateDoor43SubscriptionCols", err)
+			}
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, convert.ToCatalogSubscription(s))
+}
+
+// DeleteCatalogSubscription deletes a catalog subscription of the authenticated user
+func DeleteCatalogSubscription(ctx *context.APIContext) {
+	// swagger:operation DELETE /catalog/subscriptions/{id} catalog catalogDeleteSubscription
+	// ---
+	// summary: Delete a catalog subscription of the authenticated user
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the subscription
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	s := getCatalogSubscription(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := repo.DeleteDoor43Subscription(ctx, s); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteDoor43Subscription", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// getCatalogSubscription gets the subscription of the authenticated user with the id in the path
+func getCatalogSubscription(ctx *context.APIContext) *repo.Door43Subscription {
+	s, err := repo.GetDoor43SubscriptionByID(ctx, ctx.ParamsInt64(":id"), ctx.Doer.ID)
+	if err != nil {
+		if repo.IsErrDoor43SubscriptionNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDoor43SubscriptionByID", err)
+		}
+		return nil
+	}
+	s.User = ctx.Doer
+	return s
+}
+
+// cleanSubscriptionList trims the values of a subscription's list, leaving out empty and duplicate values
+func cleanSubscriptionList(values []string) []string {
+	cleaned := make([]string, 0, len(values))
+	seen := make(container.Set[string])
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" && seen.Add(strings.ToLower(v)) {
+			cleaned = append(cleaned, v)
+		}
+	}
+	return cleaned
+}
@@ -92,6 +92,28 @@ func ServeLangnamesJSONKeyed(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, searchLangnamesJSONKeyed(ctx))
 }
 
+// ServeReleaseAttachmentsSchema serves the JSON schema of the files.json and links.json files that can be attached
+// to releases to add attachments linking to files
+func ServeReleaseAttachmentsSchema(ctx *context.APIContext) {
+	// swagger:operation GET /schemas/release_attachments.schema.json miscellaneous getReleaseAttachmentsSchema
+	// ---
+	// summary: Get the JSON schema of the files.json and links.json files attached to releases to link to files
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ReleaseAttachmentsSchema"
+
+	schema, err := dcs.GetReleaseAttachmentsSchemaFile()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetReleaseAttachmentsSchemaFile", err)
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "application/schema+json")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	_, _ = ctx.Resp.Write(schema)
+}
+
 func searchLangnamesJSON(ctx *context.APIContext) []map[string]interface{} {
 	langnames := dcs.GetLangnamesJSON()
 	if len(langnames) == 0 {
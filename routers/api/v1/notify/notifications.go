@@ -60,6 +60,8 @@ func subjectToSource(value []string) (result []activities_model.NotificationSour
 			result = append(result, activities_model.NotificationSourceCommit)
 		case "repository":
 			result = append(result, activities_model.NotificationSourceRepository)
+		case "catalogentry": // DCS Customizations
+			result = append(result, activities_model.NotificationSourceCatalogEntry) // DCS Customizations
 		}
 	}
 	return result
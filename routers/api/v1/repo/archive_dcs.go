@@ -0,0 +1,35 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"errors"
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/util"
+	archiver_service "code.gitea.io/gitea/services/repository/archiver"
+)
+
+// prepareArchiveRequest restricts the archive request to the books and paths of the query, if any, and sets how it
+// is packaged. Returns false if an error was written
+func prepareArchiveRequest(ctx *context.APIContext, aReq *archiver_service.ArchiveRequest) bool {
+	err := aReq.SetPaths(ctx, ctx.Repo.GitRepo, ctx.FormStrings("books"), ctx.FormStrings("paths"))
+	if err == nil {
+		err = aReq.SetPackage(ctx, ctx.Repo.GitRepo)
+	}
+	if err != nil {
+		if errors.Is(err, archiver_service.ArchivePathNotFoundError{}) {
+			ctx.Error(http.StatusNotFound, "unknown book or path", err)
+		} else if errors.Is(err, archiver_service.ArchivePackageNotAvailableError{}) {
+			ctx.Error(http.StatusNotFound, "archive format not available", err)
+		} else if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusBadRequest, "invalid archive request", err)
+		} else {
+			ctx.ServerError("prepareArchiveRequest", err)
+		}
+		return false
+	}
+	return true
+}
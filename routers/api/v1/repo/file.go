@@ -29,6 +29,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/common"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata" // DCS Customizations
 	archiver_service "code.gitea.io/gitea/services/repository/archiver"
 	files_service "code.gitea.io/gitea/services/repository/files"
 )
@@ -80,6 +81,7 @@ func GetRawFile(ctx *context.APIContext) {
 	}
 
 	ctx.RespHeader().Set(giteaObjectTypeHeader, string(files_service.GetObjectTypeFromTreeEntry(entry)))
+	door43metadata_service.CountIngredientDownload(ctx, ctx.Repo.Repository.ID, ctx.Repo.RefName, ctx.Repo.TreePath) // DCS Customizations
 
 	if err := common.ServeBlob(ctx.Base, ctx.Repo.TreePath, blob, lastModified); err != nil {
 		ctx.Error(http.StatusInternalServerError, "ServeBlob", err)
@@ -129,6 +131,7 @@ func GetRawFileOrLFS(ctx *context.APIContext) {
 	}
 
 	ctx.RespHeader().Set(giteaObjectTypeHeader, string(files_service.GetObjectTypeFromTreeEntry(entry)))
+	door43metadata_service.CountIngredientDownload(ctx, ctx.Repo.Repository.ID, ctx.Repo.RefName, ctx.Repo.TreePath) // DCS Customizations
 
 	// LFS Pointer files are at most 1024 bytes - so any blob greater than 1024 bytes cannot be an LFS file
 	if blob.Size() > 1024 {
@@ -270,12 +273,30 @@ func GetArchive(ctx *context.APIContext) {
 	//   required: true
 	// - name: archive
 	//   in: path
-	//   description: the git reference for download with attached archive format (e.g. master.zip)
+	//   description: the git reference for download with attached archive format (e.g. master.zip). The rc.zip and
+	//     burrito.zip formats package a catalog entry as a resource container or a scripture burrito
 	//   type: string
 	//   required: true
+	// - name: books
+	//   in: query
+	//   description: only archive the metadata files and the ingredients of these books (identifiers of the
+	//     ingredients of the catalog entry of the reference, e.g. jhn,rom)
+	//   type: array
+	//   collectionFormat: csv
+	//   items:
+	//     type: string
+	// - name: paths
+	//   in: query
+	//   description: only archive the metadata files and these paths
+	//   type: array
+	//   collectionFormat: csv
+	//   items:
+	//     type: string
 	// responses:
 	//   200:
 	//     description: success
+	//   "400":
+	//     "$ref": "#/responses/error"
 	//   "404":
 	//     "$ref": "#/responses/notFound"
 
@@ -306,6 +327,9 @@ func archiveDownload(ctx *context.APIContext) {
 		}
 		return
 	}
+	if !prepareArchiveRequest(ctx, aReq) { // DCS Customizations
+		return
+	}
 
 	archiver, err := aReq.Await(ctx)
 	if err != nil {
@@ -313,6 +337,7 @@ func archiveDownload(ctx *context.APIContext) {
 		return
 	}
 
+	door43metadata_service.CountArchiveDownload(ctx, ctx.Repo.Repository.ID, aReq.GetRefName(), aReq.Type) // DCS Customizations
 	download(ctx, aReq.GetArchiveName(), archiver)
 }
 
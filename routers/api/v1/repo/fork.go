@@ -141,6 +141,12 @@ func CreateFork(ctx *context.APIContext) {
 		name = *form.Name
 	}
 
+	/*** DCS Customizations ***/
+	if !checkRepoNamingPolicy(ctx, forker, name) {
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	fork, err := repo_service.ForkRepository(ctx, ctx.Doer, forker, repo_service.ForkRepoOptions{
 		BaseRepo:    repo,
 		Name:        name,
@@ -0,0 +1,159 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"errors"
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
+)
+
+// GetConvertedMetadata converts the metadata of a ref to the other metadata type
+func GetConvertedMetadata(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/metadata/convert repository repoGetConvertedMetadata
+	// ---
+	// summary: Convert the RC manifest.yaml of a ref to a SB 1.0 metadata.json or the other way round, without committing it
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag. Default the repository’s default branch"
+	//   type: string
+	//   required: false
+	// - name: to
+	//   in: query
+	//   description: metadata type to convert to, the other type of the metadata found if empty
+	//   type: string
+	//   enum: [rc, sb]
+	//   required: false
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ConvertedMetadata"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	ref := ctx.FormTrim("ref")
+	if ref == "" {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(ref)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return
+	}
+
+	converted, err := door43metadata_service.ConvertMetadata(ctx.Repo.Repository, commit, ctx.FormTrim("to"))
+	if err != nil {
+		handleConvertMetadataError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &api.ConvertedMetadata{
+		From:    converted.From,
+		To:      converted.To,
+		Path:    converted.TreePath,
+		Content: string(converted.Content),
+	})
+}
+
+// ConvertMetadata converts the metadata of a branch to the other metadata type and commits it
+func ConvertMetadata(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/metadata/convert repository repoConvertMetadata
+	// ---
+	// summary: Convert the RC manifest.yaml of a branch to a SB 1.0 metadata.json or the other way round and commit it
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/ConvertMetadataOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/FileResponse"
+	//   "403":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	//   "423":
+	//     "$ref": "#/responses/repoArchivedError"
+
+	form := web.GetForm(ctx).(*api.ConvertMetadataOption)
+	opts := door43metadata_service.CommitConvertedMetadataOptions{
+		To:        form.To,
+		Branch:    form.Branch,
+		NewBranch: form.NewBranch,
+		Message:   form.Message,
+	}
+	if opts.Branch == "" {
+		opts.Branch = ctx.Repo.Repository.DefaultBranch
+	}
+	branch := opts.Branch
+	if opts.NewBranch != "" {
+		branch = opts.NewBranch
+	}
+	if !canWriteFiles(ctx, branch) {
+		ctx.Error(http.StatusForbidden, "Access", "user cannot commit to the branch")
+		return
+	}
+
+	fileResponse, err := door43metadata_service.CommitConvertedMetadata(ctx, ctx.Repo.Repository, ctx.Repo.GitRepo, ctx.Doer, opts)
+	if err != nil {
+		if git.IsErrBranchNotExist(err) {
+			ctx.NotFound()
+			return
+		}
+		handleConvertMetadataError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, fileResponse)
+}
+
+// handleConvertMetadataError writes the response of a metadata conversion or commit error
+func handleConvertMetadataError(ctx *context.APIContext, err error) {
+	switch {
+	case door43metadata_service.IsErrMetadataConversion(err), errors.Is(err, util.ErrInvalidArgument):
+		ctx.Error(http.StatusUnprocessableEntity, "ConvertMetadata", err)
+	case errors.Is(err, util.ErrNotExist):
+		ctx.NotFound(err)
+	default:
+		handleCreateOrUpdateFileError(ctx, err)
+	}
+}
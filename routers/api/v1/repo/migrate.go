@@ -104,6 +104,12 @@ func Migrate(ctx *context.APIContext) {
 		}
 	}
 
+	/*** DCS Customizations ***/
+	if !checkRepoNamingPolicy(ctx, repoOwner, form.RepoName) {
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	remoteAddr, err := forms.ParseRemoteAddr(form.CloneAddr, form.AuthUsername, form.AuthPassword)
 	if err == nil {
 		err = migrations.IsMigrateURLAllowed(remoteAddr, ctx.Doer)
@@ -0,0 +1,35 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"net/http"
+
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
+)
+
+// headerRepoNameWarning is the response header warning about a repo name not following the naming conventions
+// of an organization that only suggests names
+const headerRepoNameWarning = "X-Repo-Name-Warning"
+
+// checkRepoNamingPolicy checks the name of a repo being created or renamed against the repo naming policy of its
+// owner. Responds with 422 if the policy is enforced and else sets the warning header. Returns false if written
+func checkRepoNamingPolicy(ctx *context.APIContext, owner *user_model.User, name string) bool {
+	err := door43metadata_service.CheckRepoName(ctx, owner, name)
+	if err == nil {
+		return true
+	}
+	if !door43metadata_service.IsErrRepoNameNotConventional(err) {
+		ctx.Error(http.StatusInternalServerError, "CheckRepoName", err)
+		return false
+	}
+	if err.(door43metadata_service.ErrRepoNameNotConventional).IsEnforced() {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return false
+	}
+	ctx.Resp.Header().Set(headerRepoNameWarning, err.Error())
+	return true
+}
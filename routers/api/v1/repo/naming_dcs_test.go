@@ -0,0 +1,52 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/contexttest"
+	"code.gitea.io/gitea/modules/dcs"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForkAndMigrateRepoNamingPolicy(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	org := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 3})
+	assert.NoError(t, door43metadata_service.SetRepoNamingPolicy(db.DefaultContext, org, dcs.RepoNamingPolicyEnforce))
+
+	name := "my-fork"
+	ctx, _ := contexttest.MockAPIContext(t, "user2/repo1/forks")
+	contexttest.LoadRepo(t, ctx, 1)
+	contexttest.LoadUser(t, ctx, 2)
+	web.SetForm(ctx, &api.CreateForkOption{Organization: &org.Name, Name: &name})
+	CreateFork(ctx)
+	assert.Equal(t, http.StatusUnprocessableEntity, ctx.Resp.Status())
+
+	migrate := func() *http.Response {
+		ctx, resp := contexttest.MockAPIContext(t, "repos/migrate")
+		contexttest.LoadUser(t, ctx, 2)
+		web.SetForm(ctx, &api.MigrateRepoOptions{CloneAddr: "not a url", RepoOwner: org.Name, RepoName: "my-migration"})
+		Migrate(ctx)
+		return resp.Result()
+	}
+	resp := migrate()
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(headerRepoNameWarning))
+
+	// only warned about, the migration fails because of the clone address
+	assert.NoError(t, door43metadata_service.SetRepoNamingPolicy(db.DefaultContext, org, dcs.RepoNamingPolicySuggest))
+	resp = migrate()
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get(headerRepoNameWarning))
+}
@@ -316,6 +316,12 @@ func CreateUserRepo(ctx *context.APIContext, owner *user_model.User, opt api.Cre
 		return
 	}
 
+	/*** DCS Customizations ***/
+	if !checkRepoNamingPolicy(ctx, owner, opt.Name) {
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	repo, err := repo_service.CreateRepository(ctx, ctx.Doer, owner, repo_service.CreateRepoOptions{
 		Name:          opt.Name,
 		Description:   opt.Description,
@@ -482,6 +488,12 @@ func Generate(ctx *context.APIContext) {
 		}
 	}
 
+	/*** DCS Customizations ***/
+	if !checkRepoNamingPolicy(ctx, ctxUser, opts.Name) {
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	repo, err := repo_service.GenerateRepository(ctx, ctx.Doer, ctxUser, ctx.Repo.Repository, opts)
 	if err != nil {
 		if repo_model.IsErrRepoAlreadyExist(err) {
@@ -736,6 +748,11 @@ func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) err
 	}
 	// Check if repository name has been changed and not just a case change
 	if repo.LowerName != strings.ToLower(newRepoName) {
+		/*** DCS Customizations ***/
+		if !checkRepoNamingPolicy(ctx, owner, newRepoName) {
+			return fmt.Errorf("repo name does not follow the naming conventions [name: %s]", newRepoName)
+		}
+		/*** END DCS Customizations ***/
 		if err := repo_service.ChangeRepositoryName(ctx, ctx.Doer, repo, newRepoName); err != nil {
 			switch {
 			case repo_model.IsErrRepoAlreadyExist(err):
@@ -28,9 +28,142 @@ type swaggerResponseCatalogMetadata struct {
 	Body map[string]interface{} `json:"body"`
 }
 
+// CatalogCheck
+// swagger:response CatalogCheck
+type swaggerResponseCatalogCheck struct {
+	// in:body
+	Body api.CatalogCheck `json:"body"`
+}
+
+// CatalogCheckList
+// swagger:response CatalogCheckList
+type swaggerResponseCatalogCheckList struct {
+	// in:body
+	Body []api.CatalogCheck `json:"body"`
+}
+
+// CatalogChecker
+// swagger:response CatalogChecker
+type swaggerResponseCatalogChecker struct {
+	// in:body
+	Body api.CatalogChecker `json:"body"`
+}
+
+// CatalogCheckerList
+// swagger:response CatalogCheckerList
+type swaggerResponseCatalogCheckerList struct {
+	// in:body
+	Body []api.CatalogChecker `json:"body"`
+}
+
 // Language
 // swagger:response Language
 type swaggerResponseLanguage struct {
 	// in:body
 	Body map[string]interface{} `json:"body"`
 }
+
+// CatalogSigningKey
+// swagger:response CatalogSigningKey
+type swaggerResponseCatalogSigningKey struct {
+	// in:body
+	Body api.CatalogSigningKey `json:"body"`
+}
+
+// CatalogEntryRevisionList
+// swagger:response CatalogEntryRevisionList
+type swaggerResponseCatalogEntryRevisionList struct {
+	// in:body
+	Body []api.CatalogEntryRevision `json:"body"`
+}
+
+// CatalogGraphQLResponse
+// swagger:response CatalogGraphQLResponse
+type swaggerResponseCatalogGraphQLResponse struct {
+	// in:body
+	Body api.CatalogGraphQLResponse `json:"body"`
+}
+
+// CatalogCollection
+// swagger:response CatalogCollection
+type swaggerResponseCatalogCollection struct {
+	// in:body
+	Body api.CatalogCollection `json:"body"`
+}
+
+// CatalogCollectionList
+// swagger:response CatalogCollectionList
+type swaggerResponseCatalogCollectionList struct {
+	// in:body
+	Body []api.CatalogCollection `json:"body"`
+}
+
+// CatalogCollectionVersion
+// swagger:response CatalogCollectionVersion
+type swaggerResponseCatalogCollectionVersion struct {
+	// in:body
+	Body api.CatalogCollectionVersion `json:"body"`
+}
+
+// CatalogCollectionVersionList
+// swagger:response CatalogCollectionVersionList
+type swaggerResponseCatalogCollectionVersionList struct {
+	// in:body
+	Body []api.CatalogCollectionVersion `json:"body"`
+}
+
+// CatalogSubscription
+// swagger:response CatalogSubscription
+type swaggerResponseCatalogSubscription struct {
+	// in:body
+	Body api.CatalogSubscription `json:"body"`
+}
+
+// CatalogSubscriptionList
+// swagger:response CatalogSubscriptionList
+type swaggerResponseCatalogSubscriptionList struct {
+	// in:body
+	Body []api.CatalogSubscription `json:"body"`
+}
+
+// CatalogDownloadStats
+// swagger:response CatalogDownloadStats
+type swaggerResponseCatalogDownloadStats struct {
+	// in:body
+	Body api.CatalogDownloadStats `json:"body"`
+}
+
+// ConvertedMetadata
+// swagger:response ConvertedMetadata
+type swaggerResponseConvertedMetadata struct {
+	// in:body
+	Body api.ConvertedMetadata `json:"body"`
+}
+
+// TranslationProgress
+// swagger:response TranslationProgress
+type swaggerResponseTranslationProgress struct {
+	// in:body
+	Body api.TranslationProgress `json:"body"`
+}
+
+// TranslationProgressReport
+// swagger:response TranslationProgressReport
+type swaggerResponseTranslationProgressReport struct {
+	// in:body
+	Body api.TranslationProgressReport `json:"body"`
+}
+
+// CatalogLanguageList
+// swagger:response CatalogLanguageList
+type swaggerResponseCatalogLanguageList struct {
+	// in:body
+	Body []api.CatalogLanguage `json:"body"`
+}
+
+// CatalogLanguageOverview
+// swagger:response CatalogLanguageOverview
+type swaggerResponseCatalogLanguageOverview struct {
+	// in:body
+	Body api.CatalogLanguageOverview `json:"body"`
+}
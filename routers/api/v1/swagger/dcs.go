@@ -9,3 +9,10 @@ type swaggerResponseLangnamesJSON struct {
 	// in:body
 	Body map[string]interface{} `json:"body"`
 }
+
+// ReleaseAttachmentsSchema
+// swagger:response ReleaseAttachmentsSchema
+type swaggerResponseReleaseAttachmentsSchema struct {
+	// in:body
+	Body map[string]interface{} `json:"body"`
+}
@@ -196,4 +196,34 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	CreateOrUpdateSecretOption api.CreateOrUpdateSecretOption
+
+	/*** DCS Customizations ***/
+
+	// in:body
+	CreateCatalogCheckOption api.CreateCatalogCheckOption
+
+	// in:body
+	EditCatalogCheckerOption api.EditCatalogCheckerOption
+
+	// in:body
+	CatalogGraphQLRequest api.CatalogGraphQLRequest
+
+	// in:body
+	CreateCatalogCollectionOption api.CreateCatalogCollectionOption
+
+	// in:body
+	EditCatalogCollectionOption api.EditCatalogCollectionOption
+
+	// in:body
+	CreateCatalogCollectionVersionOption api.CreateCatalogCollectionVersionOption
+
+	// in:body
+	CreateCatalogSubscriptionOption api.CreateCatalogSubscriptionOption
+
+	// in:body
+	EditCatalogSubscriptionOption api.EditCatalogSubscriptionOption
+
+	// in:body
+	ConvertMetadataOption api.ConvertMetadataOption
+	/*** END DCS Customizations ***/
 }
@@ -145,6 +145,9 @@ func InitWebInstalled(ctx context.Context) {
 	mustInitCtx(ctx, models.Init)
 	mustInitCtx(ctx, authmodel.Init)
 	mustInitCtx(ctx, repo_service.Init)
+	/*** DCS Customizations ***/
+	mustInitCtx(ctx, door43metadata.InitDB)
+	/*** END DCS Customizations ***/
 
 	// Booting long running goroutines.
 	mustInit(indexer_service.Init)
@@ -38,7 +38,7 @@ func SetDefaultBranch(ctx *gitea_context.PrivateContext) {
 	}
 
 	/*** DCS Customizations ***/
-	if err := door43metadata_service.ProcessDoor43MetadataForRepo(ctx, ctx.Repo.Repository, branch); err != nil {
+	if err := door43metadata_service.ProcessDoor43MetadataForRepo(ctx, ctx.Repo.Repository, branch, repo_model.Door43MetadataTriggerDefaultBranch); err != nil {
 		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"Err": fmt.Sprintf("Unable to process default branch on repository: %s/%s Error: %v", ownerName, repoName, err),
 		})
@@ -6,15 +6,20 @@
 package dcs
 
 import (
+	"fmt"
+	"net/url"
 	"strings"
 
 	"code.gitea.io/gitea/models"
+
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/door43metadata"
 	"code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/dcs"
 	"code.gitea.io/gitea/modules/setting"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
 )
 
 const (
@@ -44,6 +49,8 @@ func RenderCatalogSearch(ctx *context.Context, opts *CatalogSearchOptions) {
 
 	ctx.Data["SortType"] = ctx.FormString("sort")
 	switch ctx.FormString("sort") {
+	case "relevance":
+		orderBy = door43metadata.CatalogOrderByRelevance
 	case "newest":
 		orderBy = door43metadata.CatalogOrderByNewest
 	case "oldest":
@@ -85,19 +92,80 @@ func RenderCatalogSearch(ctx *context.Context, opts *CatalogSearchOptions) {
 	case "fewestforks":
 		orderBy = door43metadata.CatalogOrderByForks
 	default:
-		ctx.Data["SortType"] = "newest"
-		orderBy = door43metadata.CatalogOrderByNewest
+		ctx.Data["SortType"] = ""
 	}
 
-	var keywords, books, langs, subjects, resources, contentFormats, repos, owners, tags, checkingLevels, metadataTypes, metadataVersions []string
-	stage := door43metadata.StageProd
 	query := strings.Trim(ctx.FormString("q"), " ")
+	searchOpts := getCatalogSearchOptions(query)
+
+	if orderBy == "" {
+		// Rank by relevance by default when searching with keywords
+		if len(searchOpts.Keywords) > 0 {
+			ctx.Data["SortType"] = "relevance"
+			orderBy = door43metadata.CatalogOrderByRelevance
+		} else {
+			ctx.Data["SortType"] = "newest"
+			orderBy = door43metadata.CatalogOrderByNewest
+		}
+	}
+	orderBys := []door43metadata.CatalogOrderBy{orderBy}
+	if orderBy == door43metadata.CatalogOrderByRelevance {
+		// Equally ranked entries, or all if the catalog indexer is not available, are shown newest first
+		orderBys = append(orderBys, door43metadata.CatalogOrderByNewest)
+	}
+
+	searchOpts.ListOptions = db.ListOptions{
+		Page:     page,
+		PageSize: opts.PageSize,
+	}
+	searchOpts.OrderBy = orderBys
+	dms, count, err = door43metadata_service.SearchCatalog(ctx, searchOpts)
+	if err != nil {
+		ctx.ServerError("SearchCatalog", err)
+		return
+	}
+	facetCounts, err := door43metadata_service.CountCatalogFacets(ctx, searchOpts, door43metadata.CatalogFacets)
+	if err != nil {
+		ctx.ServerError("CountCatalogFacets", err)
+		return
+	}
+
+	ctx.Data["Keyword"] = query
+	ctx.Data["Total"] = count
+	ctx.Data["CatalogFacets"] = getCatalogFacets(ctx, query, facetCounts)
+	ctx.Data["Door43Metadatas"] = dms
+	ctx.Data["IsRepoIndexerEnabled"] = setting.Indexer.RepoIndexerEnabled
+
+	pager := context.NewPagination(int(count), opts.PageSize, page, 5)
+	pager.SetDefaultParams(ctx)
+	pager.AddParam(ctx, "topic", "TopicOnly")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(200, opts.TplName)
+}
+
+// getCatalogSearchOptions returns the options of a catalog search for the keywords and filter tokens
+// (e.g. lang:en, subject:"Open Bible Stories", book:gen, metadata.dublin_core.publisher:unfoldingWord)
+// of the query given on the catalog page
+func getCatalogSearchOptions(query string) *door43metadata.SearchCatalogOptions {
+	var metadataFilters []*door43metadata.MetadataFilter
+	metadataFilterByPath := make(map[string]*door43metadata.MetadataFilter)
+	var keywords, books, langs, regions, countries, subjects, resources, contentFormats, repos, owners, tags, checkingLevels, metadataTypes, metadataVersions []string
+	var verifiedLevels []int
+	stage := door43metadata.StageProd
+	includeGLs := false
 	if query != "" {
 		for _, token := range door43metadata.SplitAtCommaNotInString(query, true) {
 			if strings.HasPrefix(token, "book:") {
 				books = append(books, strings.TrimPrefix(token, "book:"))
 			} else if strings.HasPrefix(token, "lang:") {
 				langs = append(langs, strings.TrimPrefix(token, "lang:"))
+			} else if strings.HasPrefix(token, "region:") {
+				regions = append(regions, strings.Trim(strings.TrimPrefix(token, "region:"), `"`))
+			} else if strings.HasPrefix(token, "country:") {
+				countries = append(countries, strings.TrimPrefix(token, "country:"))
+			} else if token == "include:gl" {
+				includeGLs = true
 			} else if strings.HasPrefix(token, "subject:") {
 				subjects = append(subjects, strings.Trim(strings.TrimPrefix(token, "subject:"), `"`))
 			} else if strings.HasPrefix(token, "resource:") {
@@ -110,12 +178,29 @@ func RenderCatalogSearch(ctx *context.Context, opts *CatalogSearchOptions) {
 				owners = append(owners, strings.TrimPrefix(token, "owner:"))
 			} else if strings.HasPrefix(token, "tag:") {
 				tags = append(tags, strings.TrimPrefix(token, "tag:"))
+			} else if strings.HasPrefix(token, "verifiedcheckinglevel:") {
+				// invalid levels are skipped rather than passed on to the integer column
+				if levels, err := door43metadata.ParseCheckingLevels([]string{strings.TrimPrefix(token, "verifiedcheckinglevel:")}); err == nil {
+					verifiedLevels = append(verifiedLevels, levels...)
+				}
 			} else if strings.HasPrefix(token, "checkinglevel:") {
 				checkingLevels = append(checkingLevels, strings.TrimPrefix(token, "checkinglevel:"))
 			} else if strings.HasPrefix(token, "metadata_type:") {
 				metadataTypes = append(metadataTypes, strings.TrimPrefix(token, "metadata_type:"))
 			} else if strings.HasPrefix(token, "metadata_version:") {
 				metadataVersions = append(metadataVersions, strings.TrimPrefix(token, "metadata_version:"))
+			} else if path, value, ok := strings.Cut(token, ":"); ok && strings.HasPrefix(path, door43metadata.MetadataFilterPrefix) {
+				value = strings.TrimSpace(strings.Trim(value, `"`))
+				if filter, ok := metadataFilterByPath[path]; ok {
+					if value != "" {
+						filter.Values = append(filter.Values, value)
+					}
+				} else if filter, err := door43metadata.ParseMetadataFilter(path, []string{value}); err == nil {
+					metadataFilterByPath[path] = filter
+					metadataFilters = append(metadataFilters, filter)
+				} else {
+					keywords = append(keywords, token)
+				}
 			} else if strings.HasPrefix(token, "stage:") {
 				if s, ok := door43metadata.StageMap[strings.Trim(strings.TrimPrefix(token, "stage:"), `"`)]; ok {
 					stage = s
@@ -128,47 +213,117 @@ func RenderCatalogSearch(ctx *context.Context, opts *CatalogSearchOptions) {
 		}
 	}
 
-	dms, count, err = models.SearchCatalog(ctx, &door43metadata.SearchCatalogOptions{
-		ListOptions: db.ListOptions{
-			Page:     page,
-			PageSize: opts.PageSize,
-		},
-		OrderBy:          []door43metadata.CatalogOrderBy{orderBy},
+	return &door43metadata.SearchCatalogOptions{
 		Keywords:         keywords,
 		Stage:            stage,
 		IncludeHistory:   false,
 		Books:            books,
+		MetadataFilters:  metadataFilters,
 		Subjects:         subjects,
 		Resources:        resources,
 		ContentFormats:   contentFormats,
 		Languages:        langs,
+		Regions:          regions,
+		Countries:        countries,
+		IncludeGLs:       includeGLs,
 		Repos:            repos,
 		Owners:           owners,
 		MetadataTypes:    metadataTypes,
 		MetadataVersions: metadataVersions,
 		Tags:             tags,
 		CheckingLevels:   checkingLevels,
-	})
-	if err != nil {
-		ctx.ServerError("SearchCatalog", err)
-		return
+		VerifiedLevels:   verifiedLevels,
 	}
-	ctx.Data["Keyword"] = query
-	ctx.Data["Total"] = count
-	ctx.Data["Door43Metadatas"] = dms
-	ctx.Data["IsRepoIndexerEnabled"] = setting.Indexer.RepoIndexerEnabled
+}
 
-	pager := context.NewPagination(int(count), opts.PageSize, page, 5)
-	pager.SetDefaultParams(ctx)
-	pager.AddParam(ctx, "topic", "TopicOnly")
-	ctx.Data["Page"] = pager
+// maxCatalogFacetValues is the maximum number of values shown for each facet
+const maxCatalogFacetValues = 10
 
-	ctx.HTML(200, opts.TplName)
+// catalogFacetTokens are the search tokens that filter by the value of a facet
+var catalogFacetTokens = map[door43metadata.CatalogFacet]string{
+	door43metadata.CatalogFacetSubject:       `subject:"%s"`,
+	door43metadata.CatalogFacetLanguage:      "lang:%s",
+	door43metadata.CatalogFacetOwner:         "owner:%s",
+	door43metadata.CatalogFacetMetadataType:  "metadata_type:%s",
+	door43metadata.CatalogFacetCheckingLevel: "checkinglevel:%s",
+	door43metadata.CatalogFacetBook:          "book:%s",
+}
+
+// catalogFacetLabels are the locale keys of the facet names
+var catalogFacetLabels = map[door43metadata.CatalogFacet]string{
+	door43metadata.CatalogFacetSubject:       "repo.metadata.subject",
+	door43metadata.CatalogFacetLanguage:      "repo.metadata.language",
+	door43metadata.CatalogFacetOwner:         "repo.metadata.owner",
+	door43metadata.CatalogFacetMetadataType:  "repo.metadata.metadata_type",
+	door43metadata.CatalogFacetCheckingLevel: "repo.metadata.checking_level",
+	door43metadata.CatalogFacetBook:          "repo.metadata.books",
+}
+
+// CatalogFacet is a facet shown on the catalog page with the number of entries for its most common values
+type CatalogFacet struct {
+	Label  string
+	Values []*CatalogFacetValue
+}
+
+// CatalogFacetValue is a value of a facet, linking to the search filtered by it, or unfiltered if already filtered by it
+type CatalogFacetValue struct {
+	Value    string
+	Count    int64
+	Link     string
+	IsActive bool
+}
+
+func getCatalogFacets(ctx *context.Context, query string, facetCounts map[door43metadata.CatalogFacet][]*models.CatalogFacetCount) []*CatalogFacet {
+	var tokens []string
+	if query != "" {
+		tokens = door43metadata.SplitAtCommaNotInString(query, true)
+	}
+
+	facets := make([]*CatalogFacet, 0, len(door43metadata.CatalogFacets))
+	for _, facet := range door43metadata.CatalogFacets {
+		counts := facetCounts[facet]
+		if len(counts) == 0 {
+			continue
+		}
+		if len(counts) > maxCatalogFacetValues {
+			counts = counts[:maxCatalogFacetValues]
+		}
+		f := &CatalogFacet{Label: ctx.Tr(catalogFacetLabels[facet])}
+		for _, count := range counts {
+			token := fmt.Sprintf(catalogFacetTokens[facet], count.Value)
+			value := &CatalogFacetValue{Value: count.Value, Count: count.Count}
+			newTokens := make([]string, 0, len(tokens)+1)
+			for _, t := range tokens {
+				if strings.EqualFold(t, token) {
+					value.IsActive = true
+				} else {
+					newTokens = append(newTokens, t)
+				}
+			}
+			if !value.IsActive {
+				newTokens = append(newTokens, token)
+			}
+			switch facet {
+			case door43metadata.CatalogFacetLanguage:
+				if title := dcs.GetLanguageTitle(count.Value); title != "" {
+					value.Value = fmt.Sprintf("%s (%s)", title, count.Value)
+				}
+			case door43metadata.CatalogFacetCheckingLevel:
+				value.Value = ctx.Tr("repo.metadata.checking_level") + " " + count.Value
+			}
+			value.Link = fmt.Sprintf("%s?sort=%s&q=%s", ctx.Link, url.QueryEscape(ctx.FormString("sort")), url.QueryEscape(strings.Join(newTokens, ", ")))
+			f.Values = append(f.Values, value)
+		}
+		facets = append(facets, f)
+	}
+	return facets
 }
 
 // Catalog render catalog page
 func Catalog(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("catalog")
+	ctx.Data["OPDSFeedURL"] = setting.AppURL + "catalog/opds"
+	ctx.Data["FeedURL"] = setting.AppSubURL + "/catalog"
 	ctx.Data["PageIsCatalog"] = true
 	ctx.Data["IsRepoIndexerEnabled"] = setting.Indexer.RepoIndexerEnabled
 
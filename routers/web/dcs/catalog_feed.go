@@ -0,0 +1,87 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package dcs
+
+import (
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/web/feed"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
+)
+
+// CatalogFeedRSS renders the newest releases of the catalog matching the query as RSS feed
+func CatalogFeedRSS(ctx *context.Context) {
+	showCatalogFeed(ctx, nil, "rss")
+}
+
+// CatalogFeedAtom renders the newest releases of the catalog matching the query as Atom feed
+func CatalogFeedAtom(ctx *context.Context) {
+	showCatalogFeed(ctx, nil, "atom")
+}
+
+// OwnerCatalogFeed renders the newest releases of an owner matching the query as RSS or Atom feed,
+// depending on the extension of the owner's name in the path
+func OwnerCatalogFeed(ctx *context.Context) {
+	isFeed, name, formatType := feed.GetFeedType(ctx.Params("username"), ctx.Req)
+	if !isFeed {
+		ctx.NotFound("GetFeedType", nil)
+		return
+	}
+	owner, err := user_model.GetUserByName(ctx, name)
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			ctx.NotFound("GetUserByName", err)
+		} else {
+			ctx.ServerError("GetUserByName", err)
+		}
+		return
+	}
+	if !user_model.IsUserVisibleToViewer(ctx, owner, ctx.Doer) {
+		ctx.NotFound("IsUserVisibleToViewer", nil)
+		return
+	}
+	showCatalogFeed(ctx, owner, formatType)
+}
+
+// showCatalogFeed renders the newest releases matching the catalog page's query, only of the owner if given
+func showCatalogFeed(ctx *context.Context, owner *user_model.User, formatType string) {
+	query := strings.Trim(ctx.FormString("q"), " ")
+	searchOpts := getCatalogSearchOptions(query)
+	searchOpts.ListOptions = db.ListOptions{
+		Page:     1,
+		PageSize: setting.UI.FeedPagingNum,
+	}
+	searchOpts.OrderBy = []door43metadata.CatalogOrderBy{door43metadata.CatalogOrderByNewest}
+
+	title := ctx.Tr("repo.metadata.feed.title", setting.AppName)
+	link := setting.AppURL + "catalog"
+	if owner != nil {
+		searchOpts.Owners = []string{owner.Name}
+		title = ctx.Tr("repo.metadata.feed.owner_title", owner.DisplayName(), setting.AppName)
+		if query == "" {
+			query = "owner:" + owner.Name
+		} else {
+			query = "owner:" + owner.Name + ", " + query
+		}
+	}
+	var description string
+	if query != "" {
+		link += "?q=" + url.QueryEscape(query)
+		description = ctx.Tr("repo.metadata.feed.query", query)
+	}
+
+	dms, _, err := door43metadata_service.SearchCatalog(ctx, searchOpts)
+	if err != nil {
+		ctx.ServerError("SearchCatalog", err)
+		return
+	}
+
+	feed.ShowCatalogFeed(ctx, dms, title, link, description, formatType)
+}
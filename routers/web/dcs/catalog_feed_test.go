@@ -0,0 +1,75 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package dcs
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/contexttest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnerCatalogFeed(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	assert.NoError(t, db.Insert(db.DefaultContext, &repo_model.Door43Metadata{
+		RepoID: 1, ReleaseID: 1, Ref: "v1.1", RefType: "tag", CommitSHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		Stage: door43metadata.StageProd, MetadataType: "rc", Title: "Test Entry", Language: "en", Subject: "Bible",
+		ReleaseDateUnix: 1000, IsLatestForStage: true,
+	}))
+
+	ownerFeed := func(username, query string) (int, []string) {
+		ctx, resp := contexttest.MockContext(t, "catalog/"+username)
+		ctx.SetParams("username", username)
+		if query != "" {
+			ctx.Req.Form.Set("q", query)
+		}
+		OwnerCatalogFeed(ctx)
+		if resp.Code != http.StatusOK {
+			return resp.Code, nil
+		}
+		rss := &struct {
+			Items []struct {
+				Title string `xml:"title"`
+			} `xml:"channel>item"`
+		}{}
+		assert.NoError(t, xml.Unmarshal(resp.Body.Bytes(), rss))
+		titles := make([]string, 0, len(rss.Items))
+		for _, item := range rss.Items {
+			titles = append(titles, item.Title)
+		}
+		return resp.Code, titles
+	}
+
+	code, titles := ownerFeed("user2.rss", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"Test Entry v1.1 (en)"}, titles)
+
+	code, titles = ownerFeed("user2.rss", "subject:Bible, lang:en")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"Test Entry v1.1 (en)"}, titles)
+
+	code, titles = ownerFeed("user2.rss", "lang:fr")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, titles)
+
+	// the entries of other owners are not included
+	code, titles = ownerFeed("user5.rss", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, titles)
+
+	// private owners are hidden from anonymous viewers
+	code, _ = ownerFeed("privated_org.rss", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = ownerFeed("user2", "")
+	assert.Equal(t, http.StatusNotFound, code)
+}
@@ -0,0 +1,185 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package dcs
+
+import (
+	"net/http"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
+)
+
+const (
+	// tplCollections collections of an owner page template.
+	tplCollections base.TplName = "catalog/collections"
+	// tplCollection collection version page template.
+	tplCollection base.TplName = "catalog/collection"
+)
+
+// Collections renders the collections of an owner
+func Collections(ctx *context.Context) {
+	owner := getCollectionOwner(ctx)
+	if ctx.Written() {
+		return
+	}
+	collections, err := repo_model.GetDoor43CollectionsByOwnerID(ctx, owner.ID)
+	if err != nil {
+		ctx.ServerError("GetDoor43CollectionsByOwnerID", err)
+		return
+	}
+	for _, collection := range collections {
+		collection.Owner = owner
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.metadata.collections") + " - " + owner.DisplayName()
+	ctx.Data["Owner"] = owner
+	ctx.Data["Collections"] = collections
+	ctx.HTML(http.StatusOK, tplCollections)
+}
+
+// Collection renders a version of a collection with its members, the most recent version if none is given
+func Collection(ctx *context.Context) {
+	collection := getCollection(ctx)
+	if ctx.Written() {
+		return
+	}
+	versions, err := repo_model.GetDoor43CollectionVersions(ctx, collection.ID)
+	if err != nil {
+		ctx.ServerError("GetDoor43CollectionVersions", err)
+		return
+	}
+
+	ctx.Data["Title"] = collection.Title
+	ctx.Data["Collection"] = collection
+	ctx.Data["Versions"] = versions
+
+	if len(versions) > 0 {
+		version := versions[0]
+		if ctx.Params("version") != "" {
+			version, err = repo_model.GetDoor43CollectionVersion(ctx, collection.ID, ctx.Params("version"))
+			if err != nil {
+				if repo_model.IsErrDoor43CollectionVersionNotExist(err) {
+					ctx.NotFound("GetDoor43CollectionVersion", err)
+				} else {
+					ctx.ServerError("GetDoor43CollectionVersion", err)
+				}
+				return
+			}
+		}
+		members, err := door43metadata_service.GetCollectionVersionMembers(ctx, ctx.Doer, version)
+		if err != nil {
+			ctx.ServerError("GetCollectionVersionMembers", err)
+			return
+		}
+		for _, m := range members {
+			if m.Entry != nil {
+				if err := m.Entry.LoadAttributes(ctx); err != nil {
+					ctx.ServerError("LoadAttributes", err)
+					return
+				}
+			}
+		}
+		ctx.Data["Version"] = version
+		ctx.Data["IsLatestVersion"] = version.ID == versions[0].ID
+		ctx.Data["Members"] = members
+	} else if ctx.Params("version") != "" {
+		ctx.NotFound("GetDoor43CollectionVersion", nil)
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplCollection)
+}
+
+// CollectionDownload downloads the contents of all the members of a collection version as one zip archive
+func CollectionDownload(ctx *context.Context) {
+	collection := getCollection(ctx)
+	if ctx.Written() {
+		return
+	}
+	version, err := repo_model.GetDoor43CollectionVersion(ctx, collection.ID, ctx.Params("version"))
+	if err != nil {
+		if repo_model.IsErrDoor43CollectionVersionNotExist(err) {
+			ctx.NotFound("GetDoor43CollectionVersion", err)
+		} else {
+			ctx.ServerError("GetDoor43CollectionVersion", err)
+		}
+		return
+	}
+	members, err := door43metadata_service.GetCollectionVersionMembers(ctx, ctx.Doer, version)
+	if err != nil {
+		ctx.ServerError("GetCollectionVersionMembers", err)
+		return
+	}
+	rPath, err := door43metadata_service.PrepareCollectionArchive(ctx, collection, version, members)
+	if err != nil {
+		if door43metadata_service.IsErrCollectionArchiveTooLarge(err) {
+			ctx.Error(http.StatusUnprocessableEntity, err.Error())
+		} else {
+			ctx.ServerError("PrepareCollectionArchive", err)
+		}
+		return
+	}
+
+	downloadName := door43metadata_service.GetCollectionArchiveName(collection, version)
+	if setting.RepoArchive.Storage.MinioConfig.ServeDirect {
+		// If we have a signed url (S3, object storage), redirect to this directly.
+		u, err := storage.RepoArchives.URL(rPath, downloadName)
+		if u != nil && err == nil {
+			ctx.Redirect(u.String())
+			return
+		}
+	}
+	fr, err := storage.RepoArchives.Open(rPath)
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer fr.Close()
+	ctx.ServeContent(fr, &context.ServeHeaderOptions{
+		Filename:     downloadName,
+		LastModified: version.CreatedUnix.AsLocalTime(),
+	})
+}
+
+// getCollectionOwner gets the owner in the path if visible to the doer
+func getCollectionOwner(ctx *context.Context) *user_model.User {
+	owner, err := user_model.GetUserByName(ctx, ctx.Params("username"))
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			ctx.NotFound("GetUserByName", err)
+		} else {
+			ctx.ServerError("GetUserByName", err)
+		}
+		return nil
+	}
+	if !user_model.IsUserVisibleToViewer(ctx, owner, ctx.Doer) {
+		ctx.NotFound("IsUserVisibleToViewer", nil)
+		return nil
+	}
+	return owner
+}
+
+// getCollection gets the collection of the owner and name in the path
+func getCollection(ctx *context.Context) *repo_model.Door43Collection {
+	owner := getCollectionOwner(ctx)
+	if ctx.Written() {
+		return nil
+	}
+	collection, err := repo_model.GetDoor43CollectionByOwnerAndName(ctx, owner.ID, ctx.Params("collection"))
+	if err != nil {
+		if repo_model.IsErrDoor43CollectionNotExist(err) {
+			ctx.NotFound("GetDoor43CollectionByOwnerAndName", err)
+		} else {
+			ctx.ServerError("GetDoor43CollectionByOwnerAndName", err)
+		}
+		return nil
+	}
+	collection.Owner = owner
+	return collection
+}
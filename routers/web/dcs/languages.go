@@ -0,0 +1,92 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package dcs
+
+import (
+	"net/http"
+	"sort"
+
+	"code.gitea.io/gitea/models/door43metadata"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/convert"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
+)
+
+const (
+	// tplLanguages languages of the catalog page template.
+	tplLanguages base.TplName = "catalog/languages"
+	// tplLanguage language portal page template.
+	tplLanguage base.TplName = "catalog/language"
+)
+
+// LanguageRegion are the languages of the catalog of a region
+type LanguageRegion struct {
+	Region    string
+	Languages []*api.CatalogLanguage
+}
+
+// Languages renders the languages of the catalog grouped by region
+func Languages(ctx *context.Context) {
+	counts, err := door43metadata_service.CountCatalogLanguages(ctx, &door43metadata.SearchCatalogOptions{Stage: door43metadata.StageLatest})
+	if err != nil {
+		ctx.ServerError("CountCatalogLanguages", err)
+		return
+	}
+
+	regions := make([]*LanguageRegion, 0, 8)
+	byRegion := make(map[string]*LanguageRegion)
+	for _, count := range counts {
+		language := convert.ToCatalogLanguage(count.Value, count.Count)
+		region, ok := byRegion[language.Region]
+		if !ok {
+			region = &LanguageRegion{Region: language.Region}
+			byRegion[language.Region] = region
+			regions = append(regions, region)
+		}
+		region.Languages = append(region.Languages, language)
+	}
+	// Languages without a region, e.g. not in langnames.json, come last
+	sort.SliceStable(regions, func(i, j int) bool {
+		if regions[i].Region == "" || regions[j].Region == "" {
+			return regions[j].Region == ""
+		}
+		return regions[i].Region < regions[j].Region
+	})
+	for _, region := range regions {
+		sort.SliceStable(region.Languages, func(i, j int) bool {
+			return region.Languages[i].Code < region.Languages[j].Code
+		})
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.metadata.languages")
+	ctx.Data["LanguageCount"] = len(counts)
+	ctx.Data["Regions"] = regions
+	ctx.HTML(http.StatusOK, tplLanguages)
+}
+
+// Language renders the details of a language and everything the catalog has in it, of all stages
+func Language(ctx *context.Context) {
+	overview, err := door43metadata_service.GetLanguageOverview(ctx, ctx.Params("lang"), door43metadata.StageLatest)
+	if err != nil {
+		ctx.ServerError("GetLanguageOverview", err)
+		return
+	}
+	if overview == nil {
+		ctx.NotFound("GetLanguageOverview", nil)
+		return
+	}
+
+	var entryCount int64
+	for _, s := range overview.Subjects {
+		entryCount += int64(len(s.Entries))
+	}
+	language := convert.ToCatalogLanguage(overview.Language, entryCount)
+
+	ctx.Data["Title"] = language.Name + " (" + language.Code + ")"
+	ctx.Data["Language"] = language
+	ctx.Data["Overview"] = overview
+	ctx.HTML(http.StatusOK, tplLanguage)
+}
@@ -0,0 +1,461 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package dcs
+
+import (
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/opds"
+	"code.gitea.io/gitea/modules/setting"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
+)
+
+// opdsPageSize is the number of publications of each page of an OPDS acquisition feed
+const opdsPageSize = 50
+
+// opdsFeed is a navigation or acquisition feed of the catalog, rendered as OPDS 1.2 or OPDS 2.0
+type opdsFeed struct {
+	Path       string // path of the feed relative to the OPDS root
+	Title      string
+	Up         string // path of the parent feed, if any
+	Navigation []*opdsNavigationItem
+	DMs        []*repo_model.Door43Metadata
+	Total      int64
+	Page       int
+	Query      string // extra query of the feed's links, e.g. the search keywords
+}
+
+// opdsNavigationItem is a link to a sub-feed of a navigation feed
+type opdsNavigationItem struct {
+	Title       string
+	Path        string
+	Count       int64
+	Acquisition bool // whether the sub-feed is an acquisition feed rather than a navigation feed
+}
+
+// OPDSVersion sets the OPDS version of the feeds rendered by the handlers of the route group
+func OPDSVersion(version int) func(ctx *context.Context) {
+	return func(ctx *context.Context) {
+		ctx.Data["OPDSVersion"] = version
+	}
+}
+
+func isOPDS2(ctx *context.Context) bool {
+	version, _ := ctx.Data["OPDSVersion"].(int)
+	return version == 2
+}
+
+func opdsBaseURL(ctx *context.Context) string {
+	if isOPDS2(ctx) {
+		return setting.AppURL + "catalog/opds2"
+	}
+	return setting.AppURL + "catalog/opds"
+}
+
+// OPDSRoot renders the root navigation feed of the catalog
+func OPDSRoot(ctx *context.Context) {
+	renderOPDSFeed(ctx, &opdsFeed{
+		Title: ctx.Tr("repo.metadata.opds.title", setting.AppName),
+		Navigation: []*opdsNavigationItem{
+			{Title: ctx.Tr("repo.metadata.opds.all"), Path: "/search", Acquisition: true},
+			{Title: ctx.Tr("repo.metadata.opds.by_language"), Path: "/languages"},
+			{Title: ctx.Tr("repo.metadata.opds.by_subject"), Path: "/subjects"},
+		},
+	})
+}
+
+// OPDSLanguages renders the navigation feed of the languages of the catalog
+func OPDSLanguages(ctx *context.Context) {
+	renderOPDSFacetFeed(ctx, door43metadata.CatalogFacetLanguage, "/languages", ctx.Tr("repo.metadata.opds.by_language"))
+}
+
+// OPDSSubjects renders the navigation feed of the subjects of the catalog
+func OPDSSubjects(ctx *context.Context) {
+	renderOPDSFacetFeed(ctx, door43metadata.CatalogFacetSubject, "/subjects", ctx.Tr("repo.metadata.opds.by_subject"))
+}
+
+func renderOPDSFacetFeed(ctx *context.Context, facet door43metadata.CatalogFacet, feedPath, title string) {
+	facetCounts, err := door43metadata_service.CountCatalogFacets(ctx, &door43metadata.SearchCatalogOptions{
+		Stage: door43metadata.StageProd,
+	}, []door43metadata.CatalogFacet{facet})
+	if err != nil {
+		ctx.ServerError("CountCatalogFacets", err)
+		return
+	}
+
+	feed := &opdsFeed{Path: feedPath, Title: title, Up: "/"}
+	for _, count := range facetCounts[facet] {
+		itemTitle := count.Value
+		if facet == door43metadata.CatalogFacetLanguage {
+			if langTitle := dcs.GetLanguageTitle(count.Value); langTitle != "" {
+				itemTitle = fmt.Sprintf("%s (%s)", langTitle, count.Value)
+			}
+		}
+		feed.Navigation = append(feed.Navigation, &opdsNavigationItem{
+			Title:       itemTitle,
+			Path:        feedPath + "/" + url.PathEscape(count.Value),
+			Count:       count.Count,
+			Acquisition: true,
+		})
+	}
+	renderOPDSFeed(ctx, feed)
+}
+
+// OPDSLanguage renders the acquisition feed of the entries of a language
+func OPDSLanguage(ctx *context.Context) {
+	lang := ctx.Params("lang")
+	title := lang
+	if langTitle := dcs.GetLanguageTitle(lang); langTitle != "" {
+		title = fmt.Sprintf("%s (%s)", langTitle, lang)
+	}
+	renderOPDSAcquisitionFeed(ctx, &opdsFeed{
+		Path:  "/languages/" + url.PathEscape(lang),
+		Title: title,
+		Up:    "/languages",
+	}, &door43metadata.SearchCatalogOptions{Languages: []string{lang}})
+}
+
+// OPDSSubject renders the acquisition feed of the entries of a subject
+func OPDSSubject(ctx *context.Context) {
+	subject := ctx.Params("subject")
+	renderOPDSAcquisitionFeed(ctx, &opdsFeed{
+		Path:  "/subjects/" + url.PathEscape(subject),
+		Title: subject,
+		Up:    "/subjects",
+	}, &door43metadata.SearchCatalogOptions{Subjects: []string{subject}})
+}
+
+// OPDSSearch renders the acquisition feed of the entries matching the keywords, or of all entries if none
+func OPDSSearch(ctx *context.Context) {
+	query := strings.TrimSpace(ctx.FormString("q"))
+	if query == "" {
+		query = strings.TrimSpace(ctx.FormString("query"))
+	}
+	feed := &opdsFeed{Path: "/search", Title: ctx.Tr("repo.metadata.opds.all"), Up: "/"}
+	opts := &door43metadata.SearchCatalogOptions{}
+	if query != "" {
+		feed.Title = ctx.Tr("repo.metadata.opds.search_results", query)
+		feed.Query = "q=" + url.QueryEscape(query)
+		opts.Keywords = door43metadata.SplitAtCommaNotInString(query, false)
+	}
+	renderOPDSAcquisitionFeed(ctx, feed, opts)
+}
+
+// OPDSOpenSearch renders the OpenSearch description of the OPDS catalog
+func OPDSOpenSearch(ctx *context.Context) {
+	writeOPDSXML(ctx, opds.TypeOpenSearch, &opds.OpenSearchDescription{
+		Xmlns:          opds.NamespaceOpenSearch,
+		ShortName:      setting.AppName,
+		Description:    ctx.Tr("repo.metadata.opds.title", setting.AppName),
+		InputEncoding:  "UTF-8",
+		OutputEncoding: "UTF-8",
+		URLs: []*opds.OpenSearchURL{
+			{Type: opds.TypeAcquisition, Template: setting.AppURL + "catalog/opds/search?q={searchTerms}"},
+			{Type: opds.TypeOPDS2, Template: setting.AppURL + "catalog/opds2/search?q={searchTerms}"},
+		},
+		Images: []*opds.OpenSearchIcon{
+			{Type: "image/png", Value: setting.AppURL + "assets/img/favicon.png"},
+		},
+	})
+}
+
+func renderOPDSAcquisitionFeed(ctx *context.Context, feed *opdsFeed, opts *door43metadata.SearchCatalogOptions) {
+	feed.Page = ctx.FormInt("page")
+	if feed.Page < 1 {
+		feed.Page = 1
+	}
+	opts.ListOptions = db.ListOptions{Page: feed.Page, PageSize: opdsPageSize}
+	opts.Stage = door43metadata.StageProd
+	opts.OrderBy = []door43metadata.CatalogOrderBy{door43metadata.CatalogOrderByNewest}
+	if len(opts.Keywords) > 0 {
+		opts.OrderBy = append([]door43metadata.CatalogOrderBy{door43metadata.CatalogOrderByRelevance}, opts.OrderBy...)
+	}
+
+	dms, count, err := door43metadata_service.SearchCatalog(ctx, opts)
+	if err != nil {
+		ctx.ServerError("SearchCatalog", err)
+		return
+	}
+	feed.DMs = dms
+	feed.Total = count
+	renderOPDSFeed(ctx, feed)
+}
+
+func renderOPDSFeed(ctx *context.Context, feed *opdsFeed) {
+	updated := time.Now()
+	if len(feed.DMs) > 0 {
+		updated = feed.DMs[0].ReleaseDateUnix.AsTime()
+		for _, dm := range feed.DMs {
+			if dm.ReleaseDateUnix.AsTime().After(updated) {
+				updated = dm.ReleaseDateUnix.AsTime()
+			}
+		}
+	}
+	if isOPDS2(ctx) {
+		renderOPDS2Feed(ctx, feed, updated)
+	} else {
+		renderOPDS1Feed(ctx, feed, updated)
+	}
+}
+
+// opdsPageLinks returns the relations and paths of the first, previous, next and last pages of an acquisition feed
+func opdsPageLinks(feed *opdsFeed) map[string]string {
+	links := map[string]string{}
+	if feed.DMs == nil && feed.Total == 0 {
+		return links
+	}
+	lastPage := int((feed.Total + opdsPageSize - 1) / opdsPageSize)
+	if lastPage < 1 {
+		lastPage = 1
+	}
+	pageLink := func(page int) string {
+		query := fmt.Sprintf("page=%d", page)
+		if feed.Query != "" {
+			query = feed.Query + "&" + query
+		}
+		return feed.Path + "?" + query
+	}
+	links[opds.RelFirst] = pageLink(1)
+	links[opds.RelLast] = pageLink(lastPage)
+	if feed.Page > 1 {
+		links[opds.RelPrevious] = pageLink(feed.Page - 1)
+	}
+	if feed.Page < lastPage {
+		links[opds.RelNext] = pageLink(feed.Page + 1)
+	}
+	return links
+}
+
+func opdsSelfPath(feed *opdsFeed) string {
+	selfPath := feed.Path
+	var query []string
+	if feed.Query != "" {
+		query = append(query, feed.Query)
+	}
+	if feed.Page > 1 {
+		query = append(query, fmt.Sprintf("page=%d", feed.Page))
+	}
+	if len(query) > 0 {
+		selfPath += "?" + strings.Join(query, "&")
+	}
+	return selfPath
+}
+
+// opdsAcquisitionLink is a downloadable file of a catalog entry
+type opdsAcquisitionLink struct {
+	Href  string
+	Type  string
+	Title string
+}
+
+func getOPDSAcquisitionLinks(dm *repo_model.Door43Metadata) []*opdsAcquisitionLink {
+	links := []*opdsAcquisitionLink{
+		{Href: dm.GetZipballURL(), Type: "application/zip", Title: dm.Repo.Name + "-" + dm.Ref + ".zip"},
+		{Href: dm.GetTarballURL(), Type: "application/gzip", Title: dm.Repo.Name + "-" + dm.Ref + ".tar.gz"},
+	}
+	if url := dm.GetPackagedZipballURL(); url != "" {
+		links = append(links, &opdsAcquisitionLink{Href: url, Type: "application/zip", Title: dm.Repo.Name + "-" + path.Base(url)})
+	}
+	if dm.Release != nil {
+		for _, attachment := range dm.Release.Attachments {
+			mimeType := mime.TypeByExtension(path.Ext(attachment.Name))
+			if mimeType == "" {
+				mimeType = "application/octet-stream"
+			}
+			links = append(links, &opdsAcquisitionLink{Href: attachment.DownloadURL(), Type: mimeType, Title: attachment.Name})
+		}
+	}
+	return links
+}
+
+func getOPDSEntryHTMLURL(dm *repo_model.Door43Metadata) string {
+	if dm.Release != nil {
+		return dm.Release.HTMLURL()
+	}
+	return dm.Repo.HTMLURL() + "/src/branch/" + url.PathEscape(dm.Ref)
+}
+
+func getOPDSAuthorName(dm *repo_model.Door43Metadata) string {
+	if dm.Repo.Owner != nil {
+		return dm.Repo.Owner.DisplayName()
+	}
+	return dm.Repo.OwnerName
+}
+
+func getOPDSAuthorURL(dm *repo_model.Door43Metadata) string {
+	if dm.Repo.Owner != nil {
+		return dm.Repo.Owner.HTMLURL()
+	}
+	return setting.AppURL + url.PathEscape(dm.Repo.OwnerName)
+}
+
+func renderOPDS1Feed(ctx *context.Context, feed *opdsFeed, updated time.Time) {
+	baseURL := opdsBaseURL(ctx)
+	kind := opds.TypeNavigation
+	if feed.Navigation == nil {
+		kind = opds.TypeAcquisition
+	}
+
+	f := opds.NewFeed(baseURL+feed.Path, feed.Title, updated)
+	f.Author = &opds.Author{Name: setting.AppName, URI: setting.AppURL}
+	f.Icon = setting.AppURL + "assets/img/favicon.png"
+	f.Links = []*opds.Link{
+		{Rel: opds.RelSelf, Href: baseURL + opdsSelfPath(feed), Type: kind},
+		{Rel: opds.RelStart, Href: baseURL, Type: opds.TypeNavigation},
+		{Rel: opds.RelSearch, Href: baseURL + "/opensearch.xml", Type: opds.TypeOpenSearch},
+	}
+	if feed.Up != "" {
+		f.Links = append(f.Links, &opds.Link{Rel: opds.RelUp, Href: strings.TrimSuffix(baseURL+feed.Up, "/"), Type: opds.TypeNavigation})
+	}
+
+	for _, item := range feed.Navigation {
+		itemKind := opds.TypeNavigation
+		if item.Acquisition {
+			itemKind = opds.TypeAcquisition
+		}
+		f.Entries = append(f.Entries, &opds.Entry{
+			ID:      baseURL + item.Path,
+			Title:   item.Title,
+			Updated: updated,
+			Content: &opds.Content{Type: "text", Value: item.Title},
+			Links:   []*opds.Link{{Rel: opds.RelSubsection, Href: baseURL + item.Path, Type: itemKind, Title: item.Title, Count: item.Count}},
+		})
+	}
+
+	if feed.Navigation == nil {
+		f.TotalResults = feed.Total
+		f.ItemsPerPage = opdsPageSize
+		for rel, pagePath := range opdsPageLinks(feed) {
+			f.Links = append(f.Links, &opds.Link{Rel: rel, Href: baseURL + pagePath, Type: opds.TypeAcquisition})
+		}
+	}
+
+	for _, dm := range feed.DMs {
+		entry := &opds.Entry{
+			ID:         dm.APIURL(),
+			Title:      dm.Title,
+			Updated:    dm.ReleaseDateUnix.AsTime(),
+			Authors:    []*opds.Author{{Name: getOPDSAuthorName(dm), URI: getOPDSAuthorURL(dm)}},
+			Language:   dm.Language,
+			Publisher:  setting.AppName,
+			Issued:     dm.ReleaseDateUnix.AsTime().Format("2006-01-02"),
+			Identifier: dm.Repo.FullName() + "@" + dm.Ref,
+			Categories: []*opds.Category{{Term: dm.Subject, Label: dm.Subject}},
+			Links: []*opds.Link{
+				{Rel: opds.RelAlternate, Href: getOPDSEntryHTMLURL(dm), Type: opds.TypeHTML},
+			},
+		}
+		if dm.Repo.Description != "" {
+			entry.Summary = &opds.Content{Type: "text", Value: dm.Repo.Description}
+		}
+		if dm.Repo.Avatar != "" {
+			avatar := dm.Repo.AvatarLink(ctx)
+			entry.Links = append(entry.Links,
+				&opds.Link{Rel: opds.RelImage, Href: avatar, Type: "image/png"},
+				&opds.Link{Rel: opds.RelThumbnail, Href: avatar, Type: "image/png"})
+		}
+		for _, link := range getOPDSAcquisitionLinks(dm) {
+			entry.Links = append(entry.Links, &opds.Link{Rel: opds.RelAcquisition, Href: link.Href, Type: link.Type, Title: link.Title})
+		}
+		f.Entries = append(f.Entries, entry)
+	}
+
+	writeOPDSXML(ctx, kind, f)
+}
+
+func renderOPDS2Feed(ctx *context.Context, feed *opdsFeed, updated time.Time) {
+	baseURL := opdsBaseURL(ctx)
+
+	f := &opds.Feed2{
+		Metadata: &opds.Feed2Metadata{
+			Title:    feed.Title,
+			Modified: &updated,
+		},
+		Links: []*opds.Link2{
+			{Rel: opds.RelSelf, Href: baseURL + opdsSelfPath(feed), Type: opds.TypeOPDS2},
+			{Rel: opds.RelStart, Href: baseURL, Type: opds.TypeOPDS2},
+			{Rel: opds.RelSearch, Href: baseURL + "/search{?query}", Type: opds.TypeOPDS2, Templated: true},
+		},
+	}
+	if feed.Up != "" {
+		f.Links = append(f.Links, &opds.Link2{Rel: opds.RelUp, Href: strings.TrimSuffix(baseURL+feed.Up, "/"), Type: opds.TypeOPDS2})
+	}
+
+	for _, item := range feed.Navigation {
+		link := &opds.Link2{Href: baseURL + item.Path, Type: opds.TypeOPDS2, Title: item.Title}
+		if item.Count > 0 {
+			link.Properties = &opds.Link2Properties{NumberOfItems: item.Count}
+		}
+		f.Navigation = append(f.Navigation, link)
+	}
+
+	if feed.Navigation == nil {
+		f.Metadata.NumberOfItems = feed.Total
+		f.Metadata.ItemsPerPage = opdsPageSize
+		f.Metadata.CurrentPage = feed.Page
+		for rel, pagePath := range opdsPageLinks(feed) {
+			f.Links = append(f.Links, &opds.Link2{Rel: rel, Href: baseURL + pagePath, Type: opds.TypeOPDS2})
+		}
+		f.Publications = make([]*opds.Publication2, 0, len(feed.DMs))
+	}
+
+	for _, dm := range feed.DMs {
+		pub := &opds.Publication2{
+			Metadata: &opds.Publication2Metadata{
+				Type:        opds.PublicationType2,
+				Identifier:  dm.APIURL(),
+				Title:       dm.Title,
+				Author:      []*opds.Contributor2{{Name: getOPDSAuthorName(dm), Links: []*opds.Link2{{Href: getOPDSAuthorURL(dm), Type: opds.TypeHTML}}}},
+				Publisher:   []*opds.Contributor2{{Name: setting.AppName}},
+				Language:    dm.Language,
+				Subject:     []string{dm.Subject},
+				Description: dm.Repo.Description,
+				Modified:    dm.ReleaseDateUnix.AsTime(),
+				Published:   dm.ReleaseDateUnix.AsTime().Format("2006-01-02"),
+			},
+			Links: []*opds.Link2{
+				{Rel: opds.RelSelf, Href: getOPDSEntryHTMLURL(dm), Type: opds.TypeHTML},
+			},
+		}
+		if dm.Repo.Avatar != "" {
+			pub.Images = []*opds.Link2{{Href: dm.Repo.AvatarLink(ctx), Type: "image/png"}}
+		}
+		for _, link := range getOPDSAcquisitionLinks(dm) {
+			pub.Links = append(pub.Links, &opds.Link2{Rel: opds.RelAcquisition, Href: link.Href, Type: link.Type, Title: link.Title})
+		}
+		f.Publications = append(f.Publications, pub)
+	}
+
+	ctx.Resp.Header().Set("Content-Type", opds.TypeOPDS2+";charset=utf-8")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(ctx.Resp).Encode(f); err != nil {
+		log.Error("Unable to write OPDS 2.0 feed %s: %v", feed.Path, err)
+	}
+}
+
+func writeOPDSXML(ctx *context.Context, contentType string, v any) {
+	ctx.Resp.Header().Set("Content-Type", contentType+";charset=utf-8")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if _, err := ctx.Resp.Write([]byte(xml.Header)); err != nil {
+		log.Error("Unable to write OPDS XML: %v", err)
+		return
+	}
+	if err := xml.NewEncoder(ctx.Resp).Encode(v); err != nil {
+		log.Error("Unable to write OPDS XML: %v", err)
+	}
+}
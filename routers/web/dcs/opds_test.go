@@ -0,0 +1,78 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package dcs
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/contexttest"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/opds"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOPDSSearch(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	assert.NoError(t, db.Insert(db.DefaultContext, &repo_model.Door43Metadata{
+		RepoID: 1, ReleaseID: 1, Ref: "v1.1", RefType: "tag", CommitSHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		Stage: door43metadata.StageProd, MetadataType: "rc", Title: "Test Entry", Language: "en", Subject: "Bible",
+		ReleaseDateUnix: 1000, IsLatestForStage: true,
+	}))
+	repoURL := setting.AppURL + "user2/repo1"
+	expected := []*opds.Link{
+		{Rel: opds.RelAcquisition, Href: repoURL + "/archive/v1.1.zip", Type: "application/zip", Title: "repo1-v1.1.zip"},
+		{Rel: opds.RelAcquisition, Href: repoURL + "/archive/v1.1.tar.gz", Type: "application/gzip", Title: "repo1-v1.1.tar.gz"},
+		{Rel: opds.RelAcquisition, Href: repoURL + "/archive/v1.1.rc.zip", Type: "application/zip", Title: "repo1-v1.1.rc.zip"},
+		{Rel: opds.RelAcquisition, Href: repoURL + "/releases/download/v1.1/attach1", Type: "application/octet-stream", Title: "attach1"},
+	}
+
+	ctx, resp := contexttest.MockContext(t, "catalog/opds/search")
+	OPDSVersion(1)(ctx)
+	OPDSSearch(ctx)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, opds.TypeAcquisition+";charset=utf-8", resp.Header().Get("Content-Type"))
+	feed := &struct {
+		Entries []*struct {
+			Title string       `xml:"title"`
+			Links []*opds.Link `xml:"link"`
+		} `xml:"entry"`
+	}{}
+	assert.NoError(t, xml.Unmarshal(resp.Body.Bytes(), feed))
+	if assert.Len(t, feed.Entries, 1) {
+		assert.Equal(t, "Test Entry", feed.Entries[0].Title)
+		var links []*opds.Link
+		for _, link := range feed.Entries[0].Links {
+			if link.Rel == opds.RelAcquisition {
+				links = append(links, link)
+			}
+		}
+		assert.Equal(t, expected, links)
+	}
+
+	ctx, resp = contexttest.MockContext(t, "catalog/opds2/search")
+	OPDSVersion(2)(ctx)
+	OPDSSearch(ctx)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	feed2 := &opds.Feed2{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), feed2))
+	assert.EqualValues(t, 1, feed2.Metadata.NumberOfItems)
+	if assert.Len(t, feed2.Publications, 1) {
+		var links []*opds.Link
+		for _, link := range feed2.Publications[0].Links {
+			if link.Rel == opds.RelAcquisition {
+				links = append(links, &opds.Link{Rel: link.Rel, Href: link.Href, Type: link.Type, Title: link.Title})
+			}
+		}
+		assert.Equal(t, expected, links)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package dcs
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// tplCatalogProgress translation progress dashboard page template.
+const tplCatalogProgress base.TplName = "catalog/progress"
+
+// CatalogProgress renders the translation progress of the Bible and Open Bible Stories repos, of an owner and of
+// a language if given
+func CatalogProgress(ctx *context.Context) {
+	page := ctx.FormInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	opts := &repo_model.Door43ProgressOptions{
+		ListOptions: db.ListOptions{Page: page, PageSize: setting.UI.ExplorePagingNum},
+		PublicOnly:  true,
+	}
+
+	ownerName := ctx.FormTrim("owner")
+	if ownerName != "" {
+		owner, err := user_model.GetUserByName(ctx, ownerName)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				ctx.NotFound("GetUserByName", err)
+			} else {
+				ctx.ServerError("GetUserByName", err)
+			}
+			return
+		}
+		opts.OwnerID = owner.ID
+		ctx.Data["Owner"] = owner
+	}
+	lang := ctx.FormTrim("lang")
+	if lang != "" {
+		opts.Languages = []string{lang}
+	}
+
+	dms, count, err := repo_model.FindDoor43MetadatasWithProgress(ctx, opts)
+	if err != nil {
+		ctx.ServerError("FindDoor43MetadatasWithProgress", err)
+		return
+	}
+	if err := dms.LoadAttributes(ctx); err != nil {
+		ctx.ServerError("LoadAttributes", err)
+		return
+	}
+	total, err := repo_model.GetDoor43ProgressTotal(ctx, opts)
+	if err != nil {
+		ctx.ServerError("GetDoor43ProgressTotal", err)
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.metadata.progress")
+	ctx.Data["OwnerName"] = ownerName
+	ctx.Data["Language"] = lang
+	ctx.Data["Report"] = convert.ToTranslationProgressReport(dms, total)
+
+	pager := context.NewPagination(int(count), opts.PageSize, page, 5)
+	pager.AddParam(ctx, "owner", "OwnerName")
+	pager.AddParam(ctx, "lang", "Language")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplCatalogProgress)
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package feed
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/gorilla/feeds"
+)
+
+// ShowCatalogFeed shows the catalog entries of releases as RSS / Atom feed
+func ShowCatalogFeed(ctx *context.Context, dms []*repo_model.Door43Metadata, title, link, description, formatType string) {
+	feed := &feeds.Feed{
+		Title:       title,
+		Link:        &feeds.Link{Href: link},
+		Description: description,
+		Created:     time.Now(),
+	}
+
+	var err error
+	feed.Items, err = catalogEntriesToFeedItems(ctx, dms)
+	if err != nil {
+		ctx.ServerError("catalogEntriesToFeedItems", err)
+		return
+	}
+
+	writeFeed(ctx, feed, formatType)
+}
+
+// catalogEntriesToFeedItems converts catalog entries to feed items linking to their release notes and downloads
+func catalogEntriesToFeedItems(ctx *context.Context, dms []*repo_model.Door43Metadata) ([]*feeds.Item, error) {
+	if err := repo_model.Door43MetadataList(dms).LoadAttributes(ctx); err != nil {
+		return nil, err
+	}
+
+	items := make([]*feeds.Item, 0, len(dms))
+	for _, dm := range dms {
+		link := &feeds.Link{Href: dm.Repo.HTMLURL() + "/releases/tag/" + util.PathEscapeSegments(dm.Ref)}
+		var content strings.Builder
+		if dm.Release != nil && dm.Release.Note != "" {
+			note, err := markdown.RenderString(&markup.RenderContext{
+				Ctx:       ctx,
+				URLPrefix: dm.Repo.Link(),
+				Metas:     dm.Repo.ComposeMetas(ctx),
+			}, dm.Release.Note)
+			if err != nil {
+				return nil, err
+			}
+			content.WriteString(note)
+		}
+		content.WriteString("<ul>")
+		writeFeedDownloadLink(&content, dm.GetZipballURL(), ctx.Tr("repo.release.source_code")+" (ZIP)")
+		writeFeedDownloadLink(&content, dm.GetTarballURL(), ctx.Tr("repo.release.source_code")+" (TAR.GZ)")
+		if dm.Release != nil {
+			for _, attachment := range dm.Release.Attachments {
+				writeFeedDownloadLink(&content, attachment.DownloadURL(), attachment.Name)
+			}
+		}
+		content.WriteString("</ul>")
+
+		author := &feeds.Author{Name: dm.Repo.OwnerName}
+		if dm.Release != nil && dm.Release.Publisher != nil {
+			author = &feeds.Author{
+				Name:  dm.Release.Publisher.DisplayName(),
+				Email: dm.Release.Publisher.GetEmail(),
+			}
+		}
+
+		items = append(items, &feeds.Item{
+			Title:       fmt.Sprintf("%s %s (%s)", dm.Title, dm.Ref, dm.Language),
+			Link:        link,
+			Description: fmt.Sprintf("%s: %s, %s", dm.Repo.FullName(), dm.Subject, dm.LanguageTitle),
+			Created:     dm.ReleaseDateUnix.AsTime(),
+			Author:      author,
+			Id:          fmt.Sprintf("%d: %s", dm.ID, link.Href),
+			Content:     content.String(),
+		})
+	}
+	return items, nil
+}
+
+func writeFeedDownloadLink(sb *strings.Builder, href, name string) {
+	fmt.Fprintf(sb, `<li><a href="%s" rel="nofollow">%s</a></li>`, html.EscapeString(href), html.EscapeString(name))
+}
@@ -16,12 +16,14 @@ import (
 	"code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/dcs" // DCS Customizations
 	"code.gitea.io/gitea/modules/log"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
 	shared_user "code.gitea.io/gitea/routers/web/shared/user"
 	user_setting "code.gitea.io/gitea/routers/web/user/setting"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata" // DCS Customizations
 	"code.gitea.io/gitea/services/forms"
 	org_service "code.gitea.io/gitea/services/org"
 	repo_service "code.gitea.io/gitea/services/repository"
@@ -54,6 +56,15 @@ func Settings(ctx *context.Context) {
 		return
 	}
 
+	/*** DCS Customizations ***/
+	ctx.Data["RepoNamingPolicies"] = dcs.RepoNamingPolicies
+	ctx.Data["RepoNamingPolicy"], err = door43metadata_service.GetRepoNamingPolicy(ctx, ctx.Org.Organization.AsUser())
+	if err != nil {
+		ctx.ServerError("GetRepoNamingPolicy", err)
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	ctx.HTML(http.StatusOK, tplSettingsOptions)
 }
 
@@ -64,6 +75,10 @@ func SettingsPost(ctx *context.Context) {
 	ctx.Data["PageIsOrgSettings"] = true
 	ctx.Data["PageIsSettingsOptions"] = true
 	ctx.Data["CurrentVisibility"] = ctx.Org.Organization.Visibility
+	/*** DCS Customizations ***/
+	ctx.Data["RepoNamingPolicies"] = dcs.RepoNamingPolicies
+	ctx.Data["RepoNamingPolicy"] = form.RepoNamingPolicy
+	/*** END DCS Customizations ***/
 
 	if ctx.HasError() {
 		ctx.HTML(http.StatusOK, tplSettingsOptions)
@@ -122,6 +137,15 @@ func SettingsPost(ctx *context.Context) {
 		return
 	}
 
+	/*** DCS Customizations ***/
+	if form.RepoNamingPolicy != "" {
+		if err := door43metadata_service.SetRepoNamingPolicy(ctx, org.AsUser(), dcs.RepoNamingPolicy(form.RepoNamingPolicy)); err != nil {
+			ctx.ServerError("SetRepoNamingPolicy", err)
+			return
+		}
+	}
+	/*** END DCS Customizations ***/
+
 	// update forks visibility
 	if visibilityChanged {
 		repos, _, err := repo_model.GetUserRepositories(ctx, &repo_model.SearchRepoOptions{
@@ -65,6 +65,12 @@ func Activity(ctx *context.Context) {
 		return
 	}
 
+	/*** DCS Customizations ***/
+	if !loadCatalogDownloads(ctx, timeFrom) {
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	ctx.HTML(http.StatusOK, tplActivity)
 }
 
@@ -0,0 +1,73 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"sort"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+)
+
+// catalogEntryDownloads are the download counts of a catalog entry of the repo in the activity period
+type catalogEntryDownloads struct {
+	EntryID int64
+	Ref     string // empty if the entry no longer exists
+	Counts  map[repo_model.Door43DownloadKind]int64
+	Total   int64
+}
+
+// loadCatalogDownloads sets the download counts of the catalog entries of the repo since the day of timeFrom,
+// most downloaded first. Returns false if there was an error
+func loadCatalogDownloads(ctx *context.Context, timeFrom time.Time) bool {
+	counts, err := repo_model.GetDoor43DownloadCounts(ctx, &repo_model.Door43DownloadStatsOptions{
+		RepoID:  ctx.Repo.Repository.ID,
+		Since:   repo_model.Door43DownloadDay(timeFrom),
+		GroupBy: []repo_model.Door43DownloadGroupBy{repo_model.Door43DownloadGroupByEntry, repo_model.Door43DownloadGroupByKind},
+	})
+	if err != nil {
+		ctx.ServerError("GetDoor43DownloadCounts", err)
+		return false
+	}
+
+	byEntry := make(map[int64]*catalogEntryDownloads)
+	entries := make([]*catalogEntryDownloads, 0, len(counts))
+	var total int64
+	for _, c := range counts {
+		entry, ok := byEntry[c.Door43MetadataID]
+		if !ok {
+			entry = &catalogEntryDownloads{EntryID: c.Door43MetadataID, Counts: make(map[repo_model.Door43DownloadKind]int64)}
+			byEntry[c.Door43MetadataID] = entry
+			entries = append(entries, entry)
+		}
+		entry.Counts[c.Kind] += c.Count
+		entry.Total += c.Count
+		total += c.Count
+	}
+
+	// the entries that no longer exist are listed without a ref
+	ids := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.EntryID)
+	}
+	dms, err := repo_model.GetDoor43MetadataMapByIDs(ctx, ctx.Repo.Repository.ID, ids)
+	if err != nil {
+		ctx.ServerError("GetDoor43MetadataMapByIDs", err)
+		return false
+	}
+	for _, entry := range entries {
+		if dm, ok := dms[entry.EntryID]; ok {
+			entry.Ref = dm.Ref
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Total > entries[j].Total
+	})
+
+	ctx.Data["CatalogDownloads"] = entries
+	ctx.Data["CatalogDownloadsTotal"] = total
+	ctx.Data["CatalogDownloadKinds"] = repo_model.Door43DownloadKinds
+	return true
+}
@@ -18,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/services/attachment"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata" // DCS Customizations
 	repo_service "code.gitea.io/gitea/services/repository"
 )
 
@@ -126,6 +127,7 @@ func ServeAttachment(ctx *context.Context, uuid string) {
 		ctx.ServerError("IncreaseDownloadCount", err)
 		return
 	}
+	door43metadata_service.CountAttachmentDownload(ctx, attach) // DCS Customizations
 
 	if setting.Attachment.Storage.MinioConfig.ServeDirect {
 		// If we have a signed url (S3, object storage), redirect to this directly.
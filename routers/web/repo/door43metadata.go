@@ -4,20 +4,27 @@
 package repo
 
 import (
+	"bytes"
+	"errors"
 	"net/http"
 
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/convert"
 	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
 
 	"xorm.io/builder"
 )
 
 const (
-	tplDoor43Metadata base.TplName = "repo/dcs_metadata"
+	tplDoor43Metadata         base.TplName = "repo/dcs_metadata"
+	tplDoor43MetadataProgress base.TplName = "repo/dcs_progress"
 )
 
 // Door43Metadtas renders door43 metadatas page
@@ -31,16 +38,26 @@ func Door43Metadatas(ctx *context.Context) {
 		log.Error("ERROR: %v", err)
 	}
 
+	revisions, err := repo_model.GetDoor43MetadataRevisionsByRepoID(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		log.Error("GetDoor43MetadataRevisionsByRepoID: %v", err)
+	}
+	revisionsByRef := make(map[string][]*repo_model.Door43MetadataRevision)
+	for _, rev := range revisions {
+		revisionsByRef[rev.Ref] = append(revisionsByRef[rev.Ref], rev)
+	}
+
 	ctx.Data["PageIsMetadata"] = true
 	ctx.Data["Title"] = "Door43 Metadata"
 	ctx.Data["PageIsSettingsDoor43Metadata"] = true
 	ctx.Data["Door43Metadatas"] = dms
+	ctx.Data["Door43MetadataRevisions"] = revisionsByRef
 	ctx.HTML(http.StatusOK, tplDoor43Metadata)
 }
 
 // UpdateDoor43Metadata updates the repo's metadata
 func UpdateDoor43Metadata(ctx *context.Context) {
-	if err := door43metadata_service.ProcessDoor43MetadataForRepo(ctx, ctx.Repo.Repository, ""); err != nil {
+	if err := door43metadata_service.ProcessDoor43MetadataForRepo(ctx, ctx.Repo.Repository, "", repo_model.Door43MetadataTriggerManual); err != nil {
 		ctx.Flash.Error("ProcessDoor43MetadataForRepo: " + err.Error())
 	} else {
 		if err := ctx.Repo.Repository.LoadLatestDMs(ctx); err != nil {
@@ -53,3 +70,132 @@ func UpdateDoor43Metadata(ctx *context.Context) {
 	}
 	ctx.Redirect(ctx.Repo.RepoLink + "/metadata")
 }
+
+// ConvertDoor43Metadata downloads the metadata of a ref converted to the other metadata type
+func ConvertDoor43Metadata(ctx *context.Context) {
+	ref := ctx.FormTrim("ref")
+	if ref == "" {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(ref)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("GetCommit", err)
+		} else {
+			ctx.ServerError("GetCommit", err)
+		}
+		return
+	}
+	converted, err := door43metadata_service.ConvertMetadata(ctx.Repo.Repository, commit, ctx.FormTrim("to"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound("ConvertMetadata", err)
+		} else if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Flash.Error(ctx.Tr("repo.metadata.convert.failed", err.Error()))
+			ctx.Redirect(ctx.Repo.RepoLink + "/metadata")
+		} else {
+			ctx.ServerError("ConvertMetadata", err)
+		}
+		return
+	}
+	ctx.ServeContent(bytes.NewReader(converted.Content), &context.ServeHeaderOptions{
+		Filename:     converted.TreePath,
+		LastModified: commit.Committer.When,
+	})
+}
+
+// ConvertDoor43MetadataPost commits the metadata of a branch converted to the other metadata type
+func ConvertDoor43MetadataPost(ctx *context.Context) {
+	opts := door43metadata_service.CommitConvertedMetadataOptions{
+		To:        ctx.FormTrim("to"),
+		Branch:    ctx.FormTrim("branch"),
+		NewBranch: ctx.FormTrim("new_branch"),
+	}
+	if opts.Branch == "" {
+		opts.Branch = ctx.Repo.Repository.DefaultBranch
+	}
+	branch := opts.Branch
+	if opts.NewBranch != "" {
+		if !git.IsValidRefPattern(opts.NewBranch) {
+			ctx.Flash.Error(ctx.Tr("repo.metadata.convert.invalid_branch"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/metadata")
+			return
+		}
+		branch = opts.NewBranch
+	}
+	if !ctx.Repo.CanWriteToBranch(ctx, ctx.Doer, branch) || ctx.Repo.Repository.IsMirror || ctx.Repo.Repository.IsArchived {
+		ctx.NotFound("CanWriteToBranch", nil)
+		return
+	}
+
+	fileResponse, err := door43metadata_service.CommitConvertedMetadata(ctx, ctx.Repo.Repository, ctx.Repo.GitRepo, ctx.Doer, opts)
+	if err != nil {
+		if git.IsErrNotExist(err) || git.IsErrBranchNotExist(err) {
+			ctx.NotFound("CommitConvertedMetadata", err)
+			return
+		}
+		ctx.Flash.Error(ctx.Tr("repo.metadata.convert.failed", err.Error()))
+		ctx.Redirect(ctx.Repo.RepoLink + "/metadata")
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.metadata.convert.success", fileResponse.Content.Path, branch))
+	ctx.Redirect(ctx.Repo.RepoLink + "/src/branch/" + util.PathEscapeSegments(branch) + "/" + util.PathEscapeSegments(fileResponse.Content.Path))
+}
+
+// Door43MetadataProgress renders the translation progress of each book and chapter of a ref, the ref of the repo's
+// metadata if none is given
+func Door43MetadataProgress(ctx *context.Context) {
+	var dm *repo_model.Door43Metadata
+	if ref := ctx.FormTrim("ref"); ref != "" {
+		var err error
+		if dm, err = repo_model.GetDoor43MetadataByRepoIDAndRef(ctx, ctx.Repo.Repository.ID, ref); err != nil {
+			if repo_model.IsErrDoor43MetadataNotExist(err) {
+				ctx.NotFound("GetDoor43MetadataByRepoIDAndRef", err)
+			} else {
+				ctx.ServerError("GetDoor43MetadataByRepoIDAndRef", err)
+			}
+			return
+		}
+	} else {
+		if err := ctx.Repo.Repository.LoadLatestDMs(ctx); err != nil {
+			ctx.ServerError("LoadLatestDMs", err)
+			return
+		}
+		if dm = ctx.Repo.Repository.RepoDM; dm == nil {
+			ctx.NotFound("LoadLatestDMs", nil)
+			return
+		}
+	}
+	dm.Repo = ctx.Repo.Repository
+
+	ctx.Data["PageIsMetadata"] = true
+	ctx.Data["Title"] = ctx.Tr("repo.metadata.progress") + " - " + dm.Title
+	ctx.Data["Door43Metadata"] = dm
+	ctx.Data["Progress"] = convert.ToTranslationProgress(dm, true)
+	ctx.HTML(http.StatusOK, tplDoor43MetadataProgress)
+}
+
+// CheckRepoNamingPolicy checks the name of a repo being created or renamed against the repo naming policy of its
+// owner and renders the template with an error if the policy is enforced. Returns the warning to flash once the
+// repo is saved if the policy only suggests names, else ""
+func CheckRepoNamingPolicy(ctx *context.Context, owner *user_model.User, name string, tpl base.TplName, form any) string {
+	err := door43metadata_service.CheckRepoName(ctx, owner, name)
+	if err == nil {
+		return ""
+	}
+	if !door43metadata_service.IsErrRepoNameNotConventional(err) {
+		ctx.ServerError("CheckRepoName", err)
+		return ""
+	}
+	namingErr := err.(door43metadata_service.ErrRepoNameNotConventional)
+	msg := ctx.TrHTMLEscapeArgs("repo.form.name_not_conventional", namingErr.Name)
+	if namingErr.Suggestion != "" {
+		msg += " " + ctx.TrHTMLEscapeArgs("repo.form.name_not_conventional_suggestion", namingErr.Suggestion)
+	}
+	if namingErr.IsEnforced() {
+		ctx.Data["Err_RepoName"] = true
+		ctx.RenderWithErr(msg, tpl, form)
+		return ""
+	}
+	return msg
+}
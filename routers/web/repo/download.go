@@ -17,6 +17,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/routers/common"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata" // DCS Customizations
 )
 
 // ServeBlobOrLFS download a git.Blob redirecting to LFS if necessary
@@ -119,6 +120,7 @@ func SingleDownload(ctx *context.Context) {
 	if blob == nil {
 		return
 	}
+	door43metadata_service.CountIngredientDownload(ctx, ctx.Repo.Repository.ID, ctx.Repo.RefName, ctx.Repo.TreePath) // DCS Customizations
 
 	if err := common.ServeBlob(ctx.Base, ctx.Repo.TreePath, blob, lastModified); err != nil {
 		ctx.ServerError("ServeBlob", err)
@@ -131,6 +133,7 @@ func SingleDownloadOrLFS(ctx *context.Context) {
 	if blob == nil {
 		return
 	}
+	door43metadata_service.CountIngredientDownload(ctx, ctx.Repo.Repository.ID, ctx.Repo.RefName, ctx.Repo.TreePath) // DCS Customizations
 
 	if err := ServeBlobOrLFS(ctx, blob, lastModified); err != nil {
 		ctx.ServerError("ServeBlobOrLFS", err)
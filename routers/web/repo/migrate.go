@@ -238,8 +238,20 @@ func MigratePost(ctx *context.Context) {
 		return
 	}
 
+	/*** DCS Customizations ***/
+	namingWarning := CheckRepoNamingPolicy(ctx, ctxUser, opts.RepoName, tpl, &form)
+	if ctx.Written() {
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	err = task.MigrateRepository(ctx, ctx.Doer, ctxUser, opts)
 	if err == nil {
+		/*** DCS Customizations ***/
+		if namingWarning != "" {
+			ctx.Flash.Warning(namingWarning)
+		}
+		/*** END DCS Customizations ***/
 		ctx.Redirect(ctxUser.HomeLink() + "/" + url.PathEscape(opts.RepoName))
 		return
 	}
@@ -275,6 +275,13 @@ func ForkPost(ctx *context.Context) {
 		}
 	}
 
+	/*** DCS Customizations ***/
+	namingWarning := CheckRepoNamingPolicy(ctx, ctxUser, form.RepoName, tplFork, &form)
+	if ctx.Written() {
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	repo, err := repo_service.ForkRepository(ctx, ctx.Doer, ctxUser, repo_service.ForkRepoOptions{
 		BaseRepo:     forkRepo,
 		Name:         form.RepoName,
@@ -312,6 +319,11 @@ func ForkPost(ctx *context.Context) {
 	}
 
 	log.Trace("Repository forked[%d]: %s/%s", forkRepo.ID, ctxUser.Name, repo.Name)
+	/*** DCS Customizations ***/
+	if namingWarning != "" {
+		ctx.Flash.Warning(namingWarning)
+	}
+	/*** END DCS Customizations ***/
 	ctx.Redirect(ctxUser.HomeLink() + "/" + url.PathEscape(repo.Name))
 }
 
@@ -166,6 +166,17 @@ func Releases(ctx *context.Context) {
 
 	ctx.Data["Releases"] = releases
 
+	/*** DCS Customizations ***/
+	if writeAccess {
+		if !loadReleaseAttachmentReports(ctx, releases) {
+			return
+		}
+	}
+	if !loadAttachmentLinkStatuses(ctx, releases) {
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	numReleases := ctx.Data["NumReleases"].(int64)
 	pager := context.NewPagination(int(numReleases), opts.PageSize, opts.Page, 5)
 	pager.SetDefaultParams(ctx)
@@ -308,6 +319,12 @@ func SingleRelease(ctx *context.Context) {
 		ctx.ServerError("LoadAttributes", err)
 		return
 	}
+	if writeAccess && !loadReleaseAttachmentReports(ctx, []*repo_model.Release{release}) {
+		return
+	}
+	if !loadAttachmentLinkStatuses(ctx, []*repo_model.Release{release}) {
+		return
+	}
 	/*** END DCS Customizations ***/
 
 	ctx.Data["Releases"] = []*repo_model.Release{release}
@@ -0,0 +1,45 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+)
+
+// loadReleaseAttachmentReports sets the problems found unpacking the files.json or links.json attachments of the
+// releases to show to the users who can fix them. Returns false if there was an error
+func loadReleaseAttachmentReports(ctx *context.Context, releases []*repo_model.Release) bool {
+	releaseIDs := make([]int64, 0, len(releases))
+	for _, r := range releases {
+		if !r.IsTag {
+			releaseIDs = append(releaseIDs, r.ID)
+		}
+	}
+	reports, err := repo_model.GetReleaseAttachmentReports(ctx, releaseIDs)
+	if err != nil {
+		ctx.ServerError("GetReleaseAttachmentReports", err)
+		return false
+	}
+	ctx.Data["ReleaseAttachmentReports"] = reports
+	return true
+}
+
+// loadAttachmentLinkStatuses sets the statuses of the external files linked by the attachments of the releases,
+// by attachment ID, to flag the broken links. Returns false if there was an error
+func loadAttachmentLinkStatuses(ctx *context.Context, releases []*repo_model.Release) bool {
+	releaseIDs := make([]int64, 0, len(releases))
+	for _, r := range releases {
+		if !r.IsTag {
+			releaseIDs = append(releaseIDs, r.ID)
+		}
+	}
+	statuses, err := repo_model.GetAttachmentLinkStatusesByReleaseIDs(ctx, releaseIDs)
+	if err != nil {
+		ctx.ServerError("GetAttachmentLinkStatusesByReleaseIDs", err)
+		return false
+	}
+	ctx.Data["AttachmentLinkStatuses"] = statuses
+	return true
+}
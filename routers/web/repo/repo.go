@@ -31,6 +31,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/services/convert"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata" // DCS Customizations
 	"code.gitea.io/gitea/services/forms"
 	repo_service "code.gitea.io/gitea/services/repository"
 	archiver_service "code.gitea.io/gitea/services/repository/archiver"
@@ -238,6 +239,13 @@ func CreatePost(ctx *context.Context) {
 		return
 	}
 
+	/*** DCS Customizations ***/
+	namingWarning := CheckRepoNamingPolicy(ctx, ctxUser, form.RepoName, tplCreate, &form)
+	if ctx.Written() {
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	var repo *repo_model.Repository
 	var err error
 	if form.RepoTemplate > 0 {
@@ -272,6 +280,11 @@ func CreatePost(ctx *context.Context) {
 		repo, err = repo_service.GenerateRepository(ctx, ctx.Doer, ctxUser, templateRepo, opts)
 		if err == nil {
 			log.Trace("Repository generated [%d]: %s/%s", repo.ID, ctxUser.Name, repo.Name)
+			/*** DCS Customizations ***/
+			if namingWarning != "" {
+				ctx.Flash.Warning(namingWarning)
+			}
+			/*** END DCS Customizations ***/
 			ctx.Redirect(repo.Link())
 			return
 		}
@@ -291,6 +304,11 @@ func CreatePost(ctx *context.Context) {
 		})
 		if err == nil {
 			log.Trace("Repository created [%d]: %s/%s", repo.ID, ctxUser.Name, repo.Name)
+			/*** DCS Customizations ***/
+			if namingWarning != "" {
+				ctx.Flash.Warning(namingWarning)
+			}
+			/*** END DCS Customizations ***/
 			ctx.Redirect(repo.Link())
 			return
 		}
@@ -428,6 +446,9 @@ func Download(ctx *context.Context) {
 		}
 		return
 	}
+	if !prepareArchiveRequest(ctx, aReq) { // DCS Customizations
+		return
+	}
 
 	archiver, err := aReq.Await(ctx)
 	if err != nil {
@@ -435,6 +456,7 @@ func Download(ctx *context.Context) {
 		return
 	}
 
+	door43metadata_service.CountArchiveDownload(ctx, ctx.Repo.Repository.ID, aReq.GetRefName(), aReq.Type) // DCS Customizations
 	download(ctx, aReq.GetArchiveName(), archiver)
 }
 
@@ -479,8 +501,11 @@ func InitiateDownload(ctx *context.Context) {
 		ctx.Error(http.StatusNotFound)
 		return
 	}
+	if !prepareArchiveRequest(ctx, aReq) { // DCS Customizations
+		return
+	}
 
-	archiver, err := repo_model.GetRepoArchiver(ctx, aReq.RepoID, aReq.Type, aReq.CommitID)
+	archiver, err := repo_model.GetRepoArchiverByPathsHash(ctx, aReq.RepoID, aReq.Type, aReq.CommitID, aReq.PathsHash()) // DCS Customizations
 	if err != nil {
 		ctx.ServerError("archiver_service.StartArchive", err)
 		return
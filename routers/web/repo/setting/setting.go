@@ -31,6 +31,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/modules/web"
+	repo_router "code.gitea.io/gitea/routers/web/repo" // DCS Customizations
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/migrations"
@@ -120,8 +121,15 @@ func SettingsPost(ctx *context.Context) {
 		}
 
 		newRepoName := form.RepoName
+		namingWarning := "" // DCS Customizations
 		// Check if repository name has been changed.
 		if repo.LowerName != strings.ToLower(newRepoName) {
+			/*** DCS Customizations ***/
+			namingWarning = repo_router.CheckRepoNamingPolicy(ctx, ctx.Repo.Owner, newRepoName, tplSettingsOptions, &form)
+			if ctx.Written() {
+				return
+			}
+			/*** END DCS Customizations ***/
 			// Close the GitRepo if open
 			if ctx.Repo.GitRepo != nil {
 				ctx.Repo.GitRepo.Close()
@@ -183,6 +191,11 @@ func SettingsPost(ctx *context.Context) {
 		log.Trace("Repository basic settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		/*** DCS Customizations ***/
+		if namingWarning != "" {
+			ctx.Flash.Warning(namingWarning)
+		}
+		/*** END DCS Customizations ***/
 		ctx.Redirect(repo.Link() + "/settings")
 
 	case "mirror":
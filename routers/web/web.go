@@ -1098,6 +1098,9 @@ func registerRoutes(m *web.Route) {
 			m.Get("", repo.Door43Metadatas)
 			m.Get("/update", repo.UpdateDoor43Metadata)
 			m.Post("/update", repo.UpdateDoor43Metadata) // TODO: Make this /{id} for a single DM
+			m.Get("/convert", reqRepoCodeReader, repo.ConvertDoor43Metadata)
+			m.Post("/convert", reqSignIn, reqRepoCodeWriter, repo.ConvertDoor43MetadataPost)
+			m.Get("/progress", reqRepoCodeReader, repo.Door43MetadataProgress)
 		})
 		// END DCS Customizations
 	}, ignSignIn, context.RepoAssignment, context.UnitTypes()) // for "/{username}/{reponame}" which doesn't require authentication
@@ -1558,8 +1561,33 @@ func registerRoutes(m *web.Route) {
 
 	/*** DCS Customizations ***/
 	m.Get("/about", dcs.About)
+	m.Group("/languages", func() {
+		m.Get("", dcs.Languages)
+		m.Get("/{lang}", dcs.Language)
+	}, ignSignIn)
 	m.Group("/catalog", func() {
 		m.Get("", dcs.Catalog)
+		m.Get(".rss", feedEnabled, dcs.CatalogFeedRSS)
+		m.Get(".atom", feedEnabled, dcs.CatalogFeedAtom)
+		m.Get("/progress", dcs.CatalogProgress)
+		opdsRoutes := func() {
+			m.Get("", dcs.OPDSRoot)
+			m.Get("/opensearch.xml", dcs.OPDSOpenSearch)
+			m.Get("/search", dcs.OPDSSearch)
+			m.Get("/languages", dcs.OPDSLanguages)
+			m.Get("/languages/{lang}", dcs.OPDSLanguage)
+			m.Get("/subjects", dcs.OPDSSubjects)
+			m.Get("/subjects/{subject}", dcs.OPDSSubject)
+		}
+		m.Group("/collections/{username}", func() {
+			m.Get("", dcs.Collections)
+			m.Get("/{collection}", dcs.Collection)
+			m.Get("/{collection}/{version}", dcs.Collection)
+			m.Get("/{collection}/{version}/download", dcs.CollectionDownload)
+		})
+		m.Group("/opds", opdsRoutes, dcs.OPDSVersion(1))
+		m.Group("/opds2", opdsRoutes, dcs.OPDSVersion(2))
+		m.Get("/{username}", feedEnabled, dcs.OwnerCatalogFeed)
 	}, ignSignIn)
 	/*** END DCS Customizations ***/
 
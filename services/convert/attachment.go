@@ -36,6 +36,8 @@ func toAttachment(repo *repo_model.Repository, a *repo_model.Attachment, getDown
 		Size:          a.Size,
 		UUID:          a.UUID,
 		DownloadURL:   getDownloadURL(repo, a), // for web request json and api request json, return different download urls
+		Checksum:      a.Checksum,              // DCS Customizations
+		MimeType:      a.MimeType,              // DCS Customizations
 	}
 }
 
@@ -5,9 +5,20 @@ package convert
 
 import (
 	"context"
+	"math"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
 
+	"code.gitea.io/gitea/models"
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	"code.gitea.io/gitea/models/door43metadata"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	"code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/dcs"
 	"code.gitea.io/gitea/modules/log"
 	api "code.gitea.io/gitea/modules/structs"
 )
@@ -49,8 +60,13 @@ func ToCatalogEntry(ctx context.Context, dm *repo.Door43Metadata, perm access_mo
 	}
 
 	var release *api.Release
+	var brokenLinks []*api.CatalogBrokenLink
 	if dm.Release != nil {
 		release = ToAPIRelease(ctx, dm.Repo, dm.Release)
+		if err := dm.LoadBrokenLinks(ctx); err != nil {
+			log.Error("ToCatalogEntry: dm.LoadBrokenLinks() ERROR: %v", err)
+		}
+		brokenLinks = toCatalogBrokenLinks(dm.BrokenLinks)
 	}
 
 	return &api.CatalogEntry{
@@ -63,6 +79,7 @@ func ToCatalogEntry(ctx context.Context, dm *repo.Door43Metadata, perm access_mo
 		Release:                release,
 		TarballURL:             dm.GetTarballURL(),
 		ZipballURL:             dm.GetZipballURL(),
+		PackagedZipballURL:     dm.GetPackagedZipballURL(),
 		GitTreesURL:            dm.GetGitTreesURL(),
 		ContentsURL:            dm.GetContentsURL(),
 		Ref:                    dm.Ref,
@@ -82,18 +99,68 @@ func ToCatalogEntry(ctx context.Context, dm *repo.Door43Metadata, perm access_mo
 		MetadataURL:            dm.GetMetadataURL(),
 		MetadataJSONURL:        dm.GetMetadataJSONURL(),
 		MetadataAPIContentsURL: dm.GetMetadataAPIContentsURL(),
-		Ingredients:            dm.Ingredients,
+		Ingredients:            toCatalogIngredients(dm),
 		Books:                  dm.GetIngredientsIdentifierList(),
 		ContentFormat:          dm.ContentFormat,
+		CheckingLevel:          dm.CheckingLevel,
+		VerifiedCheckingLevel:  dm.VerifiedCheckingLevel,
+		BrokenLinks:            brokenLinks,
 	}
 }
 
+// toCatalogBrokenLinks converts the statuses of the broken links of the assets of a release
+func toCatalogBrokenLinks(statuses []*repo.AttachmentLinkStatus) []*api.CatalogBrokenLink {
+	if len(statuses) == 0 {
+		return nil
+	}
+	links := make([]*api.CatalogBrokenLink, 0, len(statuses))
+	for _, status := range statuses {
+		links = append(links, &api.CatalogBrokenLink{
+			Name:               status.Name,
+			BrowserDownloadURL: status.URL,
+			StatusCode:         status.StatusCode,
+			Error:              status.Error,
+			BrokenSince:        status.BrokenSinceUnix.AsTime(),
+			LastChecked:        status.CheckedUnix.AsTime(),
+		})
+	}
+	return links
+}
+
+// toCatalogIngredients returns copies of the ingredients of an entry with the URLs of their partial archives
+func toCatalogIngredients(dm *repo.Door43Metadata) []*api.Ingredient {
+	if dm.Ingredients == nil {
+		return nil
+	}
+	ingredients := make([]*api.Ingredient, 0, len(dm.Ingredients))
+	for _, ing := range dm.Ingredients {
+		ingredient := *ing
+		ingredient.ZipballURL = dm.GetZipballURL()
+		ingredient.TarballURL = dm.GetTarballURL()
+		if ing.Progress != nil {
+			// the progress of each chapter is only given by the progress of the entry
+			ingredient.Progress = &api.IngredientProgress{Completed: ing.Progress.Completed, Total: ing.Progress.Total}
+		}
+		if p := path.Clean("/" + ing.Path)[1:]; p != "" {
+			query := "?paths=" + url.QueryEscape(p)
+			ingredient.ZipballURL += query
+			ingredient.TarballURL += query
+		}
+		ingredients = append(ingredients, &ingredient)
+	}
+	return ingredients
+}
+
 // ToCatalogStage converts a Door43Metadata to an api.CatalogStage
 func ToCatalogStage(ctx context.Context, dm *repo.Door43Metadata) *api.CatalogStage {
 	if dm == nil {
 		return nil
 	}
-	_ = dm.LoadAttributes(ctx)
+	// Only the repo is needed, the release URL is made from the release ID if the release isn't loaded
+	if err := dm.LoadRepo(ctx); err != nil {
+		log.Error("ToCatalogStage: dm.LoadRepo() ERROR: %v", err)
+		return nil
+	}
 	catalogStage := &api.CatalogStage{
 		Ref:         dm.Ref,
 		Released:    dm.ReleaseDateUnix.AsTime(),
@@ -109,3 +176,339 @@ func ToCatalogStage(ctx context.Context, dm *repo.Door43Metadata) *api.CatalogSt
 	}
 	return catalogStage
 }
+
+// ToCatalogCheck converts a Door43Check to an api.CatalogCheck
+func ToCatalogCheck(ctx context.Context, check *repo.Door43Check, doer *user_model.User) *api.CatalogCheck {
+	if err := check.LoadChecker(ctx); err != nil {
+		log.Error("ToCatalogCheck: check.LoadChecker() ERROR: %v", err)
+		return nil
+	}
+	return &api.CatalogCheck{
+		ID:        check.ID,
+		Checker:   ToUser(ctx, check.Checker, doer),
+		CommitSHA: check.CommitSHA,
+		Level:     check.Level,
+		Note:      check.Note,
+		Created:   check.CreatedUnix.AsTime(),
+	}
+}
+
+// ToCatalogChecker converts a Door43Checker to an api.CatalogChecker
+func ToCatalogChecker(ctx context.Context, checker *repo.Door43Checker, doer *user_model.User) *api.CatalogChecker {
+	if err := checker.LoadChecker(ctx); err != nil {
+		log.Error("ToCatalogChecker: checker.LoadChecker() ERROR: %v", err)
+		return nil
+	}
+	return &api.CatalogChecker{
+		Checker:  ToUser(ctx, checker.Checker, doer),
+		MaxLevel: checker.MaxLevel,
+		Created:  checker.CreatedUnix.AsTime(),
+	}
+}
+
+// ToCatalogSigningKey converts a Door43SigningKey to an api.CatalogSigningKey
+func ToCatalogSigningKey(key *asymkey_model.Door43SigningKey, owner *user_model.User) *api.CatalogSigningKey {
+	publicKeyPEM, err := key.PublicKeyPEM()
+	if err != nil {
+		log.Error("ToCatalogSigningKey: key.PublicKeyPEM() ERROR: %v", err)
+		return nil
+	}
+	apiKey := &api.CatalogSigningKey{
+		KeyID:        key.KeyID,
+		Algorithm:    asymkey_model.Door43SigningKeyAlgorithm,
+		PublicKey:    key.PublicKey,
+		PublicKeyPEM: publicKeyPEM,
+		Created:      key.CreatedUnix.AsTime(),
+	}
+	if !key.IsInstanceKey() && owner != nil {
+		apiKey.Owner = owner.Name
+	}
+	return apiKey
+}
+
+// ToCatalogEntryRevision converts a Door43MetadataRevision to an api.CatalogEntryRevision
+func ToCatalogEntryRevision(ctx context.Context, rev *repo.Door43MetadataRevision, perm access_model.Permission) *api.CatalogEntryRevision {
+	return &api.CatalogEntryRevision{
+		ID:            rev.ID,
+		Trigger:       string(rev.Trigger),
+		ChangedFields: rev.ChangedFields,
+		Entry:         ToCatalogEntry(ctx, rev.ToDoor43Metadata(), perm),
+		ValidFrom:     rev.ValidFromUnix.AsTime(),
+		Replaced:      rev.CreatedUnix.AsTime(),
+	}
+}
+
+// ToCatalogFacets converts the facet counts of a catalog search to API format
+func ToCatalogFacets(facets map[door43metadata.CatalogFacet][]*models.CatalogFacetCount) map[string][]*api.CatalogFacetCount {
+	result := make(map[string][]*api.CatalogFacetCount, len(facets))
+	for facet, counts := range facets {
+		result[string(facet)] = ToCatalogFacetCounts(counts)
+	}
+	return result
+}
+
+// ToCatalogFacetCounts converts the counts of the values of a facet to API format
+func ToCatalogFacetCounts(counts []*models.CatalogFacetCount) []*api.CatalogFacetCount {
+	apiCounts := make([]*api.CatalogFacetCount, 0, len(counts))
+	for _, count := range counts {
+		apiCounts = append(apiCounts, &api.CatalogFacetCount{
+			Value: count.Value,
+			Count: count.Count,
+		})
+	}
+	return apiCounts
+}
+
+// ToCatalogCollection converts a Door43Collection to an api.CatalogCollection
+func ToCatalogCollection(ctx context.Context, collection *repo.Door43Collection, latest *repo.Door43CollectionVersion) *api.CatalogCollection {
+	if err := collection.LoadOwner(ctx); err != nil {
+		log.Error("ToCatalogCollection: collection.LoadOwner() ERROR: %v", err)
+		return nil
+	}
+	apiCollection := &api.CatalogCollection{
+		ID:          collection.ID,
+		Owner:       collection.Owner.Name,
+		Name:        collection.Name,
+		FullName:    collection.FullName(),
+		Title:       collection.Title,
+		Description: collection.Description,
+		URL:         collection.APIURL(),
+		HTMLURL:     collection.HTMLURL(),
+		Created:     collection.CreatedUnix.AsTime(),
+		Updated:     collection.UpdatedUnix.AsTime(),
+	}
+	if latest != nil {
+		apiCollection.LatestVersion = latest.Version
+	}
+	return apiCollection
+}
+
+// ToCatalogCollectionVersion converts a Door43CollectionVersion with the given members to an api.CatalogCollectionVersion
+func ToCatalogCollectionVersion(ctx context.Context, collection *repo.Door43Collection, v *repo.Door43CollectionVersion, members []*repo.Door43CollectionMember, doer *user_model.User) *api.CatalogCollectionVersion {
+	if err := collection.LoadOwner(ctx); err != nil {
+		log.Error("ToCatalogCollectionVersion: collection.LoadOwner() ERROR: %v", err)
+		return nil
+	}
+	apiVersion := &api.CatalogCollectionVersion{
+		ID:          v.ID,
+		Collection:  collection.FullName(),
+		Version:     v.Version,
+		Note:        v.Note,
+		Members:     make([]*api.CatalogCollectionMember, 0, len(members)),
+		DownloadURL: collection.APIURL() + "/versions/" + v.Version + "/download",
+		HTMLURL:     collection.HTMLURL() + "/" + v.Version,
+		Created:     v.CreatedUnix.AsTime(),
+	}
+	for _, m := range members {
+		member := &api.CatalogCollectionMember{
+			Ref:       m.Ref,
+			CommitSHA: m.CommitSHA,
+		}
+		if m.Repo != nil {
+			member.FullName = m.Repo.FullName()
+		}
+		if m.Entry != nil {
+			if err := m.Entry.LoadAttributes(ctx); err != nil {
+				log.Error("ToCatalogCollectionVersion: m.Entry.LoadAttributes() ERROR: %v", err)
+				return nil
+			}
+			perm, err := access_model.GetUserRepoPermission(ctx, m.Repo, doer)
+			if err != nil {
+				log.Error("ToCatalogCollectionVersion: GetUserRepoPermission() ERROR: %v", err)
+				return nil
+			}
+			member.Entry = ToCatalogEntry(ctx, m.Entry, perm)
+		}
+		apiVersion.Members = append(apiVersion.Members, member)
+	}
+	return apiVersion
+}
+
+// ToCatalogSubscription converts a Door43Subscription to an api.CatalogSubscription
+func ToCatalogSubscription(s *repo.Door43Subscription) *api.CatalogSubscription {
+	return &api.CatalogSubscription{
+		ID:          s.ID,
+		Name:        s.Name,
+		Languages:   emptyIfNil(s.Languages),
+		Subjects:    emptyIfNil(s.Subjects),
+		Owners:      emptyIfNil(s.Owners),
+		Books:       emptyIfNil(s.Books),
+		Stage:       s.Stage.String(),
+		EmailDigest: s.EmailDigest,
+		Created:     s.CreatedUnix.AsTime(),
+		Updated:     s.UpdatedUnix.AsTime(),
+	}
+}
+
+func emptyIfNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// ToCatalogDownloadStats converts the sums of download counts of the days from since to until, grouped by the given
+// fields, to an api.CatalogDownloadStats
+func ToCatalogDownloadStats(ctx context.Context, since, until time.Time, counts []*repo.Door43DownloadCount, groupBy []repo.Door43DownloadGroupBy) *api.CatalogDownloadStats {
+	stats := &api.CatalogDownloadStats{
+		Since: since.Format("2006-01-02"),
+		Until: until.Format("2006-01-02"),
+	}
+	grouped := make(map[repo.Door43DownloadGroupBy]bool, len(groupBy))
+	for _, g := range groupBy {
+		grouped[g] = true
+	}
+
+	repoIDs := make([]int64, 0, len(counts))
+	dms := make(map[int64]*repo.Door43Metadata)
+	for _, c := range counts {
+		if grouped[repo.Door43DownloadGroupByEntry] {
+			if _, ok := dms[c.Door43MetadataID]; !ok {
+				dm, err := repo.GetDoor43MetadataByID(ctx, c.Door43MetadataID, 0)
+				if err != nil && !repo.IsErrDoor43MetadataNotExist(err) {
+					log.Error("GetDoor43MetadataByID [%d]: %v", c.Door43MetadataID, err)
+				}
+				dms[c.Door43MetadataID] = dm
+				if dm != nil {
+					repoIDs = append(repoIDs, dm.RepoID)
+				}
+			}
+		}
+		if grouped[repo.Door43DownloadGroupByRepo] {
+			repoIDs = append(repoIDs, c.RepoID)
+		}
+	}
+	repos, err := repo.GetRepositoriesMapByIDs(ctx, repoIDs)
+	if err != nil {
+		log.Error("GetRepositoriesMapByIDs: %v", err)
+		repos = map[int64]*repo.Repository{}
+	}
+
+	for _, c := range counts {
+		stats.Total += c.Count
+		if len(groupBy) == 0 {
+			continue
+		}
+		apiCount := &api.CatalogDownloadCount{Count: c.Count}
+		if grouped[repo.Door43DownloadGroupByDay] {
+			apiCount.Day = c.Day.AsTimeInLocation(time.UTC).Format("2006-01-02")
+		}
+		if grouped[repo.Door43DownloadGroupByKind] {
+			apiCount.Kind = string(c.Kind)
+		}
+		if grouped[repo.Door43DownloadGroupByLanguage] {
+			apiCount.Language = c.Language
+		}
+		if grouped[repo.Door43DownloadGroupBySubject] {
+			apiCount.Subject = c.Subject
+		}
+		repoID := c.RepoID
+		if grouped[repo.Door43DownloadGroupByEntry] {
+			apiCount.EntryID = c.Door43MetadataID
+			if dm := dms[c.Door43MetadataID]; dm != nil {
+				apiCount.Ref = dm.Ref
+				repoID = dm.RepoID
+			}
+		}
+		if r, ok := repos[repoID]; ok {
+			apiCount.FullName = r.FullName()
+		}
+		stats.Counts = append(stats.Counts, apiCount)
+	}
+	return stats
+}
+
+// progressPercent returns the percentage of the total which is completed, rounded to one decimal
+func progressPercent(completed, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(completed)*1000/float64(total)) / 10
+}
+
+// ToTranslationProgress converts a Door43Metadata, with its repo loaded, to an api.TranslationProgress with the
+// progress of each of its books, and of each chapter if withChapters
+func ToTranslationProgress(dm *repo.Door43Metadata, withChapters bool) *api.TranslationProgress {
+	progress := &api.TranslationProgress{
+		Ref:           dm.Ref,
+		CommitSHA:     dm.CommitSHA,
+		Title:         dm.Title,
+		Subject:       dm.Subject,
+		MetadataType:  dm.MetadataType,
+		Language:      dm.Language,
+		LanguageTitle: dm.LanguageTitle,
+		Updated:       dm.UpdatedUnix.AsTime(),
+	}
+	if dm.Repo != nil {
+		progress.FullName = dm.Repo.FullName()
+		progress.Owner = dm.Repo.OwnerName
+		progress.Name = dm.Repo.Name
+		progress.URL = dm.GetProgressURL()
+	}
+	for _, ing := range dm.Ingredients {
+		if ing.Progress == nil {
+			continue
+		}
+		book := &api.BookProgress{
+			Identifier: ing.Identifier,
+			Title:      ing.Title,
+			Completed:  ing.Progress.Completed,
+			Total:      ing.Progress.Total,
+			Percent:    progressPercent(int64(ing.Progress.Completed), int64(ing.Progress.Total)),
+		}
+		if withChapters {
+			book.Chapters = ing.Progress.Chapters
+		}
+		progress.Completed += int64(book.Completed)
+		progress.Total += int64(book.Total)
+		progress.Books = append(progress.Books, book)
+	}
+	sort.SliceStable(progress.Books, func(i, j int) bool {
+		return dcs.GetBookSort(strings.ToLower(progress.Books[i].Identifier)) < dcs.GetBookSort(strings.ToLower(progress.Books[j].Identifier))
+	})
+	progress.Percent = progressPercent(progress.Completed, progress.Total)
+	return progress
+}
+
+// ToTranslationProgressReport converts the entries and the sum of their progress to an api.TranslationProgressReport
+func ToTranslationProgressReport(dms []*repo.Door43Metadata, total *repo.Door43Progress) *api.TranslationProgressReport {
+	report := &api.TranslationProgressReport{
+		Completed: total.Completed,
+		Total:     total.Total,
+		Percent:   progressPercent(total.Completed, total.Total),
+		Entries:   make([]*api.TranslationProgress, 0, len(dms)),
+	}
+	for _, dm := range dms {
+		report.Entries = append(report.Entries, ToTranslationProgress(dm, false))
+	}
+	return report
+}
+
+// ToCatalogLanguage converts a language code to an api.CatalogLanguage with its details from langnames.json
+func ToCatalogLanguage(lang string, entryCount int64) *api.CatalogLanguage {
+	return &api.CatalogLanguage{
+		Code:             lang,
+		Name:             dcs.GetLanguageTitle(lang),
+		AnglicizedName:   dcs.GetLanguageAnglicizedTitle(lang),
+		Direction:        dcs.GetLanguageDirection(lang),
+		Region:           dcs.GetLanguageRegion(lang),
+		Countries:        emptyIfNil(dcs.GetLanguageCountries(lang)),
+		AlternativeNames: emptyIfNil(dcs.GetLanguageAlternativeNames(lang)),
+		IsGL:             dcs.LanguageIsGL(lang),
+		GatewayLanguages: emptyIfNil(dcs.GetGatewayLanguages(lang)),
+		EntryCount:       entryCount,
+	}
+}
+
+// ToCatalogLanguageSubject converts the entries of a subject in a language to an api.CatalogLanguageSubject
+func ToCatalogLanguageSubject(subject string, dms []*repo.Door43Metadata) *api.CatalogLanguageSubject {
+	s := &api.CatalogLanguageSubject{
+		Subject: subject,
+		Total:   int64(len(dms)),
+		Stages:  make(map[string]int64, 3),
+	}
+	for _, dm := range dms {
+		s.Stages[dm.StageStr()]++
+	}
+	return s
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package convert
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm/contexts"
+)
+
+// queryCounter counts the queries run by the test engine
+type queryCounter struct {
+	count atomic.Int64
+}
+
+func (c *queryCounter) BeforeProcess(ctx *contexts.ContextHook) (context.Context, error) {
+	return ctx.Ctx, nil
+}
+
+func (c *queryCounter) AfterProcess(ctx *contexts.ContextHook) error {
+	c.count.Add(1)
+	return nil
+}
+
+var (
+	catalogQueryCounter     = &queryCounter{}
+	catalogQueryCounterOnce sync.Once
+)
+
+// insertBenchmarkCatalogEntries inserts an entry for each of the fixture releases and each repo's default branch
+func insertBenchmarkCatalogEntries(b *testing.B) []int64 {
+	releases := make([]*repo_model.Release, 0, 20)
+	assert.NoError(b, db.GetEngine(db.DefaultContext).Where("is_tag = ? AND is_draft = ?", false, false).Find(&releases))
+	repos := make([]*repo_model.Repository, 0, 50)
+	assert.NoError(b, db.GetEngine(db.DefaultContext).Limit(40).Find(&repos))
+
+	dms := make([]*repo_model.Door43Metadata, 0, len(releases)+len(repos))
+	for _, rel := range releases {
+		dms = append(dms, &repo_model.Door43Metadata{
+			RepoID:           rel.RepoID,
+			ReleaseID:        rel.ID,
+			Ref:              rel.TagName,
+			RefType:          "tag",
+			CommitSHA:        "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+			Stage:            door43metadata.StageProd,
+			MetadataType:     "rc",
+			Title:            rel.Title,
+			Language:         "en",
+			ReleaseDateUnix:  timeutil.TimeStampNow(),
+			IsLatestForStage: true,
+		})
+	}
+	for _, repo := range repos {
+		dms = append(dms, &repo_model.Door43Metadata{
+			RepoID:           repo.ID,
+			Ref:              "benchmark-branch",
+			RefType:          "branch",
+			CommitSHA:        "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+			Stage:            door43metadata.StageLatest,
+			MetadataType:     "rc",
+			Title:            repo.Name,
+			Language:         "en",
+			ReleaseDateUnix:  timeutil.TimeStampNow(),
+			IsLatestForStage: true,
+			IsRepoMetadata:   true,
+		})
+	}
+	ids := make([]int64, 0, len(dms))
+	for _, dm := range dms {
+		assert.NoError(b, db.Insert(db.DefaultContext, dm))
+		ids = append(ids, dm.ID)
+	}
+	return ids
+}
+
+// BenchmarkToCatalogEntries reports the queries needed to convert a page of catalog entries,
+// loading their attributes per entry or in batches with Door43MetadataList.LoadAttributes
+func BenchmarkToCatalogEntries(b *testing.B) {
+	assert.NoError(b, unittest.PrepareTestDatabase())
+	catalogQueryCounterOnce.Do(func() {
+		unittest.GetXORMEngine().AddHook(catalogQueryCounter)
+	})
+	ids := insertBenchmarkCatalogEntries(b)
+
+	convertEntries := func(b *testing.B, loadAttributes func(ctx context.Context, dms repo_model.Door43MetadataList) error) {
+		b.ReportAllocs()
+		start := catalogQueryCounter.count.Load()
+		for i := 0; i < b.N; i++ {
+			ctx := cache.WithCacheContext(db.DefaultContext)
+			dms := make(repo_model.Door43MetadataList, 0, len(ids))
+			assert.NoError(b, db.GetEngine(ctx).In("id", ids).Find(&dms))
+			assert.NoError(b, loadAttributes(ctx, dms))
+			perms := make(map[int64]access_model.Permission)
+			for _, dm := range dms {
+				perm, ok := perms[dm.RepoID]
+				if !ok {
+					var err error
+					perm, err = access_model.GetUserRepoPermission(ctx, dm.Repo, nil)
+					assert.NoError(b, err)
+					perms[dm.RepoID] = perm
+				}
+				assert.NotNil(b, ToCatalogEntry(ctx, dm, perm))
+			}
+		}
+		b.ReportMetric(float64(catalogQueryCounter.count.Load()-start)/float64(b.N), "queries/op")
+		b.ReportMetric(float64(len(ids)), "entries/op")
+	}
+
+	b.Run("PerEntry", func(b *testing.B) {
+		convertEntries(b, func(ctx context.Context, dms repo_model.Door43MetadataList) error {
+			for _, dm := range dms {
+				if err := dm.LoadAttributes(ctx); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+
+	b.Run("Batch", func(b *testing.B) {
+		convertEntries(b, func(ctx context.Context, dms repo_model.Door43MetadataList) error {
+			return dms.LoadAttributes(ctx)
+		})
+	})
+}
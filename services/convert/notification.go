@@ -5,11 +5,13 @@ package convert
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 
 	activities_model "code.gitea.io/gitea/models/activities"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 )
 
@@ -82,6 +84,15 @@ func ToNotificationThread(ctx context.Context, n *activities_model.Notification)
 			URL:     n.Repository.Link(),
 			HTMLURL: n.Repository.HTMLURL(),
 		}
+	/*** DCS Customizations ***/
+	case activities_model.NotificationSourceCatalogEntry:
+		result.Subject = &api.NotificationSubject{
+			Type:    api.NotifySubjectCatalogEntry,
+			Title:   n.Repository.FullName() + " " + n.CommitID,
+			URL:     fmt.Sprintf("%sapi/v1/catalog/entry/%s/%s/", setting.AppURL, n.Repository.FullName(), url.PathEscape(n.CommitID)),
+			HTMLURL: n.HTMLURL(ctx),
+		}
+		/*** END DCS Customizations ***/
 	}
 
 	return result
@@ -8,14 +8,30 @@ import (
 
 	"code.gitea.io/gitea/models"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/cache"
 	api "code.gitea.io/gitea/modules/structs"
 )
 
+// userRepoCatalogFields are the catalog values of a user's repos added to the API user
+type userRepoCatalogFields struct {
+	languages     []string
+	subjects      []string
+	metadataTypes []string
+}
+
 func toUserDCS(ctx context.Context, user *user_model.User, apiUser *api.User) *api.User {
 	if user != nil && apiUser != nil {
-		apiUser.RepoLanguages = models.GetRepoLanguages(ctx, user)
-		apiUser.RepoSubjects = models.GetRepoSubjects(ctx, user)
-		apiUser.RepoMetadataTypes = models.GetRepoMetadataTypes(ctx, user)
+		// The same owner is often converted for many entries of a request, so the values are kept in the request's cache
+		fields, _ := cache.GetWithContextCache(ctx, "user_repo_catalog_fields", user.ID, func() (*userRepoCatalogFields, error) {
+			return &userRepoCatalogFields{
+				languages:     models.GetRepoLanguages(ctx, user),
+				subjects:      models.GetRepoSubjects(ctx, user),
+				metadataTypes: models.GetRepoMetadataTypes(ctx, user),
+			}, nil
+		})
+		apiUser.RepoLanguages = fields.languages
+		apiUser.RepoSubjects = fields.subjects
+		apiUser.RepoMetadataTypes = fields.metadataTypes
 	}
 	return apiUser
 }
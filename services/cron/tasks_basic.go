@@ -183,6 +183,9 @@ func initBasicTasks() {
 	/*** DCS Customizations ***/
 	registerUpdateDoor43MetadataTask()
 	registerLoadMetadataSchemasTask()
+	registerRebuildCatalogIndexerTask()
+	registerSendCatalogSubscriptionDigestsTask()
+	registerCheckAttachmentLinksTask()
 	/*** END DCS Customizations ***/
 	if !setting.Repository.DisableMigrations {
 		registerUpdateMigrationPosterID()
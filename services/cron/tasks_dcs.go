@@ -6,7 +6,9 @@ package cron
 import (
 	"context"
 
+	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
+	catalog_indexer "code.gitea.io/gitea/modules/indexer/catalog"
 	metadata_service "code.gitea.io/gitea/services/door43metadata"
 )
 
@@ -16,7 +18,7 @@ func registerUpdateDoor43MetadataTask() {
 		RunAtStart: false,
 		Schedule:   "@every 72h",
 	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
-		return metadata_service.UpdateDoor43Metadata(ctx)
+		return metadata_service.UpdateDoor43Metadata(ctx, repo_model.Door43MetadataTriggerCron, false)
 	})
 }
 
@@ -29,3 +31,33 @@ func registerLoadMetadataSchemasTask() {
 		return metadata_service.LoadMetadataSchemas(ctx)
 	})
 }
+
+func registerRebuildCatalogIndexerTask() {
+	RegisterTaskFatal("rebuild_catalog_indexer", &BaseConfig{
+		Enabled:    false,
+		RunAtStart: false,
+		Schedule:   "@every 72h",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return catalog_indexer.PopulateCatalogIndexer(ctx)
+	})
+}
+
+func registerSendCatalogSubscriptionDigestsTask() {
+	RegisterTaskFatal("send_catalog_subscription_digests", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 24h",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return metadata_service.SendSubscriptionDigests(ctx)
+	})
+}
+
+func registerCheckAttachmentLinksTask() {
+	RegisterTaskFatal("check_attachment_links", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 24h",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return metadata_service.CheckAttachmentLinks(ctx)
+	})
+}
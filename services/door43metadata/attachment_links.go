@@ -0,0 +1,187 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/hostmatcher"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/proxy"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+// newAttachmentLinkHTTPClient returns the client checking the external files linked by attachments, which can only
+// connect to the hosts allowed by the ATTACHMENT_LINK_CHECK_ALLOWED_HOST_LIST setting
+func newAttachmentLinkHTTPClient() *http.Client {
+	allowList := hostmatcher.ParseHostMatchList("dcs.ATTACHMENT_LINK_CHECK_ALLOWED_HOST_LIST", setting.DCS.AttachmentLinkCheckAllowedHostList)
+	return &http.Client{
+		Timeout: setting.DCS.AttachmentLinkCheckTimeout,
+		Transport: &http.Transport{
+			Proxy:       proxy.Proxy(),
+			DialContext: hostmatcher.NewDialContextWithProxy("attachment link check", allowList, nil, setting.Proxy.ProxyURLFixed),
+		},
+	}
+}
+
+// CheckAttachmentLinks checks that the external files linked by release attachments can still be downloaded,
+// records the status of each link and emails the admins of the repos the links that were found broken
+func CheckAttachmentLinks(ctx context.Context) error {
+	if _, err := repo_model.DeleteOrphanedAttachmentLinkStatuses(ctx); err != nil {
+		return err
+	}
+
+	client := newAttachmentLinkHTTPClient()
+	numChecked := 0
+	numBroken := 0
+	numNewlyBroken := 0
+	newlyBroken := make(map[int64][]*repo_model.AttachmentLinkStatus) // by repo ID
+	err := db.Iterate(ctx, repo_model.IsExternalAttachmentCond(), func(ctx context.Context, a *repo_model.Attachment) error {
+		if !strings.HasPrefix(a.BrowserDownloadURL, "http://") && !strings.HasPrefix(a.BrowserDownloadURL, "https://") {
+			return nil // only HTTP links can be checked
+		}
+		status, wasBroken, err := checkAttachmentLink(ctx, client, a)
+		if err != nil {
+			return err
+		}
+		numChecked++
+		if status.IsBroken {
+			numBroken++
+			if !wasBroken {
+				numNewlyBroken++
+				newlyBroken[a.RepoID] = append(newlyBroken[a.RepoID], status)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Info("CheckAttachmentLinks: %d external attachment links checked, %d broken, %d newly broken", numChecked, numBroken, numNewlyBroken)
+
+	for repoID, statuses := range newlyBroken {
+		if err := notifyBrokenAttachmentLinks(ctx, repoID, statuses); err != nil {
+			log.Error("notifyBrokenAttachmentLinks [repo: %d]: %v", repoID, err)
+		}
+	}
+	return nil
+}
+
+// checkAttachmentLink requests the file linked by an attachment and saves the status of its link. Also returns
+// if the link was already broken. The size of the attachment is set from the response if it wasn't known
+func checkAttachmentLink(ctx context.Context, client *http.Client, a *repo_model.Attachment) (*repo_model.AttachmentLinkStatus, bool, error) {
+	status, err := repo_model.GetAttachmentLinkStatus(ctx, a.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if status == nil {
+		status = &repo_model.AttachmentLinkStatus{AttachmentID: a.ID}
+	} else if status.URL != a.BrowserDownloadURL {
+		// the link changed since it was checked
+		*status = repo_model.AttachmentLinkStatus{ID: status.ID, AttachmentID: a.ID}
+	}
+	wasBroken := status.IsBroken
+
+	status.RepoID = a.RepoID
+	status.ReleaseID = a.ReleaseID
+	status.Name = a.Name
+	status.URL = a.BrowserDownloadURL
+	status.StatusCode, status.Size, err = requestAttachmentLink(ctx, client, a.BrowserDownloadURL)
+	status.IsBroken = err != nil
+	status.Error = ""
+	if err != nil {
+		status.Error = err.Error()
+	}
+	status.CheckedUnix = timeutil.TimeStampNow()
+	if !status.IsBroken {
+		status.BrokenSinceUnix = 0
+	} else if status.BrokenSinceUnix == 0 {
+		status.BrokenSinceUnix = status.CheckedUnix
+	}
+	if err := repo_model.SaveAttachmentLinkStatus(ctx, status); err != nil {
+		return nil, false, err
+	}
+
+	if !status.IsBroken && a.Size == 0 && status.Size > 0 {
+		if _, err := db.GetEngine(ctx).ID(a.ID).Cols("size").NoAutoTime().Update(&repo_model.Attachment{Size: status.Size}); err != nil {
+			return nil, false, err
+		}
+	}
+	return status, wasBroken, nil
+}
+
+// requestAttachmentLink makes a HEAD request to the URL, or a GET request of its first byte if the server doesn't
+// allow HEAD requests, and returns the status code and the size of the file, -1 if unknown. Returns an error if the
+// file can't be downloaded
+func requestAttachmentLink(ctx context.Context, client *http.Client, url string) (int, int64, error) {
+	resp, err := doAttachmentLinkRequest(ctx, client, http.MethodHead, url)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp, err = doAttachmentLinkRequest(ctx, client, http.MethodGet, url)
+	}
+	if err != nil {
+		return 0, -1, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp.StatusCode, -1, fmt.Errorf("HTTP %s", resp.Status)
+	}
+
+	size := resp.ContentLength
+	if resp.StatusCode == http.StatusPartialContent {
+		// Content-Range: bytes 0-0/<size>
+		size = -1
+		if _, total, ok := strings.Cut(resp.Header.Get("Content-Range"), "/"); ok {
+			if n, err := strconv.ParseInt(total, 10, 64); err == nil {
+				size = n
+			}
+		}
+	}
+	return resp.StatusCode, size, nil
+}
+
+func doAttachmentLinkRequest(ctx context.Context, client *http.Client, method, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "GiteaServer")
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+	resp.Body.Close()
+	return resp, nil
+}
+
+// notifyBrokenAttachmentLinks emails the admins of a repo the links of its releases that were found broken
+func notifyBrokenAttachmentLinks(ctx context.Context, repoID int64, statuses []*repo_model.AttachmentLinkStatus) error {
+	repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	releases := make(map[int64]*repo_model.Release, len(statuses))
+	for _, status := range statuses {
+		if _, ok := releases[status.ReleaseID]; ok {
+			continue
+		}
+		release, err := repo_model.GetReleaseByID(ctx, status.ReleaseID)
+		if err != nil {
+			return err
+		}
+		releases[release.ID] = release
+	}
+	return mailer.SendReleaseBrokenLinksMail(ctx, repo, statuses, releases)
+}
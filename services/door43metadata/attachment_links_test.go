@@ -0,0 +1,66 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestAttachmentLink(t *testing.T) {
+	defer test.MockVariableValue(&setting.DCS.AttachmentLinkCheckTimeout, 5*time.Second)()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/file.mp3":
+			w.Header().Set("Content-Length", "1234")
+		case "/no-head.pdf":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			assert.Equal(t, "bytes=0-0", r.Header.Get("Range"))
+			w.Header().Set("Content-Range", "bytes 0-0/5678")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte("%"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("NotAllowed", func(t *testing.T) {
+		defer test.MockVariableValue(&setting.DCS.AttachmentLinkCheckAllowedHostList, "external")()
+		code, size, err := requestAttachmentLink(context.Background(), newAttachmentLinkHTTPClient(), server.URL+"/file.mp3")
+		assert.Error(t, err)
+		assert.Equal(t, 0, code)
+		assert.EqualValues(t, -1, size)
+	})
+
+	defer test.MockVariableValue(&setting.DCS.AttachmentLinkCheckAllowedHostList, "loopback")()
+	client := newAttachmentLinkHTTPClient()
+
+	code, size, err := requestAttachmentLink(context.Background(), client, server.URL+"/file.mp3")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.EqualValues(t, 1234, size)
+
+	code, size, err = requestAttachmentLink(context.Background(), client, server.URL+"/no-head.pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPartialContent, code)
+	assert.EqualValues(t, 5678, size)
+
+	code, size, err = requestAttachmentLink(context.Background(), client, server.URL+"/missing.mp4")
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.EqualValues(t, -1, size)
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// CheckDoor43Metadata records that the doer has checked the commit of a door43 metadata entry at the given level.
+// The doer must be designated as a checker by the repo's owner for that level.
+func CheckDoor43Metadata(ctx context.Context, doer *user_model.User, dm *repo_model.Door43Metadata, level int, note string) (*repo_model.Door43Check, error) {
+	if !repo_model.IsValidCheckingLevel(level) {
+		return nil, repo_model.ErrInvalidCheckingLevel{Level: level}
+	}
+	if err := dm.LoadRepo(ctx); err != nil {
+		return nil, err
+	}
+	checker, err := repo_model.GetDoor43Checker(ctx, dm.Repo.OwnerID, doer.ID)
+	if err != nil {
+		return nil, err
+	}
+	if level > checker.MaxLevel {
+		return nil, repo_model.ErrCheckingLevelNotAllowed{Level: level, MaxLevel: checker.MaxLevel}
+	}
+
+	check := &repo_model.Door43Check{
+		RepoID:    dm.RepoID,
+		CommitSHA: dm.CommitSHA,
+		CheckerID: doer.ID,
+		Checker:   doer,
+		Level:     level,
+		Note:      note,
+	}
+	var verifiedLevel int
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := repo_model.InsertDoor43Check(ctx, check); err != nil {
+			return err
+		}
+		verifiedLevel, err = repo_model.UpdateVerifiedCheckingLevel(ctx, dm.Repo, dm.CommitSHA)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	dm.VerifiedCheckingLevel = verifiedLevel
+
+	log.Trace("Door43 Metadata checked at level %d by %s for repo: %s, ref: %s", level, doer.Name, dm.Repo.FullName(), dm.Ref)
+
+	return check, nil
+}
+
+// RemoveDoor43Checker removes a checker designated by an owner and recalculates the verified checking levels
+// of the owner's commits the checker had signed off
+func RemoveDoor43Checker(ctx context.Context, owner, checker *user_model.User) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		checks, err := repo_model.GetCheckedCommitsByOwnerAndChecker(ctx, owner.ID, checker.ID)
+		if err != nil {
+			return err
+		}
+		if err := repo_model.DeleteDoor43Checker(ctx, owner.ID, checker.ID); err != nil {
+			return err
+		}
+		return updateVerifiedCheckingLevels(ctx, checks)
+	})
+}
+
+// SetDoor43Checker designates a user as a checker of the owner's resources up to the given level and
+// recalculates the verified checking levels of the owner's commits the checker has signed off
+func SetDoor43Checker(ctx context.Context, owner, checker *user_model.User, maxLevel int) (*repo_model.Door43Checker, error) {
+	var c *repo_model.Door43Checker
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		c, err = repo_model.SetDoor43Checker(ctx, owner.ID, checker.ID, maxLevel)
+		if err != nil {
+			return err
+		}
+		checks, err := repo_model.GetCheckedCommitsByOwnerAndChecker(ctx, owner.ID, checker.ID)
+		if err != nil {
+			return err
+		}
+		return updateVerifiedCheckingLevels(ctx, checks)
+	}); err != nil {
+		return nil, err
+	}
+	c.Checker = checker
+	return c, nil
+}
+
+func updateVerifiedCheckingLevels(ctx context.Context, checks []*repo_model.Door43Check) error {
+	for _, check := range checks {
+		repo, err := repo_model.GetRepositoryByID(ctx, check.RepoID)
+		if err != nil {
+			log.Error("GetRepositoryByID [%d]: %v", check.RepoID, err)
+			continue
+		}
+		if _, err := repo_model.UpdateVerifiedCheckingLevel(ctx, repo, check.CommitSHA); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDoor43Metadata(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	checker := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4})
+	dm := &repo_model.Door43Metadata{RepoID: 1, Ref: "v1.1", RefType: "tag", CommitSHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d"}
+	assert.NoError(t, db.Insert(db.DefaultContext, dm))
+
+	// Only designated checkers can check
+	_, err := CheckDoor43Metadata(db.DefaultContext, checker, dm, 1, "")
+	assert.True(t, repo_model.IsErrDoor43CheckerNotExist(err))
+
+	_, err = SetDoor43Checker(db.DefaultContext, owner, checker, 2)
+	assert.NoError(t, err)
+
+	_, err = CheckDoor43Metadata(db.DefaultContext, checker, dm, 0, "")
+	assert.True(t, repo_model.IsErrInvalidCheckingLevel(err))
+	_, err = CheckDoor43Metadata(db.DefaultContext, checker, dm, 3, "")
+	assert.True(t, repo_model.IsErrCheckingLevelNotAllowed(err))
+	unittest.AssertCount(t, &repo_model.Door43Check{}, 0)
+
+	check, err := CheckDoor43Metadata(db.DefaultContext, checker, dm, 2, "looks good")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, check.Level)
+	assert.Equal(t, 2, dm.VerifiedCheckingLevel)
+	assert.Equal(t, 2, unittest.AssertExistsAndLoadBean(t, &repo_model.Door43Metadata{ID: dm.ID}).VerifiedCheckingLevel)
+
+	// Lowering the checker's level only counts the checks at or below it
+	_, err = SetDoor43Checker(db.DefaultContext, owner, checker, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, unittest.AssertExistsAndLoadBean(t, &repo_model.Door43Metadata{ID: dm.ID}).VerifiedCheckingLevel)
+
+	_, err = SetDoor43Checker(db.DefaultContext, owner, checker, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, unittest.AssertExistsAndLoadBean(t, &repo_model.Door43Metadata{ID: dm.ID}).VerifiedCheckingLevel)
+
+	// Removing the checker no longer counts their checks
+	assert.NoError(t, RemoveDoor43Checker(db.DefaultContext, owner, checker))
+	unittest.AssertNotExistsBean(t, &repo_model.Door43Checker{OwnerID: owner.ID, CheckerID: checker.ID})
+	assert.Equal(t, 0, unittest.AssertExistsAndLoadBean(t, &repo_model.Door43Metadata{ID: dm.ID}).VerifiedCheckingLevel)
+	unittest.AssertCount(t, &repo_model.Door43Check{}, 1)
+}
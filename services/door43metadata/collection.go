@@ -0,0 +1,386 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/sync"
+	archiver_service "code.gitea.io/gitea/services/repository/archiver"
+)
+
+// CollectionManifestFilename is the name of the file describing the collection in its combined archive
+const CollectionManifestFilename = "collection.json"
+
+// CreateCollectionVersion releases a version of a collection pinning the given release tags. Each member must be
+// a catalog entry of a release tag of a repo the doer can read, and a repo can only be a member once.
+func CreateCollectionVersion(ctx context.Context, doer *user_model.User, collection *repo_model.Door43Collection, version, note string, members []*api.CatalogCollectionMemberOption) (*repo_model.Door43CollectionVersion, error) {
+	v := &repo_model.Door43CollectionVersion{
+		CollectionID: collection.ID,
+		Collection:   collection,
+		Version:      version,
+		Note:         note,
+		PublisherID:  doer.ID,
+		Members:      make([]*repo_model.Door43CollectionMember, 0, len(members)),
+	}
+
+	seen := make(container.Set[int64])
+	for _, opt := range members {
+		fullName := opt.Owner + "/" + opt.Repo
+		repo, err := repo_model.GetRepositoryByOwnerAndName(ctx, opt.Owner, opt.Repo)
+		if err != nil {
+			if repo_model.IsErrRepoNotExist(err) {
+				return nil, repo_model.ErrDoor43CollectionMemberInvalid{Repo: fullName, Ref: opt.Ref, Reason: "repository does not exist"}
+			}
+			return nil, err
+		}
+		if canRead, err := canReadCollectionMemberRepo(ctx, doer, repo); err != nil {
+			return nil, err
+		} else if !canRead {
+			return nil, repo_model.ErrDoor43CollectionMemberInvalid{Repo: fullName, Ref: opt.Ref, Reason: "repository does not exist"}
+		}
+		if !seen.Add(repo.ID) {
+			return nil, repo_model.ErrDoor43CollectionMemberInvalid{Repo: fullName, Ref: opt.Ref, Reason: "repository is already a member"}
+		}
+		dm, err := repo_model.GetDoor43MetadataByRepoIDAndRef(ctx, repo.ID, opt.Ref)
+		if err != nil {
+			if repo_model.IsErrDoor43MetadataNotExist(err) {
+				return nil, repo_model.ErrDoor43CollectionMemberInvalid{Repo: fullName, Ref: opt.Ref, Reason: "ref is not a catalog entry"}
+			}
+			return nil, err
+		}
+		if dm.RefType != "tag" {
+			return nil, repo_model.ErrDoor43CollectionMemberInvalid{Repo: fullName, Ref: opt.Ref, Reason: "ref is not a release tag"}
+		}
+		dm.Repo = repo
+		v.Members = append(v.Members, &repo_model.Door43CollectionMember{
+			RepoID:    repo.ID,
+			Repo:      repo,
+			Ref:       dm.Ref,
+			CommitSHA: dm.CommitSHA,
+			Entry:     dm,
+		})
+	}
+
+	if err := repo_model.InsertDoor43CollectionVersion(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// GetCollectionVersionMembers loads the members of a collection version with their catalog entries,
+// leaving out the members whose repo no longer exists or the doer cannot read
+func GetCollectionVersionMembers(ctx context.Context, doer *user_model.User, v *repo_model.Door43CollectionVersion) ([]*repo_model.Door43CollectionMember, error) {
+	if err := v.LoadMembers(ctx); err != nil {
+		return nil, err
+	}
+	members := make([]*repo_model.Door43CollectionMember, 0, len(v.Members))
+	for _, m := range v.Members {
+		if err := m.LoadEntry(ctx); err != nil {
+			return nil, err
+		}
+		if m.Repo == nil {
+			continue
+		}
+		if canRead, err := canReadCollectionMemberRepo(ctx, doer, m.Repo); err != nil {
+			return nil, err
+		} else if canRead {
+			members = append(members, m)
+		}
+	}
+	return members, nil
+}
+
+func canReadCollectionMemberRepo(ctx context.Context, doer *user_model.User, repo *repo_model.Repository) (bool, error) {
+	perm, err := access_model.GetUserRepoPermission(ctx, repo, doer)
+	if err != nil {
+		return false, err
+	}
+	return perm.CanRead(unit.TypeCode), nil
+}
+
+// collectionManifest describes a collection version in its combined archive
+type collectionManifest struct {
+	Collection  string                     `json:"collection"`
+	Title       string                     `json:"title"`
+	Description string                     `json:"description"`
+	Version     string                     `json:"version"`
+	Note        string                     `json:"note"`
+	Released    time.Time                  `json:"released"`
+	Members     []*collectionManifestEntry `json:"members"`
+}
+
+// collectionManifestEntry describes a member of a collection version in its combined archive
+type collectionManifestEntry struct {
+	FullName  string `json:"full_name"`
+	Ref       string `json:"ref"`
+	CommitSHA string `json:"commit_sha"`
+	Path      string `json:"path"`
+}
+
+// GetCollectionArchiveName returns the file name of the combined archive of a collection version
+func GetCollectionArchiveName(collection *repo_model.Door43Collection, v *repo_model.Door43CollectionVersion) string {
+	return fmt.Sprintf("%s-%s.zip", strings.ReplaceAll(collection.FullName(), "/", "-"), v.Version)
+}
+
+// ErrCollectionArchiveTooLarge represents an error that the combined archive of a collection version exceeds the maximum size
+type ErrCollectionArchiveTooLarge struct {
+	MaxSize int64
+}
+
+// IsErrCollectionArchiveTooLarge checks if an error is a ErrCollectionArchiveTooLarge.
+func IsErrCollectionArchiveTooLarge(err error) bool {
+	_, ok := err.(ErrCollectionArchiveTooLarge)
+	return ok
+}
+
+func (err ErrCollectionArchiveTooLarge) Error() string {
+	return fmt.Sprintf("collection archive is larger than the maximum size [max_size: %d]", err.MaxSize)
+}
+
+var collectionArchiveWorkingPool = sync.NewExclusivePool()
+
+func newCollectionManifest(collection *repo_model.Door43Collection, v *repo_model.Door43CollectionVersion, members []*repo_model.Door43CollectionMember) *collectionManifest {
+	manifest := &collectionManifest{
+		Collection:  collection.FullName(),
+		Title:       collection.Title,
+		Description: collection.Description,
+		Version:     v.Version,
+		Note:        v.Note,
+		Released:    v.CreatedUnix.AsTime(),
+		Members:     make([]*collectionManifestEntry, 0, len(members)),
+	}
+	for _, m := range members {
+		manifest.Members = append(manifest.Members, &collectionManifestEntry{
+			FullName:  m.Repo.FullName(),
+			Ref:       m.Ref,
+			CommitSHA: m.CommitSHA,
+			Path:      m.Repo.FullName() + "/",
+		})
+	}
+	return manifest
+}
+
+// collectionArchivesPath returns the directory of the combined archives of a collection's versions in the repo archives storage
+func collectionArchivesPath(collectionID int64) string {
+	return fmt.Sprintf("door43-collections/%d", collectionID)
+}
+
+// GetCollectionArchivePath returns the path in the repo archives storage of the combined archive of a collection version
+// with the given members, which depends on the manifest since the members the doer can read and the collection's title may differ
+func GetCollectionArchivePath(collection *repo_model.Door43Collection, v *repo_model.Door43CollectionVersion, members []*repo_model.Door43CollectionMember) (string, error) {
+	manifest, err := json.Marshal(newCollectionManifest(collection, v, members))
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(manifest)
+	return fmt.Sprintf("%s/%d-%s.zip", collectionArchivesPath(collection.ID), v.ID, hex.EncodeToString(hash[:10])), nil
+}
+
+// PrepareCollectionArchive builds the combined archive of a collection version with the given members in the repo archives
+// storage, unless it was already built, returning its path there
+func PrepareCollectionArchive(ctx context.Context, collection *repo_model.Door43Collection, v *repo_model.Door43CollectionVersion, members []*repo_model.Door43CollectionMember) (string, error) {
+	rPath, err := GetCollectionArchivePath(collection, v, members)
+	if err != nil {
+		return "", err
+	}
+	collectionArchiveWorkingPool.CheckIn(rPath)
+	defer collectionArchiveWorkingPool.CheckOut(rPath)
+
+	if _, err := storage.RepoArchives.Stat(rPath); err == nil {
+		return rPath, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("unable to stat collection archive: %w", err)
+	}
+
+	tmp, err := os.CreateTemp("", "door43-collection-*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+	w := &maxSizeWriter{w: tmp, remaining: setting.DCS.CollectionArchiveMaxSize}
+	if err := WriteCollectionArchive(ctx, w, collection, v, members); err != nil {
+		return "", err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	if _, err := storage.RepoArchives.Save(rPath, tmp, size); err != nil {
+		return "", fmt.Errorf("unable to write collection archive: %w", err)
+	}
+	return rPath, nil
+}
+
+// DeleteCollection deletes a collection with all its versions and their combined archives
+func DeleteCollection(ctx context.Context, collection *repo_model.Door43Collection) error {
+	if err := repo_model.DeleteDoor43Collection(ctx, collection); err != nil {
+		return err
+	}
+	if err := deleteCollectionArchives(collection.ID, 0); err != nil {
+		log.Error("Unable to delete the archives of collection %d: %v", collection.ID, err)
+	}
+	return nil
+}
+
+// DeleteCollectionVersion deletes a version of a collection and its combined archives
+func DeleteCollectionVersion(ctx context.Context, v *repo_model.Door43CollectionVersion) error {
+	if err := repo_model.DeleteDoor43CollectionVersion(ctx, v); err != nil {
+		return err
+	}
+	if err := deleteCollectionArchives(v.CollectionID, v.ID); err != nil {
+		log.Error("Unable to delete the archives of version %d of collection %d: %v", v.ID, v.CollectionID, err)
+	}
+	return nil
+}
+
+// deleteCollectionArchives deletes the combined archives built of a collection's version, or of all its versions if versionID is 0
+func deleteCollectionArchives(collectionID, versionID int64) error {
+	err := storage.RepoArchives.IterateObjects(collectionArchivesPath(collectionID), func(p string, obj storage.Object) error {
+		_ = obj.Close()
+		if versionID != 0 && !strings.HasPrefix(path.Base(p), fmt.Sprintf("%d-", versionID)) {
+			return nil
+		}
+		return storage.RepoArchives.Delete(p)
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// maxSizeWriter is a writer that fails once more than the maximum size is written
+type maxSizeWriter struct {
+	w         io.Writer
+	remaining int64
+}
+
+func (w *maxSizeWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > w.remaining {
+		return 0, ErrCollectionArchiveTooLarge{MaxSize: setting.DCS.CollectionArchiveMaxSize}
+	}
+	n, err := w.w.Write(p)
+	w.remaining -= int64(n)
+	return n, err
+}
+
+// WriteCollectionArchive writes a zip archive of the contents of the members at their pinned commits, each
+// in a directory named after the member's repo, along with a manifest of the collection version
+func WriteCollectionArchive(ctx context.Context, w io.Writer, collection *repo_model.Door43Collection, v *repo_model.Door43CollectionVersion, members []*repo_model.Door43CollectionMember) error {
+	zw := zip.NewWriter(w)
+	mw, err := zw.Create(CollectionManifestFilename)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(mw).Encode(newCollectionManifest(collection, v, members)); err != nil {
+		return err
+	}
+	for _, m := range members {
+		if err := copyMemberArchive(ctx, zw, m); err != nil {
+			if IsErrCollectionArchiveTooLarge(err) {
+				return err
+			}
+			return fmt.Errorf("archive of %s@%s: %w", m.Repo.FullName(), m.Ref, err)
+		}
+	}
+	return zw.Close()
+}
+
+// copyMemberArchive copies the files of the repo archive of the member's pinned commit, generated by the archiver
+// queue if it isn't already, to the combined archive
+func copyMemberArchive(ctx context.Context, zw *zip.Writer, m *repo_model.Door43CollectionMember) error {
+	gitRepo, err := git.OpenRepository(ctx, m.Repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	aReq, err := archiver_service.NewRequest(m.Repo.ID, gitRepo, m.CommitSHA+".zip")
+	if err != nil {
+		return err
+	}
+	archiver, err := aReq.Await(ctx)
+	if err != nil {
+		return err
+	}
+	fr, err := storage.RepoArchives.Open(archiver.RelativePath())
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
+
+	tmp, err := os.CreateTemp("", "door43-collection-member-*.zip")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+	size, err := io.Copy(tmp, fr)
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(tmp, size)
+	if err != nil {
+		return err
+	}
+
+	// the repo archives have their files in a directory named after the repo if PrefixArchiveFiles is set
+	archivePrefix := ""
+	if setting.Repository.PrefixArchiveFiles {
+		archivePrefix = m.Repo.Name + "/"
+	}
+	prefix := m.Repo.FullName()
+	for _, f := range zr.File {
+		name := strings.TrimPrefix(f.Name, archivePrefix)
+		if name == "" {
+			continue
+		}
+		header := f.FileHeader
+		header.Name = path.Join(prefix, name)
+		if f.FileInfo().IsDir() {
+			header.Name += "/"
+		}
+		fw, err := zw.CreateRaw(&header)
+		if err != nil {
+			return err
+		}
+		fr, err := f.OpenRaw()
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(fw, fr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
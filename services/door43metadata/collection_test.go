@@ -0,0 +1,93 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/test"
+	archiver_service "code.gitea.io/gitea/services/repository/archiver"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readCollectionArchive(t *testing.T, rPath string) []string {
+	fr, err := storage.RepoArchives.Open(rPath)
+	assert.NoError(t, err)
+	defer fr.Close()
+	data, err := io.ReadAll(fr)
+	assert.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+	names := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestPrepareCollectionArchive(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	assert.NoError(t, repo.LoadOwner(db.DefaultContext))
+	commitSHA := "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+
+	// The archive of the member is generated by the archiver queue, which isn't running in unit tests
+	gitRepo, err := git.OpenRepository(git.DefaultContext, repo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+	aReq, err := archiver_service.NewRequest(repo.ID, gitRepo, commitSHA+".zip")
+	assert.NoError(t, err)
+	_, err = archiver_service.ArchiveRepository(db.DefaultContext, aReq)
+	assert.NoError(t, err)
+
+	collection := &repo_model.Door43Collection{ID: 1, OwnerID: owner.ID, Owner: owner, Name: "bible", Title: "Bible"}
+	v := &repo_model.Door43CollectionVersion{ID: 1, CollectionID: collection.ID, Version: "v1"}
+	members := []*repo_model.Door43CollectionMember{{RepoID: repo.ID, Repo: repo, Ref: "v1.1", CommitSHA: commitSHA}}
+
+	rPath, err := PrepareCollectionArchive(db.DefaultContext, collection, v, members)
+	assert.NoError(t, err)
+	names := readCollectionArchive(t, rPath)
+	assert.Contains(t, names, CollectionManifestFilename)
+	assert.Contains(t, names, "user2/repo1/README.md")
+
+	// The archive is built once and depends on the members the doer can read
+	rPath2, err := PrepareCollectionArchive(db.DefaultContext, collection, v, members)
+	assert.NoError(t, err)
+	assert.Equal(t, rPath, rPath2)
+	emptyPath, err := PrepareCollectionArchive(db.DefaultContext, collection, v, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, rPath, emptyPath)
+	assert.Equal(t, []string{CollectionManifestFilename}, readCollectionArchive(t, emptyPath))
+
+	// An archive larger than the maximum size isn't stored
+	defer test.MockVariableValue(&setting.DCS.CollectionArchiveMaxSize, 100)()
+	v2 := &repo_model.Door43CollectionVersion{ID: 2, CollectionID: collection.ID, Version: "v2"}
+	_, err = PrepareCollectionArchive(db.DefaultContext, collection, v2, members)
+	assert.True(t, IsErrCollectionArchiveTooLarge(err))
+	v2Path, err := GetCollectionArchivePath(collection, v2, members)
+	assert.NoError(t, err)
+	_, err = storage.RepoArchives.Stat(v2Path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	assert.NoError(t, deleteCollectionArchives(collection.ID, v.ID))
+	_, err = storage.RepoArchives.Stat(rPath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = storage.RepoArchives.Stat(emptyPath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.NoError(t, deleteCollectionArchives(collection.ID, 0))
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/git"
+	catalog_indexer "code.gitea.io/gitea/modules/indexer/catalog"
+
+	"xorm.io/builder"
+)
+
+// RefreshLatestDoor43Metadata determines again which entries of a repo are the latest for their stage and which one
+// is the repo's metadata, and reindexes them
+func RefreshLatestDoor43Metadata(ctx context.Context, repoID int64) error {
+	repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	// handleRepoDM only unsets the other entries if the repo's metadata entry isn't already set
+	if _, err := db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repo.ID}).
+		Cols("is_repo_metadata").
+		Update(&repo_model.Door43Metadata{IsRepoMetadata: false}); err != nil {
+		return err
+	}
+	if err := processDoor43MetadataForRepoLatestDMs(ctx, repo); err != nil {
+		return err
+	}
+	catalog_indexer.UpdateRepoIndexer(ctx, repo.ID)
+	return nil
+}
+
+// DeleteDoor43Metadatas deletes the entries and their search index, then refreshes the latest entries of their repos
+func DeleteDoor43Metadatas(ctx context.Context, dms repo_model.Door43MetadataList) error {
+	repoIDs := make(container.Set[int64])
+	for _, dm := range dms {
+		catalog_indexer.DeleteCatalogIndexer(ctx, dm.ID)
+		if err := repo_model.DeleteDoor43Metadata(ctx, dm); err != nil {
+			return err
+		}
+		repoIDs.Add(dm.RepoID)
+	}
+	for repoID := range repoIDs {
+		if err := RefreshLatestDoor43Metadata(ctx, repoID); err != nil && !repo_model.IsErrRepoNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteRepoDoor43Metadatas deletes all the entries of a repo and their search index
+func DeleteRepoDoor43Metadatas(ctx context.Context, repoID int64) error {
+	catalog_indexer.DeleteRepoCatalogIndexer(ctx, repoID)
+	_, err := repo_model.DeleteAllDoor43MetadatasByRepoID(ctx, repoID)
+	return err
+}
+
+// GetUnreachableDoor43Metadatas returns the entries of a repo whose ref no longer exists or whose commit
+// is no longer in the repo
+func GetUnreachableDoor43Metadatas(ctx context.Context, repo *repo_model.Repository) (repo_model.Door43MetadataList, error) {
+	dms, err := repo_model.GetDoor43MetadatasByRepoID(ctx, repo.ID)
+	if err != nil || len(dms) == 0 {
+		return nil, err
+	}
+	gitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, repo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	unreachable := make(repo_model.Door43MetadataList, 0, len(dms))
+	for _, dm := range dms {
+		refExists := gitRepo.IsBranchExist(dm.Ref)
+		if dm.RefType == "tag" {
+			refExists = gitRepo.IsTagExist(dm.Ref)
+		}
+		if !refExists || !gitRepo.IsCommitExist(dm.CommitSHA) {
+			unreachable = append(unreachable, dm)
+		}
+	}
+	return unreachable, nil
+}
+
+// FixUnreachableDoor43Metadatas deletes the unreachable entries of a repo and processes the metadata of its refs again
+func FixUnreachableDoor43Metadatas(ctx context.Context, repo *repo_model.Repository, dms repo_model.Door43MetadataList) error {
+	if err := DeleteDoor43Metadatas(ctx, dms); err != nil {
+		return err
+	}
+	return ProcessDoor43MetadataForRepo(ctx, repo, "", repo_model.Door43MetadataTriggerDoctor)
+}
@@ -0,0 +1,814 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	files_service "code.gitea.io/gitea/services/repository/files"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The metadata types metadata can be converted between and their files
+const (
+	MetadataTypeRC = "rc"
+	MetadataTypeSB = "sb"
+
+	rcManifestFile = "manifest.yaml"
+	sbMetadataFile = "metadata.json"
+)
+
+// rcRightsLicenseURLs are the URLs of the licenses of the RC rights values
+var rcRightsLicenseURLs = map[string]string{
+	"CC BY 3.0":    "https://creativecommons.org/licenses/by/3.0/",
+	"CC BY-SA 3.0": "https://creativecommons.org/licenses/by-sa/3.0/",
+	"CC BY-SA 4.0": "https://creativecommons.org/licenses/by-sa/4.0/",
+}
+
+// rcRightsWithoutURL are the RC rights values which have no license URL
+var rcRightsWithoutURL = []string{"Free Translate 2.0 International Public License", "Public Domain"}
+
+// rcIdentifierRegex matches the identifiers of RC resources
+var rcIdentifierRegex = regexp.MustCompile(`^[a-z][a-z0-9-]`)
+
+// usfmBookRegex matches the book of USFM file names like 43-JHN.usfm or JHN.usfm
+var usfmBookRegex = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])([1-3a-z][a-z0-9][a-z0-9])\.usfm$`)
+
+// ErrMetadataConversion represents metadata which can't be converted to the other metadata type
+type ErrMetadataConversion struct {
+	From   string
+	To     string
+	Reason string
+}
+
+// IsErrMetadataConversion checks if an error is a ErrMetadataConversion.
+func IsErrMetadataConversion(err error) bool {
+	_, ok := err.(ErrMetadataConversion)
+	return ok
+}
+
+func (err ErrMetadataConversion) Error() string {
+	return fmt.Sprintf("metadata can't be converted from %s to %s: %s", err.From, err.To, err.Reason)
+}
+
+func (err ErrMetadataConversion) Unwrap() error {
+	return util.ErrInvalidArgument
+}
+
+// ConvertedMetadata is the metadata file of a commit converted to the other metadata type
+type ConvertedMetadata struct {
+	From     string
+	To       string
+	TreePath string
+	Content  []byte
+}
+
+// ConvertMetadata converts the RC manifest.yaml of a commit to a SB 1.0 metadata.json or the SB metadata.json to a RC
+// manifest.yaml. If to is empty, the metadata found is converted to the other type. The converted metadata is
+// validated against the schema of its type
+func ConvertMetadata(repo *repo_model.Repository, commit *git.Commit, to string) (*ConvertedMetadata, error) {
+	var from string
+	switch to {
+	case MetadataTypeSB:
+		from = MetadataTypeRC
+	case MetadataTypeRC:
+		from = MetadataTypeSB
+	case "":
+		if _, err := commit.GetTreeEntryByPath(rcManifestFile); err == nil {
+			from, to = MetadataTypeRC, MetadataTypeSB
+		} else if _, err := commit.GetTreeEntryByPath(sbMetadataFile); err == nil {
+			from, to = MetadataTypeSB, MetadataTypeRC
+		} else {
+			return nil, util.NewNotExistErrorf("neither %s nor %s found", rcManifestFile, sbMetadataFile)
+		}
+	default:
+		return nil, util.NewInvalidArgumentErrorf("metadata can only be converted to %s or %s", MetadataTypeRC, MetadataTypeSB)
+	}
+
+	converted := &ConvertedMetadata{From: from, To: to}
+	var result any
+	var err error
+	if from == MetadataTypeRC {
+		converted.TreePath = sbMetadataFile
+		result, err = convertRCManifestToSB(repo, commit)
+	} else {
+		converted.TreePath = rcManifestFile
+		result, err = convertSBMetadataToRC(repo, commit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the schemas validate JSON values
+	content, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]any
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+	validate := dcs.ValidateMapBySB100Schema
+	if to == MetadataTypeRC {
+		validate = dcs.ValidateMapByRC02Schema
+	}
+	valErr, err := validate(&data)
+	if err != nil {
+		return nil, err
+	}
+	if valErr != nil {
+		return nil, ErrMetadataConversion{From: from, To: to, Reason: "the converted metadata is not valid: " + dcs.ConvertValidationErrorToString(valErr)}
+	}
+
+	if to == MetadataTypeSB {
+		converted.Content, err = json.MarshalIndent(result, "", "  ")
+	} else {
+		converted.Content, err = yaml.Marshal(result)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return converted, nil
+}
+
+// CommitConvertedMetadataOptions are the options to commit the converted metadata of a branch
+type CommitConvertedMetadataOptions struct {
+	To        string
+	Branch    string // branch of which the metadata is converted, the default branch if empty
+	NewBranch string // branch the converted metadata is committed to, Branch if empty
+	Message   string
+}
+
+// CommitConvertedMetadata converts the metadata of a branch and commits it to the branch or to a new one
+func CommitConvertedMetadata(ctx context.Context, repo *repo_model.Repository, gitRepo *git.Repository, doer *user_model.User, opts CommitConvertedMetadataOptions) (*api.FileResponse, error) {
+	if opts.Branch == "" {
+		opts.Branch = repo.DefaultBranch
+	}
+	commit, err := gitRepo.GetBranchCommit(opts.Branch)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := ConvertMetadata(repo, commit, opts.To)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &files_service.ChangeRepoFile{
+		Operation:     "create",
+		TreePath:      converted.TreePath,
+		ContentReader: strings.NewReader(string(converted.Content)),
+	}
+	if entry, err := commit.GetTreeEntryByPath(converted.TreePath); err == nil {
+		file.Operation = "update"
+		file.SHA = entry.ID.String()
+	} else if !git.IsErrNotExist(err) {
+		return nil, err
+	}
+	if opts.Message == "" {
+		opts.Message = fmt.Sprintf("Convert %s to %s", map[string]string{MetadataTypeRC: rcManifestFile, MetadataTypeSB: sbMetadataFile}[converted.From], converted.TreePath)
+	}
+
+	filesResponse, err := files_service.ChangeRepoFiles(ctx, repo, doer, &files_service.ChangeRepoFilesOptions{
+		LastCommitID: commit.ID.String(),
+		OldBranch:    opts.Branch,
+		NewBranch:    opts.NewBranch,
+		Message:      opts.Message,
+		Files:        []*files_service.ChangeRepoFile{file},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files_service.GetFileResponseFromFilesResponse(filesResponse, 0), nil
+}
+
+// stringAt returns the string at the path of keys in nested maps, empty if there is none
+func stringAt(m map[string]any, keys ...string) string {
+	var value any = m
+	for _, key := range keys {
+		mm, ok := value.(map[string]any)
+		if !ok {
+			return ""
+		}
+		value = mm[key]
+	}
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	default:
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+}
+
+// localizedText returns the text in the language of a SB localized text, or else in English or any language
+func localizedText(m map[string]any, lang string) string {
+	if text := stringAt(m, lang); text != "" {
+		return text
+	}
+	if text := stringAt(m, "en"); text != "" {
+		return text
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if text := stringAt(m, key); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// ingredientMimeType returns the media type of an ingredient file
+func ingredientMimeType(p string) string {
+	ext := strings.ToLower(path.Ext(p))
+	switch ext {
+	case ".usfm", ".sfm":
+		return "text/x-usfm"
+	case ".md":
+		return "text/markdown"
+	case ".tsv":
+		return "text/tab-separated-values"
+	case ".txt":
+		return "text/plain"
+	case ".yaml", ".yml":
+		return "text/yaml"
+	case ".json":
+		return "application/json"
+	}
+	if mimeType, _, _ := strings.Cut(mime.TypeByExtension(ext), ";"); mimeType != "" {
+		return strings.ToLower(strings.TrimSpace(mimeType))
+	}
+	return "application/octet-stream"
+}
+
+// newSBIngredient returns the SB ingredient of a blob, with its size and checksum
+func newSBIngredient(treePath string, blob *git.Blob) (map[string]any, error) {
+	rd, err := blob.DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+	hash := md5.New()
+	size, err := io.Copy(hash, rd)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"mimeType": ingredientMimeType(treePath),
+		"size":     size,
+		"checksum": map[string]any{"md5": hex.EncodeToString(hash.Sum(nil))},
+	}, nil
+}
+
+// listSBIngredients returns the SB ingredients of the file or of the files in the directory at the path, by path
+func listSBIngredients(commit *git.Commit, treePath string) (map[string]map[string]any, error) {
+	entry, err := commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		return nil, err
+	}
+	ingredients := make(map[string]map[string]any)
+	if !entry.IsDir() {
+		ingredient, err := newSBIngredient(treePath, entry.Blob())
+		if err != nil {
+			return nil, err
+		}
+		ingredients[treePath] = ingredient
+		return ingredients, nil
+	}
+
+	tree, err := commit.SubTree(treePath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := tree.ListEntriesRecursiveFast()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsRegular() && !e.IsExecutable() || strings.HasPrefix(path.Base(e.Name()), ".") {
+			continue
+		}
+		p := path.Join(treePath, e.Name())
+		ingredient, err := newSBIngredient(p, e.Blob())
+		if err != nil {
+			return nil, err
+		}
+		ingredients[p] = ingredient
+	}
+	return ingredients, nil
+}
+
+// bibleScope is the scope of all the books of the Bible, used for resources like OBS spanning it
+func bibleScope() map[string]any {
+	scope := make(map[string]any)
+	for book := range dcs.BookNames {
+		if dcs.BookIsOT(book) || dcs.BookIsNT(book) {
+			scope[strings.ToUpper(book)] = []any{}
+		}
+	}
+	return scope
+}
+
+// convertRCManifestToSB converts the manifest.yaml of a commit to SB 1.0 metadata
+func convertRCManifestToSB(repo *repo_model.Repository, commit *git.Commit) (map[string]any, error) {
+	conversionErr := func(format string, args ...any) error {
+		return ErrMetadataConversion{From: MetadataTypeRC, To: MetadataTypeSB, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	entry, err := commit.GetTreeEntryByPath(rcManifestFile)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, util.NewNotExistErrorf("%s not found", rcManifestFile)
+		}
+		return nil, err
+	}
+	manifestPtr, err := dcs.ReadYAMLFromBlob(entry.Blob())
+	if err != nil {
+		return nil, conversionErr("%s is not valid YAML: %v", rcManifestFile, err)
+	}
+	if manifestPtr == nil {
+		return nil, conversionErr("%s is empty", rcManifestFile)
+	}
+	manifest := *manifestPtr
+	dc, _ := manifest["dublin_core"].(map[string]any)
+	if dc == nil {
+		return nil, conversionErr("%s has no dublin_core", rcManifestFile)
+	}
+
+	lang := stringAt(dc, "language", "identifier")
+	if lang == "" {
+		return nil, conversionErr("%s has no language identifier", rcManifestFile)
+	}
+	langTitle := stringAt(dc, "language", "title")
+	if langTitle == "" {
+		langTitle = dcs.GetLanguageTitle(lang)
+	}
+	if langTitle == "" {
+		langTitle = lang
+	}
+	direction := stringAt(dc, "language", "direction")
+	if direction != "ltr" && direction != "rtl" {
+		direction = dcs.GetLanguageDirection(lang)
+	}
+	identifier := strings.ToLower(stringAt(dc, "identifier"))
+	title := stringAt(dc, "title")
+	if title == "" {
+		title = repo.Name
+	}
+
+	subject := stringAt(dc, "subject")
+	var isScripture bool
+	var flavorType map[string]any
+	switch subject {
+	case "Bible", "Aligned Bible", "Greek New Testament", "Hebrew Old Testament":
+		isScripture = true
+		flavorType = map[string]any{
+			"name": "scripture",
+			"flavor": map[string]any{
+				"name":            "textTranslation",
+				"projectType":     "standard",
+				"translationType": "firstTranslation",
+				"audience":        "common",
+				"usfmVersion":     "3.0",
+			},
+		}
+	case "Open Bible Stories":
+		flavorType = map[string]any{
+			"name":         "gloss",
+			"flavor":       map[string]any{"name": "textStories"},
+			"currentScope": bibleScope(),
+		}
+	default:
+		return nil, conversionErr("the subject %q has no Scripture Burrito flavor", subject)
+	}
+
+	ingredients := make(map[string]any)
+	scope := make(map[string]any)
+	localizedNames := make(map[string]any)
+	projects, _ := manifest["projects"].([]any)
+	for _, p := range projects {
+		project, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		projectPath := path.Clean("/" + stringAt(project, "path"))[1:]
+		if projectPath == "" {
+			return nil, conversionErr("the project %q must not be the whole repository", stringAt(project, "identifier"))
+		}
+		files, err := listSBIngredients(commit, projectPath)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				return nil, conversionErr("the path %q of the project %q was not found", projectPath, stringAt(project, "identifier"))
+			}
+			return nil, err
+		}
+
+		var bookScope map[string]any
+		if book := strings.ToLower(stringAt(project, "identifier")); isScripture && (dcs.BookIsOT(book) || dcs.BookIsNT(book)) {
+			bookID := strings.ToUpper(book)
+			scope[bookID] = []any{}
+			bookScope = map[string]any{bookID: []any{}}
+			bookTitle := stringAt(project, "title")
+			if bookTitle == "" {
+				bookTitle = dcs.BookNames[book]
+			}
+			localizedNames["book-"+book] = map[string]any{
+				"short": map[string]any{lang: bookTitle},
+				"abbr":  map[string]any{lang: bookID},
+			}
+		}
+		for filePath, ingredient := range files {
+			if bookScope != nil {
+				ingredient["scope"] = bookScope
+			}
+			ingredients[filePath] = ingredient
+		}
+	}
+	if len(ingredients) == 0 {
+		return nil, conversionErr("%s has no projects with files", rcManifestFile)
+	}
+	if isScripture {
+		if len(scope) == 0 {
+			return nil, conversionErr("%s has no Bible book projects", rcManifestFile)
+		}
+		flavorType["currentScope"] = scope
+	}
+
+	copyright, err := rcRightsToSBCopyright(stringAt(dc, "rights"))
+	if err != nil {
+		return nil, conversionErr("%v", err)
+	}
+
+	date := commit.Committer.When.UTC()
+	identification := map[string]any{
+		"name": map[string]any{lang: title},
+		"primary": map[string]any{
+			"dcs": map[string]any{
+				repo.FullName(): map[string]any{
+					"revision":  commit.ID.String(),
+					"timestamp": date.Format("2006-01-02"),
+				},
+			},
+		},
+	}
+	if identifier != "" {
+		identification["abbreviation"] = map[string]any{lang: identifier}
+	}
+	if description := stringAt(dc, "description"); description != "" {
+		identification["description"] = map[string]any{lang: description}
+	}
+
+	metadata := map[string]any{
+		"format": "scripture burrito",
+		"meta": map[string]any{
+			"version":       "1.0.0",
+			"category":      "source",
+			"dateCreated":   date.Format(time.RFC3339),
+			"defaultLocale": lang,
+			"normalization": "NFC",
+		},
+		"idAuthorities": map[string]any{
+			"dcs": map[string]any{
+				"id":   setting.AppURL,
+				"name": map[string]any{"en": "Door43 Content Service"},
+			},
+		},
+		"identification": identification,
+		"confidential":   false,
+		"languages": []any{
+			map[string]any{
+				"tag":             lang,
+				"name":            map[string]any{lang: langTitle},
+				"scriptDirection": direction,
+			},
+		},
+		"type":        map[string]any{"flavorType": flavorType},
+		"copyright":   copyright,
+		"ingredients": ingredients,
+	}
+	if len(localizedNames) > 0 {
+		metadata["localizedNames"] = localizedNames
+	}
+	if publisher := stringAt(dc, "publisher"); publisher != "" {
+		metadata["agencies"] = []any{
+			map[string]any{
+				"id":    "dcs::" + strings.Join(strings.Fields(publisher), "-"),
+				"name":  map[string]any{lang: publisher},
+				"roles": []any{"rightsHolder"},
+			},
+		}
+	}
+	return metadata, nil
+}
+
+// rcRightsToSBCopyright converts the rights of a RC to a SB copyright
+func rcRightsToSBCopyright(rights string) (map[string]any, error) {
+	if rights == "" {
+		return nil, fmt.Errorf("the rights are missing")
+	}
+	if rights == "Public Domain" {
+		return map[string]any{"publicDomain": true}, nil
+	}
+	if url, ok := rcRightsLicenseURLs[rights]; ok {
+		return map[string]any{"licenses": []any{map[string]any{"url": url}}}, nil
+	}
+	return map[string]any{"shortStatements": []any{map[string]any{"statement": rights, "lang": "en"}}}, nil
+}
+
+// sbCopyrightToRCRights converts a SB copyright to the rights of a RC
+func sbCopyrightToRCRights(copyright map[string]any) (string, error) {
+	if publicDomain, _ := copyright["publicDomain"].(bool); publicDomain {
+		return "Public Domain", nil
+	}
+	licenses, _ := copyright["licenses"].([]any)
+	for _, l := range licenses {
+		license, _ := l.(map[string]any)
+		url := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(stringAt(license, "url"), "https://"), "http://"), "/")
+		for rights, rightsURL := range rcRightsLicenseURLs {
+			if strings.EqualFold(url, strings.TrimSuffix(strings.TrimPrefix(rightsURL, "https://"), "/")) {
+				return rights, nil
+			}
+		}
+	}
+	statements, _ := copyright["shortStatements"].([]any)
+	for _, s := range statements {
+		shortStatement, _ := s.(map[string]any)
+		statement := stringAt(shortStatement, "statement")
+		if _, ok := rcRightsLicenseURLs[statement]; ok {
+			return statement, nil
+		}
+		for _, rights := range rcRightsWithoutURL {
+			if statement == rights {
+				return rights, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("the copyright doesn't match any of the RC rights")
+}
+
+// rcManifest is a RC 0.2 manifest.yaml
+type rcManifest struct {
+	DublinCore rcDublinCore `yaml:"dublin_core" json:"dublin_core"`
+	Checking   rcChecking   `yaml:"checking" json:"checking"`
+	Projects   []*rcProject `yaml:"projects" json:"projects"`
+}
+
+type rcDublinCore struct {
+	ConformsTo  string           `yaml:"conformsto" json:"conformsto"`
+	Contributor []string         `yaml:"contributor" json:"contributor"`
+	Creator     string           `yaml:"creator" json:"creator"`
+	Description string           `yaml:"description" json:"description"`
+	Format      string           `yaml:"format" json:"format"`
+	Identifier  string           `yaml:"identifier" json:"identifier"`
+	Issued      string           `yaml:"issued" json:"issued"`
+	Language    rcLanguage       `yaml:"language" json:"language"`
+	Modified    string           `yaml:"modified" json:"modified"`
+	Publisher   string           `yaml:"publisher" json:"publisher"`
+	Relation    []string         `yaml:"relation" json:"relation"`
+	Rights      string           `yaml:"rights" json:"rights"`
+	Source      []map[string]any `yaml:"source" json:"source"`
+	Subject     string           `yaml:"subject" json:"subject"`
+	Title       string           `yaml:"title" json:"title"`
+	Type        string           `yaml:"type" json:"type"`
+	Version     string           `yaml:"version" json:"version"`
+}
+
+type rcLanguage struct {
+	Direction  string `yaml:"direction" json:"direction"`
+	Identifier string `yaml:"identifier" json:"identifier"`
+	Title      string `yaml:"title" json:"title"`
+}
+
+type rcChecking struct {
+	CheckingEntity []string `yaml:"checking_entity" json:"checking_entity"`
+	CheckingLevel  string   `yaml:"checking_level" json:"checking_level"`
+}
+
+type rcProject struct {
+	Title         string   `yaml:"title" json:"title"`
+	Versification string   `yaml:"versification" json:"versification"`
+	Identifier    string   `yaml:"identifier" json:"identifier"`
+	Sort          int      `yaml:"sort" json:"sort"`
+	Path          string   `yaml:"path" json:"path"`
+	Categories    []string `yaml:"categories" json:"categories"`
+}
+
+// convertSBMetadataToRC converts the metadata.json of a commit to a RC 0.2 manifest
+func convertSBMetadataToRC(repo *repo_model.Repository, commit *git.Commit) (*rcManifest, error) {
+	conversionErr := func(format string, args ...any) error {
+		return ErrMetadataConversion{From: MetadataTypeSB, To: MetadataTypeRC, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	blob, err := commit.GetBlobByPath(sbMetadataFile)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, util.NewNotExistErrorf("%s not found", sbMetadataFile)
+		}
+		return nil, err
+	}
+	sb, err := dcs.GetSBDataFromBlob(blob)
+	if err != nil {
+		return nil, conversionErr("%s is not valid JSON: %v", sbMetadataFile, err)
+	}
+	metadata := *sb.Metadata
+
+	if len(sb.Languages) == 0 || sb.Languages[0].Tag == "" {
+		return nil, conversionErr("%s has no language", sbMetadataFile)
+	}
+	languages, _ := metadata["languages"].([]any)
+	language, _ := languages[0].(map[string]any)
+	lang := sb.Languages[0].Tag
+	langTitle := dcs.GetLanguageTitle(lang)
+	if langTitle == "" {
+		langName, _ := language["name"].(map[string]any)
+		langTitle = localizedText(langName, lang)
+	}
+	if langTitle == "" {
+		langTitle = lang
+	}
+	direction := stringAt(language, "scriptDirection")
+	if direction == "" {
+		direction = dcs.GetLanguageDirection(lang)
+	}
+
+	identification, _ := metadata["identification"].(map[string]any)
+	name, _ := identification["name"].(map[string]any)
+	abbreviation, _ := identification["abbreviation"].(map[string]any)
+	description, _ := identification["description"].(map[string]any)
+	title := localizedText(name, lang)
+	if title == "" {
+		title = repo.Name
+	}
+
+	manifest := &rcManifest{
+		DublinCore: rcDublinCore{
+			ConformsTo:  "rc0.2",
+			Contributor: []string{},
+			Creator:     repo.OwnerName,
+			Description: localizedText(description, lang),
+			Language: rcLanguage{
+				Direction:  direction,
+				Identifier: lang,
+				Title:      langTitle,
+			},
+			Modified:  commit.Committer.When.UTC().Format("2006-01-02"),
+			Publisher: repo.OwnerName,
+			Relation:  []string{},
+			Source:    []map[string]any{},
+			Title:     title,
+			Version:   "1",
+		},
+		Checking: rcChecking{
+			CheckingEntity: []string{repo.OwnerName},
+			CheckingLevel:  "1",
+		},
+	}
+	manifest.DublinCore.Issued = manifest.DublinCore.Modified
+	if dateCreated := stringAt(metadata, "meta", "dateCreated"); len(dateCreated) >= 10 {
+		manifest.DublinCore.Issued = dateCreated[:10]
+	}
+
+	agencies, _ := metadata["agencies"].([]any)
+	for _, a := range agencies {
+		agency, _ := a.(map[string]any)
+		roles, _ := agency["roles"].([]any)
+		for _, role := range roles {
+			if role == "rightsHolder" {
+				agencyName, _ := agency["name"].(map[string]any)
+				if n := localizedText(agencyName, lang); n != "" {
+					manifest.DublinCore.Creator = n
+					manifest.DublinCore.Publisher = n
+				}
+			}
+		}
+	}
+
+	copyright, _ := metadata["copyright"].(map[string]any)
+	if manifest.DublinCore.Rights, err = sbCopyrightToRCRights(copyright); err != nil {
+		return nil, conversionErr("%v", err)
+	}
+
+	ingredients, _ := metadata["ingredients"].(map[string]any)
+	ingredientPaths := make([]string, 0, len(ingredients))
+	for p := range ingredients {
+		ingredientPaths = append(ingredientPaths, p)
+	}
+	sort.Strings(ingredientPaths)
+
+	switch flavor := sb.Type.FlavorType.Name + "/" + sb.Type.FlavorType.Flavor.Name; flavor {
+	case "scripture/textTranslation":
+		manifest.DublinCore.Subject = "Bible"
+		manifest.DublinCore.Type = "bundle"
+		manifest.DublinCore.Format = "text/usfm3"
+		manifest.DublinCore.Identifier = localizedText(abbreviation, lang)
+		localizedNames, _ := metadata["localizedNames"].(map[string]any)
+		for _, p := range ingredientPaths {
+			book := ingredientBook(p, ingredients[p])
+			if book == "" {
+				continue
+			}
+			localizedName, _ := localizedNames["book-"+book].(map[string]any)
+			short, _ := localizedName["short"].(map[string]any)
+			bookTitle := localizedText(short, lang)
+			if bookTitle == "" {
+				bookTitle = dcs.BookNames[book]
+			}
+			manifest.Projects = append(manifest.Projects, &rcProject{
+				Title:         bookTitle,
+				Versification: "ufw",
+				Identifier:    book,
+				Sort:          dcs.GetBookSort(book),
+				Path:          "./" + p,
+				Categories:    dcs.GetBookCategories(book),
+			})
+		}
+		sort.SliceStable(manifest.Projects, func(i, j int) bool {
+			return manifest.Projects[i].Sort < manifest.Projects[j].Sort
+		})
+	case "gloss/textStories":
+		manifest.DublinCore.Subject = "Open Bible Stories"
+		manifest.DublinCore.Type = "book"
+		manifest.DublinCore.Format = "text/markdown"
+		manifest.DublinCore.Identifier = "obs"
+		if len(ingredientPaths) > 0 {
+			manifest.Projects = append(manifest.Projects, &rcProject{
+				Title:      title,
+				Identifier: "obs",
+				Path:       "./" + commonDir(ingredientPaths),
+				Categories: []string{"obs"},
+			})
+		}
+	default:
+		return nil, conversionErr("the flavor %q has no Resource Container subject", flavor)
+	}
+	if len(manifest.Projects) == 0 {
+		return nil, conversionErr("%s has no ingredients for the projects", sbMetadataFile)
+	}
+
+	manifest.DublinCore.Identifier = strings.ToLower(manifest.DublinCore.Identifier)
+	if !rcIdentifierRegex.MatchString(manifest.DublinCore.Identifier) {
+		// e.g. en_ult
+		_, manifest.DublinCore.Identifier, _ = strings.Cut(strings.ToLower(repo.Name), "_")
+		if !rcIdentifierRegex.MatchString(manifest.DublinCore.Identifier) {
+			return nil, conversionErr("no valid resource identifier found")
+		}
+	}
+	return manifest, nil
+}
+
+// ingredientBook returns the Bible book of a USFM ingredient from its scope or else its file name, empty if none
+func ingredientBook(p string, ingredient any) string {
+	if !strings.EqualFold(path.Ext(p), ".usfm") {
+		return ""
+	}
+	if i, ok := ingredient.(map[string]any); ok {
+		if scope, ok := i["scope"].(map[string]any); ok && len(scope) == 1 {
+			for book := range scope {
+				if book = strings.ToLower(book); dcs.BookIsOT(book) || dcs.BookIsNT(book) {
+					return book
+				}
+			}
+		}
+	}
+	if matches := usfmBookRegex.FindStringSubmatch(path.Base(p)); matches != nil {
+		if book := strings.ToLower(matches[1]); dcs.BookIsOT(book) || dcs.BookIsNT(book) {
+			return book
+		}
+	}
+	return ""
+}
+
+// commonDir returns the directory all the paths are in, "." if none
+func commonDir(paths []string) string {
+	dir := path.Dir(paths[0])
+	for _, p := range paths[1:] {
+		for dir != "." && !strings.HasPrefix(p, dir+"/") {
+			dir = path.Dir(dir)
+		}
+	}
+	return dir
+}
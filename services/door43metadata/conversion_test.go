@@ -0,0 +1,134 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+const testRCManifest = `dublin_core:
+  conformsto: rc0.2
+  contributor: []
+  creator: Door43 World Missions Community
+  description: An open-licensed translation
+  format: text/usfm3
+  identifier: ult
+  issued: "2023-01-01"
+  language:
+    direction: ltr
+    identifier: en
+    title: English
+  modified: "2023-01-01"
+  publisher: unfoldingWord
+  relation: []
+  rights: CC BY-SA 4.0
+  source: []
+  subject: Aligned Bible
+  title: unfoldingWord Literal Text
+  type: bundle
+  version: "1"
+checking:
+  checking_entity:
+    - unfoldingWord
+  checking_level: "3"
+projects:
+  - title: Ruth
+    versification: ufw
+    identifier: rut
+    sort: 8
+    path: ./08-RUT.usfm
+    categories:
+      - bible-ot
+  - title: 3 John
+    versification: ufw
+    identifier: 3jn
+    sort: 64
+    path: ./65-3JN.usfm
+    categories:
+      - bible-nt
+`
+
+func TestConvertMetadata(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	files := map[string]string{
+		"manifest.yaml": testRCManifest,
+		"08-RUT.usfm":   "\\id RUT\n",
+		"65-3JN.usfm":   "\\id 3JN\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0o644))
+	}
+	assert.NoError(t, git.InitRepository(ctx, repoPath, false))
+	assert.NoError(t, git.NewCommand(ctx, "add", "--all").Run(&git.RunOpts{Dir: repoPath}))
+	assert.NoError(t, git.NewCommand(ctx, "commit", "-m", "init").Run(&git.RunOpts{
+		Dir: repoPath,
+		Env: append(os.Environ(), "GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@example.com", "GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@example.com"),
+	}))
+
+	gitRepo, err := git.OpenRepository(ctx, repoPath)
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetCommit("HEAD")
+	assert.NoError(t, err)
+	repo := &repo_model.Repository{OwnerName: "unfoldingWord", Name: "en_ult"}
+
+	_, err = ConvertMetadata(repo, commit, "ts")
+	assert.Error(t, err)
+
+	converted, err := ConvertMetadata(repo, commit, "")
+	assert.NoError(t, err)
+	assert.Equal(t, MetadataTypeRC, converted.From)
+	assert.Equal(t, MetadataTypeSB, converted.To)
+	assert.Equal(t, "metadata.json", converted.TreePath)
+
+	var metadata map[string]any
+	assert.NoError(t, json.Unmarshal(converted.Content, &metadata))
+	assert.EqualValues(t, map[string]any{"RUT": []any{}, "3JN": []any{}}, metadata["type"].(map[string]any)["flavorType"].(map[string]any)["currentScope"])
+	assert.EqualValues(t, map[string]any{"licenses": []any{map[string]any{"url": "https://creativecommons.org/licenses/by-sa/4.0/"}}}, metadata["copyright"])
+	assert.EqualValues(t, map[string]any{
+		"mimeType": "text/x-usfm",
+		"size":     float64(8),
+		"checksum": map[string]any{"md5": "df0c07a2047c8890a439bd10f5843540"},
+		"scope":    map[string]any{"RUT": []any{}},
+	}, metadata["ingredients"].(map[string]any)["08-RUT.usfm"])
+
+	// and back again
+	assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "metadata.json"), converted.Content, 0o644))
+	assert.NoError(t, os.Remove(filepath.Join(repoPath, "manifest.yaml")))
+	assert.NoError(t, git.NewCommand(ctx, "add", "--all").Run(&git.RunOpts{Dir: repoPath}))
+	assert.NoError(t, git.NewCommand(ctx, "commit", "-m", "burrito").Run(&git.RunOpts{
+		Dir: repoPath,
+		Env: append(os.Environ(), "GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@example.com", "GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@example.com"),
+	}))
+	commit, err = gitRepo.GetCommit("HEAD")
+	assert.NoError(t, err)
+
+	converted, err = ConvertMetadata(repo, commit, MetadataTypeRC)
+	assert.NoError(t, err)
+	assert.Equal(t, "manifest.yaml", converted.TreePath)
+
+	var manifest rcManifest
+	assert.NoError(t, yaml.Unmarshal(converted.Content, &manifest))
+	assert.Equal(t, "ult", manifest.DublinCore.Identifier)
+	assert.Equal(t, "CC BY-SA 4.0", manifest.DublinCore.Rights)
+	assert.Equal(t, "unfoldingWord", manifest.DublinCore.Publisher)
+	assert.Equal(t, "Bible", manifest.DublinCore.Subject)
+	if assert.Len(t, manifest.Projects, 2) {
+		assert.Equal(t, "rut", manifest.Projects[0].Identifier)
+		assert.Equal(t, "Ruth", manifest.Projects[0].Title)
+		assert.Equal(t, "./08-RUT.usfm", manifest.Projects[0].Path)
+		assert.Equal(t, "3jn", manifest.Projects[1].Identifier)
+	}
+}
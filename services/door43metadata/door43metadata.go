@@ -8,10 +8,13 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
+	"net/url"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"code.gitea.io/gitea/models/db"
@@ -21,6 +24,7 @@ import (
 	"code.gitea.io/gitea/modules/charset"
 	"code.gitea.io/gitea/modules/dcs"
 	"code.gitea.io/gitea/modules/git"
+	catalog_indexer "code.gitea.io/gitea/modules/indexer/catalog"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -29,23 +33,70 @@ import (
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/services/convert"
+	notify_service "code.gitea.io/gitea/services/notify"
 
 	"github.com/google/uuid"
 	"xorm.io/builder"
 )
 
-func processDoor43MetadataForRepoRefs(ctx context.Context, repo *repo_model.Repository) error {
+// processingVersion is the version of how the metadata of a ref is read and stored. Increase it when that changes so
+// the next rescan processes the refs again, even those whose commit and release haven't changed
+const processingVersion = 1
+
+// refResult is the outcome of processing the metadata of a ref
+type refResult int
+
+const (
+	refProcessed  refResult = iota + 1 // the entry of the ref was stored
+	refUnchanged                       // skipped as the ref hasn't changed since it was last processed
+	refNoMetadata                      // not a SB, TC, TS nor RC ref, so not in the catalog
+)
+
+// refCounts are the numbers of refs of a rescan by result
+type refCounts struct {
+	Processed  int
+	Unchanged  int
+	NoMetadata int
+}
+
+func (c *refCounts) add(result refResult) {
+	switch result {
+	case refProcessed:
+		c.Processed++
+	case refUnchanged:
+		c.Unchanged++
+	case refNoMetadata:
+		c.NoMetadata++
+	}
+}
+
+// isRefUnchanged returns true if the ref is at the same commit, release and stage as when its entry was stored by the
+// current processing version
+func isRefUnchanged(prev *repo_model.Door43Metadata, commitID string, releaseID int64, releaseDateUnix timeutil.TimeStamp, stage door43metadata.Stage) bool {
+	return prev != nil &&
+		prev.ProcessingVersion == processingVersion &&
+		prev.CommitSHA == commitID &&
+		prev.ReleaseID == releaseID &&
+		prev.ReleaseDateUnix == releaseDateUnix &&
+		prev.Stage == stage
+}
+
+// processDoor43MetadataForRepoRefs processes the metadata of all the releases and branches of a repo, reusing one git
+// repository for all of them. If skipUnchanged, refs that haven't changed since they were last processed are skipped.
+// Returns the number of refs by result
+func processDoor43MetadataForRepoRefs(ctx context.Context, repo *repo_model.Repository, trigger repo_model.Door43MetadataTrigger, skipUnchanged bool) (counts refCounts, err error) {
 	refs, err := repo_model.GetRepoReleaseTagsForMetadata(ctx, repo.ID)
 	if err != nil {
 		log.Error("GetRepoReleaseTagsForMetadata Error %s: %v", repo.FullName(), err)
 	}
 
-	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	gitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, repo.RepoPath())
 	if err != nil {
 		log.Error("git.OpenRepository Error %s: %v", repo.FullName(), err)
 	}
 	if gitRepo != nil {
-		defer gitRepo.Close()
+		defer closer.Close()
+		ctx = context.WithValue(ctx, git.RepositoryContextKey, gitRepo)
 		branchNames, _, err := gitRepo.GetBranchNames(0, 0)
 		if err != nil {
 			log.Error("git.GetBranchNames Error %s: %v", repo.FullName(), err)
@@ -55,14 +106,17 @@ func processDoor43MetadataForRepoRefs(ctx context.Context, repo *repo_model.Repo
 	}
 
 	for _, ref := range refs {
-		if err := processDoor43MetadataForRepoRef(ctx, repo, ref); err != nil {
+		result, err := processDoor43MetadataForRepoRef(ctx, repo, ref, trigger, skipUnchanged)
+		if err != nil {
 			log.Info("Failed to process metadata for repo %s, ref %s: %v", repo.FullName(), ref, err)
 			if err = system.CreateRepositoryNotice("Failed to process metadata for repository (%s) ref (%s): %v", repo.FullName(), ref, err); err != nil {
 				log.Error("processDoor43MetadataForRepoRef: %v", err)
 			}
+			continue
 		}
+		counts.add(result)
 	}
-	return nil
+	return counts, nil
 }
 
 func handleLatestStageDM(ctx context.Context, repo *repo_model.Repository, stage door43metadata.Stage, earliestDate *timeutil.TimeStamp) (*repo_model.Door43Metadata, error) {
@@ -164,33 +218,52 @@ func processDoor43MetadataForRepoLatestDMs(ctx context.Context, repo *repo_model
 }
 
 // ProcessDoor43MetadataForRepo handles the metadata for a given repo for all its releases
-func ProcessDoor43MetadataForRepo(ctx context.Context, repo *repo_model.Repository, ref string) error {
+func ProcessDoor43MetadataForRepo(ctx context.Context, repo *repo_model.Repository, ref string, trigger repo_model.Door43MetadataTrigger) error {
+	_, err := processDoor43MetadataForRepo(ctx, repo, ref, trigger, false)
+	return err
+}
+
+// processDoor43MetadataForRepo handles the metadata for a given ref or all the refs of a repo, skipping the refs
+// that haven't changed if skipUnchanged. Returns the number of refs by result
+func processDoor43MetadataForRepo(ctx context.Context, repo *repo_model.Repository, ref string, trigger repo_model.Door43MetadataTrigger, skipUnchanged bool) (counts refCounts, err error) {
 	if ctx == nil || repo == nil {
-		return fmt.Errorf("no repository provided")
+		return counts, fmt.Errorf("no repository provided")
 	}
 
 	if repo.IsArchived || repo.IsPrivate {
+		catalog_indexer.DeleteRepoCatalogIndexer(ctx, repo.ID)
 		_, err := repo_model.DeleteAllDoor43MetadatasByRepoID(ctx, repo.ID)
 		if err != nil {
 			log.Error("DeleteAllDoor43MetadatasByRepoID: %v", err)
 		}
-		return err // No need to process any thing else below
+		return counts, err // No need to process any thing else below
 	}
 
 	if ref == "" {
 		log.Debug(">>>>>> PROCESSING REFS: %s", repo.FullName())
-		if err := processDoor43MetadataForRepoRefs(ctx, repo); err != nil {
+		counts, err = processDoor43MetadataForRepoRefs(ctx, repo, trigger, skipUnchanged)
+		if err != nil {
 			// log error but keep on going
 			log.Error("processDoor43MetadataForRepoRefs %s Error: %v", repo.FullName(), err)
 		}
 	} else {
-		if err := processDoor43MetadataForRepoRef(ctx, repo, ref); err != nil {
+		result, err := processDoor43MetadataForRepoRef(ctx, repo, ref, trigger, skipUnchanged)
+		if err != nil {
 			// log error but keep on going
 			log.Error("processDoor43MetadataForRepoRefs %s Error: %v", repo.FullName(), err)
+		} else {
+			counts.add(result)
 		}
 	}
 
-	return processDoor43MetadataForRepoLatestDMs(ctx, repo)
+	if err := processDoor43MetadataForRepoLatestDMs(ctx, repo); err != nil {
+		return counts, err
+	}
+
+	if !skipUnchanged || counts.Processed > 0 {
+		catalog_indexer.UpdateRepoIndexer(ctx, repo.ID)
+	}
+	return counts, nil
 }
 
 func GetBookAlignmentCount(bookPath string, commit *git.Commit) (int, error) {
@@ -534,38 +607,48 @@ func GetSBDoor43Metadata(dm *repo_model.Door43Metadata, repo *repo_model.Reposit
 	return GetDoor43MetadataFromSBMetadata(dm, sbMetadata, repo, commit)
 }
 
-func processDoor43MetadataForRepoRef(ctx context.Context, repo *repo_model.Repository, ref string) (err error) {
+// processDoor43MetadataForRepoRef reads, validates and stores the metadata of a ref of a repo. If skipUnchanged and the
+// ref's commit and release are the same as when it was last processed by the current processing version, its stored
+// metadata is kept as is and the ref is unchanged
+func processDoor43MetadataForRepoRef(ctx context.Context, repo *repo_model.Repository, ref string, trigger repo_model.Door43MetadataTrigger, skipUnchanged bool) (result refResult, err error) {
 	if repo == nil {
-		return fmt.Errorf("no repository provided")
+		return 0, fmt.Errorf("no repository provided")
 	}
 	if ref == "" {
-		return fmt.Errorf("no ref profided")
+		return 0, fmt.Errorf("no ref profided")
 	}
 
 	err = repo.LoadLatestDMs(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	var dm *repo_model.Door43Metadata
 	dm, err = repo_model.GetDoor43MetadataByRepoIDAndRef(ctx, repo.ID, ref)
 	if err != nil && !repo_model.IsErrDoor43MetadataNotExist(err) {
-		return err
+		return 0, err
 	}
+	var prev *repo_model.Door43Metadata
 	if dm == nil {
 		dm = &repo_model.Door43Metadata{
 			RepoID: repo.ID,
 			Ref:    ref,
 		}
+	} else {
+		// Loaded separately so the previous values can be recorded as a revision if they change
+		prev, err = repo_model.GetDoor43MetadataByID(ctx, dm.ID, repo.ID)
+		if err != nil {
+			return 0, err
+		}
 	}
 	dm.Repo = repo
 
-	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	gitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, repo.RepoPath())
 	if err != nil {
 		log.Error("OpenRepository Error: %v\n", err)
-		return err
+		return 0, err
 	}
-	defer gitRepo.Close()
+	defer closer.Close()
 
 	var commit *git.Commit
 	var commitID string
@@ -575,15 +658,15 @@ func processDoor43MetadataForRepoRef(ctx context.Context, repo *repo_model.Repos
 
 	release, err := repo_model.GetRelease(ctx, repo.ID, ref)
 	if err != nil && !repo_model.IsErrReleaseNotExist(err) {
-		return err
+		return 0, err
 	}
 	if release != nil {
 		// We don't support releases that are just tags or are drafts
 		if release.IsTag || release.IsDraft {
-			return fmt.Errorf("ref for repo %s [%d] must be a branch or a (pre-)release: %s", repo.FullName(), repo.ID, ref)
+			return 0, fmt.Errorf("ref for repo %s [%d] must be a branch or a (pre-)release: %s", repo.FullName(), repo.ID, ref)
 		}
 		if !release.IsCatalogVersion() {
-			return fmt.Errorf("release tag for repo %s [%d] must start with v and a digit or be a year: %s", repo.FullName(), repo.ID, release.TagName)
+			return 0, fmt.Errorf("release tag for repo %s [%d] must start with v and a digit or be a year: %s", repo.FullName(), repo.ID, release.TagName)
 		}
 		dm.RefType = "tag"
 		dm.Release = release
@@ -595,16 +678,16 @@ func processDoor43MetadataForRepoRef(ctx context.Context, repo *repo_model.Repos
 		commit, err = gitRepo.GetTagCommit(ref)
 		if err != nil {
 			log.Error("GetTagCommit [%s/%s]: %v\n", repo.FullName(), ref, err)
-			return err
+			return 0, err
 		}
 		commitID = commit.ID.String()
 		releaseDateUnix = release.CreatedUnix
 		releaseID = release.ID
 	} else {
 		if branch, err := gitRepo.GetBranch(ref); err != nil && !git.IsErrBranchNotExist(err) {
-			return err
+			return 0, err
 		} else if branch == nil {
-			return fmt.Errorf("ref for repo %s [%d] does not exist: %s", repo.FullName(), repo.ID, ref)
+			return 0, fmt.Errorf("ref for repo %s [%d] does not exist: %s", repo.FullName(), repo.ID, ref)
 		}
 		if ref == repo.DefaultBranch {
 			stage = door43metadata.StageLatest
@@ -616,18 +699,23 @@ func processDoor43MetadataForRepoRef(ctx context.Context, repo *repo_model.Repos
 		commit, err = gitRepo.GetBranchCommit(ref)
 		if err != nil {
 			log.Error("GetBranchCommit: %v\n", err)
-			return err
+			return 0, err
 		}
 		commitID = commit.ID.String()
 		releaseDateUnix = timeutil.TimeStamp(commit.Author.When.Unix())
 	}
 
+	if skipUnchanged && isRefUnchanged(prev, commitID, releaseID, releaseDateUnix, stage) {
+		log.Trace("processDoor43MetadataForRef: %s/%s is unchanged at %s", repo.FullName(), ref, commitID)
+		return refUnchanged, nil
+	}
+
 	// Check for SB (Scripture Burrito)
 	err = GetSBDoor43Metadata(dm, repo, commit)
 	if err != nil {
 		if !git.IsErrNotExist(err) {
 			log.Info("processDoor43MetadataForRef: ERROR! Unable to populate SB for %s/%s from TS or TC metadata.json: %v\n", repo.FullName(), ref, err)
-			return err
+			return 0, err
 		}
 	}
 
@@ -637,7 +725,7 @@ func processDoor43MetadataForRepoRef(ctx context.Context, repo *repo_model.Repos
 		if err != nil {
 			if !git.IsErrNotExist(err) {
 				log.Info("processDoor43MetadataForRef: ERROR! Unable to populate DM for %s/%s from TS or TC manifest.json: %v\n", repo.FullName(), ref, err)
-				return err
+				return 0, err
 			}
 		}
 	}
@@ -648,36 +736,76 @@ func processDoor43MetadataForRepoRef(ctx context.Context, repo *repo_model.Repos
 		if err != nil {
 			if !git.IsErrNotExist(err) {
 				log.Info("processDoor43MetadataForRef: ERROR! Unable to populate DM for %s/%s from RC manifest.yaml: %v\n", repo.FullName(), ref, err)
-				return err
+				return 0, err
 			}
 			log.Info("processDoor43MetadataForRef: %s/%s is not a SB, TC, TS nor RC repo. Not adding to door43_metadata\n", repo.FullName(), ref)
-			return nil // nothing to process, not a SB, TC, TS nor RC repo
+			return refNoMetadata, nil // nothing to process, not a SB, TC, TS nor RC repo
 		}
 	}
 
+	setIngredientChecksums(dm, commit)
+	setIngredientsProgress(dm, commit)
+
 	dm.CommitSHA = commitID
 	dm.ReleaseID = releaseID
 	dm.Release = release
 	dm.ReleaseDateUnix = releaseDateUnix
 	dm.Stage = stage
+	dm.ProcessingVersion = processingVersion
+
+	dm.VerifiedCheckingLevel, err = repo_model.GetVerifiedCheckingLevel(ctx, repo, commitID)
+	if err != nil {
+		return 0, err
+	}
 
 	if dm.ID > 0 {
+		if prev != nil {
+			if changedFields := repo_model.GetDoor43MetadataChangedFields(prev, dm); len(changedFields) > 0 {
+				if err := repo_model.InsertDoor43MetadataRevision(ctx, prev, trigger, changedFields); err != nil {
+					return 0, err
+				}
+			}
+		}
 		err = repo_model.UpdateDoor43Metadata(ctx, dm)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	} else {
 		err = repo_model.InsertDoor43Metadata(ctx, dm)
 		if err != nil {
-			return err
+			return 0, err
+		}
+		// Only a release being published is a new entry, not an entry created again for an existing release,
+		// e.g. when the repo is made public, unarchived, transferred or migrated
+		if dm.RefType == "tag" && trigger == repo_model.Door43MetadataTriggerRelease {
+			notify_service.NewCatalogEntry(ctx, dm)
 		}
 	}
 
-	return nil
+	return refProcessed, nil
+}
+
+// setIngredientChecksums sets the checksum of each ingredient to the git object SHA of its path at the commit
+func setIngredientChecksums(dm *repo_model.Door43Metadata, commit *git.Commit) {
+	for _, ingredient := range dm.Ingredients {
+		path := strings.Trim(strings.TrimPrefix(ingredient.Path, "./"), "/")
+		if path == "" || path == "." {
+			ingredient.Checksum = commit.Tree.ID.String()
+			continue
+		}
+		entry, err := commit.GetTreeEntryByPath(path)
+		if err != nil {
+			log.Warn("setIngredientChecksums: unable to get %s in %s at %s: %v", path, dm.Repo.FullName(), commit.ID.String(), err)
+			continue
+		}
+		ingredient.Checksum = entry.ID.String()
+	}
 }
 
 // UpdateDoor43Metadata generates door43_metadata table entries for valid repos/releases that don't have them
-func UpdateDoor43Metadata(ctx context.Context) error {
+// and updates the entries of the refs that changed since they were last processed, or of all refs if force.
+// Repos are processed by DCS.MetadataRescanWorkers workers at once, or one on SQLite, logging the progress as it goes
+func UpdateDoor43Metadata(ctx context.Context, trigger repo_model.Door43MetadataTrigger, force bool) error {
 	log.Trace("Doing: UpdateDoor43Metadata")
 
 	repos, err := repo_model.GetReposForMetadata(ctx)
@@ -685,124 +813,226 @@ func UpdateDoor43Metadata(ctx context.Context) error {
 		log.Error("GetReposForMetadata: %v", err)
 	}
 
+	var done, failed, processedRefs, unchangedRefs, noMetadataRefs atomic.Int64
+	logProgress := func() {
+		log.Info("UpdateDoor43Metadata: %d of %d repos done (%d failed), %d refs processed, %d refs unchanged, %d refs without metadata",
+			done.Load(), len(repos), failed.Load(), processedRefs.Load(), unchangedRefs.Load(), noMetadataRefs.Load())
+	}
+
+	workers := setting.DCS.MetadataRescanWorkers
+	if setting.Database.Type.IsSQLite3() {
+		// SQLite has a single writer, so more workers would only wait for its lock
+		workers = 1
+	}
+	repoCh := make(chan *repo_model.Repository)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range repoCh {
+				counts, err := processDoor43MetadataForRepo(ctx, repo, "", trigger, !force)
+				if err != nil {
+					failed.Add(1)
+					log.Info("Failed to process metadata for repo (%v): %v", repo, err)
+					if err = system.CreateRepositoryNotice("Failed to process metadata for repository (%s): %v", repo.FullName(), err); err != nil {
+						log.Error("ProcessDoor43MetadataForRepo: %v", err)
+					}
+				}
+				processedRefs.Add(int64(counts.Processed))
+				unchangedRefs.Add(int64(counts.Unchanged))
+				noMetadataRefs.Add(int64(counts.NoMetadata))
+				done.Add(1)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+feed:
 	for _, repo := range repos {
-		if err := ProcessDoor43MetadataForRepo(ctx, repo, ""); err != nil {
-			log.Info("Failed to process metadata for repo (%v): %v", repo, err)
-			if err = system.CreateRepositoryNotice("Failed to process metadata for repository (%s): %v", repo.FullName(), err); err != nil {
-				log.Error("ProcessDoor43MetadataForRepo: %v", err)
+		for {
+			select {
+			case <-ctx.Done():
+				break feed
+			case <-ticker.C:
+				logProgress()
+				continue
+			case repoCh <- repo:
 			}
+			break
 		}
 	}
+	close(repoCh)
+	wg.Wait()
+
+	logProgress()
 	log.Trace("Finished: UpdateDoor43Metadata")
-	return nil
+	return ctx.Err()
 }
 
 func DeleteDoor43MetadataByRepoRef(ctx context.Context, repo *repo_model.Repository, ref string) error {
+	if dm, err := repo_model.GetDoor43MetadataByRepoIDAndRef(ctx, repo.ID, ref); err == nil {
+		catalog_indexer.DeleteCatalogIndexer(ctx, dm.ID)
+	}
 	err := repo_model.DeleteDoor43MetadataByRepoRef(ctx, repo, ref)
 	if err != nil {
 		return err
 	}
+	if err := repo_model.DeleteDoor43MetadataRevisionsByRepoRef(ctx, repo.ID, ref); err != nil {
+		return err
+	}
 
 	return processDoor43MetadataForRepoLatestDMs(ctx, repo)
 }
 
+// UnpackJSONAttachments replaces the files.json or links.json attachments (can be singular file.json and link.json too)
+// of a release with the attachments linking to the files they list. Problems are reported on the release page
 func UnpackJSONAttachments(ctx context.Context, release *repo_model.Release) {
-	if release == nil || len(release.Attachments) == 0 {
+	if release == nil {
 		return
 	}
+	report := &repo_model.ReleaseAttachmentReport{
+		RepoID:    release.RepoID,
+		ReleaseID: release.ID,
+	}
 	jsonFileNameSuffix := regexp.MustCompile(`(file|link)s*\.json$`)
 	for _, attachment := range release.Attachments {
-		if jsonFileNameSuffix.MatchString(attachment.Name) {
-			remoteAttachments, err := GetAttachmentsFromJSON(attachment)
-			if err != nil {
-				log.Error("GetAttachmentsFromJSON Error: %v", err)
-				continue
-			}
-			for _, remoteAttachment := range remoteAttachments {
-				remoteAttachment.ReleaseID = attachment.ReleaseID
-				remoteAttachment.RepoID = attachment.RepoID
-				remoteAttachment.UploaderID = attachment.UploaderID
-				foundExisting := false
-				for _, a := range release.Attachments {
-					if a.Name == remoteAttachment.Name {
-						if remoteAttachment.Size > 0 {
-							a.Size = remoteAttachment.Size
-						}
-						if remoteAttachment.BrowserDownloadURL != "" {
-							a.BrowserDownloadURL = remoteAttachment.BrowserDownloadURL
-						}
-						a.BrowserDownloadURL = remoteAttachment.BrowserDownloadURL
-						if err := repo_model.UpdateAttachment(ctx, a); err != nil {
-							log.Error("UpdateAttachment [%d]: %v", a.ID, err)
-							continue
-						}
-						foundExisting = true
-						break
-					}
+		if !jsonFileNameSuffix.MatchString(attachment.Name) {
+			continue
+		}
+		linkedAttachments, err := GetAttachmentsFromJSON(attachment)
+		if err != nil {
+			log.Warn("GetAttachmentsFromJSON [%s, %d]: %v", attachment.Name, attachment.ID, err)
+			report.Problems = append(report.Problems, fmt.Sprintf("%s: %v", attachment.Name, err))
+			continue // kept so it can be fixed and the release updated
+		}
+		num, err := unpackJSONAttachment(ctx, release, attachment, linkedAttachments)
+		if err != nil {
+			log.Error("unpackJSONAttachment [%s, %d]: %v", attachment.Name, attachment.ID, err)
+			report.Problems = append(report.Problems, fmt.Sprintf("%s: unable to add the linked files: %v", attachment.Name, err))
+			continue
+		}
+		report.NumUnpacked += num
+		if err := storage.Attachments.Delete(attachment.RelativePath()); err != nil {
+			log.Error("delete attachment file [%d]: %v", attachment.ID, err)
+		}
+	}
+	if err := repo_model.SaveReleaseAttachmentReport(ctx, report); err != nil {
+		log.Error("SaveReleaseAttachmentReport [%d]: %v", release.ID, err)
+	}
+}
+
+// unpackJSONAttachment adds the attachments linked by a files.json or links.json attachment to its release, updating
+// the ones with the same name, and deletes the JSON attachment, all in one transaction. Returns the number of
+// attachments added or updated
+func unpackJSONAttachment(ctx context.Context, release *repo_model.Release, jsonAttachment *repo_model.Attachment, linkedAttachments []*repo_model.Attachment) (int, error) {
+	existing := make(map[string]*repo_model.Attachment, len(release.Attachments))
+	for _, a := range release.Attachments {
+		if a.ID != jsonAttachment.ID {
+			existing[a.Name] = a
+		}
+	}
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		for _, linked := range linkedAttachments {
+			if a, ok := existing[linked.Name]; ok {
+				if linked.Size > 0 {
+					a.Size = linked.Size
 				}
-				if foundExisting {
-					continue
+				if a.BrowserDownloadURL != linked.BrowserDownloadURL {
+					// the status of the previous link no longer applies
+					if err := repo_model.DeleteAttachmentLinkStatuses(ctx, a.ID); err != nil {
+						return err
+					}
 				}
-				// No existing attachment was found with the same name, so we insert a new one
-				remoteAttachment.UUID = uuid.New().String()
-				if _, err = db.GetEngine(db.DefaultContext).Insert(remoteAttachment); err != nil {
-					log.Error("insert attachment [%d]: %v", remoteAttachment.ID, err)
-					continue
+				a.BrowserDownloadURL = linked.BrowserDownloadURL
+				a.Checksum = linked.Checksum
+				a.MimeType = linked.MimeType
+				if _, err := db.GetEngine(ctx).ID(a.ID).Cols("name", "size", "checksum", "mime_type").Update(a); err != nil {
+					return fmt.Errorf("update attachment %s: %w", a.Name, err)
 				}
-			}
-			if err := repo_model.DeleteAttachment(ctx, attachment, true); err != nil {
-				log.Error("delete attachment [%d]: %v", attachment.ID, err)
 				continue
 			}
-			continue
+			// No existing attachment was found with the same name, so we insert a new one
+			linked.UUID = uuid.New().String()
+			linked.ReleaseID = jsonAttachment.ReleaseID
+			linked.RepoID = jsonAttachment.RepoID
+			linked.UploaderID = jsonAttachment.UploaderID
+			if err := db.Insert(ctx, linked); err != nil {
+				return fmt.Errorf("insert attachment %s: %w", linked.Name, err)
+			}
+			existing[linked.Name] = linked
 		}
+		// the file is only removed from the storage once the transaction is committed
+		return repo_model.DeleteAttachment(ctx, jsonAttachment, false)
+	})
+	if err != nil {
+		return 0, err
 	}
+	return len(linkedAttachments), nil
 }
 
-// GetAttachmentsFromJSON gets the attachments from uploaded
-func GetAttachmentsFromJSON(attachment *repo_model.Attachment) ([]*repo_model.Attachment, error) {
-	var url string
-	if setting.Attachment.Storage.MinioConfig.ServeDirect {
-		// If we have a signed url (S3, object storage), redirect to this directly.
-		urlObj, err := storage.Attachments.URL(attachment.RelativePath(), attachment.Name)
+// maxJSONAttachmentSize is the maximum size of a files.json or links.json attachment read
+const maxJSONAttachmentSize = 1 << 20
+
+// jsonAttachmentLink is a file listed in a files.json or links.json attachment,
+// see options/schema/attachments/release_attachments.schema.json
+type jsonAttachmentLink struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Size               int64  `json:"size"`
+	Checksum           string `json:"checksum"`
+	MimeType           string `json:"mime_type"`
+}
 
-		if urlObj != nil && err == nil {
-			url = urlObj.String()
-		}
-	} else {
-		url = attachment.DownloadURL()
-	}
-	client := http.Client{
-		Timeout: time.Second * 2, // Timeout after 2 seconds
-	}
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+// GetAttachmentsFromJSON reads a files.json or links.json attachment from the attachment storage, validates it
+// against the release attachments schema and returns the attachments it links to
+func GetAttachmentsFromJSON(attachment *repo_model.Attachment) ([]*repo_model.Attachment, error) {
+	f, err := storage.Attachments.Open(attachment.RelativePath())
 	if err != nil {
-		return nil, fmt.Errorf("http.NewRequest Error: %v", err)
+		return nil, fmt.Errorf("unable to read the file: %w", err)
 	}
-	req.Header.Set("User-Agent", "dcs")
-	res, err := client.Do(req)
+	defer f.Close()
+	body, err := io.ReadAll(io.LimitReader(f, maxJSONAttachmentSize+1))
 	if err != nil {
-		return nil, fmt.Errorf("client.Do Error: %v", err)
-	}
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("client.Do Error: `%s` returned StatusCode [%d]", attachment.DownloadURL(), res.StatusCode)
+		return nil, fmt.Errorf("unable to read the file: %w", err)
 	}
-	if res.Body != nil {
-		defer res.Body.Close()
+	if len(body) > maxJSONAttachmentSize {
+		return nil, fmt.Errorf("the file is larger than %d bytes", maxJSONAttachmentSize)
 	}
 
-	body, err := io.ReadAll(res.Body)
+	valErr, err := dcs.ValidateReleaseAttachmentsJSON(body)
 	if err != nil {
-		return nil, fmt.Errorf("io.ReadAll Error: %v", err)
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if valErr != nil {
+		return nil, fmt.Errorf("does not match the schema: %s", strings.TrimSpace(dcs.ConvertValidationErrorToString(valErr)))
 	}
-	attachments := []*repo_model.Attachment{}
-	if err1 := json.Unmarshal(body, &attachments); err1 != nil {
-		// We couldn't unmarshal an array of attachments, so lets see if it is just a single attachment
-		attachment := &repo_model.Attachment{}
-		if err2 := json.Unmarshal(body, attachment); err2 != nil {
-			return nil, fmt.Errorf("json.Unmarshal Error: %v", err1)
+
+	links := []*jsonAttachmentLink{}
+	if err1 := json.Unmarshal(body, &links); err1 != nil {
+		// We couldn't unmarshal an array of links, so lets see if it is just a single link
+		link := &jsonAttachmentLink{}
+		if err2 := json.Unmarshal(body, link); err2 != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err1)
+		}
+		links = append(links, link)
+	}
+	attachments := make([]*repo_model.Attachment, 0, len(links))
+	for _, link := range links {
+		name := link.Name
+		if name == "" {
+			if u, err := url.Parse(link.BrowserDownloadURL); err == nil {
+				name = path.Base(u.Path)
+			}
 		}
-		attachments = append(attachments, attachment)
+		attachments = append(attachments, &repo_model.Attachment{
+			Name:               name,
+			BrowserDownloadURL: link.BrowserDownloadURL,
+			Size:               link.Size,
+			Checksum:           link.Checksum,
+			MimeType:           link.MimeType,
+		})
 	}
 	return attachments, nil
 }
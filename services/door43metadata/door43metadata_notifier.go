@@ -6,11 +6,14 @@ package door43metadata
 import (
 	"context"
 
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
+	catalog_indexer "code.gitea.io/gitea/modules/indexer/catalog"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
 	notify_service "code.gitea.io/gitea/services/notify"
 )
 
@@ -23,29 +26,41 @@ var _ notify_service.Notifier = &metadataNotifier{}
 func Init() error {
 	notify_service.RegisterNotifier(NewNotifier())
 
+	if setting.DCS.EnableDownloadStats {
+		return initDownloadStatsQueue()
+	}
 	return nil
 }
 
+// InitDB prepares the door43 metadata tables for the configured database and generates the instance signing key,
+// once the database is initialized
+func InitDB(ctx context.Context) error {
+	if err := repo_model.InitDoor43Metadata(ctx); err != nil {
+		return err
+	}
+	return asymkey_model.InitInstanceDoor43SigningKey(ctx)
+}
+
 // NewNotifier create a new metadataNotifier notifier
 func NewNotifier() notify_service.Notifier {
 	return &metadataNotifier{}
 }
 
 func (m *metadataNotifier) CreateRepository(ctx context.Context, doer, u *user_model.User, repo *repo_model.Repository) {
-	if err := ProcessDoor43MetadataForRepo(ctx, repo, ""); err != nil {
+	if err := ProcessDoor43MetadataForRepo(ctx, repo, "", repo_model.Door43MetadataTriggerRepository); err != nil {
 		log.Error("CreateRepository: ProcessDoor43MetadataForRepo failed [%s]: %v", repo.FullName(), err)
 	}
 }
 
 func (m *metadataNotifier) SyncCreateRepository(ctx context.Context, doer, u *user_model.User, repo *repo_model.Repository) {
-	if err := ProcessDoor43MetadataForRepo(ctx, repo, ""); err != nil {
+	if err := ProcessDoor43MetadataForRepo(ctx, repo, "", repo_model.Door43MetadataTriggerRepository); err != nil {
 		log.Error("SyncCreateRepository: ProcessDoor43MetadataForRepo failed [%s]: %v", repo.FullName(), err)
 	}
 }
 
 func (m *metadataNotifier) NewRelease(ctx context.Context, rel *repo_model.Release) {
 	if rel != nil && !rel.IsTag {
-		if err := ProcessDoor43MetadataForRepo(ctx, rel.Repo, rel.TagName); err != nil {
+		if err := ProcessDoor43MetadataForRepo(ctx, rel.Repo, rel.TagName, repo_model.Door43MetadataTriggerRelease); err != nil {
 			log.Error("NewRelease: ProcessDoor43MetadataForRepo failed [%s, %s]: %v", rel.Repo.FullName(), rel.TagName, err)
 		}
 
@@ -56,7 +71,7 @@ func (m *metadataNotifier) NewRelease(ctx context.Context, rel *repo_model.Relea
 
 func (m *metadataNotifier) UpdateRelease(ctx context.Context, doer *user_model.User, rel *repo_model.Release) {
 	if rel != nil && !rel.IsTag {
-		if err := ProcessDoor43MetadataForRepo(ctx, rel.Repo, rel.TagName); err != nil {
+		if err := ProcessDoor43MetadataForRepo(ctx, rel.Repo, rel.TagName, repo_model.Door43MetadataTriggerRelease); err != nil {
 			log.Error("UpdateRelease: ProcessDoor43MetadataForRepo failed [%s, %s]: %v", rel.Repo.FullName(), rel.TagName, err)
 		}
 
@@ -69,12 +84,15 @@ func (m *metadataNotifier) DeleteRelease(ctx context.Context, doer *user_model.U
 	if err := DeleteDoor43MetadataByRepoRef(ctx, rel.Repo, rel.TagName); err != nil {
 		log.Error("DeleteRelease: DeleteDoor43MetadataByRepoRef failed [%s, %s]: %v", rel.Repo.FullName(), rel.TagName, err)
 	}
+	if err := repo_model.DeleteReleaseAttachmentReport(ctx, rel.ID); err != nil {
+		log.Error("DeleteRelease: DeleteReleaseAttachmentReport failed [%s, %s]: %v", rel.Repo.FullName(), rel.TagName, err)
+	}
 }
 
 func (m *metadataNotifier) PushCommits(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
 	if opts.RefFullName.IsBranch() {
 		ref := opts.RefFullName.BranchName()
-		if err := ProcessDoor43MetadataForRepo(ctx, repo, ref); err != nil {
+		if err := ProcessDoor43MetadataForRepo(ctx, repo, ref, repo_model.Door43MetadataTriggerPush); err != nil {
 			log.Error("PushCommits: ProcessDoor43MetadataForRepo failed [%s, %s]: %v", repo.FullName(), ref, err)
 		}
 	}
@@ -83,46 +101,60 @@ func (m *metadataNotifier) PushCommits(ctx context.Context, pusher *user_model.U
 func (m *metadataNotifier) SyncPushCommits(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
 	if opts.RefFullName.IsBranch() {
 		ref := opts.RefFullName.BranchName()
-		if err := ProcessDoor43MetadataForRepo(ctx, repo, ref); err != nil {
+		if err := ProcessDoor43MetadataForRepo(ctx, repo, ref, repo_model.Door43MetadataTriggerPush); err != nil {
 			log.Error("SyncPushCommits: ProcessDoor43MetadataForRepo failed [%s, %s]: %v", repo.FullName(), ref, err)
 		}
 	}
 }
 
 func (m *metadataNotifier) DeleteRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository) {
+	catalog_indexer.DeleteRepoCatalogIndexer(ctx, repo.ID)
 	if _, err := repo_model.DeleteAllDoor43MetadatasByRepoID(ctx, repo.ID); err != nil {
 		log.Error("DeleteRepository: DeleteAllDoor43MetadatasByRepoID failed [%s]: %v", repo.FullName(), err)
 	}
+	if err := repo_model.DeleteDoor43MetadataRevisionsByRepoID(ctx, repo.ID); err != nil {
+		log.Error("DeleteRepository: DeleteDoor43MetadataRevisionsByRepoID failed [%s]: %v", repo.FullName(), err)
+	}
+	if err := repo_model.DeleteDoor43DownloadStatsByRepoID(ctx, repo.ID); err != nil {
+		log.Error("DeleteRepository: DeleteDoor43DownloadStatsByRepoID failed [%s]: %v", repo.FullName(), err)
+	}
 }
 
 func (m *metadataNotifier) SyncDeleteRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository) {
+	catalog_indexer.DeleteRepoCatalogIndexer(ctx, repo.ID)
 	if _, err := repo_model.DeleteAllDoor43MetadatasByRepoID(ctx, repo.ID); err != nil {
 		log.Error("SyncDeleteRepository: DeleteAllDoor43MetadatasByRepoID failed [%s]: %v", repo.FullName(), err)
 	}
+	if err := repo_model.DeleteDoor43MetadataRevisionsByRepoID(ctx, repo.ID); err != nil {
+		log.Error("SyncDeleteRepository: DeleteDoor43MetadataRevisionsByRepoID failed [%s]: %v", repo.FullName(), err)
+	}
+	if err := repo_model.DeleteDoor43DownloadStatsByRepoID(ctx, repo.ID); err != nil {
+		log.Error("SyncDeleteRepository: DeleteDoor43DownloadStatsByRepoID failed [%s]: %v", repo.FullName(), err)
+	}
 }
 
 func (m *metadataNotifier) MigrateRepository(ctx context.Context, doer, u *user_model.User, repo *repo_model.Repository) {
-	if err := ProcessDoor43MetadataForRepo(ctx, repo, ""); err != nil {
+	if err := ProcessDoor43MetadataForRepo(ctx, repo, "", repo_model.Door43MetadataTriggerRepository); err != nil {
 		log.Error("MigrateRepository: ProcessDoor43MetadataForRepo failed [%s]: %v", repo.FullName(), err)
 	}
 }
 
 func (m *metadataNotifier) TransferRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, newOwnerName string) {
 	// Shouldn't really need if the repo is transfered as it keeps the same IDs, releases, etc, but just in case
-	if err := ProcessDoor43MetadataForRepo(ctx, repo, ""); err != nil {
+	if err := ProcessDoor43MetadataForRepo(ctx, repo, "", repo_model.Door43MetadataTriggerRepository); err != nil {
 		log.Error("TransferRepository: ProcessDoor43MetadataForRepo failed [%s]: %v", repo.FullName(), err)
 	}
 }
 
 func (m *metadataNotifier) ForkRepository(ctx context.Context, doer *user_model.User, oldRepo, repo *repo_model.Repository) {
-	if err := ProcessDoor43MetadataForRepo(ctx, repo, ""); err != nil {
+	if err := ProcessDoor43MetadataForRepo(ctx, repo, "", repo_model.Door43MetadataTriggerRepository); err != nil {
 		log.Error("ForkRepository: ProcessDoor43MetadataForRepo failed [%s]: %v", repo.FullName(), err)
 	}
 }
 
 func (m *metadataNotifier) RenameRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, oldName string) {
 	// Shouldn't really need if the repo is renamed as it keeps the same IDs, releases, etc, but just in case
-	if err := ProcessDoor43MetadataForRepo(ctx, repo, ""); err != nil {
+	if err := ProcessDoor43MetadataForRepo(ctx, repo, "", repo_model.Door43MetadataTriggerRepository); err != nil {
 		log.Error("RenameRepository: ProcessDoor43MetadataForRepo failed [%s]: %v", repo.FullName(), err)
 	}
 }
@@ -137,7 +169,7 @@ func (m *metadataNotifier) DeleteRef(ctx context.Context, doer *user_model.User,
 }
 
 func (m *metadataNotifier) ChangeDefaultBranch(ctx context.Context, repo *repo_model.Repository) {
-	if err := ProcessDoor43MetadataForRepo(ctx, repo, repo.DefaultBranch); err != nil {
+	if err := ProcessDoor43MetadataForRepo(ctx, repo, repo.DefaultBranch, repo_model.Door43MetadataTriggerDefaultBranch); err != nil {
 		log.Error("ChangeDefaultBranch: ProcessDoor43MetadataForRef failed [%s, %s]: %v", repo.FullName(), repo.DefaultBranch)
 		return
 	}
@@ -0,0 +1,210 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// commitTestFiles commits the files to the branch of the bare repository as the only files of its tree
+func commitTestFiles(t *testing.T, repoPath, branch string, files map[string]string) {
+	ctx := db.DefaultContext
+	indexFile := t.TempDir() + "/index"
+	env := append(os.Environ(), "GIT_INDEX_FILE="+indexFile,
+		"GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@example.com", "GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@example.com")
+	for name, content := range files {
+		sha, _, err := git.NewCommand(ctx, "hash-object", "-w", "--stdin").RunStdString(&git.RunOpts{Dir: repoPath, Stdin: strings.NewReader(content)})
+		assert.NoError(t, err)
+		_, _, err = git.NewCommand(ctx, "update-index", "--add", "--cacheinfo").
+			AddDynamicArguments("100644", strings.TrimSpace(sha), name).
+			RunStdString(&git.RunOpts{Dir: repoPath, Env: env})
+		assert.NoError(t, err)
+	}
+	tree, _, err := git.NewCommand(ctx, "write-tree").RunStdString(&git.RunOpts{Dir: repoPath, Env: env})
+	assert.NoError(t, err)
+	commit, _, err := git.NewCommand(ctx, "commit-tree", "-m", "metadata").AddDynamicArguments(strings.TrimSpace(tree)).
+		RunStdString(&git.RunOpts{Dir: repoPath, Env: env})
+	assert.NoError(t, err)
+	_, _, err = git.NewCommand(ctx, "update-ref").AddDynamicArguments("refs/heads/"+branch, strings.TrimSpace(commit)).
+		RunStdString(&git.RunOpts{Dir: repoPath})
+	assert.NoError(t, err)
+}
+
+func TestProcessDoor43MetadataForRepoSkipsUnchanged(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	commitTestFiles(t, repo.RepoPath(), "master", map[string]string{
+		"manifest.yaml": testRCManifest,
+		"08-RUT.usfm":   "\\id RUT\n",
+		"65-3JN.usfm":   "\\id 3JN\n",
+	})
+	trigger := repo_model.Door43MetadataTriggerCron
+
+	counts, err := processDoor43MetadataForRepo(db.DefaultContext, repo, "master", trigger, true)
+	assert.NoError(t, err)
+	assert.Equal(t, refCounts{Processed: 1}, counts)
+	dm, err := repo_model.GetDoor43MetadataByRepoIDAndRef(db.DefaultContext, repo.ID, "master")
+	assert.NoError(t, err)
+	assert.Equal(t, door43metadata.StageLatest, dm.Stage)
+	assert.Equal(t, processingVersion, dm.ProcessingVersion)
+
+	counts, err = processDoor43MetadataForRepo(db.DefaultContext, repo, "master", trigger, true)
+	assert.NoError(t, err)
+	assert.Equal(t, refCounts{Unchanged: 1}, counts)
+
+	// an entry stored by an older version of the processing is processed again
+	dm.ProcessingVersion = processingVersion - 1
+	_, err = db.GetEngine(db.DefaultContext).ID(dm.ID).Cols("processing_version").Update(dm)
+	assert.NoError(t, err)
+	counts, err = processDoor43MetadataForRepo(db.DefaultContext, repo, "master", trigger, true)
+	assert.NoError(t, err)
+	assert.Equal(t, refCounts{Processed: 1}, counts)
+
+	// unless forced
+	counts, err = processDoor43MetadataForRepo(db.DefaultContext, repo, "master", trigger, false)
+	assert.NoError(t, err)
+	assert.Equal(t, refCounts{Processed: 1}, counts)
+
+	// the release of the repo has no metadata, and the other branches have no catalog entry
+	counts, err = processDoor43MetadataForRepo(db.DefaultContext, repo, "", trigger, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, counts.Unchanged)
+	assert.Equal(t, 0, counts.Processed)
+	assert.Positive(t, counts.NoMetadata)
+	_, err = repo_model.GetDoor43MetadataByRepoIDAndRef(db.DefaultContext, repo.ID, "v1.1")
+	assert.True(t, repo_model.IsErrDoor43MetadataNotExist(err))
+}
+
+func TestDeleteDoor43MetadataByRepoRef(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	dm := &repo_model.Door43Metadata{RepoID: repo.ID, Ref: "branch2", RefType: "branch", CommitSHA: "aaa", Stage: door43metadata.StageLatest}
+	assert.NoError(t, db.Insert(db.DefaultContext, dm))
+	assert.NoError(t, repo_model.InsertDoor43MetadataRevision(db.DefaultContext, dm, repo_model.Door43MetadataTriggerPush, []string{"commit_sha"}))
+
+	assert.NoError(t, DeleteDoor43MetadataByRepoRef(db.DefaultContext, repo, "branch2"))
+	unittest.AssertNotExistsBean(t, &repo_model.Door43Metadata{ID: dm.ID})
+	unittest.AssertNotExistsBean(t, &repo_model.Door43MetadataRevision{Door43MetadataID: dm.ID})
+}
+
+func TestIsRefUnchanged(t *testing.T) {
+	prev := &repo_model.Door43Metadata{
+		CommitSHA:         "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		ReleaseID:         1,
+		ReleaseDateUnix:   1000,
+		Stage:             door43metadata.StageProd,
+		ProcessingVersion: processingVersion,
+	}
+	assert.True(t, isRefUnchanged(prev, prev.CommitSHA, 1, 1000, door43metadata.StageProd))
+	assert.False(t, isRefUnchanged(nil, prev.CommitSHA, 1, 1000, door43metadata.StageProd))
+	assert.False(t, isRefUnchanged(prev, "2a47ca4b614a9f5a43abbd5ad851a54a616ffee6", 1, 1000, door43metadata.StageProd))
+	assert.False(t, isRefUnchanged(prev, prev.CommitSHA, 2, 1000, door43metadata.StageProd))
+	assert.False(t, isRefUnchanged(prev, prev.CommitSHA, 1, 2000, door43metadata.StageProd))
+	assert.False(t, isRefUnchanged(prev, prev.CommitSHA, 1, 1000, door43metadata.StagePreProd))
+	prev.ProcessingVersion--
+	assert.False(t, isRefUnchanged(prev, prev.CommitSHA, 1, 1000, door43metadata.StageProd))
+}
+
+// addTestReleaseAttachment adds an attachment with the content to the release
+func addTestReleaseAttachment(t *testing.T, release *repo_model.Release, name, content string) *repo_model.Attachment {
+	attach := &repo_model.Attachment{
+		UUID:      uuid.New().String(),
+		RepoID:    release.RepoID,
+		ReleaseID: release.ID,
+		Name:      name,
+		Size:      int64(len(content)),
+	}
+	_, err := storage.Attachments.Save(attach.RelativePath(), strings.NewReader(content), attach.Size)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Insert(db.DefaultContext, attach))
+	return attach
+}
+
+func TestUnpackJSONAttachments(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	release := unittest.AssertExistsAndLoadBean(t, &repo_model.Release{ID: 1})
+	files := addTestReleaseAttachment(t, release, "files.json", `[
+	{"name": "attach1", "browser_download_url": "https://example.com/v1/attach1.zip", "size": 10, "checksum": "sha256:0a1b", "mime_type": "application/zip"},
+	{"browser_download_url": "https://example.com/v1/book.pdf", "mime_type": "application/pdf"}
+]`)
+	links := addTestReleaseAttachment(t, release, "links.json", `{"name": "no-url.zip"}`)
+	oversized := addTestReleaseAttachment(t, release, "file.json", "["+strings.Repeat(" ", maxJSONAttachmentSize)+"]")
+	notJSON := addTestReleaseAttachment(t, release, "link.json", `[{"browser_download_url": `)
+	assert.NoError(t, release.LoadAttributes(db.DefaultContext))
+
+	UnpackJSONAttachments(db.DefaultContext, release)
+
+	// an existing attachment of the same name is updated
+	attach1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Attachment{ID: 9})
+	assert.Equal(t, "https://example.com/v1/attach1.zip", attach1.BrowserDownloadURL)
+	assert.EqualValues(t, 10, attach1.Size)
+	assert.Equal(t, "sha256:0a1b", attach1.Checksum)
+	assert.Equal(t, "application/zip", attach1.MimeType)
+	// named after the URL if it has no name
+	unpacked := unittest.AssertExistsAndLoadBean(t, &repo_model.Release{ID: release.ID})
+	assert.NoError(t, unpacked.LoadAttributes(db.DefaultContext))
+	var book *repo_model.Attachment
+	for _, attach := range unpacked.Attachments {
+		if attach.Name == "book.pdf" {
+			book = attach
+		}
+	}
+	if assert.NotNil(t, book) {
+		assert.Equal(t, "https://example.com/v1/book.pdf", book.BrowserDownloadURL)
+		assert.Equal(t, "application/pdf", book.MimeType)
+		assert.Equal(t, files.UploaderID, book.UploaderID)
+	}
+	unittest.AssertNotExistsBean(t, &repo_model.Attachment{ID: files.ID})
+	_, err := storage.Attachments.Stat(files.RelativePath())
+	assert.Error(t, err)
+
+	// the invalid ones are kept so they can be fixed
+	for _, attach := range []*repo_model.Attachment{links, oversized, notJSON} {
+		unittest.AssertExistsAndLoadBean(t, &repo_model.Attachment{ID: attach.ID})
+	}
+	reports, err := repo_model.GetReleaseAttachmentReports(db.DefaultContext, []int64{release.ID})
+	assert.NoError(t, err)
+	if assert.Contains(t, reports, release.ID) {
+		report := reports[release.ID]
+		assert.Equal(t, 2, report.NumUnpacked)
+		if assert.Len(t, report.Problems, 3) {
+			problems := strings.Join(report.Problems, "\n")
+			assert.Contains(t, problems, "links.json: does not match the schema")
+			assert.Contains(t, problems, "file.json: the file is larger than")
+			assert.Contains(t, problems, "link.json: invalid JSON")
+		}
+	}
+
+	// once fixed, the report is removed
+	for _, attach := range []*repo_model.Attachment{links, oversized, notJSON} {
+		assert.NoError(t, repo_model.DeleteAttachment(db.DefaultContext, attach, true))
+	}
+	addTestReleaseAttachment(t, release, "links.json", `{"name": "attach1", "browser_download_url": "https://example.com/v2/attach1.zip"}`)
+	release = unittest.AssertExistsAndLoadBean(t, &repo_model.Release{ID: 1})
+	assert.NoError(t, release.LoadAttributes(db.DefaultContext))
+	UnpackJSONAttachments(db.DefaultContext, release)
+	reports, err = repo_model.GetReleaseAttachmentReports(db.DefaultContext, []int64{release.ID})
+	assert.NoError(t, err)
+	assert.Empty(t, reports)
+	attach1 = unittest.AssertExistsAndLoadBean(t, &repo_model.Attachment{ID: 9})
+	assert.Equal(t, "https://example.com/v2/attach1.zip", attach1.BrowserDownloadURL)
+	// only what the file has is set
+	assert.EqualValues(t, 10, attach1.Size)
+	assert.Empty(t, attach1.Checksum)
+}
@@ -0,0 +1,163 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// door43Download is a download to count, resolved to its catalog entry by the queue so requests never wait for it
+type door43Download struct {
+	RepoID    int64
+	Ref       string
+	ReleaseID int64  // release of a downloaded attachment, its tag being the ref
+	TreePath  string // downloaded file of an ingredient
+	Kind      repo_model.Door43DownloadKind
+	Day       timeutil.TimeStamp
+}
+
+// door43DownloadKey is what the downloads of a batch of the queue are summed up by
+type door43DownloadKey struct {
+	Door43MetadataID int64
+	Kind             repo_model.Door43DownloadKind
+	Day              timeutil.TimeStamp
+}
+
+var downloadStatsQueue *queue.WorkerPoolQueue[*door43Download]
+
+// initDownloadStatsQueue runs the queue counting the downloads of catalog entries
+func initDownloadStatsQueue() error {
+	downloadStatsQueue = queue.CreateSimpleQueue(graceful.GetManager().ShutdownContext(), "door43_download_stats", downloadStatsHandler)
+	if downloadStatsQueue == nil {
+		return fmt.Errorf("unable to create door43_download_stats queue")
+	}
+	go graceful.GetManager().RunWithCancel(downloadStatsQueue)
+	return nil
+}
+
+// downloadStatsHandler adds the downloads of a batch to the counts of their entries, with one update per entry, kind
+// and day. Errors are only logged so downloads are never counted twice
+func downloadStatsHandler(items ...*door43Download) []*door43Download {
+	ctx := graceful.GetManager().HammerContext()
+	counts := make(map[door43DownloadKey]int64, len(items))
+	dms := make(map[int64]*repo_model.Door43Metadata, len(items))
+	refDMs := make(map[string]*repo_model.Door43Metadata, len(items))
+	for _, d := range items {
+		dm := getDownloadedDoor43Metadata(ctx, d, refDMs)
+		if dm == nil {
+			continue
+		}
+		dms[dm.ID] = dm
+		counts[door43DownloadKey{dm.ID, d.Kind, d.Day}]++
+	}
+	for key, count := range counts {
+		if err := repo_model.AddDoor43DownloadCount(ctx, dms[key.Door43MetadataID], key.Kind, key.Day, count); err != nil {
+			log.Error("AddDoor43DownloadCount [door43_metadata: %d, kind: %s]: %v", key.Door43MetadataID, key.Kind, err)
+		}
+	}
+	return nil
+}
+
+// getDownloadedDoor43Metadata returns the catalog entry of a download, nil if there is none or the download doesn't
+// count for it. Entries are cached by repo and ref for the batch
+func getDownloadedDoor43Metadata(ctx context.Context, d *door43Download, refDMs map[string]*repo_model.Door43Metadata) *repo_model.Door43Metadata {
+	if d.ReleaseID > 0 {
+		release, err := repo_model.GetReleaseByID(ctx, d.ReleaseID)
+		if err != nil {
+			if !repo_model.IsErrReleaseNotExist(err) {
+				log.Error("GetReleaseByID [%d]: %v", d.ReleaseID, err)
+			}
+			return nil
+		}
+		if release.IsDraft || release.IsTag {
+			return nil
+		}
+		d.RepoID, d.Ref = release.RepoID, release.TagName
+	}
+
+	key := fmt.Sprintf("%d:%s", d.RepoID, d.Ref)
+	dm, ok := refDMs[key]
+	if !ok {
+		var err error
+		dm, err = repo_model.GetDoor43MetadataByRepoIDAndRef(ctx, d.RepoID, d.Ref)
+		if err != nil {
+			if !repo_model.IsErrDoor43MetadataNotExist(err) {
+				log.Error("GetDoor43MetadataByRepoIDAndRef [repo: %d, ref: %s]: %v", d.RepoID, d.Ref, err)
+			}
+			dm = nil
+		}
+		refDMs[key] = dm
+	}
+	if dm == nil || (d.Kind == repo_model.Door43DownloadKindIngredient && !isIngredientPath(dm, d.TreePath)) {
+		return nil
+	}
+	return dm
+}
+
+// isIngredientPath returns true if the file is one of the ingredients of the entry or in the directory of one
+func isIngredientPath(dm *repo_model.Door43Metadata, treePath string) bool {
+	treePath = path.Clean("/" + treePath)[1:]
+	for _, ingredient := range dm.Ingredients {
+		ingredientPath := path.Clean("/" + ingredient.Path)[1:]
+		if ingredientPath == "" {
+			// such as "./", which would match every file of the repo
+			continue
+		}
+		if treePath == ingredientPath || strings.HasPrefix(treePath, ingredientPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// countDownload pushes a download to the queue counting them, only logging errors so downloads never fail
+// because of the counting
+func countDownload(d *door43Download) {
+	d.Day = repo_model.Door43DownloadDay(time.Now())
+	if downloadStatsQueue == nil {
+		// the queue isn't running, such as in unit tests
+		downloadStatsHandler(d)
+		return
+	}
+	if err := downloadStatsQueue.Push(d); err != nil {
+		log.Error("Unable to push download of repo %d to the door43_download_stats queue: %v", d.RepoID, err)
+	}
+}
+
+// CountArchiveDownload counts the download of a zipball, tarball or packaged archive of a ref of a repo if the ref is a catalog entry
+func CountArchiveDownload(ctx context.Context, repoID int64, ref string, archiveType git.ArchiveType) {
+	if !setting.DCS.EnableDownloadStats || archiveType == git.BUNDLE {
+		return
+	}
+	countDownload(&door43Download{RepoID: repoID, Ref: ref, Kind: repo_model.Door43DownloadKindArchive})
+}
+
+// CountAttachmentDownload counts the download of an attachment if it belongs to the release of a catalog entry
+func CountAttachmentDownload(ctx context.Context, attach *repo_model.Attachment) {
+	if !setting.DCS.EnableDownloadStats || attach.ReleaseID == 0 {
+		return
+	}
+	countDownload(&door43Download{ReleaseID: attach.ReleaseID, Kind: repo_model.Door43DownloadKindAttachment})
+}
+
+// CountIngredientDownload counts the download of a raw or media file of a ref of a repo if the ref is a catalog entry
+// and the file is one of its ingredients or in the directory of one
+func CountIngredientDownload(ctx context.Context, repoID int64, ref, treePath string) {
+	if !setting.DCS.EnableDownloadStats || ref == "" || treePath == "" {
+		return
+	}
+	countDownload(&door43Download{RepoID: repoID, Ref: ref, TreePath: treePath, Kind: repo_model.Door43DownloadKindIngredient})
+}
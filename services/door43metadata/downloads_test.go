@@ -0,0 +1,79 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadStatsHandler(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	dm := &repo_model.Door43Metadata{
+		RepoID:      1,
+		ReleaseID:   1,
+		Ref:         "v1.1",
+		RefType:     "tag",
+		Stage:       door43metadata.StageProd,
+		Language:    "en",
+		Ingredients: []*structs.Ingredient{{Identifier: "root", Path: "./"}, {Identifier: "gen", Path: "./content/gen"}},
+	}
+	assert.NoError(t, db.Insert(db.DefaultContext, dm))
+
+	today := repo_model.Door43DownloadDay(time.Now())
+	download := func(d *door43Download) *door43Download {
+		d.Day = today
+		return d
+	}
+	assert.Nil(t, downloadStatsHandler(
+		download(&door43Download{RepoID: 1, Ref: "v1.1", Kind: repo_model.Door43DownloadKindArchive}),
+		download(&door43Download{RepoID: 1, Ref: "v1.1", Kind: repo_model.Door43DownloadKindArchive}),
+		download(&door43Download{ReleaseID: 1, Kind: repo_model.Door43DownloadKindAttachment}),
+		download(&door43Download{RepoID: 1, Ref: "v1.1", TreePath: "content/gen/01.md", Kind: repo_model.Door43DownloadKindIngredient}),
+		download(&door43Download{RepoID: 1, Ref: "v1.1", TreePath: "content/gen", Kind: repo_model.Door43DownloadKindIngredient}),
+		// not an ingredient, the ingredient of the whole repo is ignored
+		download(&door43Download{RepoID: 1, Ref: "v1.1", TreePath: "README.md", Kind: repo_model.Door43DownloadKindIngredient}),
+		download(&door43Download{RepoID: 1, Ref: "v1.1", TreePath: "content/genesis.md", Kind: repo_model.Door43DownloadKindIngredient}),
+		// not a catalog entry
+		download(&door43Download{RepoID: 1, Ref: "master", Kind: repo_model.Door43DownloadKindArchive}),
+		download(&door43Download{ReleaseID: 1000, Kind: repo_model.Door43DownloadKindAttachment}),
+	))
+
+	// one row per entry, kind and day
+	unittest.AssertCount(t, &repo_model.Door43DownloadStat{}, 3)
+	counts, err := repo_model.GetDoor43DownloadCounts(db.DefaultContext, &repo_model.Door43DownloadStatsOptions{
+		RepoID:  1,
+		GroupBy: []repo_model.Door43DownloadGroupBy{repo_model.Door43DownloadGroupByKind},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, counts, 3) {
+		assert.Equal(t, repo_model.Door43DownloadKindArchive, counts[0].Kind)
+		assert.EqualValues(t, 2, counts[0].Count)
+		assert.Equal(t, repo_model.Door43DownloadKindAttachment, counts[1].Kind)
+		assert.EqualValues(t, 1, counts[1].Count)
+		assert.Equal(t, repo_model.Door43DownloadKindIngredient, counts[2].Kind)
+		assert.EqualValues(t, 2, counts[2].Count)
+	}
+
+	// without the queue running, a download is counted right away
+	CountArchiveDownload(db.DefaultContext, 1, "v1.1", git.ZIP)
+	counts, err = repo_model.GetDoor43DownloadCounts(db.DefaultContext, &repo_model.Door43DownloadStatsOptions{
+		RepoID: 1,
+		Kinds:  []repo_model.Door43DownloadKind{repo_model.Door43DownloadKindArchive},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, counts, 1) {
+		assert.EqualValues(t, 3, counts[0].Count)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"context"
+	"sort"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/dcs"
+)
+
+// maxRecentLanguageEntries is the number of most recently released or updated entries of a language overview
+const maxRecentLanguageEntries = 10
+
+// LanguageOverview is everything the catalog has in a language
+type LanguageOverview struct {
+	Language string
+	// the entries of each subject, of all the stages up to the given one, sorted by subject and title
+	Subjects []*LanguageSubjectEntries
+	// the number of entries of each owner, most first
+	Owners []*models.CatalogFacetCount
+	// the most recently released or updated entries, including previous releases
+	Recent repo_model.Door43MetadataList
+}
+
+// LanguageSubjectEntries are the entries of a subject in a language
+type LanguageSubjectEntries struct {
+	Subject string
+	Entries repo_model.Door43MetadataList
+}
+
+// CountCatalogLanguages counts the entries of each language in the catalog matching the search options
+func CountCatalogLanguages(ctx context.Context, opts *door43metadata.SearchCatalogOptions) ([]*models.CatalogFacetCount, error) {
+	counts, err := CountCatalogFacets(ctx, opts, []door43metadata.CatalogFacet{door43metadata.CatalogFacetLanguage})
+	if err != nil {
+		return nil, err
+	}
+	return counts[door43metadata.CatalogFacetLanguage], nil
+}
+
+// GetLanguageOverview returns the entries of the catalog in the language of the given stage or lower, grouped by
+// subject, the owners of those entries and the most recent ones. Returns nil if the language is neither in
+// langnames.json nor has any entries
+func GetLanguageOverview(ctx context.Context, lang string, stage door43metadata.Stage) (*LanguageOverview, error) {
+	opts := &door43metadata.SearchCatalogOptions{
+		ListOptions: db.ListOptions{ListAll: true},
+		Stage:       stage,
+		Languages:   []string{lang},
+		OrderBy:     []door43metadata.CatalogOrderBy{door43metadata.CatalogOrderBySubject, door43metadata.CatalogOrderByTitle},
+	}
+	dms, _, err := SearchCatalog(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(dms) == 0 && !dcs.IsValidLanguage(lang) {
+		return nil, nil
+	}
+	if err := dms.LoadAttributes(ctx); err != nil {
+		return nil, err
+	}
+
+	overview := &LanguageOverview{Language: lang}
+	bySubject := make(map[string]*LanguageSubjectEntries)
+	for _, dm := range dms {
+		subject, ok := bySubject[dm.Subject]
+		if !ok {
+			subject = &LanguageSubjectEntries{Subject: dm.Subject}
+			bySubject[dm.Subject] = subject
+			overview.Subjects = append(overview.Subjects, subject)
+		}
+		subject.Entries = append(subject.Entries, dm)
+	}
+	sort.SliceStable(overview.Subjects, func(i, j int) bool {
+		return overview.Subjects[i].Subject < overview.Subjects[j].Subject
+	})
+
+	counts, err := CountCatalogFacets(ctx, opts, []door43metadata.CatalogFacet{door43metadata.CatalogFacetOwner})
+	if err != nil {
+		return nil, err
+	}
+	overview.Owners = counts[door43metadata.CatalogFacetOwner]
+
+	if overview.Recent, _, err = SearchCatalog(ctx, &door43metadata.SearchCatalogOptions{
+		ListOptions:    db.ListOptions{Page: 1, PageSize: maxRecentLanguageEntries},
+		Stage:          stage,
+		Languages:      []string{lang},
+		IncludeHistory: true,
+		OrderBy:        []door43metadata.CatalogOrderBy{door43metadata.CatalogOrderByNewest},
+	}); err != nil {
+		return nil, err
+	}
+	if err := overview.Recent.LoadAttributes(ctx); err != nil {
+		return nil, err
+	}
+	return overview, nil
+}
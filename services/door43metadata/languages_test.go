@@ -0,0 +1,56 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLanguageOverview(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, dm := range []*repo_model.Door43Metadata{
+		{RepoID: 1, Ref: "v1", RefType: "tag", Stage: door43metadata.StageProd, Subject: "Bible", Title: "Unlocked Literal Bible", Language: "en", IsLatestForStage: true, ReleaseDateUnix: timeutil.TimeStamp(1000)},
+		{RepoID: 1, Ref: "master", RefType: "branch", Stage: door43metadata.StageLatest, Subject: "Bible", Title: "Unlocked Literal Bible", Language: "en", IsLatestForStage: true, ReleaseDateUnix: timeutil.TimeStamp(2000)},
+		{RepoID: 4, Ref: "v2", RefType: "tag", Stage: door43metadata.StageProd, Subject: "Translation Notes", Title: "Translation Notes", Language: "en", IsLatestForStage: true, ReleaseDateUnix: timeutil.TimeStamp(3000)},
+		{RepoID: 4, Ref: "v1", RefType: "tag", Stage: door43metadata.StageProd, Subject: "Translation Notes", Title: "Translation Notes", Language: "en", ReleaseDateUnix: timeutil.TimeStamp(500)},
+		{RepoID: 2, Ref: "v1", RefType: "tag", Stage: door43metadata.StageProd, Subject: "Bible", Title: "Private Bible", Language: "en", IsLatestForStage: true},
+	} {
+		assert.NoError(t, db.Insert(db.DefaultContext, dm))
+	}
+
+	overview, err := GetLanguageOverview(db.DefaultContext, "en", door43metadata.StageLatest)
+	assert.NoError(t, err)
+	if assert.NotNil(t, overview) && assert.Len(t, overview.Subjects, 2) {
+		assert.Equal(t, "Bible", overview.Subjects[0].Subject)
+		assert.Len(t, overview.Subjects[0].Entries, 2)
+		assert.Equal(t, "Translation Notes", overview.Subjects[1].Subject)
+		assert.Len(t, overview.Subjects[1].Entries, 1)
+	}
+	assert.Len(t, overview.Owners, 2)
+	if assert.Len(t, overview.Recent, 4) {
+		assert.Equal(t, "v2", overview.Recent[0].Ref)
+		assert.Equal(t, "v1", overview.Recent[3].Ref)
+	}
+
+	overview, err = GetLanguageOverview(db.DefaultContext, "en", door43metadata.StageProd)
+	assert.NoError(t, err)
+	assert.Len(t, overview.Subjects, 2)
+	assert.Len(t, overview.Subjects[0].Entries, 1)
+
+	counts, err := CountCatalogLanguages(db.DefaultContext, &door43metadata.SearchCatalogOptions{Stage: door43metadata.StageLatest})
+	assert.NoError(t, err)
+	if assert.Len(t, counts, 1) {
+		assert.Equal(t, "en", counts[0].Value)
+		assert.EqualValues(t, 3, counts[0].Count)
+	}
+}
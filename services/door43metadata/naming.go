@@ -0,0 +1,83 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"context"
+	"fmt"
+
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ErrRepoNameNotConventional represents a repo name not following the naming conventions of the catalog
+// in an organization with a repo naming policy
+type ErrRepoNameNotConventional struct {
+	Name       string
+	Policy     dcs.RepoNamingPolicy
+	Suggestion string
+}
+
+// IsErrRepoNameNotConventional checks if an error is a ErrRepoNameNotConventional.
+func IsErrRepoNameNotConventional(err error) bool {
+	_, ok := err.(ErrRepoNameNotConventional)
+	return ok
+}
+
+func (err ErrRepoNameNotConventional) Error() string {
+	if err.Suggestion != "" {
+		return fmt.Sprintf("repo name does not follow the naming conventions [name: %s, suggestion: %s]", err.Name, err.Suggestion)
+	}
+	return fmt.Sprintf("repo name does not follow the naming conventions [name: %s]", err.Name)
+}
+
+func (err ErrRepoNameNotConventional) Unwrap() error {
+	return util.ErrInvalidArgument
+}
+
+// IsEnforced returns true if the repo name must be rejected rather than only warned about
+func (err ErrRepoNameNotConventional) IsEnforced() bool {
+	return err.Policy == dcs.RepoNamingPolicyEnforce
+}
+
+// GetRepoNamingPolicy returns the repo naming policy of an owner, which is always off for users
+func GetRepoNamingPolicy(ctx context.Context, owner *user_model.User) (dcs.RepoNamingPolicy, error) {
+	if owner == nil || !owner.IsOrganization() {
+		return dcs.RepoNamingPolicyOff, nil
+	}
+	policy, err := user_model.GetUserSetting(ctx, owner.ID, user_model.SettingsKeyRepoNamingPolicy, string(dcs.RepoNamingPolicyOff))
+	if err != nil {
+		return dcs.RepoNamingPolicyOff, err
+	}
+	if !dcs.IsValidRepoNamingPolicy(policy) {
+		return dcs.RepoNamingPolicyOff, nil
+	}
+	return dcs.RepoNamingPolicy(policy), nil
+}
+
+// SetRepoNamingPolicy sets the repo naming policy of an organization
+func SetRepoNamingPolicy(ctx context.Context, org *user_model.User, policy dcs.RepoNamingPolicy) error {
+	if !dcs.IsValidRepoNamingPolicy(string(policy)) {
+		return fmt.Errorf("invalid repo naming policy [%s]: %w", policy, util.ErrInvalidArgument)
+	}
+	if policy == dcs.RepoNamingPolicyOff {
+		return user_model.DeleteUserSetting(ctx, org.ID, user_model.SettingsKeyRepoNamingPolicy)
+	}
+	return user_model.SetUserSetting(ctx, org.ID, user_model.SettingsKeyRepoNamingPolicy, string(policy))
+}
+
+// CheckRepoName checks the name of a repo being created or renamed against the repo naming policy of its owner.
+// Returns ErrRepoNameNotConventional with a suggested name, if any, if the name does not follow the naming
+// conventions and the policy is not off. Callers must reject the name if the error IsEnforced and else only warn
+func CheckRepoName(ctx context.Context, owner *user_model.User, name string) error {
+	policy, err := GetRepoNamingPolicy(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if policy == dcs.RepoNamingPolicyOff || dcs.RepoNameFollowsConventions(name) {
+		return nil
+	}
+	return ErrRepoNameNotConventional{Name: name, Policy: policy, Suggestion: dcs.SuggestRepoName(name)}
+}
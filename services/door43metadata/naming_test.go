@@ -0,0 +1,48 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/dcs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRepoName(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	org := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 3})
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	policy, err := GetRepoNamingPolicy(db.DefaultContext, org)
+	assert.NoError(t, err)
+	assert.Equal(t, dcs.RepoNamingPolicyOff, policy)
+	assert.NoError(t, CheckRepoName(db.DefaultContext, org, "my-repo"))
+
+	assert.NoError(t, SetRepoNamingPolicy(db.DefaultContext, org, dcs.RepoNamingPolicySuggest))
+	err = CheckRepoName(db.DefaultContext, org, "my-repo")
+	if assert.True(t, IsErrRepoNameNotConventional(err)) {
+		assert.False(t, err.(ErrRepoNameNotConventional).IsEnforced())
+	}
+
+	assert.NoError(t, SetRepoNamingPolicy(db.DefaultContext, org, dcs.RepoNamingPolicyEnforce))
+	err = CheckRepoName(db.DefaultContext, org, "my-repo")
+	if assert.True(t, IsErrRepoNameNotConventional(err)) {
+		assert.True(t, err.(ErrRepoNameNotConventional).IsEnforced())
+	}
+
+	assert.Error(t, SetRepoNamingPolicy(db.DefaultContext, org, "strict"))
+
+	// Users have no repo naming policy
+	assert.NoError(t, user_model.SetUserSetting(db.DefaultContext, user.ID, user_model.SettingsKeyRepoNamingPolicy, string(dcs.RepoNamingPolicyEnforce)))
+	assert.NoError(t, CheckRepoName(db.DefaultContext, user, "my-repo"))
+
+	assert.NoError(t, SetRepoNamingPolicy(db.DefaultContext, org, dcs.RepoNamingPolicyOff))
+	assert.NoError(t, CheckRepoName(db.DefaultContext, org, "my-repo"))
+}
@@ -0,0 +1,264 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"bufio"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	// usfmChapterVerseRegex matches the chapter and verse markers of USFM, with the second verse of a verse range
+	usfmChapterVerseRegex = regexp.MustCompile(`\\(c|v)\s+(\d+)(?:-(\d+))?`)
+	// usfmNoteRegex matches the footnotes and cross references of USFM, which aren't the text of a verse
+	usfmNoteRegex = regexp.MustCompile(`(?s)\\(f|fe|x)\s.*?\\(f|fe|x)\*`)
+	// usfmAttributesRegex matches the attributes of USFM character markers, e.g. |x-occurrence="1"
+	usfmAttributesRegex = regexp.MustCompile(`\|[^\\]*`)
+	// usfmMarkerRegex matches USFM markers, e.g. \w, \zaln-s and \zaln-e\*
+	usfmMarkerRegex = regexp.MustCompile(`\\[a-z0-9\-]+\*?|\\\*`)
+	// obsImageRegex matches the image of an OBS frame in markdown, which each frame starts with
+	obsImageRegex = regexp.MustCompile(`(?m)^\s*!\[[^\]]*\]\([^)]*\)\s*$`)
+)
+
+// isProgressTracked returns true if the translation progress of the ingredients of the entry is computed. It is of
+// the Bible and Open Bible Stories translations of tS, tC and RC repos
+func isProgressTracked(dm *repo_model.Door43Metadata) bool {
+	switch dm.MetadataType {
+	case "rc", "tc", "ts":
+	default:
+		return false
+	}
+	switch dm.Subject {
+	case "Bible", "Aligned Bible", "Open Bible Stories":
+		return true
+	}
+	return false
+}
+
+// setIngredientsProgress computes how many of the verses, or frames of OBS, of each ingredient have content
+func setIngredientsProgress(dm *repo_model.Door43Metadata, commit *git.Commit) {
+	if !isProgressTracked(dm) {
+		return
+	}
+	for _, ingredient := range dm.Ingredients {
+		book := strings.ToLower(ingredient.Identifier)
+		expected := dcs.GetChapterVerseCounts(book)
+		if expected == nil {
+			continue
+		}
+		filled, err := getIngredientFilledVerses(dm, book, ingredient.Path, commit)
+		if err != nil {
+			log.Warn("setIngredientsProgress: unable to get the progress of %s in %s at %s: %v", ingredient.Path, dm.Repo.FullName(), commit.ID.String(), err)
+			continue
+		}
+		ingredient.Progress = newIngredientProgress(expected, filled)
+	}
+}
+
+// newIngredientProgress counts the verses of each chapter that have content out of the expected verses
+func newIngredientProgress(expected []int, filled map[int]map[int]bool) *structs.IngredientProgress {
+	progress := &structs.IngredientProgress{Chapters: make([]*structs.ChapterProgress, 0, len(expected))}
+	for i, total := range expected {
+		chapter := &structs.ChapterProgress{Chapter: i + 1, Total: total}
+		for verse := range filled[chapter.Chapter] {
+			if verse >= 1 && verse <= total {
+				chapter.Completed++
+			}
+		}
+		progress.Completed += chapter.Completed
+		progress.Total += chapter.Total
+		progress.Chapters = append(progress.Chapters, chapter)
+	}
+	return progress
+}
+
+// getIngredientFilledVerses returns the verses, or frames of OBS, of each chapter of an ingredient that have content
+func getIngredientFilledVerses(dm *repo_model.Door43Metadata, book, ingredientPath string, commit *git.Commit) (map[int]map[int]bool, error) {
+	treePath := strings.Trim(strings.TrimPrefix(path.Clean("/"+ingredientPath), "/"), "/")
+	if dm.MetadataType == "ts" {
+		// translationStudio projects have a directory per chapter with a file per chunk of verses or per frame
+		return getTsFilledVerses(commit, treePath, book == "obs")
+	}
+	if book == "obs" {
+		return getOBSFilledFrames(commit, treePath)
+	}
+	if !strings.HasSuffix(strings.ToLower(treePath), ".usfm") {
+		return nil, nil
+	}
+	content, err := readBlobString(commit, treePath)
+	if err != nil {
+		return nil, err
+	}
+	filled := make(map[int]map[int]bool)
+	addUSFMFilledVerses(filled, content, 0)
+	return filled, nil
+}
+
+// readBlobString reads the file at the path of the commit
+func readBlobString(commit *git.Commit, treePath string) (string, error) {
+	blob, err := commit.GetBlobByPath(treePath)
+	if err != nil {
+		return "", err
+	}
+	content, err := blob.GetBlobContent(blob.Size())
+	if err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// hasText returns true if the string has any letter or number
+func hasText(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) >= 0
+}
+
+// usfmHasText returns true if USFM has text other than markers, their attributes and notes
+func usfmHasText(usfm string) bool {
+	usfm = usfmNoteRegex.ReplaceAllString(usfm, " ")
+	usfm = usfmAttributesRegex.ReplaceAllString(usfm, " ")
+	usfm = usfmMarkerRegex.ReplaceAllString(usfm, " ")
+	return hasText(usfm)
+}
+
+// addUSFMFilledVerses adds the verses of the USFM that have text to the filled verses of each chapter. Verses before
+// any chapter marker are of the given chapter
+func addUSFMFilledVerses(filled map[int]map[int]bool, usfm string, chapter int) {
+	matches := usfmChapterVerseRegex.FindAllStringSubmatchIndex(usfm, -1)
+	for i, m := range matches {
+		number, _ := strconv.Atoi(usfm[m[4]:m[5]])
+		if usfm[m[2]:m[3]] == "c" {
+			chapter = number
+			continue
+		}
+		end := len(usfm)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		if chapter == 0 || !usfmHasText(usfm[m[1]:end]) {
+			continue
+		}
+		last := number
+		if m[6] >= 0 {
+			last, _ = strconv.Atoi(usfm[m[6]:m[7]])
+		}
+		if filled[chapter] == nil {
+			filled[chapter] = make(map[int]bool)
+		}
+		for verse := number; verse <= last && verse-number < 200; verse++ {
+			filled[chapter][verse] = true
+		}
+	}
+}
+
+// getOBSFilledFrames returns the frames that have text of each story of a RC OBS, which has a markdown file per
+// story in the directory, e.g. content/01.md, in which each frame starts with an image
+func getOBSFilledFrames(commit *git.Commit, dir string) (map[int]map[int]bool, error) {
+	tree := &commit.Tree
+	if dir != "" {
+		var err error
+		if tree, err = commit.SubTree(dir); err != nil {
+			return nil, err
+		}
+	}
+	entries, err := tree.ListEntries()
+	if err != nil {
+		return nil, err
+	}
+	filled := make(map[int]map[int]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		story, err := strconv.Atoi(strings.TrimSuffix(name, ".md"))
+		if err != nil || !strings.HasSuffix(name, ".md") || entry.IsDir() {
+			continue
+		}
+		content, err := readBlobString(commit, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		images := obsImageRegex.FindAllStringIndex(content, -1)
+		for i, image := range images {
+			end := len(content)
+			if i+1 < len(images) {
+				end = images[i+1][0]
+			}
+			if obsFrameHasText(content[image[1]:end]) {
+				if filled[story] == nil {
+					filled[story] = make(map[int]bool)
+				}
+				filled[story][i+1] = true
+			}
+		}
+	}
+	return filled, nil
+}
+
+// obsFrameHasText returns true if the markdown of a frame has text other than the italic Bible reference
+// ending the story
+func obsFrameHasText(markdown string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(markdown))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) > 1 && (line[0] == '_' && line[len(line)-1] == '_' || line[0] == '*' && line[len(line)-1] == '*') {
+			continue
+		}
+		if hasText(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// getTsFilledVerses returns the verses, or frames of OBS, that have content of each chapter of a translationStudio
+// project. It has a directory per chapter, e.g. 01, with a file per chunk of USFM verses or per OBS frame, e.g. 01.txt
+func getTsFilledVerses(commit *git.Commit, dir string, isOBS bool) (map[int]map[int]bool, error) {
+	tree := &commit.Tree
+	if dir != "" && dir != "." {
+		var err error
+		if tree, err = commit.SubTree(dir); err != nil {
+			return nil, err
+		}
+	}
+	entries, err := tree.ListEntriesRecursiveFast()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	filled := make(map[int]map[int]bool)
+	for _, entry := range entries {
+		chapterDir, chunkFile := path.Split(entry.Name())
+		chapter, err := strconv.Atoi(strings.TrimSuffix(chapterDir, "/"))
+		if err != nil || chapter == 0 || entry.IsDir() || !strings.HasSuffix(chunkFile, ".txt") {
+			continue // e.g. front/title.txt and manifest.json
+		}
+		chunk, err := strconv.Atoi(strings.TrimSuffix(chunkFile, ".txt"))
+		if err != nil {
+			continue // e.g. 01/title.txt and 01/reference.txt
+		}
+		content, err := entry.Blob().GetBlobContent(entry.Blob().Size())
+		if err != nil {
+			return nil, err
+		}
+		if !isOBS {
+			addUSFMFilledVerses(filled, content, chapter)
+		} else if hasText(content) {
+			if filled[chapter] == nil {
+				filled[chapter] = make(map[int]bool)
+			}
+			filled[chapter][chunk] = true
+		}
+	}
+	return filled, nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddUSFMFilledVerses(t *testing.T) {
+	usfm := `\id TIT
+\c 1
+\p
+\v 1 Paul, a servant of God\f + \ft a note\f*
+\v 2 \w in|x-occurrence="1" x-occurrences="1"\w* hope
+\v 3
+\v 4-5 Titus, my true son
+\c 2
+\v 1 \zaln-s |x-strong="G4771"\*\zaln-e\*
+\v 2 But you`
+	filled := make(map[int]map[int]bool)
+	addUSFMFilledVerses(filled, usfm, 0)
+	assert.Equal(t, map[int]map[int]bool{
+		1: {1: true, 2: true, 4: true, 5: true},
+		2: {2: true},
+	}, filled)
+
+	// a translationStudio chunk has no chapter marker
+	filled = make(map[int]map[int]bool)
+	addUSFMFilledVerses(filled, `\v 6 one \v 7 `, 3)
+	assert.Equal(t, map[int]map[int]bool{3: {6: true}}, filled)
+
+	progress := newIngredientProgress([]int{16, 15, 15}, map[int]map[int]bool{
+		1: {1: true, 2: true, 4: true, 5: true},
+		2: {2: true, 99: true},
+	})
+	assert.Equal(t, 5, progress.Completed)
+	assert.Equal(t, 46, progress.Total)
+	assert.Len(t, progress.Chapters, 3)
+	assert.Equal(t, 4, progress.Chapters[0].Completed)
+	assert.Equal(t, 1, progress.Chapters[1].Completed)
+	assert.Equal(t, 0, progress.Chapters[2].Completed)
+}
+
+func TestOBSFrameHasText(t *testing.T) {
+	assert.True(t, obsFrameHasText("\n\nThis is how God made everything.\n"))
+	assert.False(t, obsFrameHasText("\n\n"))
+	assert.False(t, obsFrameHasText("\n_A Bible story from: Genesis 1-2_\n"))
+}
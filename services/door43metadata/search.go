@@ -0,0 +1,99 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/container"
+	catalog_indexer "code.gitea.io/gitea/modules/indexer/catalog"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// SearchCatalog searches the catalog, ranking the entries matching the keywords by relevance if the catalog indexer is available
+func SearchCatalog(ctx context.Context, opts *door43metadata.SearchCatalogOptions) (repo_model.Door43MetadataList, int64, error) {
+	matchKeywords(ctx, opts)
+	return models.SearchCatalog(ctx, opts)
+}
+
+// SearchDoor43MetadataField returns the distinct values of a door43metadata field of the entries matching the search options
+func SearchDoor43MetadataField(ctx context.Context, opts *door43metadata.SearchCatalogOptions, field string) ([]string, error) {
+	matchKeywords(ctx, opts)
+	return models.SearchDoor43MetadataField(ctx, opts, field)
+}
+
+// CountCatalogFacets counts the entries matching the search options by the values of each facet
+func CountCatalogFacets(ctx context.Context, opts *door43metadata.SearchCatalogOptions, facets []door43metadata.CatalogFacet) (map[door43metadata.CatalogFacet][]*models.CatalogFacetCount, error) {
+	matchKeywords(ctx, opts)
+	return models.CountCatalogFacets(ctx, opts, facets)
+}
+
+// matchKeywords finds the entries matching the keywords with the catalog indexer, most relevant first,
+// unless already done for these options. If the indexer is disabled or not available, or a keyword has
+// a wildcard, the keywords are matched in the database instead.
+func matchKeywords(ctx context.Context, opts *door43metadata.SearchCatalogOptions) {
+	if opts.UseMatchedIDs || len(opts.Keywords) == 0 || hasWildcardKeyword(opts.Keywords) || !catalog_indexer.IsAvailable(ctx) {
+		return
+	}
+
+	// Interleave the results of each keyword so the best matches of all keywords come first
+	var rankedIDs [][]int64
+	numIDs := 0
+	for _, keyword := range opts.Keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+		ids, err := catalog_indexer.SearchCatalog(ctx, keyword)
+		if err != nil {
+			log.Error("catalog_indexer.SearchCatalog [%s]: %v, falling back to database search", keyword, err)
+			return
+		}
+		rankedIDs = append(rankedIDs, ids)
+		numIDs += len(ids)
+	}
+
+	opts.MatchedIDs = interleaveRankedIDs(rankedIDs, numIDs)
+	opts.UseMatchedIDs = true
+}
+
+// hasWildcardKeyword returns true if a keyword has a _ or % wildcard of the database LIKE matching,
+// which the catalog indexer doesn't support
+func hasWildcardKeyword(keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.ContainsAny(keyword, "_%") {
+			return true
+		}
+	}
+	return false
+}
+
+// interleaveRankedIDs merges lists of IDs ranked by relevance, taking the IDs of the same rank of each list in turn,
+// up to catalog_indexer.MaxSearchMatches IDs
+func interleaveRankedIDs(rankedIDs [][]int64, numIDs int) []int64 {
+	numIDs = min(numIDs, catalog_indexer.MaxSearchMatches)
+	seen := make(container.Set[int64], numIDs)
+	matchedIDs := make([]int64, 0, numIDs)
+	for rank := 0; ; rank++ {
+		found := false
+		for _, ids := range rankedIDs {
+			if rank < len(ids) {
+				found = true
+				if seen.Add(ids[rank]) {
+					matchedIDs = append(matchedIDs, ids[rank])
+					if len(matchedIDs) == numIDs {
+						return matchedIDs
+					}
+				}
+			}
+		}
+		if !found {
+			return matchedIDs
+		}
+	}
+}
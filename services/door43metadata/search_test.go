@@ -0,0 +1,43 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	catalog_indexer "code.gitea.io/gitea/modules/indexer/catalog"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterleaveRankedIDs(t *testing.T) {
+	assert.Equal(t, []int64{1, 4, 2, 3, 5}, interleaveRankedIDs([][]int64{{1, 2, 3}, {4, 1, 5}}, 6))
+	assert.Equal(t, []int64{1, 2}, interleaveRankedIDs([][]int64{{1, 2}, nil}, 2))
+	assert.Empty(t, interleaveRankedIDs(nil, 0))
+
+	// up to the maximum number of matches
+	defer test.MockVariableValue(&catalog_indexer.MaxSearchMatches, 3)()
+	assert.Equal(t, []int64{1, 4, 2}, interleaveRankedIDs([][]int64{{1, 2, 3}, {4, 1, 5}}, 6))
+}
+
+func TestMatchKeywords(t *testing.T) {
+	assert.True(t, hasWildcardKeyword([]string{"bible", "en_ult"}))
+	assert.True(t, hasWildcardKeyword([]string{"100%"}))
+	assert.False(t, hasWildcardKeyword([]string{"bible", "en-ult"}))
+
+	// The keywords are matched by the indexer by default, in the database if it isn't available
+	assert.Equal(t, "bleve", setting.Indexer.CatalogType)
+	opts := &door43metadata.SearchCatalogOptions{Keywords: []string{"bible"}}
+	matchKeywords(db.DefaultContext, opts)
+	assert.False(t, opts.UseMatchedIDs)
+
+	// or if it is disabled
+	defer test.MockVariableValue(&setting.Indexer.CatalogType, "db")()
+	matchKeywords(db.DefaultContext, opts)
+	assert.False(t, opts.UseMatchedIDs)
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+// GetSigningKeyForOwner returns the key used to sign an owner's catalog entries,
+// which is the owner's own key if it has one, otherwise the instance key
+func GetSigningKeyForOwner(ctx context.Context, ownerID int64) (*asymkey_model.Door43SigningKey, error) {
+	key, err := asymkey_model.GetDoor43SigningKey(ctx, ownerID)
+	if err == nil || !asymkey_model.IsErrDoor43SigningKeyNotExist(err) {
+		return key, err
+	}
+	return asymkey_model.GetDoor43SigningKey(ctx, 0)
+}
+
+// GetSignedPayload returns the content of a door43 metadata entry that is signed
+func GetSignedPayload(dm *repo_model.Door43Metadata) *structs.CatalogSignedPayload {
+	ingredients := make(map[string]string, len(dm.Ingredients))
+	for _, ingredient := range dm.Ingredients {
+		ingredients[strings.TrimPrefix(ingredient.Path, "./")] = ingredient.Checksum
+	}
+	return &structs.CatalogSignedPayload{
+		Owner:       dm.Repo.OwnerName,
+		Repo:        dm.Repo.Name,
+		Ref:         dm.Ref,
+		CommitSHA:   dm.CommitSHA,
+		Metadata:    dm.Metadata,
+		Ingredients: ingredients,
+	}
+}
+
+// SignDoor43Metadata signs the metadata, commit SHA and ingredient checksums of a door43 metadata entry
+// with the signing key of the repo's owner
+func SignDoor43Metadata(ctx context.Context, dm *repo_model.Door43Metadata) (*structs.CatalogSignature, error) {
+	if err := dm.LoadRepo(ctx); err != nil {
+		return nil, err
+	}
+	key, err := GetSigningKeyForOwner(ctx, dm.Repo.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(GetSignedPayload(dm))
+	if err != nil {
+		return nil, err
+	}
+	signature, err := key.Sign(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &structs.CatalogSignature{
+		KeyID:     key.KeyID,
+		Algorithm: asymkey_model.Door43SigningKeyAlgorithm,
+		Payload:   base64.StdEncoding.EncodeToString(payload),
+		Signature: signature,
+	}, nil
+}
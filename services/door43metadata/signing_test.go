@@ -0,0 +1,85 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func verifyCatalogSignature(t *testing.T, key *asymkey_model.Door43SigningKey, signature *structs.CatalogSignature) *structs.CatalogSignedPayload {
+	pub, err := key.PublicKeyBytes()
+	assert.NoError(t, err)
+	payload, err := base64.StdEncoding.DecodeString(signature.Payload)
+	assert.NoError(t, err)
+	sig, err := base64.StdEncoding.DecodeString(signature.Signature)
+	assert.NoError(t, err)
+	assert.True(t, ed25519.Verify(pub, payload, sig))
+
+	signed := &structs.CatalogSignedPayload{}
+	assert.NoError(t, json.Unmarshal(payload, signed))
+	return signed
+}
+
+func TestSignDoor43Metadata(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	dm := &repo_model.Door43Metadata{
+		RepoID:    1,
+		Ref:       "v1.1",
+		CommitSHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		Metadata:  &map[string]interface{}{"dublin_core": map[string]interface{}{"identifier": "ult"}},
+		Ingredients: []*structs.Ingredient{
+			{Identifier: "gen", Path: "./01-GEN.usfm", Checksum: "abc"},
+		},
+	}
+
+	// No instance key has been generated
+	_, err := SignDoor43Metadata(db.DefaultContext, dm)
+	assert.True(t, asymkey_model.IsErrDoor43SigningKeyNotExist(err))
+
+	assert.NoError(t, asymkey_model.InitInstanceDoor43SigningKey(db.DefaultContext))
+	assert.NoError(t, asymkey_model.InitInstanceDoor43SigningKey(db.DefaultContext))
+	instanceKey, err := asymkey_model.GetDoor43SigningKey(db.DefaultContext, 0)
+	assert.NoError(t, err)
+
+	signature, err := SignDoor43Metadata(db.DefaultContext, dm)
+	assert.NoError(t, err)
+	assert.Equal(t, instanceKey.KeyID, signature.KeyID)
+	assert.Equal(t, asymkey_model.Door43SigningKeyAlgorithm, signature.Algorithm)
+	signed := verifyCatalogSignature(t, instanceKey, signature)
+	assert.Equal(t, "user2", signed.Owner)
+	assert.Equal(t, "repo1", signed.Repo)
+	assert.Equal(t, dm.Ref, signed.Ref)
+	assert.Equal(t, dm.CommitSHA, signed.CommitSHA)
+	assert.Equal(t, map[string]string{"01-GEN.usfm": "abc"}, signed.Ingredients)
+
+	// The owner's own key takes precedence over the instance key
+	ownerKey, err := asymkey_model.CreateDoor43SigningKey(db.DefaultContext, 2)
+	assert.NoError(t, err)
+	signature, err = SignDoor43Metadata(db.DefaultContext, dm)
+	assert.NoError(t, err)
+	assert.Equal(t, ownerKey.KeyID, signature.KeyID)
+	verifyCatalogSignature(t, ownerKey, signature)
+
+	// A signature doesn't verify with another key or a modified payload
+	pub, err := instanceKey.PublicKeyBytes()
+	assert.NoError(t, err)
+	payload, _ := base64.StdEncoding.DecodeString(signature.Payload)
+	sig, _ := base64.StdEncoding.DecodeString(signature.Signature)
+	assert.False(t, ed25519.Verify(pub, payload, sig))
+	pub, err = ownerKey.PublicKeyBytes()
+	assert.NoError(t, err)
+	assert.False(t, ed25519.Verify(pub, append(payload, ' '), sig))
+}
@@ -0,0 +1,142 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"context"
+
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+// GetEntrySubscriberIDs returns the IDs of the active users with a subscription matching the catalog entry
+// who can read the entry's release
+func GetEntrySubscriberIDs(ctx context.Context, dm *repo_model.Door43Metadata) ([]int64, error) {
+	if err := dm.LoadRepo(ctx); err != nil {
+		return nil, err
+	}
+	subscriptions, err := repo_model.GetDoor43SubscriptionsMatchingEntry(ctx, dm)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int64, 0, len(subscriptions))
+	seen := make(container.Set[int64])
+	for _, s := range subscriptions {
+		if !seen.Add(s.UserID) {
+			continue
+		}
+		if err := s.LoadUser(ctx); err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if canRead, err := canReadSubscribedEntry(ctx, s.User, dm); err != nil {
+			return nil, err
+		} else if canRead {
+			userIDs = append(userIDs, s.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+// canReadSubscribedEntry returns true if the user is active and can read the releases of the entry's repo
+func canReadSubscribedEntry(ctx context.Context, user *user_model.User, dm *repo_model.Door43Metadata) (bool, error) {
+	if !user.IsActive || user.ProhibitLogin {
+		return false, nil
+	}
+	perm, err := access_model.GetUserRepoPermission(ctx, dm.Repo, user)
+	if err != nil {
+		return false, err
+	}
+	return perm.CanRead(unit.TypeReleases), nil
+}
+
+// SendSubscriptionDigests emails each user who asked for a digest of a subscription the catalog entries of
+// releases published since the last digest that match the subscription
+func SendSubscriptionDigests(ctx context.Context) error {
+	subscriptions, err := repo_model.GetDoor43SubscriptionsWithEmailDigest(ctx)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	since := timeutil.TimeStampNow()
+	for _, s := range subscriptions {
+		if after := subscriptionDigestAfter(s); after < since {
+			since = after
+		}
+	}
+	dms, err := repo_model.GetReleaseDoor43MetadataReleasedSince(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	now := timeutil.TimeStampNow()
+	for _, s := range subscriptions {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if err := s.LoadUser(ctx); err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		entries, err := getSubscriptionDigestEntries(ctx, s, dms)
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			if err := mailer.SendCatalogSubscriptionDigestMail(ctx, s, entries); err != nil {
+				log.Error("SendCatalogSubscriptionDigestMail [subscription: %d]: %v", s.ID, err)
+				continue
+			}
+		}
+
+		s.LastDigestUnix = now
+		if err := repo_model.UpdateDoor43SubscriptionCols(ctx, s, "last_digest_unix"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subscriptionDigestAfter returns the time after which entries are new to the subscription's next digest
+func subscriptionDigestAfter(s *repo_model.Door43Subscription) timeutil.TimeStamp {
+	if s.LastDigestUnix < s.CreatedUnix {
+		return s.CreatedUnix
+	}
+	return s.LastDigestUnix
+}
+
+// getSubscriptionDigestEntries returns the catalog entries the subscription's user can read that match the subscription
+// and were released since its last digest, or since it was created if there was none. The subscription's user must be loaded
+func getSubscriptionDigestEntries(ctx context.Context, s *repo_model.Door43Subscription, dms []*repo_model.Door43Metadata) ([]*repo_model.Door43Metadata, error) {
+	after := subscriptionDigestAfter(s)
+	entries := make([]*repo_model.Door43Metadata, 0, 10)
+	for _, dm := range dms {
+		if dm.ReleaseDateUnix <= after || !s.Matches(dm) {
+			continue
+		}
+		if canRead, err := canReadSubscribedEntry(ctx, s.User, dm); err != nil {
+			return nil, err
+		} else if canRead {
+			entries = append(entries, dm)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package door43metadata
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoor43SubscriptionMatches(t *testing.T) {
+	repo := &repo_model.Repository{OwnerName: "unfoldingWord"}
+	dm := &repo_model.Door43Metadata{
+		Repo:        repo,
+		RefType:     "tag",
+		Stage:       door43metadata.StageProd,
+		Language:    "en",
+		Subject:     "Aligned Bible",
+		Ingredients: []*structs.Ingredient{{Identifier: "gen"}, {Identifier: "exo"}},
+	}
+
+	s := &repo_model.Door43Subscription{Stage: door43metadata.StageProd, Languages: []string{"fr", "EN"}}
+	assert.True(t, s.Matches(dm))
+	s.Subjects = []string{"aligned bible"}
+	s.Owners = []string{"unfoldingword"}
+	s.Books = []string{"EXO"}
+	assert.True(t, s.Matches(dm))
+
+	// Each non-empty filter must match
+	s.Books = []string{"lev"}
+	assert.False(t, s.Matches(dm))
+	s.Books = nil
+	s.Owners = []string{"door43-catalog"}
+	assert.False(t, s.Matches(dm))
+	s.Owners = nil
+
+	// A prod subscription doesn't match preprod releases, a preprod one matches both
+	dm.Stage = door43metadata.StagePreProd
+	assert.False(t, s.Matches(dm))
+	s.Stage = door43metadata.StagePreProd
+	assert.True(t, s.Matches(dm))
+
+	// Branches never match
+	dm.RefType = "branch"
+	dm.Stage = door43metadata.StageLatest
+	assert.False(t, s.Matches(dm))
+}
+
+func TestGetEntrySubscriberIDs(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, s := range []*repo_model.Door43Subscription{
+		{UserID: 4, Name: "english", Languages: []string{"en"}, Stage: door43metadata.StageProd},
+		{UserID: 4, Name: "owner", Owners: []string{"user2"}, Stage: door43metadata.StagePreProd},
+		{UserID: 5, Name: "preprod", Languages: []string{"en"}, Stage: door43metadata.StagePreProd},
+		{UserID: 2, Name: "french", Languages: []string{"fr"}, Stage: door43metadata.StageProd},
+	} {
+		assert.NoError(t, repo_model.InsertDoor43Subscription(db.DefaultContext, s))
+	}
+
+	// public repo
+	dm := &repo_model.Door43Metadata{RepoID: 1, RefType: "tag", Stage: door43metadata.StageProd, Language: "en"}
+	userIDs, err := GetEntrySubscriberIDs(db.DefaultContext, dm)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{4, 5}, userIDs)
+
+	dm = &repo_model.Door43Metadata{RepoID: 1, RefType: "tag", Stage: door43metadata.StagePreProd, Language: "en"}
+	userIDs, err = GetEntrySubscriberIDs(db.DefaultContext, dm)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{4, 5}, userIDs)
+
+	// private repo of user2 that only its owner can read
+	dm = &repo_model.Door43Metadata{RepoID: 2, RefType: "tag", Stage: door43metadata.StageProd, Language: "fr"}
+	userIDs, err = GetEntrySubscriberIDs(db.DefaultContext, dm)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2}, userIDs)
+	dm = &repo_model.Door43Metadata{RepoID: 2, RefType: "tag", Stage: door43metadata.StageProd, Language: "en"}
+	userIDs, err = GetEntrySubscriberIDs(db.DefaultContext, dm)
+	assert.NoError(t, err)
+	assert.Empty(t, userIDs)
+}
+
+func TestSendSubscriptionDigests(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	now := timeutil.TimeStampNow()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4})
+	s := &repo_model.Door43Subscription{UserID: user.ID, Name: "english", Languages: []string{"en"}, Stage: door43metadata.StageProd, EmailDigest: true}
+	assert.NoError(t, repo_model.InsertDoor43Subscription(db.DefaultContext, s))
+	s.CreatedUnix = now - 1000
+	s.LastDigestUnix = now - 100
+	_, err := db.GetEngine(db.DefaultContext).ID(s.ID).Cols("created_unix", "last_digest_unix").NoAutoTime().Update(s)
+	assert.NoError(t, err)
+	noDigest := &repo_model.Door43Subscription{UserID: user.ID, Name: "all english", Languages: []string{"en"}, Stage: door43metadata.StageProd}
+	assert.NoError(t, repo_model.InsertDoor43Subscription(db.DefaultContext, noDigest))
+
+	// The entry of a release published before the last digest that was created again, e.g. when the repo was made public,
+	// isn't new, nor are entries of repos the user can't read
+	for _, dm := range []*repo_model.Door43Metadata{
+		{RepoID: 1, Ref: "v1", RefType: "tag", Stage: door43metadata.StageProd, Language: "en", ReleaseDateUnix: now - 200},
+		{RepoID: 1, Ref: "v2", RefType: "tag", Stage: door43metadata.StageProd, Language: "en", ReleaseDateUnix: now - 50},
+		{RepoID: 1, Ref: "v3", RefType: "tag", Stage: door43metadata.StageProd, Language: "fr", ReleaseDateUnix: now - 50},
+		{RepoID: 2, Ref: "v1", RefType: "tag", Stage: door43metadata.StageProd, Language: "en", ReleaseDateUnix: now - 50},
+	} {
+		assert.NoError(t, db.Insert(db.DefaultContext, dm))
+	}
+
+	dms, err := repo_model.GetReleaseDoor43MetadataReleasedSince(db.DefaultContext, now-100)
+	assert.NoError(t, err)
+	assert.Len(t, dms, 3)
+
+	s.User = user
+	entries, err := getSubscriptionDigestEntries(db.DefaultContext, s, dms)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "v2", entries[0].Ref)
+		assert.EqualValues(t, 1, entries[0].RepoID)
+	}
+
+	assert.NoError(t, SendSubscriptionDigests(db.DefaultContext))
+	s = unittest.AssertExistsAndLoadBean(t, &repo_model.Door43Subscription{ID: s.ID})
+	assert.GreaterOrEqual(t, s.LastDigestUnix, now)
+	noDigest = unittest.AssertExistsAndLoadBean(t, &repo_model.Door43Subscription{ID: noDigest.ID})
+	assert.EqualValues(t, 0, noDigest.LastDigestUnix)
+}
@@ -45,6 +45,7 @@ type UpdateOrgSettingForm struct {
 	Visibility                structs.VisibleType
 	MaxRepoCreation           int
 	RepoAdminChangeTeamAccess bool
+	RepoNamingPolicy          string `binding:"In(,off,suggest,enforce)"` // DCS Customizations
 }
 
 // Validate validates the fields
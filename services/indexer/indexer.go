@@ -4,6 +4,7 @@
 package indexer
 
 import (
+	catalog_indexer "code.gitea.io/gitea/modules/indexer/catalog"
 	code_indexer "code.gitea.io/gitea/modules/indexer/code"
 	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
 	stats_indexer "code.gitea.io/gitea/modules/indexer/stats"
@@ -16,5 +17,6 @@ func Init() error {
 
 	issue_indexer.InitIssueIndexer(false)
 	code_indexer.Init()
+	catalog_indexer.InitCatalogIndexer(false) // DCS Customizations
 	return stats_indexer.Init()
 }
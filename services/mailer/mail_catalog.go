@@ -0,0 +1,102 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/templates"
+	"code.gitea.io/gitea/modules/translation"
+)
+
+const (
+	mailCatalogSubscriptionDigest base.TplName = "catalog/subscription_digest"
+	mailReleaseBrokenLinks        base.TplName = "catalog/broken_links"
+)
+
+// SendCatalogSubscriptionDigestMail sends the user of a catalog subscription a digest of the new catalog entries
+// matching it. The subscription's user and the entries' repos must be loaded
+func SendCatalogSubscriptionDigestMail(ctx context.Context, s *repo_model.Door43Subscription, entries []*repo_model.Door43Metadata) error {
+	if setting.MailService == nil {
+		// No mail service configured
+		return nil
+	}
+
+	locale := translation.NewLocale(s.User.Language)
+	subject := locale.Tr("mail.catalog.subscription.digest.subject", s.Name)
+	data := map[string]any{
+		"Subscription": s,
+		"Entries":      entries,
+		"Subject":      subject,
+		"Language":     locale.Language(),
+		"Link":         setting.AppURL + "notifications",
+		// helper
+		"locale":    locale,
+		"Str2html":  templates.Str2html,
+		"DotEscape": templates.DotEscape,
+	}
+
+	var content bytes.Buffer
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailCatalogSubscriptionDigest), data); err != nil {
+		return err
+	}
+
+	msg := NewMessage(s.User.Email, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, catalog subscription digest", s.UserID)
+
+	SendAsync(msg)
+	return nil
+}
+
+// SendReleaseBrokenLinksMail sends the admins of a repo the links of its release attachments that were found broken.
+// releases must contain the release of each link by ID
+func SendReleaseBrokenLinksMail(ctx context.Context, repo *repo_model.Repository, links []*repo_model.AttachmentLinkStatus, releases map[int64]*repo_model.Release) error {
+	if setting.MailService == nil || len(links) == 0 {
+		// No mail service configured
+		return nil
+	}
+
+	admins, err := access_model.GetRepoAdmins(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	for _, admin := range admins {
+		if !admin.IsMailable() || admin.EmailNotifications() == user_model.EmailNotificationsDisabled {
+			continue
+		}
+		locale := translation.NewLocale(admin.Language)
+		subject := locale.Tr("mail.release.broken_links.subject", repo.FullName())
+		data := map[string]any{
+			"Repo":     repo,
+			"Links":    links,
+			"Releases": releases,
+			"Subject":  subject,
+			"Language": locale.Language(),
+			"Link":     repo.HTMLURL() + "/releases",
+			// helper
+			"locale":    locale,
+			"Str2html":  templates.Str2html,
+			"DotEscape": templates.DotEscape,
+		}
+
+		var content bytes.Buffer
+		if err := bodyTemplates.ExecuteTemplate(&content, string(mailReleaseBrokenLinks), data); err != nil {
+			return err
+		}
+
+		msg := NewMessage(admin.Email, subject, content.String())
+		msg.Info = fmt.Sprintf("UID: %d, broken release links of %s", admin.ID, repo.FullName())
+
+		SendAsync(msg)
+	}
+	return nil
+}
@@ -74,4 +74,8 @@ type Notifier interface {
 	PackageDelete(ctx context.Context, doer *user_model.User, pd *packages_model.PackageDescriptor)
 
 	ChangeDefaultBranch(ctx context.Context, repo *repo_model.Repository)
+
+	/*** DCS Customizations ***/
+	NewCatalogEntry(ctx context.Context, dm *repo_model.Door43Metadata)
+	/*** END DCS Customizations ***/
 }
@@ -367,3 +367,14 @@ func ChangeDefaultBranch(ctx context.Context, repo *repo_model.Repository) {
 		notifier.ChangeDefaultBranch(ctx, repo)
 	}
 }
+
+/*** DCS Customizations ***/
+
+// NewCatalogEntry notifies a new catalog entry of a prod or preprod release to notifiers
+func NewCatalogEntry(ctx context.Context, dm *repo_model.Door43Metadata) {
+	for _, notifier := range notifiers {
+		notifier.NewCatalogEntry(ctx, dm)
+	}
+}
+
+/*** END DCS Customizations ***/
@@ -208,3 +208,11 @@ func (*NullNotifier) PackageDelete(ctx context.Context, doer *user_model.User, p
 // ChangeDefaultBranch places a place holder function
 func (*NullNotifier) ChangeDefaultBranch(ctx context.Context, repo *repo_model.Repository) {
 }
+
+/*** DCS Customizations ***/
+
+// NewCatalogEntry places a place holder function
+func (*NullNotifier) NewCatalogEntry(ctx context.Context, dm *repo_model.Door43Metadata) {
+}
+
+/*** END DCS Customizations ***/
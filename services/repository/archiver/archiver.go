@@ -34,6 +34,11 @@ type ArchiveRequest struct {
 	refName  string
 	Type     git.ArchiveType
 	CommitID string
+	/*** DCS Customizations ***/
+	Paths      []string // only these paths are archived if any
+	pathsName  string
+	PackageDir string // directory of the files in a packaged archive
+	/*** END DCS Customizations ***/
 }
 
 // SHA1 hashes will only go up to 40 characters, but SHA256 hashes will go all
@@ -81,6 +86,14 @@ func NewRequest(repoID int64, repo *git.Repository, uri string) (*ArchiveRequest
 
 	var ext string
 	switch {
+	/*** DCS Customizations ***/
+	case strings.HasSuffix(uri, ".rc.zip"):
+		ext = ".rc.zip"
+		r.Type = git.RCZIP
+	case strings.HasSuffix(uri, ".burrito.zip"):
+		ext = ".burrito.zip"
+		r.Type = git.BURRITOZIP
+	/*** END DCS Customizations ***/
 	case strings.HasSuffix(uri, ".zip"):
 		ext = ".zip"
 		r.Type = git.ZIP
@@ -126,9 +139,23 @@ func NewRequest(repoID int64, repo *git.Repository, uri string) (*ArchiveRequest
 // GetArchiveName returns the name of the caller, based on the ref used by the
 // caller to create this request.
 func (aReq *ArchiveRequest) GetArchiveName() string {
+	/*** DCS Customizations ***/
+	if aReq.pathsName != "" {
+		return strings.ReplaceAll(aReq.refName, "/", "-") + "-" + aReq.pathsName + "." + aReq.Type.String()
+	}
+	/*** END DCS Customizations ***/
 	return strings.ReplaceAll(aReq.refName, "/", "-") + "." + aReq.Type.String()
 }
 
+/*** DCS Customizations ***/
+
+// GetRefName returns the branch, tag or commit the archive was requested for
+func (aReq *ArchiveRequest) GetRefName() string {
+	return aReq.refName
+}
+
+/*** END DCS Customizations ***/
+
 // Await awaits the completion of an ArchiveRequest. If the archive has
 // already been prepared the method returns immediately. Otherwise an archiver
 // process will be started and its completion awaited. On success the returned
@@ -136,7 +163,7 @@ func (aReq *ArchiveRequest) GetArchiveName() string {
 // context is cancelled/times out a started archiver will still continue to run
 // in the background.
 func (aReq *ArchiveRequest) Await(ctx context.Context) (*repo_model.RepoArchiver, error) {
-	archiver, err := repo_model.GetRepoArchiver(ctx, aReq.RepoID, aReq.Type, aReq.CommitID)
+	archiver, err := repo_model.GetRepoArchiverByPathsHash(ctx, aReq.RepoID, aReq.Type, aReq.CommitID, aReq.PathsHash()) // DCS Customizations
 	if err != nil {
 		return nil, fmt.Errorf("models.GetRepoArchiver: %w", err)
 	}
@@ -161,7 +188,7 @@ func (aReq *ArchiveRequest) Await(ctx context.Context) (*repo_model.RepoArchiver
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		case <-poll.C:
-			archiver, err = repo_model.GetRepoArchiver(ctx, aReq.RepoID, aReq.Type, aReq.CommitID)
+			archiver, err = repo_model.GetRepoArchiverByPathsHash(ctx, aReq.RepoID, aReq.Type, aReq.CommitID, aReq.PathsHash()) // DCS Customizations
 			if err != nil {
 				return nil, fmt.Errorf("repo_model.GetRepoArchiver: %w", err)
 			}
@@ -181,7 +208,7 @@ func doArchive(ctx context.Context, r *ArchiveRequest) (*repo_model.RepoArchiver
 	ctx, _, finished := process.GetManager().AddContext(txCtx, fmt.Sprintf("ArchiveRequest[%d]: %s", r.RepoID, r.GetArchiveName()))
 	defer finished()
 
-	archiver, err := repo_model.GetRepoArchiver(ctx, r.RepoID, r.Type, r.CommitID)
+	archiver, err := repo_model.GetRepoArchiverByPathsHash(ctx, r.RepoID, r.Type, r.CommitID, r.PathsHash()) // DCS Customizations
 	if err != nil {
 		return nil, err
 	}
@@ -194,10 +221,11 @@ func doArchive(ctx context.Context, r *ArchiveRequest) (*repo_model.RepoArchiver
 		}
 	} else {
 		archiver = &repo_model.RepoArchiver{
-			RepoID:   r.RepoID,
-			Type:     r.Type,
-			CommitID: r.CommitID,
-			Status:   repo_model.ArchiverGenerating,
+			RepoID:    r.RepoID,
+			Type:      r.Type,
+			CommitID:  r.CommitID,
+			PathsHash: r.PathsHash(), // DCS Customizations
+			Status:    repo_model.ArchiverGenerating,
 		}
 		if err := repo_model.AddRepoArchiver(ctx, archiver); err != nil {
 			return nil, err
@@ -250,6 +278,8 @@ func doArchive(ctx context.Context, r *ArchiveRequest) (*repo_model.RepoArchiver
 				archiver.CommitID,
 				w,
 			)
+		} else if archiver.Type == git.RCZIP || archiver.Type == git.BURRITOZIP { // DCS Customizations
+			err = createPackagedArchive(ctx, gitRepo, r, w) // DCS Customizations
 		} else {
 			err = gitRepo.CreateArchive(
 				ctx,
@@ -257,6 +287,7 @@ func doArchive(ctx context.Context, r *ArchiveRequest) (*repo_model.RepoArchiver
 				w,
 				setting.Repository.PrefixArchiveFiles,
 				archiver.CommitID,
+				r.Paths..., // DCS Customizations
 			)
 		}
 		_ = w.CloseWithError(err)
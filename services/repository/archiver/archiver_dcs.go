@@ -0,0 +1,147 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package archiver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// archiveMetadataFiles are the files describing a resource which are always included in partial archives if they exist
+var archiveMetadataFiles = []string{"manifest.yaml", "metadata.json", "manifest.json", "LICENSE.md", "LICENSE"}
+
+// ArchivePathNotFoundError is returned when a book or path a partial archive was requested for was not found.
+type ArchivePathNotFoundError struct {
+	Book string
+	Path string
+}
+
+// Error implements error.
+func (e ArchivePathNotFoundError) Error() string {
+	if e.Book != "" {
+		return fmt.Sprintf("no ingredient found for book: %s", e.Book)
+	}
+	return fmt.Sprintf("path not found: %s", e.Path)
+}
+
+func (e ArchivePathNotFoundError) Is(err error) bool {
+	_, ok := err.(ArchivePathNotFoundError)
+	return ok
+}
+
+// splitArchiveFilter splits the comma separated values of the books or paths of a partial archive request
+func splitArchiveFilter(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+// getArchiveDoor43Metadata returns the catalog entry of the ref of the request, or else of its commit
+func getArchiveDoor43Metadata(ctx context.Context, repoID int64, refName, commitID string) (*repo_model.Door43Metadata, error) {
+	dm, err := repo_model.GetDoor43MetadataByRepoIDAndRef(ctx, repoID, refName)
+	if repo_model.IsErrDoor43MetadataNotExist(err) {
+		// branch entries link to their archives by commit
+		dm, err = repo_model.GetDoor43MetadataByRepoIDAndCommitSHA(ctx, repoID, commitID)
+	}
+	return dm, err
+}
+
+// SetPaths restricts the archive to the metadata files and the ingredients of the given books, which are the
+// identifiers of the ingredients of the ref's catalog entry, and to the given paths. Books and paths can be comma
+// separated. The whole tree is archived if neither are given or one of the paths is the root
+func (aReq *ArchiveRequest) SetPaths(ctx context.Context, repo *git.Repository, books, paths []string) error {
+	books = splitArchiveFilter(books)
+	paths = splitArchiveFilter(paths)
+	if len(books) == 0 && len(paths) == 0 {
+		return nil
+	}
+	if aReq.Type == git.BUNDLE {
+		return util.NewInvalidArgumentErrorf("a bundle can't be restricted to books or paths")
+	}
+
+	commit, err := repo.GetCommit(aReq.CommitID)
+	if err != nil {
+		return err
+	}
+
+	selected := make(container.Set[string])
+	if len(books) > 0 {
+		dm, err := getArchiveDoor43Metadata(ctx, aReq.RepoID, aReq.refName, commit.ID.String())
+		if err != nil {
+			if repo_model.IsErrDoor43MetadataNotExist(err) {
+				return ArchivePathNotFoundError{Book: books[0]}
+			}
+			return err
+		}
+		for _, book := range books {
+			found := false
+			for _, ingredient := range dm.Ingredients {
+				if strings.EqualFold(ingredient.Identifier, book) {
+					selected.Add(ingredient.Path)
+					found = true
+				}
+			}
+			if !found {
+				return ArchivePathNotFoundError{Book: book}
+			}
+		}
+	}
+	selected.AddMultiple(paths...)
+
+	archivePaths := make(container.Set[string], len(selected)+len(archiveMetadataFiles))
+	for p := range selected {
+		p = path.Clean("/" + p)[1:]
+		if p == "" {
+			return nil
+		}
+		if _, err := commit.GetTreeEntryByPath(p); err != nil {
+			if git.IsErrNotExist(err) {
+				return ArchivePathNotFoundError{Path: p}
+			}
+			return err
+		}
+		archivePaths.Add(p)
+	}
+	for _, file := range archiveMetadataFiles {
+		if _, err := commit.GetTreeEntryByPath(file); err == nil {
+			archivePaths.Add(file)
+		} else if !git.IsErrNotExist(err) {
+			return err
+		}
+	}
+
+	aReq.Paths = archivePaths.Values()
+	sort.Strings(aReq.Paths)
+	if len(paths) == 0 {
+		aReq.pathsName = strings.ToLower(strings.Join(books, "-"))
+	} else {
+		aReq.pathsName = aReq.PathsHash()[:10]
+	}
+	return nil
+}
+
+// PathsHash returns the hash of the paths the archive is restricted to, empty if it is of the whole tree
+func (aReq *ArchiveRequest) PathsHash() string {
+	if len(aReq.Paths) == 0 {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(strings.Join(aReq.Paths, "\n")))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package archiver
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/contexttest"
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchive_Paths(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	ctx, _ := contexttest.MockContext(t, "user27/repo49")
+	contexttest.LoadRepo(t, ctx, 49)
+	contexttest.LoadGitRepo(t, ctx)
+	defer ctx.Repo.GitRepo.Close()
+
+	newRequest := func(books, paths []string) (*ArchiveRequest, error) {
+		aReq, err := NewRequest(ctx.Repo.Repository.ID, ctx.Repo.GitRepo, "master.zip")
+		assert.NoError(t, err)
+		return aReq, aReq.SetPaths(db.DefaultContext, ctx.Repo.GitRepo, books, paths)
+	}
+
+	aReq, err := newRequest(nil, []string{"./"})
+	assert.NoError(t, err)
+	assert.Empty(t, aReq.Paths)
+	assert.Empty(t, aReq.PathsHash())
+	assert.EqualValues(t, "master.zip", aReq.GetArchiveName())
+
+	_, err = newRequest([]string{"jhn"}, nil)
+	assert.True(t, errors.Is(err, ArchivePathNotFoundError{}))
+
+	_, err = newRequest(nil, []string{"test,missing"})
+	assert.True(t, errors.Is(err, ArchivePathNotFoundError{}))
+
+	aReq, err = newRequest(nil, []string{"/test/"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"test"}, aReq.Paths)
+	assert.Len(t, aReq.PathsHash(), 64)
+	assert.EqualValues(t, "master-"+aReq.PathsHash()[:10]+".zip", aReq.GetArchiveName())
+
+	archiver, err := ArchiveRepository(db.DefaultContext, aReq)
+	assert.NoError(t, err)
+	if assert.NotNil(t, archiver) {
+		assert.EqualValues(t, aReq.PathsHash(), archiver.PathsHash)
+
+		f, err := storage.RepoArchives.Open(archiver.RelativePath())
+		assert.NoError(t, err)
+		defer f.Close()
+		content, err := io.ReadAll(f)
+		assert.NoError(t, err)
+		zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		assert.NoError(t, err)
+		var files []string
+		for _, file := range zr.File {
+			if !file.FileInfo().IsDir() {
+				files = append(files, file.Name)
+			}
+		}
+		if assert.Len(t, files, 1) { // no README.md
+			assert.True(t, strings.HasSuffix(files[0], "test/test.txt"))
+		}
+	}
+}
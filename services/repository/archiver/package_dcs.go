@@ -0,0 +1,174 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package archiver
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// packagedArchiveMetadataTypes are the metadata types of the catalog entries each packaged archive type is available for
+var packagedArchiveMetadataTypes = map[git.ArchiveType]string{
+	git.RCZIP:      "rc",
+	git.BURRITOZIP: "sb",
+}
+
+// ArchivePackageNotAvailableError is returned when a packaged archive was requested for a ref which doesn't have a
+// catalog entry of the matching metadata type.
+type ArchivePackageNotAvailableError struct {
+	Type git.ArchiveType
+}
+
+// Error implements error.
+func (e ArchivePackageNotAvailableError) Error() string {
+	return fmt.Sprintf("no %s metadata found for a %s archive", packagedArchiveMetadataTypes[e.Type], e.Type.String())
+}
+
+func (e ArchivePackageNotAvailableError) Is(err error) bool {
+	_, ok := err.(ArchivePackageNotAvailableError)
+	return ok
+}
+
+// SetPackage checks that a packaged archive can be made of the ref of the request from its catalog entry and sets
+// how it is laid out. It does nothing for the other archive types
+func (aReq *ArchiveRequest) SetPackage(ctx context.Context, repo *git.Repository) error {
+	metadataType, ok := packagedArchiveMetadataTypes[aReq.Type]
+	if !ok {
+		return nil
+	}
+	commit, err := repo.GetCommit(aReq.CommitID)
+	if err != nil {
+		return err
+	}
+	dm, err := getArchiveDoor43Metadata(ctx, aReq.RepoID, aReq.refName, commit.ID.String())
+	if err != nil {
+		if repo_model.IsErrDoor43MetadataNotExist(err) {
+			return ArchivePackageNotAvailableError{Type: aReq.Type}
+		}
+		return err
+	}
+	if dm.MetadataType != metadataType {
+		return ArchivePackageNotAvailableError{Type: aReq.Type}
+	}
+	if aReq.Type == git.RCZIP {
+		// a resource container is the directory <language>_<resource>
+		aReq.PackageDir = strings.ToLower(dm.Language + "_" + dm.Resource)
+		if aReq.PackageDir == "_" || strings.ContainsAny(aReq.PackageDir, `/\`) {
+			return util.NewInvalidArgumentErrorf("invalid resource container name: %s", aReq.PackageDir)
+		}
+	}
+	return nil
+}
+
+// readBurritoMetadata reads the metadata.json of a commit
+func readBurritoMetadata(gitRepo *git.Repository, commitID string) (map[string]any, error) {
+	commit, err := gitRepo.GetCommit(commitID)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := commit.GetBlobByPath("metadata.json")
+	if err != nil {
+		return nil, err
+	}
+	rd, err := blob.DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+	var metadata map[string]any
+	if err := json.NewDecoder(rd).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata.json: %w", err)
+	}
+	return metadata, nil
+}
+
+// createPackagedArchive repackages the files of the commit of the request as a zip archive laid out according to
+// its type. A resource container has all the files in its directory. A scripture burrito has the metadata.json at
+// the root, only the ingredients listed in it, and their checksums and sizes updated to the archived files
+func createPackagedArchive(ctx context.Context, gitRepo *git.Repository, r *ArchiveRequest, target io.Writer) error {
+	var metadata, ingredients map[string]any
+	if r.Type == git.BURRITOZIP {
+		var err error
+		if metadata, err = readBurritoMetadata(gitRepo, r.CommitID); err != nil {
+			return err
+		}
+		ingredients, _ = metadata["ingredients"].(map[string]any)
+	}
+
+	rd, w := io.Pipe()
+	defer rd.Close()
+	go func() {
+		_ = w.CloseWithError(gitRepo.CreateTarArchive(ctx, w, r.CommitID, r.Paths...))
+	}()
+
+	zw := zip.NewWriter(target)
+	archived := make(container.Set[string])
+	tr := tar.NewReader(rd)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := hdr.Name
+		var ingredient map[string]any
+		if r.Type == git.RCZIP {
+			name = r.PackageDir + "/" + name
+		} else if ingredient, _ = ingredients[hdr.Name].(map[string]any); ingredient == nil {
+			continue // the metadata.json is written last and other files aren't part of the burrito
+		}
+
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: hdr.ModTime})
+		if err != nil {
+			return err
+		}
+		hash := md5.New()
+		size, err := io.Copy(io.MultiWriter(fw, hash), tr)
+		if err != nil {
+			return err
+		}
+		if ingredient != nil {
+			ingredient["checksum"] = map[string]any{"md5": hex.EncodeToString(hash.Sum(nil))}
+			ingredient["size"] = size
+		}
+		archived.Add(hdr.Name)
+	}
+
+	if metadata != nil {
+		for p := range ingredients {
+			if !archived.Contains(p) {
+				delete(ingredients, p)
+			}
+		}
+		content, err := json.MarshalIndent(metadata, "", "  ")
+		if err != nil {
+			return err
+		}
+		fw, err := zw.Create("metadata.json")
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package archiver
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatePackagedArchive(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	files := map[string]string{
+		"metadata.json":              `{"format": "scripture burrito", "ingredients": {"ingredients/JHN.usfm": {"mimeType": "text/plain", "size": 1}, "ingredients/ROM.usfm": {"size": 2}}}`,
+		"ingredients/JHN.usfm":       "\\id JHN\n",
+		"README.md":                  "readme",
+		"ingredients/not-listed.txt": "other",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(repoPath, filepath.Dir(name)), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0o644))
+	}
+	assert.NoError(t, git.InitRepository(ctx, repoPath, false))
+	assert.NoError(t, git.NewCommand(ctx, "add", "--all").Run(&git.RunOpts{Dir: repoPath}))
+	assert.NoError(t, git.NewCommand(ctx, "commit", "-m", "init").Run(&git.RunOpts{
+		Dir: repoPath,
+		Env: append(os.Environ(), "GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@example.com", "GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@example.com"),
+	}))
+
+	gitRepo, err := git.OpenRepository(ctx, repoPath)
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+	commitID, err := gitRepo.GetRefCommitID("HEAD")
+	assert.NoError(t, err)
+
+	readZip := func(r *ArchiveRequest) map[string][]byte {
+		var buf bytes.Buffer
+		assert.NoError(t, createPackagedArchive(ctx, gitRepo, r, &buf))
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.NoError(t, err)
+		contents := make(map[string][]byte, len(zr.File))
+		for _, f := range zr.File {
+			rd, err := f.Open()
+			assert.NoError(t, err)
+			contents[f.Name], err = io.ReadAll(rd)
+			assert.NoError(t, err)
+			rd.Close()
+		}
+		return contents
+	}
+	names := func(contents map[string][]byte) []string {
+		result := make([]string, 0, len(contents))
+		for name := range contents {
+			result = append(result, name)
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	t.Run("ResourceContainer", func(t *testing.T) {
+		contents := readZip(&ArchiveRequest{Type: git.RCZIP, CommitID: commitID, PackageDir: "en_ult"})
+		assert.EqualValues(t, []string{
+			"en_ult/README.md",
+			"en_ult/ingredients/JHN.usfm",
+			"en_ult/ingredients/not-listed.txt",
+			"en_ult/metadata.json",
+		}, names(contents))
+	})
+
+	t.Run("ScriptureBurrito", func(t *testing.T) {
+		contents := readZip(&ArchiveRequest{Type: git.BURRITOZIP, CommitID: commitID})
+		assert.EqualValues(t, []string{"ingredients/JHN.usfm", "metadata.json"}, names(contents))
+
+		var metadata map[string]any
+		assert.NoError(t, json.Unmarshal(contents["metadata.json"], &metadata))
+		assert.Equal(t, "scripture burrito", metadata["format"])
+		assert.EqualValues(t, map[string]any{
+			"ingredients/JHN.usfm": map[string]any{
+				"mimeType": "text/plain",
+				"size":     float64(8),
+				"checksum": map[string]any{"md5": "c06630a66ac44ba78c6874eb2a0f7c5e"},
+			},
+		}, metadata["ingredients"])
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/queue"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata"
 	notify_service "code.gitea.io/gitea/services/notify"
 )
 
@@ -259,3 +260,22 @@ func (ns *notificationService) RepoPendingTransfer(ctx context.Context, doer, ne
 		log.Error("CreateRepoTransferNotification: %v", err)
 	}
 }
+
+/*** DCS Customizations ***/
+
+func (ns *notificationService) NewCatalogEntry(ctx context.Context, dm *repo_model.Door43Metadata) {
+	userIDs, err := door43metadata_service.GetEntrySubscriberIDs(ctx, dm)
+	if err != nil {
+		log.Error("GetEntrySubscriberIDs [%d]: %v", dm.ID, err)
+		return
+	}
+	updatedBy := dm.Repo.OwnerID
+	if dm.Release != nil {
+		updatedBy = dm.Release.PublisherID
+	}
+	if err := activities_model.CreateCatalogEntryNotifications(ctx, userIDs, dm.Repo, dm.Ref, updatedBy); err != nil {
+		log.Error("CreateCatalogEntryNotifications [%d]: %v", dm.ID, err)
+	}
+}
+
+/*** END DCS Customizations ***/
@@ -22,6 +22,7 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/setting"
+	door43metadata_service "code.gitea.io/gitea/services/door43metadata" // DCS Customizations
 
 	"xorm.io/builder"
 )
@@ -90,6 +91,9 @@ func deleteUser(ctx context.Context, u *user_model.User, purge bool) (err error)
 		&pull_model.AutoMerge{DoerID: u.ID},
 		&pull_model.ReviewState{UserID: u.ID},
 		&user_model.Redirect{RedirectUserID: u.ID},
+		&repo_model.Door43Subscription{UserID: u.ID}, // DCS Customizations
+		&repo_model.Door43Checker{OwnerID: u.ID},     // DCS Customizations
+		&repo_model.Door43Checker{CheckerID: u.ID},   // DCS Customizations
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %w", err)
 	}
@@ -98,6 +102,20 @@ func deleteUser(ctx context.Context, u *user_model.User, purge bool) (err error)
 		return err
 	}
 
+	/*** DCS Customizations ***/
+	// ***** START: Door43Collection *****
+	collections, err := repo_model.GetDoor43CollectionsByOwnerID(ctx, u.ID)
+	if err != nil {
+		return fmt.Errorf("GetDoor43CollectionsByOwnerID: %w", err)
+	}
+	for _, collection := range collections {
+		if err := door43metadata_service.DeleteCollection(ctx, collection); err != nil {
+			return fmt.Errorf("DeleteCollection: %w", err)
+		}
+	}
+	// ***** END: Door43Collection *****
+	/*** END DCS Customizations ***/
+
 	if purge || (setting.Service.UserDeleteWithCommentsMaxTime != 0 &&
 		u.CreatedUnix.AsTime().Add(setting.Service.UserDeleteWithCommentsMaxTime).After(time.Now())) {
 
@@ -172,6 +190,12 @@ func deleteUser(ctx context.Context, u *user_model.User, purge bool) (err error)
 	}
 	// ***** END: GPGPublicKey *****
 
+	/*** DCS Customizations ***/
+	if err = asymkey_model.DeleteDoor43SigningKey(ctx, u.ID); err != nil {
+		return fmt.Errorf("DeleteDoor43SigningKey: %w", err)
+	}
+	/*** END DCS Customizations ***/
+
 	// Clear assignee.
 	if _, err = db.DeleteByBean(ctx, &issues_model.IssueAssignees{AssigneeID: u.ID}); err != nil {
 		return fmt.Errorf("clear assignee: %w", err)
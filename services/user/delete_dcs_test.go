@@ -0,0 +1,57 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package user
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteUserDoor43Data(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 8})
+
+	collection := &repo_model.Door43Collection{OwnerID: user.ID, LowerName: "kit", Name: "kit", Title: "Kit"}
+	assert.NoError(t, db.Insert(db.DefaultContext, collection))
+	version := &repo_model.Door43CollectionVersion{CollectionID: collection.ID, LowerVersion: "v1", Version: "v1", PublisherID: user.ID}
+	assert.NoError(t, db.Insert(db.DefaultContext, version))
+	member := &repo_model.Door43CollectionMember{VersionID: version.ID, RepoID: 1, Ref: "v1.1", CommitSHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d"}
+	assert.NoError(t, db.Insert(db.DefaultContext, member))
+	archivePath := fmt.Sprintf("door43-collections/%d/%d-kit-v1.zip", collection.ID, version.ID)
+	_, err := storage.RepoArchives.Save(archivePath, strings.NewReader("zip"), 3)
+	assert.NoError(t, err)
+	assert.NoError(t, asymkey_model.InitInstanceDoor43SigningKey(db.DefaultContext))
+	_, err = asymkey_model.CreateDoor43SigningKey(db.DefaultContext, user.ID)
+	assert.NoError(t, err)
+
+	ownedChecker := &repo_model.Door43Checker{OwnerID: user.ID, CheckerID: 2, MaxLevel: 3}
+	assert.NoError(t, db.Insert(db.DefaultContext, ownedChecker))
+	designatedChecker := &repo_model.Door43Checker{OwnerID: 3, CheckerID: user.ID, MaxLevel: 3}
+	assert.NoError(t, db.Insert(db.DefaultContext, designatedChecker))
+
+	assert.NoError(t, DeleteUser(db.DefaultContext, user, false))
+
+	unittest.AssertNotExistsBean(t, &repo_model.Door43Collection{ID: collection.ID})
+	unittest.AssertNotExistsBean(t, &repo_model.Door43CollectionVersion{ID: version.ID})
+	unittest.AssertNotExistsBean(t, &repo_model.Door43CollectionMember{ID: member.ID})
+	unittest.AssertNotExistsBean(t, &repo_model.Door43Checker{ID: ownedChecker.ID})
+	unittest.AssertNotExistsBean(t, &repo_model.Door43Checker{ID: designatedChecker.ID})
+	_, err = storage.RepoArchives.Stat(archivePath)
+	assert.Error(t, err)
+	_, err = asymkey_model.GetDoor43SigningKey(db.DefaultContext, user.ID)
+	assert.True(t, asymkey_model.IsErrDoor43SigningKeyNotExist(err))
+	// but not the instance key
+	_, err = asymkey_model.GetDoor43SigningKey(db.DefaultContext, 0)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,156 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/door43metadata"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// prepareCatalogEntries adds catalog entries for the releases of user2/repo1 and of user2/repo-release,
+// which is renamed to es-419_tn so its language can be matched by its name
+func prepareCatalogEntries(t *testing.T) (dm1, dm57 *repo_model.Door43Metadata) {
+	repo57 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 57})
+	repo57.Name, repo57.LowerName = "es-419_tn", "es-419_tn"
+	assert.NoError(t, repo_model.UpdateRepositoryCols(db.DefaultContext, repo57, "name", "lower_name"))
+
+	dm1 = &repo_model.Door43Metadata{
+		RepoID:          1,
+		ReleaseID:       1,
+		Ref:             "v1.1",
+		RefType:         "tag",
+		CommitSHA:       "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		Stage:           door43metadata.StageProd,
+		MetadataType:    "rc",
+		MetadataVersion: "0.2",
+		Subject:         "Bible",
+		Title:           "Repo1 Bible",
+		Language:        "en",
+		Ingredients:     []*api.Ingredient{{Identifier: "GEN", Path: "./01-GEN.usfm"}, {Identifier: "exo", Path: "./02-EXO.usfm"}},
+		Metadata: &map[string]any{
+			"dublin_core": map[string]any{
+				"publisher":   "unfoldingWord",
+				"rights":      "CC BY-SA 4.0",
+				"contributor": []any{"Alice", "Bob"},
+				"version":     3,
+			},
+		},
+		ReleaseDateUnix:  timeutil.TimeStampNow(),
+		IsLatestForStage: true,
+	}
+	assert.NoError(t, repo_model.InsertDoor43Metadata(db.DefaultContext, dm1))
+	dm57 = &repo_model.Door43Metadata{
+		RepoID:          57,
+		ReleaseID:       8,
+		Ref:             "v2.0",
+		RefType:         "tag",
+		CommitSHA:       "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		Stage:           door43metadata.StageProd,
+		MetadataType:    "rc",
+		MetadataVersion: "0.2",
+		Subject:         "Translation Notes",
+		Title:           "Translation Notes",
+		Language:        "fr",
+		Ingredients:     []*api.Ingredient{{Identifier: "mat", Path: "./tn_MAT.tsv"}},
+		Metadata: &map[string]any{
+			"dublin_core": map[string]any{
+				"publisher": "Door43 World Missions Community",
+				"rights":    "CC BY-SA 4.0",
+			},
+		},
+		ReleaseDateUnix:  timeutil.TimeStampNow(),
+		IsLatestForStage: true,
+	}
+	assert.NoError(t, repo_model.InsertDoor43Metadata(db.DefaultContext, dm57))
+	return dm1, dm57
+}
+
+func searchCatalogEntryIDs(t *testing.T, query string) []int64 {
+	t.Helper()
+	resp := MakeRequest(t, NewRequest(t, "GET", "/api/v1/catalog/search?"+query), http.StatusOK)
+	var results api.CatalogSearchResults
+	DecodeJSON(t, resp, &results)
+	ids := make([]int64, 0, len(results.Data))
+	for _, entry := range results.Data {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+func TestAPICatalogSearch(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	dm1, dm57 := prepareCatalogEntries(t)
+
+	unittest.AssertCount(t, &repo_model.Door43MetadataIngredient{Door43MetadataID: dm1.ID}, 2)
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Door43MetadataIngredient{Door43MetadataID: dm1.ID, Identifier: "gen"})
+
+	t.Run("Book", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		assert.Equal(t, []int64{dm1.ID}, searchCatalogEntryIDs(t, "book=gen"))
+		assert.ElementsMatch(t, []int64{dm1.ID, dm57.ID}, searchCatalogEntryIDs(t, "book=MAT,exo"))
+		assert.Empty(t, searchCatalogEntryIDs(t, "book=lev"))
+	})
+
+	t.Run("Language", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		assert.Equal(t, []int64{dm1.ID}, searchCatalogEntryIDs(t, "lang=en"))
+		assert.Equal(t, []int64{dm57.ID}, searchCatalogEntryIDs(t, "lang=fr"))
+		// matched by the language code the repo's name starts with
+		assert.Equal(t, []int64{dm57.ID}, searchCatalogEntryIDs(t, "lang=es-419"))
+		assert.Empty(t, searchCatalogEntryIDs(t, "lang=es"))
+		assert.Equal(t, []int64{dm57.ID}, searchCatalogEntryIDs(t, "lang=419&partialMatch=true"))
+	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		assert.Equal(t, []int64{dm1.ID}, searchCatalogEntryIDs(t, "metadata.dublin_core.publisher=unfoldingWord"))
+		assert.ElementsMatch(t, []int64{dm1.ID, dm57.ID}, searchCatalogEntryIDs(t, "metadata.dublin_core.rights=CC+BY-SA+4.0"))
+		// an element of an array, a number and several values
+		assert.Equal(t, []int64{dm1.ID}, searchCatalogEntryIDs(t, "metadata.dublin_core.contributor=Bob"))
+		assert.Equal(t, []int64{dm1.ID}, searchCatalogEntryIDs(t, "metadata.dublin_core.version=3"))
+		assert.ElementsMatch(t, []int64{dm1.ID, dm57.ID}, searchCatalogEntryIDs(t, "metadata.dublin_core.publisher=unfoldingWord&metadata.dublin_core.publisher=Door43+World+Missions+Community"))
+		// all the paths must match
+		assert.Empty(t, searchCatalogEntryIDs(t, "metadata.dublin_core.publisher=unfoldingWord&metadata.dublin_core.contributor=Carol"))
+		assert.Empty(t, searchCatalogEntryIDs(t, "metadata.dublin_core.publisher=unfolding"))
+		assert.Equal(t, []int64{dm57.ID}, searchCatalogEntryIDs(t, "metadata.dublin_core.publisher=Missions&partialMatch=true"))
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/catalog/search?metadata.dublin_core.publisher'=x"), http.StatusUnprocessableEntity)
+	})
+
+	t.Run("BookFacet", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		resp := MakeRequest(t, NewRequest(t, "GET", "/api/v1/catalog/search?facets=book,lang"), http.StatusOK)
+		var results api.CatalogSearchResults
+		DecodeJSON(t, resp, &results)
+		assert.Equal(t, []*api.CatalogFacetCount{{Value: "exo", Count: 1}, {Value: "gen", Count: 1}, {Value: "mat", Count: 1}}, results.Facets["book"])
+		assert.Equal(t, []*api.CatalogFacetCount{{Value: "en", Count: 1}, {Value: "fr", Count: 1}}, results.Facets["lang"])
+	})
+
+	t.Run("UpdateIngredients", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		dm1.Ingredients = []*api.Ingredient{{Identifier: "lev", Path: "./03-LEV.usfm"}}
+		assert.NoError(t, repo_model.UpdateDoor43Metadata(db.DefaultContext, dm1))
+		assert.Empty(t, searchCatalogEntryIDs(t, "book=gen"))
+		assert.Equal(t, []int64{dm1.ID}, searchCatalogEntryIDs(t, "book=lev"))
+	})
+
+	t.Run("DeleteEntries", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		assert.NoError(t, repo_model.DeleteDoor43Metadata(db.DefaultContext, dm1))
+		unittest.AssertNotExistsBean(t, &repo_model.Door43MetadataIngredient{Door43MetadataID: dm1.ID})
+		_, err := repo_model.DeleteAllDoor43MetadatasByRepoID(db.DefaultContext, 57)
+		assert.NoError(t, err)
+		unittest.AssertNotExistsBean(t, &repo_model.Door43MetadataIngredient{RepoID: 57})
+		assert.Empty(t, searchCatalogEntryIDs(t, "book=mat"))
+	})
+}